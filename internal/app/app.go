@@ -3,8 +3,11 @@ package app
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
@@ -12,26 +15,82 @@ import (
 	"github.com/jeircul/pim/internal/state"
 )
 
+// debugLogFile is the file in the config directory the TUI's --debug log
+// is appended to.
+const debugLogFile = "debug.log"
+
 // App wires together the azure client, state store, and execution mode.
 type App struct {
 	Client  *azure.Client
 	Store   *state.Store
 	Config  Config
 	Version string
+
+	cache   *state.ResponseCache
+	closers []func() // run by Close, newest first
 }
 
 // New creates an App from the given config. Does not authenticate yet.
+// With --demo and no --config-dir, state lives in a temporary directory
+// removed by Close.
 func New(cfg Config, version string) (*App, error) {
-	store, err := state.New(cfg.ConfigDir)
+	a := &App{Config: cfg, Version: version}
+	dir := cfg.ConfigDir
+	if cfg.Demo && dir == "" {
+		var err error
+		if dir, err = a.demoConfigDir(); err != nil {
+			return nil, err
+		}
+	}
+	store, err := state.New(dir)
 	if err != nil {
+		a.Close()
 		return nil, fmt.Errorf("open state store: %w", err)
 	}
-	return &App{Store: store, Config: cfg, Version: version}, nil
+	a.Store = store
+	return a, nil
+}
+
+// Close releases what the app started: the --demo server and its config
+// directory. It is safe to call more than once.
+func (a *App) Close() {
+	for i := len(a.closers) - 1; i >= 0; i-- {
+		a.closers[i]()
+	}
+	a.closers = nil
 }
 
-// Connect creates the Azure client and validates credentials.
+// Connect creates the Azure client and validates credentials. With --demo
+// the client talks to an in-process fake instead; see connectDemo.
 func (a *App) Connect(_ context.Context) error {
-	client, err := azure.NewClient()
+	var err error
+	if a.cache, err = a.Store.ResponseCache(); err != nil {
+		return fmt.Errorf("config.toml: %w", err)
+	}
+	debugLog, err := a.debugLog()
+	if err != nil {
+		return err
+	}
+	if a.Config.Demo {
+		return a.connectDemo(debugLog)
+	}
+	cl, err := a.resolveCloud()
+	if err != nil {
+		return err
+	}
+	sources, err := a.resolveSources()
+	if err != nil {
+		return err
+	}
+	retry, err := a.Store.RetryPolicy()
+	if err != nil {
+		return fmt.Errorf("config.toml: %w", err)
+	}
+	network, err := a.resolveNetwork()
+	if err != nil {
+		return err
+	}
+	client, err := azure.NewClient(azure.ClientOptions{Cloud: cl, TenantID: a.Config.Tenant, Sources: sources, Retry: retry, Network: network, DebugLog: debugLog})
 	if err != nil {
 		return err
 	}
@@ -39,12 +98,128 @@ func (a *App) Connect(_ context.Context) error {
 	return nil
 }
 
-// DefaultContext returns a context that cancels on SIGINT/SIGTERM or after 2 minutes.
-func DefaultContext() (context.Context, context.CancelFunc) {
-	sigCtx, sigCancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
-	timeCtx, timeCancel := context.WithTimeout(sigCtx, 2*time.Minute)
+// debugLog returns where --debug writes the HTTP log, or nil without it. The
+// TUI owns the terminal, so it logs to debug.log in the config directory.
+func (a *App) debugLog() (io.Writer, error) {
+	switch {
+	case !a.Config.Debug:
+		return nil, nil
+	case !a.Config.RunsTUI():
+		return os.Stderr, nil
+	}
+	path := filepath.Join(a.Store.Dir(), debugLogFile)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open debug log: %w", err)
+	}
+	a.closers = append(a.closers, func() { _ = f.Close() })
+	return f, nil
+}
+
+// TenantID returns the tenant Client targets, which the TUI and headless
+// commands key tenant-scoped state by; before Connect it is --tenant.
+func (a *App) TenantID() string {
+	if a.Client != nil {
+		return a.Client.TenantID()
+	}
+	return a.Config.Tenant
+}
+
+// SwitchTenant points Client at tenantID, reusing cached credentials when the
+// tenant was used before. An empty tenantID returns to the startup tenant.
+func (a *App) SwitchTenant(tenantID string) error {
+	client, err := a.Client.ForTenant(tenantID)
+	if err != nil {
+		return fmt.Errorf("switch tenant: %w", err)
+	}
+	a.Client = client
+	return nil
+}
+
+// resolveCloud picks the cloud from --cloud, then PIM_CLOUD, then
+// [preferences] cloud. Endpoint overrides come from PIM_AUTHORITY_HOST,
+// PIM_ARM_ENDPOINT and PIM_GRAPH_ENDPOINT, falling back to config.toml.
+func (a *App) resolveCloud() (azure.Cloud, error) {
+	prefName, overrides := a.Store.CloudPreference()
+	name := firstNonEmpty(a.Config.Cloud, os.Getenv("PIM_CLOUD"), prefName)
+	overrides.AuthorityHost = firstNonEmpty(os.Getenv("PIM_AUTHORITY_HOST"), overrides.AuthorityHost)
+	overrides.ARMEndpoint = firstNonEmpty(os.Getenv("PIM_ARM_ENDPOINT"), overrides.ARMEndpoint)
+	overrides.GraphEndpoint = firstNonEmpty(os.Getenv("PIM_GRAPH_ENDPOINT"), overrides.GraphEndpoint)
+	return azure.LookupCloud(name, overrides)
+}
+
+// resolveNetwork reads [network] from config.toml and overrides it with
+// PIM_PROXY, PIM_NO_PROXY (comma-separated), PIM_CA_BUNDLE and
+// PIM_HTTP_TIMEOUT.
+func (a *App) resolveNetwork() (azure.NetworkConfig, error) {
+	n, err := a.Store.Network()
+	if err != nil {
+		return n, fmt.Errorf("config.toml: %w", err)
+	}
+	n.Proxy = firstNonEmpty(os.Getenv("PIM_PROXY"), n.Proxy)
+	n.CABundle = firstNonEmpty(os.Getenv("PIM_CA_BUNDLE"), n.CABundle)
+	if v := os.Getenv("PIM_NO_PROXY"); v != "" {
+		n.NoProxy = strings.Split(v, ",")
+	}
+	if v := os.Getenv("PIM_HTTP_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return n, fmt.Errorf("invalid PIM_HTTP_TIMEOUT %q: must be a positive duration such as 2m", v)
+		}
+		n.Timeout = d
+	}
+	return n, nil
+}
+
+// resolveSources picks the credential sources from --auth, then [auth]
+// sources in config.toml. Nil selects the default chain.
+func (a *App) resolveSources() ([]string, error) {
+	if len(a.Config.Auth) > 0 {
+		return a.Config.Auth, nil
+	}
+	sources, err := azure.ParseCredentialSources(strings.Join(a.Store.AuthSources(), ","))
+	if err != nil {
+		return nil, fmt.Errorf("config.toml [auth]: %w", err)
+	}
+	return sources, nil
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// DefaultTimeout bounds a command that does not wait for provisioning.
+const DefaultTimeout = 2 * time.Minute
+
+// DefaultContext returns a context that cancels on SIGINT/SIGTERM or after
+// cfg's command timeout; see commandTimeout.
+func DefaultContext(cfg Config) (context.Context, context.CancelFunc) {
+	return commandContext(context.Background(), cfg, DefaultTimeout)
+}
+
+func commandContext(parent context.Context, cfg Config, base time.Duration) (context.Context, context.CancelFunc) {
+	sigCtx, sigCancel := signal.NotifyContext(parent, os.Interrupt, syscall.SIGTERM)
+	timeCtx, timeCancel := context.WithTimeout(sigCtx, commandTimeout(cfg, base))
 	return timeCtx, func() {
 		timeCancel()
 		sigCancel()
 	}
 }
+
+// commandTimeout is base, plus the wait timeout with --wait so that waiting
+// for provisioning is bounded by --wait-timeout rather than by base.
+func commandTimeout(cfg Config, base time.Duration) time.Duration {
+	if !cfg.Wait {
+		return base
+	}
+	wait := cfg.WaitTimeout
+	if wait <= 0 {
+		wait = DefaultWaitTimeout
+	}
+	return base + wait
+}
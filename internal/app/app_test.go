@@ -0,0 +1,65 @@
+package app
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCommandContextHonoursWait(t *testing.T) {
+	const base = 20 * time.Millisecond
+
+	ctx, cancel := commandContext(t.Context(), Config{}, base)
+	defer cancel()
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("context without --wait outlived the base timeout")
+	}
+
+	// A wait longer than the base timeout must not be cut short by it.
+	cfg := Config{Wait: true, WaitTimeout: 10 * base}
+	ctx, cancel = commandContext(t.Context(), cfg, base)
+	defer cancel()
+	time.Sleep(3 * base)
+	if err := ctx.Err(); err != nil {
+		t.Fatalf("context with --wait-timeout %s ended after %s: %v", cfg.WaitTimeout, 3*base, err)
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok || time.Until(deadline) < 5*base {
+		t.Errorf("deadline %v leaves less than the wait timeout", deadline)
+	}
+
+	if got := commandTimeout(Config{Wait: true, WaitTimeout: 10 * time.Minute}, DefaultTimeout); got != 12*time.Minute {
+		t.Errorf("commandTimeout(--wait-timeout 10m) = %s, want 12m", got)
+	}
+	if got := commandTimeout(Config{Wait: true}, DefaultTimeout); got != DefaultTimeout+DefaultWaitTimeout {
+		t.Errorf("commandTimeout(--wait) = %s, want %s", got, DefaultTimeout+DefaultWaitTimeout)
+	}
+}
+
+func TestSwitchTenant(t *testing.T) {
+	a, err := New(Config{Demo: true, ConfigDir: t.TempDir()}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	if err := a.Connect(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	home := a.Client
+
+	if err := a.SwitchTenant("t-b"); err != nil {
+		t.Fatal(err)
+	}
+	// The TUI hands the cached client's tenant to the activation wizard,
+	// which records it on recent activations.
+	if got := a.Cached(a.Client, "p-1").TenantID(); got != "t-b" || a.TenantID() != "t-b" {
+		t.Errorf("tenant after switch = %q (cached %q), want t-b", a.TenantID(), got)
+	}
+	if err := a.SwitchTenant(""); err != nil {
+		t.Fatal(err)
+	}
+	if a.Client != home {
+		t.Error("SwitchTenant(\"\") did not return to the startup client")
+	}
+}
@@ -0,0 +1,162 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/jeircul/pim/internal/azure"
+	"github.com/jeircul/pim/internal/state"
+)
+
+// CachedClient is an azure.Client whose eligible roles, active assignments
+// and management group subscription lists are served from the on-disk
+// response cache of one tenant and principal while fresh. Activations,
+// deactivations and renewals invalidate the entries they change. Cache write
+// failures are ignored; the cache only ever saves calls.
+type CachedClient struct {
+	*azure.Client
+	cache     *state.ResponseCache // nil when caching is off
+	tenant    string
+	principal string
+	refresh   bool // skip cached entries but store fresh responses
+	// liveAssignments skips cached active assignments but still stores
+	// fresh ones; set outside the TUI, where commands act on them at once.
+	liveAssignments bool
+}
+
+// mgSubscriptions is the cached result of ListAllSubscriptionsUnderMG.
+type mgSubscriptions struct {
+	Subscriptions []azure.Subscription
+	Parents       map[string]string
+	Warnings      []string
+}
+
+// Cached wraps client for principalID, honouring --no-cache and --refresh.
+// An empty principalID disables caching, as entries are keyed by it. Only
+// the TUI reads cached active assignments: a script that deactivates or
+// checks status right after a change must see the current ones.
+func (a *App) Cached(client *azure.Client, principalID string) *CachedClient {
+	c := &CachedClient{Client: client, tenant: client.TenantID(), principal: principalID, refresh: a.Config.Refresh,
+		liveAssignments: !a.Config.RunsTUI()}
+	if !a.Config.NoCache && principalID != "" && a.cache.Enabled() {
+		c.cache = a.cache
+	}
+	return c
+}
+
+// GetEligibleRoles returns the cached eligible roles, fetching them when the
+// entry is missing or expired.
+func (c *CachedClient) GetEligibleRoles(ctx context.Context) ([]azure.Role, error) {
+	return cachedFetch(c, state.CacheEligibleRoles, func() ([]azure.Role, error) {
+		return c.Client.GetEligibleRoles(ctx)
+	})
+}
+
+// GetActiveAssignments returns the cached active assignments, fetching them
+// when the entry is missing or expired, or always outside the TUI.
+// Assignments that ended since they were cached are dropped.
+func (c *CachedClient) GetActiveAssignments(ctx context.Context) ([]azure.ActiveAssignment, error) {
+	src := c
+	if c.liveAssignments {
+		fresh := *c
+		fresh.refresh = true
+		src = &fresh
+	}
+	active, err := cachedFetch(src, state.CacheActiveAssignments, func() ([]azure.ActiveAssignment, error) {
+		return c.Client.GetActiveAssignments(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := active[:0:0]
+	for _, a := range active {
+		if end, err := time.Parse(time.RFC3339, a.EndDateTime); err == nil && !end.After(time.Now()) {
+			continue
+		}
+		out = append(out, a)
+	}
+	return out, nil
+}
+
+// ListAllSubscriptionsUnderMG returns the cached subscriptions under mgID,
+// walking the management group tree when the entry is missing or expired.
+func (c *CachedClient) ListAllSubscriptionsUnderMG(ctx context.Context, mgID string) ([]azure.Subscription, map[string]string, []string, error) {
+	res, err := cachedFetch(c, state.CacheMGSubscriptions(mgID), func() (mgSubscriptions, error) {
+		subs, parents, warnings, err := c.Client.ListAllSubscriptionsUnderMG(ctx, mgID)
+		return mgSubscriptions{Subscriptions: subs, Parents: parents, Warnings: warnings}, err
+	})
+	return res.Subscriptions, res.Parents, res.Warnings, err
+}
+
+// ActivateRole submits the activation and invalidates the cached active
+// assignments.
+func (c *CachedClient) ActivateRole(ctx context.Context, req azure.ActivationRequest) (*azure.ScheduleResponse, error) {
+	defer c.invalidate(state.CacheActiveAssignments)
+	return c.Client.ActivateRole(ctx, req)
+}
+
+// WaitForActivation waits for provisioning and invalidates the cached active
+// assignments again, in case they were re-read before the role was active.
+func (c *CachedClient) WaitForActivation(ctx context.Context, req azure.ActivationRequest, resp *azure.ScheduleResponse, onStatus func(string)) error {
+	defer c.invalidate(state.CacheActiveAssignments)
+	return c.Client.WaitForActivation(ctx, req, resp, onStatus)
+}
+
+// DeactivateRole submits the deactivation and invalidates the cached active
+// assignments.
+func (c *CachedClient) DeactivateRole(ctx context.Context, assignment azure.ActiveAssignment, principalID string) (*azure.ScheduleResponse, error) {
+	defer c.invalidate(state.CacheActiveAssignments)
+	return c.Client.DeactivateRole(ctx, assignment, principalID)
+}
+
+// RenewEligibility submits the renewal and invalidates the cached eligible
+// roles, whose end dates it changes.
+func (c *CachedClient) RenewEligibility(ctx context.Context, req azure.RenewalRequest) (*azure.ScheduleResponse, error) {
+	defer c.invalidate(state.CacheEligibleRoles)
+	return c.Client.RenewEligibility(ctx, req)
+}
+
+// Revalidate refetches the cached eligible roles and active assignments and
+// stores them, so the next read is served fresh from the cache. Keys that
+// were never cached are left to the first read.
+func (c *CachedClient) Revalidate(ctx context.Context) error {
+	if c.cache == nil {
+		return nil
+	}
+	fresh := *c
+	fresh.refresh = true
+	if c.cache.Has(c.tenant, c.principal, state.CacheActiveAssignments) {
+		if _, err := fresh.GetActiveAssignments(ctx); err != nil {
+			return err
+		}
+	}
+	if c.cache.Has(c.tenant, c.principal, state.CacheEligibleRoles) {
+		if _, err := fresh.GetEligibleRoles(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *CachedClient) invalidate(key string) {
+	if c.cache != nil {
+		_ = c.cache.Invalidate(c.tenant, c.principal, key)
+	}
+}
+
+// cachedFetch returns the cached entry for key, or calls fetch and caches
+// its result.
+func cachedFetch[T any](c *CachedClient, key string, fetch func() (T, error)) (T, error) {
+	var v T
+	if c.cache != nil && !c.refresh && c.cache.Get(c.tenant, c.principal, key, &v) {
+		return v, nil
+	}
+	v, err := fetch()
+	if err != nil {
+		return v, err
+	}
+	if c.cache != nil {
+		_ = c.cache.Put(c.tenant, c.principal, key, v)
+	}
+	return v, nil
+}
@@ -0,0 +1,109 @@
+package app
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jeircul/pim/internal/azure"
+	"github.com/jeircul/pim/internal/state"
+)
+
+func TestCachedFetch(t *testing.T) {
+	s, err := state.New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &App{Store: s}
+	if a.cache, err = s.ResponseCache(); err != nil {
+		t.Fatal(err)
+	}
+	client := &azure.Client{}
+
+	calls := 0
+	fetch := func() ([]string, error) {
+		calls++
+		return []string{"Owner"}, nil
+	}
+
+	c := a.Cached(client, "p-1")
+	for range 2 {
+		if got, err := cachedFetch(c, state.CacheEligibleRoles, fetch); err != nil || len(got) != 1 {
+			t.Fatalf("cachedFetch = %v, %v", got, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("fetch called %d times, want 1 (second read cached)", calls)
+	}
+
+	c.invalidate(state.CacheEligibleRoles)
+	if _, err := cachedFetch(c, state.CacheEligibleRoles, fetch); err != nil || calls != 2 {
+		t.Errorf("after invalidate: calls = %d, err = %v; want a fetch", calls, err)
+	}
+
+	a.Config.Refresh = true
+	if _, err := cachedFetch(a.Cached(client, "p-1"), state.CacheEligibleRoles, fetch); err != nil || calls != 3 {
+		t.Errorf("--refresh: calls = %d, err = %v; want a fetch", calls, err)
+	}
+	a.Config.Refresh = false
+	if _, err := cachedFetch(a.Cached(client, "p-1"), state.CacheEligibleRoles, fetch); err != nil || calls != 3 {
+		t.Errorf("after --refresh: calls = %d, want the refreshed entry served", calls)
+	}
+
+	a.Config.NoCache = true
+	if a.Cached(client, "p-1").cache != nil {
+		t.Error("--no-cache left the cache enabled")
+	}
+	a.Config.NoCache = false
+	if a.Cached(client, "").cache != nil {
+		t.Error("cache enabled without a principal")
+	}
+
+	failing := func() ([]string, error) { return nil, errors.New("boom") }
+	if _, err := cachedFetch(a.Cached(client, "p-2"), state.CacheEligibleRoles, failing); err == nil {
+		t.Fatal("expected fetch error")
+	}
+	if rc, _ := a.Store.ResponseCache(); rc.Has("", "p-2", state.CacheEligibleRoles) {
+		t.Error("failed fetch was cached")
+	}
+}
+
+func TestCachedActiveAssignmentsLiveOutsideTUI(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		cfg       Config
+		wantStale bool
+	}{
+		{"tui", Config{}, true},
+		{"headless status", Config{Command: CmdStatus, Headless: true}, false},
+		{"headless deactivate", Config{Command: CmdDeactivate, Headless: true}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.cfg.Demo, tc.cfg.ConfigDir = true, t.TempDir()
+			a, err := New(tc.cfg, "test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer a.Close()
+			if err := a.Connect(t.Context()); err != nil {
+				t.Fatal(err)
+			}
+			user, err := a.Client.GetCurrentUser(t.Context())
+			if err != nil {
+				t.Fatal(err)
+			}
+			stale := []azure.ActiveAssignment{{RoleName: "Stale", Scope: "/subscriptions/gone", EndDateTime: "2099-01-01T00:00:00Z"}}
+			if err := a.cache.Put(a.Client.TenantID(), user.ID, state.CacheActiveAssignments, stale); err != nil {
+				t.Fatal(err)
+			}
+
+			active, err := a.Cached(a.Client, user.ID).GetActiveAssignments(t.Context())
+			if err != nil {
+				t.Fatal(err)
+			}
+			gotStale := len(active) == 1 && active[0].RoleName == "Stale"
+			if gotStale != tc.wantStale {
+				t.Errorf("served the cached entry = %v, want %v (got %+v)", gotStale, tc.wantStale, active)
+			}
+		})
+	}
+}
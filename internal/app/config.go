@@ -4,7 +4,11 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/jeircul/pim/internal/azure"
 )
 
 // Command names.
@@ -14,8 +18,26 @@ const (
 	CmdStatus     = "status"
 	CmdCompletion = "completion"
 	CmdSearch     = "search"
+	CmdRequests   = "requests"
+	CmdRenew      = "renew"
+	CmdHistory    = "history"
+	CmdRole       = "role"
+	CmdCache      = "cache"
+	CmdWhoami     = "whoami"
+	CmdDoctor     = "doctor"
 )
 
+// DefaultWaitTimeout bounds how long activation waits for provisioning.
+const DefaultWaitTimeout = 5 * time.Minute
+
+// DefaultParallel is how many targets headless activate and deactivate
+// submit at once without --parallel, and how many requests --cancel and
+// renew submit at once.
+const DefaultParallel = 4
+
+// DefaultHistoryWindow is how far back pim history looks without --since.
+const DefaultHistoryWindow = 30 * 24 * time.Hour
+
 // OutputFormat controls headless output style.
 type OutputFormat string
 
@@ -23,11 +45,12 @@ const (
 	OutputTable OutputFormat = "table"
 	OutputJSON  OutputFormat = "json"
 	OutputTOML  OutputFormat = "toml"
+	OutputCSV   OutputFormat = "csv"
 )
 
 // Config holds all parsed CLI configuration.
 type Config struct {
-	// Command is the subcommand (activate, deactivate, status, completion, or "" for TUI dashboard).
+	// Command is the subcommand (activate, deactivate, status, requests, renew, history, role, whoami, doctor, cache, completion, or "" for TUI dashboard).
 	Command string
 
 	// TUI mode flags
@@ -39,7 +62,32 @@ type Config struct {
 	Scopes        []string
 	TimeStr       string
 	Justification string
-	Yes           bool
+	Ticket        string
+	TicketSystem  string
+	// StartStr schedules the activation (--at / --start); empty means now.
+	StartStr string
+	Yes      bool
+
+	// RenewDays is the eligibility length requested by pim renew; zero uses
+	// azure.DefaultRenewalDays.
+	RenewDays int
+
+	// Since and Until bound pim history by request creation time; Until is
+	// exclusive and zero means now.
+	Since time.Time
+	Until time.Time
+
+	// Cancel cancels the matching outstanding requests (pim requests only).
+	Cancel bool
+
+	// Wait blocks headless activate until each assignment is provisioned, for
+	// at most WaitTimeout. The TUI always waits, bounded by WaitTimeout.
+	Wait        bool
+	WaitTimeout time.Duration
+
+	// Parallel bounds how many targets headless activate, deactivate,
+	// requests --cancel and renew submit concurrently.
+	Parallel int
 
 	// Output
 	Output OutputFormat
@@ -47,19 +95,45 @@ type Config struct {
 	// Config dir override (empty = default ~/.config/pim)
 	ConfigDir string
 
+	// Cloud selects the Azure cloud (--cloud); empty defers to PIM_CLOUD and config.toml.
+	Cloud string
+
+	// Tenant is the tenant ID to sign in to (--tenant); empty defers to AZURE_TENANT_ID.
+	Tenant string
+
+	// NoCache bypasses the on-disk response cache (--no-cache); Refresh
+	// ignores cached entries but stores the fresh responses (--refresh).
+	NoCache bool
+	Refresh bool
+
+	// Demo runs against an in-process fake Azure seeded with the built-in
+	// demo fixture (--demo, not listed in help); see internal/azure/fake.
+	Demo bool
+
+	// Debug logs every HTTP request (--debug or PIM_DEBUG): to stderr
+	// outside the TUI, to debug.log in the config directory under it.
+	Debug bool
+
+	// Auth lists credential sources to try in order (--auth, comma-separated);
+	// empty defers to [auth] sources in config.toml, then the default chain.
+	Auth []string
+
 	// CompletionShell is set when Command == CmdCompletion (bash | zsh | fish).
 	CompletionShell string
 
 	// SearchQuery is the optional filter passed to pim search.
 	SearchQuery string
 
+	// RoleQuery is the role name passed to pim role show.
+	RoleQuery string
+
 	// MGFilter limits pim search to a specific management group (exact name or substring).
 	MGFilter string
 }
 
 // Parse parses os.Args[1:] into a Config.
 func Parse(args []string) (Config, error) {
-	cfg := Config{Output: OutputTable}
+	cfg := Config{Output: OutputTable, WaitTimeout: DefaultWaitTimeout, Parallel: DefaultParallel}
 
 	if len(args) == 0 {
 		return cfg, nil
@@ -85,6 +159,33 @@ func Parse(args []string) (Config, error) {
 	case CmdSearch:
 		cfg.Command = CmdSearch
 		args = args[1:]
+	case CmdRequests, "req":
+		cfg.Command = CmdRequests
+		args = args[1:]
+	case CmdRenew:
+		cfg.Command = CmdRenew
+		args = args[1:]
+	case CmdHistory, "hist":
+		cfg.Command = CmdHistory
+		args = args[1:]
+	case CmdRole:
+		if len(args) < 2 || strings.ToLower(args[1]) != "show" {
+			return cfg, fmt.Errorf("role: expected 'pim role show <name>'")
+		}
+		cfg.Command = CmdRole
+		args = args[2:]
+	case CmdWhoami:
+		cfg.Command = CmdWhoami
+		args = args[1:]
+	case CmdDoctor:
+		cfg.Command = CmdDoctor
+		args = args[1:]
+	case CmdCache:
+		if len(args) < 2 || strings.ToLower(args[1]) != "clear" {
+			return cfg, fmt.Errorf("cache: expected 'pim cache clear'")
+		}
+		cfg.Command = CmdCache
+		args = args[2:]
 	case "version", "v":
 		cfg.Version = true
 		return cfg, nil
@@ -102,14 +203,34 @@ func Parse(args []string) (Config, error) {
 	fs.StringVar(&cfg.TimeStr, "t", "", "activation duration (shorthand)")
 	fs.StringVar(&cfg.Justification, "justification", "", "justification text")
 	fs.StringVar(&cfg.Justification, "j", "", "justification (shorthand)")
+	fs.StringVar(&cfg.Ticket, "ticket", "", "ticket number for policies that require one")
+	fs.StringVar(&cfg.TicketSystem, "ticket-system", "", "ticket system name (e.g. ServiceNow)")
+	fs.StringVar(&cfg.StartStr, "at", "", "schedule activation start (e.g. +3h, 22:00, 2006-01-02 15:04)")
+	fs.StringVar(&cfg.StartStr, "start", "", "schedule activation start (alias of --at)")
 	fs.BoolVar(&cfg.Yes, "yes", false, "skip confirmation prompt")
 	fs.BoolVar(&cfg.Yes, "y", false, "skip confirmation (shorthand)")
-	fs.BoolVar(&cfg.Headless, "headless", false, "non-TUI mode; exit with code 0/1")
+	fs.BoolVar(&cfg.Headless, "headless", false, "non-TUI mode; see the README for exit codes")
+	var sinceStr, untilStr string
+	fs.StringVar(&sinceStr, "since", "", "history start: 7d, 24h, 2006-01-02, or RFC 3339 (default 30d)")
+	fs.StringVar(&untilStr, "until", "", "history end (exclusive; a date includes that day)")
+	fs.IntVar(&cfg.RenewDays, "days", 0, "requested eligibility length in days (renew only)")
+	fs.BoolVar(&cfg.Cancel, "cancel", false, "cancel matching outstanding requests (requests only)")
+	fs.BoolVar(&cfg.Wait, "wait", false, "wait until activated roles are provisioned (activate only)")
+	fs.DurationVar(&cfg.WaitTimeout, "wait-timeout", DefaultWaitTimeout, "maximum time to wait for provisioning (e.g. 2m)")
+	fs.IntVar(&cfg.Parallel, "parallel", DefaultParallel, "targets to activate, deactivate, cancel or renew at once (headless)")
 
 	var outStr string
-	fs.StringVar(&outStr, "output", "table", "output format: table | json | toml")
+	fs.StringVar(&outStr, "output", "table", "output format: table | json | toml | csv")
 	fs.StringVar(&outStr, "o", "table", "output format (shorthand)")
 	fs.StringVar(&cfg.ConfigDir, "config-dir", "", "override config directory")
+	fs.StringVar(&cfg.Cloud, "cloud", "", "Azure cloud: AzurePublic | AzureUSGovernment | AzureChina | Custom")
+	var authStr string
+	fs.StringVar(&authStr, "auth", "", "credential sources to try in order, comma-separated (e.g. azure-cli,device-code)")
+	fs.StringVar(&cfg.Tenant, "tenant", "", "tenant ID to sign in to (default AZURE_TENANT_ID)")
+	fs.BoolVar(&cfg.NoCache, "no-cache", false, "do not read or write the response cache")
+	fs.BoolVar(&cfg.Refresh, "refresh", false, "ignore cached responses and fetch fresh ones")
+	fs.BoolVar(&cfg.Demo, "demo", false, "use a fake offline tenant")
+	fs.BoolVar(&cfg.Debug, "debug", envEnabled("PIM_DEBUG"), "log every HTTP request (also PIM_DEBUG=1)")
 	fs.StringVar(&cfg.MGFilter, "mg", "", "filter by management group (matches eligibility scope or physical parent)")
 
 	remaining := args
@@ -121,6 +242,12 @@ func Parse(args []string) (Config, error) {
 		if len(rest) == 0 {
 			break
 		}
+		if cfg.Command == CmdRole {
+			// Role names contain spaces; accept them unquoted.
+			cfg.RoleQuery = strings.TrimSpace(cfg.RoleQuery + " " + rest[0])
+			remaining = rest[1:]
+			continue
+		}
 		if cfg.Command != CmdSearch {
 			return cfg, fmt.Errorf("unexpected argument: %q", rest[0])
 		}
@@ -131,6 +258,9 @@ func Parse(args []string) (Config, error) {
 		remaining = rest[1:]
 	}
 
+	parallelSet := false
+	fs.Visit(func(f *flag.Flag) { parallelSet = parallelSet || f.Name == "parallel" })
+
 	cfg.Roles = []string(roles)
 	cfg.Scopes = []string(scopes)
 
@@ -141,22 +271,155 @@ func Parse(args []string) (Config, error) {
 		cfg.Output = OutputJSON
 	case "toml":
 		cfg.Output = OutputTOML
+	case "csv":
+		cfg.Output = OutputCSV
 	default:
-		return cfg, fmt.Errorf("invalid --output %q: must be table, json, or toml", outStr)
+		return cfg, fmt.Errorf("invalid --output %q: must be table, json, toml, or csv", outStr)
+	}
+	if cfg.Output == OutputCSV && cfg.Command != CmdHistory {
+		return cfg, fmt.Errorf("--output csv is only valid for the history command")
 	}
 
-	if cfg.Command == CmdSearch && (len(cfg.Roles) > 0 || len(cfg.Scopes) > 0 || cfg.TimeStr != "" || cfg.Justification != "" || cfg.Yes) {
-		return cfg, fmt.Errorf("search: --role, --scope, --time, --justification, --yes are not valid for this command")
+	if (sinceStr != "" || untilStr != "") && cfg.Command != CmdHistory {
+		return cfg, fmt.Errorf("--since and --until are only valid for the history command")
+	}
+	if cfg.Command == CmdHistory {
+		if cfg.TimeStr != "" || cfg.Justification != "" || cfg.Ticket != "" || cfg.TicketSystem != "" || cfg.StartStr != "" || cfg.Yes {
+			return cfg, fmt.Errorf("history: --time, --justification, --ticket, --ticket-system, --at, --yes are not valid for this command")
+		}
+		// JSON and CSV are for export; there is nothing to render in the TUI.
+		// The history screen cycles fixed look-back windows and filters with
+		// its own box, so explicit bounds and filters are printed instead.
+		if cfg.Output != OutputTable || sinceStr != "" || untilStr != "" || len(cfg.Roles) > 0 || len(cfg.Scopes) > 0 {
+			cfg.Headless = true
+		}
+		var err error
+		now := time.Now()
+		cfg.Since = now.Add(-DefaultHistoryWindow)
+		if sinceStr != "" {
+			if cfg.Since, err = parseHistoryTime(sinceStr, now, false); err != nil {
+				return cfg, fmt.Errorf("invalid --since: %w", err)
+			}
+		}
+		if untilStr != "" {
+			if cfg.Until, err = parseHistoryTime(untilStr, now, true); err != nil {
+				return cfg, fmt.Errorf("invalid --until: %w", err)
+			}
+			if !cfg.Until.After(cfg.Since) {
+				return cfg, fmt.Errorf("--until %s is not after --since %s", cfg.Until.Format("2006-01-02 15:04"), cfg.Since.Format("2006-01-02 15:04"))
+			}
+		}
+	}
+
+	auth, err := azure.ParseCredentialSources(authStr)
+	if err != nil {
+		return cfg, fmt.Errorf("invalid --auth: %w", err)
+	}
+	cfg.Auth = auth
+
+	if cfg.Cancel && cfg.Command != CmdRequests {
+		return cfg, fmt.Errorf("--cancel is only valid for the requests command")
+	}
+	// The pending requests screen lists everything and cancels one request
+	// at a time; cancelling or filtering by flag runs headless.
+	if cfg.Command == CmdRequests && (cfg.Cancel || len(cfg.Roles) > 0 || len(cfg.Scopes) > 0) {
+		cfg.Headless = true
+	}
+
+	if cfg.RenewDays != 0 && cfg.Command != CmdRenew {
+		return cfg, fmt.Errorf("--days is only valid for the renew command")
+	}
+	if cfg.RenewDays < 0 {
+		return cfg, fmt.Errorf("invalid --days %d: must be positive", cfg.RenewDays)
+	}
+	if cfg.Command == CmdRenew && (cfg.TimeStr != "" || cfg.Ticket != "" || cfg.TicketSystem != "" || cfg.StartStr != "") {
+		return cfg, fmt.Errorf("renew: --time, --ticket, --ticket-system, --at are not valid for this command; use --days")
+	}
+
+	if cfg.Wait && cfg.Command != CmdActivate {
+		return cfg, fmt.Errorf("--wait is only valid for the activate command")
+	}
+	if cfg.Wait && cfg.StartStr != "" {
+		return cfg, fmt.Errorf("--wait cannot be combined with --at: a scheduled activation is not active until it starts")
+	}
+	if cfg.WaitTimeout <= 0 {
+		return cfg, fmt.Errorf("invalid --wait-timeout %s: must be positive", cfg.WaitTimeout)
+	}
+
+	if parallelSet && !cfg.fansOut() {
+		return cfg, fmt.Errorf("--parallel is only valid for activate, deactivate, requests --cancel and renew")
+	}
+	if cfg.Parallel < 1 {
+		return cfg, fmt.Errorf("invalid --parallel %d: must be at least 1", cfg.Parallel)
+	}
+
+	if cfg.NoCache && cfg.Refresh {
+		return cfg, fmt.Errorf("--no-cache and --refresh cannot be combined")
+	}
+	if cfg.Demo && (cfg.Cloud != "" || cfg.Tenant != "" || len(cfg.Auth) > 0) {
+		return cfg, fmt.Errorf("--demo cannot be combined with --cloud, --tenant or --auth")
+	}
+
+	if cfg.Command == CmdRole {
+		if cfg.RoleQuery == "" {
+			return cfg, fmt.Errorf("role show: role name required")
+		}
+		if len(cfg.Roles) > 0 || cfg.TimeStr != "" || cfg.Justification != "" || cfg.Ticket != "" || cfg.TicketSystem != "" || cfg.StartStr != "" || cfg.Yes {
+			return cfg, fmt.Errorf("role show: --role, --time, --justification, --ticket, --ticket-system, --at, --yes are not valid for this command")
+		}
+	}
+
+	if (cfg.Command == CmdWhoami || cfg.Command == CmdDoctor) && (len(cfg.Roles) > 0 || len(cfg.Scopes) > 0 || cfg.TimeStr != "" || cfg.Justification != "" || cfg.Ticket != "" || cfg.TicketSystem != "" || cfg.StartStr != "" || cfg.Yes || cfg.MGFilter != "") {
+		return cfg, fmt.Errorf("%s: only --output, --tenant, --cloud, --auth and --config-dir are valid for this command", cfg.Command)
+	}
+
+	if cfg.Command == CmdSearch && (len(cfg.Roles) > 0 || len(cfg.Scopes) > 0 || cfg.TimeStr != "" || cfg.Justification != "" || cfg.Ticket != "" || cfg.TicketSystem != "" || cfg.StartStr != "" || cfg.Yes) {
+		return cfg, fmt.Errorf("search: --role, --scope, --time, --justification, --ticket, --ticket-system, --at, --yes are not valid for this command")
 	}
 
 	return cfg, nil
 }
 
+// fansOut reports whether the command submits one request per target, at
+// most Parallel at once.
+func (c Config) fansOut() bool {
+	switch c.Command {
+	case CmdActivate, CmdDeactivate, CmdRenew:
+		return true
+	case CmdRequests:
+		return c.Cancel
+	}
+	return false
+}
+
 // IsHeadless reports whether the run should skip the TUI entirely.
 func (c Config) IsHeadless() bool {
 	return c.Headless
 }
 
+// RunsTUI reports whether the command opens the TUI. Search, renew, role,
+// whoami and doctor print their results like headless commands.
+func (c Config) RunsTUI() bool {
+	if c.IsHeadless() {
+		return false
+	}
+	switch c.Command {
+	case CmdSearch, CmdRenew, CmdRole, CmdWhoami, CmdDoctor:
+		return false
+	}
+	return true
+}
+
+// envEnabled reports whether the environment variable name is set to 1, true
+// or yes.
+func envEnabled(name string) bool {
+	switch strings.ToLower(os.Getenv(name)) {
+	case "1", "true", "yes":
+		return true
+	}
+	return false
+}
+
 // HasRoleFilter reports whether role filters were provided.
 func (c Config) HasRoleFilter() bool { return len(c.Roles) > 0 }
 
@@ -168,6 +431,31 @@ func (c Config) CanAutoAdvance() bool {
 	return c.HasRoleFilter() && c.HasScopeFilter() && c.TimeStr != "" && c.Justification != ""
 }
 
+// parseHistoryTime parses a --since / --until value: a look-back ("7d",
+// "24h", "90m"), a local date ("2006-01-02"), or RFC 3339. A date used as an
+// end bound covers that whole day.
+func parseHistoryTime(s string, now time.Time, end bool) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if days, ok := strings.CutSuffix(strings.ToLower(s), "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n > 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if mins, err := azure.ParseDurationMinutes(s); err == nil {
+		return now.Add(-time.Duration(mins) * time.Minute), nil
+	}
+	if d, err := time.ParseInLocation("2006-01-02", s, now.Location()); err == nil {
+		if end {
+			d = d.AddDate(0, 0, 1)
+		}
+		return d, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("unrecognised time %q; expected 7d, 24h, 2006-01-02, or RFC 3339", s)
+}
+
 // multiFlag is a flag.Value that accumulates repeated values.
 type multiFlag []string
 
@@ -186,7 +474,14 @@ Usage:
   pim activate [flags]         activate roles (TUI, flags pre-fill wizard)
   pim deactivate               deactivate roles (TUI)
   pim status                   view active/eligible roles (TUI)
+  pim requests                 view and cancel pending activation requests (TUI); --headless, --role or --scope lists them, --cancel cancels matches
+  pim history [flags]          browse your PIM request audit trail (TUI); --headless, --role, --scope, --since, --until, -o json or -o csv print it
+  pim role show <name>         list the actions, notActions, dataActions and notDataActions a role grants; --scope picks among same-named roles and is where custom roles you are not eligible for are looked up (built-in roles only without it), -o json for machine-readable output
+  pim renew [flags]            request extension (or renewal, once expired) of eligibilities matching --role / --scope; needs --justification
   pim search [query]           list PIM-eligible subscriptions; optional query filters by name or GUID (exact-first, substring-fallback); use --output json for machine-readable output; use --output toml for paste-ready favorites; use --mg to limit to a management group
+  pim whoami                   show the signed-in user, object ID, tenant, credential source and token expiry; -o json for machine-readable output
+  pim doctor                   check each credential source, Graph /me, the eligibility endpoints and the config files, with hints for failures
+  pim cache clear              delete cached eligible roles, active assignments and management group trees
   pim completion <bash|zsh|fish>  print shell completion script
   pim version                  print version
 
@@ -195,8 +490,43 @@ Activation flags:
   --scope <path>        target scope path (repeatable)
   --time, -t <dur>      duration: 1h, 30m, 1h30m, 1.5h
   --justification, -j   justification text
+  --ticket <number>     ticket number (required by some role policies)
+  --ticket-system <sys> ticket system name (e.g. ServiceNow)
+  --at, --start <when>  schedule the start: +3h, 22:00, 2006-01-02 15:04, RFC 3339
+  --wait                wait until the assignment is provisioned (headless)
+  --wait-timeout <dur>  maximum provisioning wait (default 5m)
+  --parallel <n>        targets activated, deactivated, cancelled or renewed at once (headless, default 4)
   --yes, -y             skip confirmation prompt
   --headless            non-TUI mode (for scripting)
   --output, -o          table | json | toml (headless only)
+
+Request flags:
+  --cancel              cancel requests matching --role / --scope (--yes cancels all)
+
+History flags:
+  --role, --scope       filter by role / scope (as for activate)
+  --since <when>        7d, 24h, 2006-01-02, or RFC 3339 (default 30d)
+  --until <when>        end of range, exclusive (a date includes that day)
+  --output, -o          table | json | csv
+
+Renew flags:
+  --role, --scope       select eligibilities (as for activate)
+  --justification, -j   justification text (required)
+  --days <n>            requested eligibility length (default 365)
+
+Global flags:
+  --cloud <name>        AzurePublic | AzureUSGovernment | AzureChina | Custom
+                        (also PIM_CLOUD or [preferences] cloud in config.toml)
+  --tenant <id>         tenant to sign in to (default AZURE_TENANT_ID)
+  --auth <sources>      credential sources in order, comma-separated:
+                        azure-cli, powershell, device-code, browser,
+                        environment, workload-identity
+  --config-dir <dir>    override config directory
+  --refresh             ignore cached responses and fetch fresh ones
+  --no-cache            do not read or write the response cache
+  --debug               log every HTTP request with its request IDs, tokens
+                        and principal IDs redacted: to stderr, or to
+                        debug.log in the config directory for the TUI
+                        (also PIM_DEBUG=1)
 `)
 }
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"flag"
 	"testing"
+	"time"
 )
 
 func TestParse_commands(t *testing.T) {
@@ -21,6 +22,10 @@ func TestParse_commands(t *testing.T) {
 		{[]string{"off"}, CmdDeactivate, false, false},
 		{[]string{"status"}, CmdStatus, false, false},
 		{[]string{"s"}, CmdStatus, false, false},
+		{[]string{"requests"}, CmdRequests, false, false},
+		{[]string{"req"}, CmdRequests, false, false},
+		{[]string{"whoami"}, CmdWhoami, false, false},
+		{[]string{"doctor", "-o", "json"}, CmdDoctor, false, false},
 		{[]string{"version"}, "", true, false},
 		{[]string{"v"}, "", true, false},
 		{[]string{"completion", "bash"}, CmdCompletion, false, false},
@@ -57,6 +62,9 @@ func TestParse_activationFlags(t *testing.T) {
 		"--scope", "/sub/aaa",
 		"--time", "2h",
 		"--justification", "ticket",
+		"--ticket", "CHG0012345",
+		"--ticket-system", "ServiceNow",
+		"--at", "+3h",
 		"--yes",
 		"--headless",
 		"--output", "json",
@@ -79,6 +87,12 @@ func TestParse_activationFlags(t *testing.T) {
 	if cfg.Justification != "ticket" {
 		t.Errorf("Justification = %q, want ticket", cfg.Justification)
 	}
+	if cfg.Ticket != "CHG0012345" || cfg.TicketSystem != "ServiceNow" {
+		t.Errorf("Ticket = %q/%q, want CHG0012345/ServiceNow", cfg.Ticket, cfg.TicketSystem)
+	}
+	if cfg.StartStr != "+3h" {
+		t.Errorf("StartStr = %q, want +3h", cfg.StartStr)
+	}
 	if !cfg.Yes {
 		t.Error("Yes should be true")
 	}
@@ -176,6 +190,10 @@ func TestSearchRejectsActivationFlags(t *testing.T) {
 		{[]string{"search", "--scope", "my-sub"}},
 		{[]string{"search", "-t", "1h"}},
 		{[]string{"search", "-j", "test"}},
+		{[]string{"search", "--ticket", "CHG1"}},
+		{[]string{"search", "--ticket-system", "ServiceNow"}},
+		{[]string{"search", "--at", "22:00"}},
+		{[]string{"search", "--start", "+1h"}},
 		{[]string{"search", "--yes"}},
 	}
 	for _, tc := range tests {
@@ -185,3 +203,328 @@ func TestSearchRejectsActivationFlags(t *testing.T) {
 		}
 	}
 }
+
+func TestDiagnosticsRejectActivationFlags(t *testing.T) {
+	for _, args := range [][]string{
+		{"whoami", "--role", "Reader"},
+		{"whoami", "--mg", "mg-1"},
+		{"doctor", "-t", "1h"},
+		{"doctor", "--yes"},
+	} {
+		if _, err := Parse(args); err == nil {
+			t.Errorf("Parse(%v) expected error, got nil", args)
+		}
+	}
+}
+
+func TestParse_wait(t *testing.T) {
+	cfg, err := Parse([]string{"activate", "--wait", "--wait-timeout", "2m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.Wait || cfg.WaitTimeout != 2*time.Minute {
+		t.Errorf("Wait = %v, WaitTimeout = %s, want true, 2m", cfg.Wait, cfg.WaitTimeout)
+	}
+
+	cfg, err = Parse([]string{"activate"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.WaitTimeout != DefaultWaitTimeout {
+		t.Errorf("WaitTimeout = %s, want %s", cfg.WaitTimeout, DefaultWaitTimeout)
+	}
+
+	for _, args := range [][]string{
+		{"status", "--wait"},
+		{"activate", "--wait", "--at", "+1h"},
+		{"activate", "--wait-timeout", "0s"},
+	} {
+		if _, err := Parse(args); err == nil {
+			t.Errorf("Parse(%v) want error", args)
+		}
+	}
+}
+
+func TestParse_parallel(t *testing.T) {
+	cfg, err := Parse([]string{"deactivate", "--parallel", "8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Parallel != 8 {
+		t.Errorf("Parallel = %d, want 8", cfg.Parallel)
+	}
+	if cfg, err = Parse([]string{"activate"}); err != nil || cfg.Parallel != DefaultParallel {
+		t.Errorf("Parallel = %d, %v; want %d", cfg.Parallel, err, DefaultParallel)
+	}
+	for _, args := range [][]string{
+		{"requests", "--cancel", "--yes", "--parallel", "2"},
+		{"renew", "--role", "Reader", "-j", "x", "--parallel", "2"},
+	} {
+		if cfg, err := Parse(args); err != nil || cfg.Parallel != 2 {
+			t.Errorf("Parse(%v) = %d, %v; want Parallel 2", args, cfg.Parallel, err)
+		}
+	}
+	for _, args := range [][]string{
+		{"status", "--parallel", "2"},
+		{"requests", "--parallel", "2"},
+		{"activate", "--parallel", "0"},
+	} {
+		if _, err := Parse(args); err == nil {
+			t.Errorf("Parse(%v) want error", args)
+		}
+	}
+}
+
+func TestParse_cloud(t *testing.T) {
+	cfg, err := Parse([]string{"status", "--cloud", "AzureUSGovernment", "--tenant", "contoso.onmicrosoft.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Cloud != "AzureUSGovernment" {
+		t.Errorf("Cloud = %q, want AzureUSGovernment", cfg.Cloud)
+	}
+	if cfg.Tenant != "contoso.onmicrosoft.com" {
+		t.Errorf("Tenant = %q, want contoso.onmicrosoft.com", cfg.Tenant)
+	}
+}
+
+func TestParse_auth(t *testing.T) {
+	cfg, err := Parse([]string{"status", "--auth", "device-code,azure-cli"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Auth) != 2 || cfg.Auth[0] != "device-code" || cfg.Auth[1] != "azure-cli" {
+		t.Errorf("Auth = %v, want [device-code azure-cli]", cfg.Auth)
+	}
+	if _, err := Parse([]string{"status", "--auth", "kerberos"}); err == nil {
+		t.Error("expected error for unknown credential source")
+	}
+}
+
+func TestParse_requests(t *testing.T) {
+	cfg, err := Parse([]string{"requests", "--cancel", "--role", "Owner", "--headless"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Command != CmdRequests {
+		t.Errorf("Command = %q, want requests", cfg.Command)
+	}
+	if !cfg.Cancel {
+		t.Error("Cancel should be true")
+	}
+	if len(cfg.Roles) != 1 || cfg.Roles[0] != "Owner" {
+		t.Errorf("Roles = %v, want [Owner]", cfg.Roles)
+	}
+
+	if _, err := Parse([]string{"activate", "--cancel"}); err == nil {
+		t.Error("Parse(activate --cancel) expected error, got nil")
+	}
+
+	// Without --headless, cancelling or filtering must not open the screen,
+	// which would ignore the flags and cancel nothing.
+	for _, args := range [][]string{
+		{"requests", "--cancel", "--role", "Owner"},
+		{"requests", "--cancel", "--yes"},
+		{"requests", "--scope", "prod"},
+	} {
+		cfg, err := Parse(args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !cfg.IsHeadless() || cfg.RunsTUI() {
+			t.Errorf("Parse(%v): Headless = %v, want headless", args, cfg.IsHeadless())
+		}
+	}
+	if cfg, _ := Parse([]string{"requests"}); !cfg.RunsTUI() {
+		t.Error("Parse(requests) should open the TUI")
+	}
+}
+
+func TestParse_history(t *testing.T) {
+	cfg, err := Parse([]string{"history"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Command != CmdHistory || cfg.IsHeadless() {
+		t.Errorf("Command = %q, Headless = %v; want history in the TUI", cfg.Command, cfg.IsHeadless())
+	}
+	if d := time.Since(cfg.Since); d < DefaultHistoryWindow-time.Minute || d > DefaultHistoryWindow+time.Minute {
+		t.Errorf("Since = %v, want about %v ago", cfg.Since, DefaultHistoryWindow)
+	}
+
+	cfg, err = Parse([]string{"history", "-o", "csv", "--since", "7d", "--until", "2099-01-01"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Output != OutputCSV || !cfg.IsHeadless() {
+		t.Errorf("Output = %q, Headless = %v; want csv headless", cfg.Output, cfg.IsHeadless())
+	}
+	if d := time.Since(cfg.Since); d < 7*24*time.Hour-time.Minute || d > 7*24*time.Hour+time.Minute {
+		t.Errorf("Since = %v, want about 7d ago", cfg.Since)
+	}
+	if want := time.Date(2099, 1, 2, 0, 0, 0, 0, time.Local); !cfg.Until.Equal(want) {
+		t.Errorf("Until = %v, want %v", cfg.Until, want)
+	}
+
+	// The history screen has fixed windows and its own filter box, so
+	// explicit bounds and filters print the table instead.
+	for _, args := range [][]string{
+		{"history", "--since", "7d"},
+		{"history", "--until", "2099-01-01"},
+		{"history", "--role", "Owner"},
+		{"history", "--scope", "prod"},
+	} {
+		cfg, err := Parse(args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !cfg.IsHeadless() || cfg.RunsTUI() {
+			t.Errorf("Parse(%v): Headless = %v; want the table printed", args, cfg.IsHeadless())
+		}
+	}
+
+	for _, args := range [][]string{
+		{"status", "-o", "csv"},
+		{"status", "--since", "7d"},
+		{"history", "--since", "yesterday"},
+		{"history", "--since", "2024-02-01", "--until", "2024-01-01"},
+		{"history", "--time", "1h"},
+	} {
+		if _, err := Parse(args); err == nil {
+			t.Errorf("Parse(%v) expected error, got nil", args)
+		}
+	}
+}
+
+func TestParse_role(t *testing.T) {
+	cfg, err := Parse([]string{"role", "show", "Key", "Vault", "Reader", "--scope", "sub-one", "-o", "json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Command != CmdRole {
+		t.Errorf("Command = %q, want role", cfg.Command)
+	}
+	if cfg.RoleQuery != "Key Vault Reader" {
+		t.Errorf("RoleQuery = %q, want %q", cfg.RoleQuery, "Key Vault Reader")
+	}
+	if len(cfg.Scopes) != 1 || cfg.Output != OutputJSON {
+		t.Errorf("Scopes = %v, Output = %q", cfg.Scopes, cfg.Output)
+	}
+
+	for _, args := range [][]string{
+		{"role"},
+		{"role", "list"},
+		{"role", "show"},
+		{"role", "show", "Owner", "--role", "Reader"},
+		{"role", "show", "Owner", "--time", "1h"},
+	} {
+		if _, err := Parse(args); err == nil {
+			t.Errorf("Parse(%v) expected error, got nil", args)
+		}
+	}
+}
+
+func TestParse_cache(t *testing.T) {
+	cfg, err := Parse([]string{"cache", "clear"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Command != CmdCache {
+		t.Errorf("Command = %q, want cache", cfg.Command)
+	}
+
+	cfg, err = Parse([]string{"search", "--refresh"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.Refresh || cfg.NoCache {
+		t.Errorf("Refresh = %v, NoCache = %v; want refresh only", cfg.Refresh, cfg.NoCache)
+	}
+	cfg, err = Parse([]string{"status", "--no-cache"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.NoCache {
+		t.Error("NoCache = false, want true")
+	}
+
+	for _, args := range [][]string{
+		{"cache"},
+		{"cache", "show"},
+		{"status", "--no-cache", "--refresh"},
+	} {
+		if _, err := Parse(args); err == nil {
+			t.Errorf("Parse(%v) expected error, got nil", args)
+		}
+	}
+}
+
+func TestParse_renew(t *testing.T) {
+	cfg, err := Parse([]string{"renew", "--role", "Owner", "-j", "still needed", "--days", "90"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Command != CmdRenew {
+		t.Errorf("Command = %q, want renew", cfg.Command)
+	}
+	if cfg.RenewDays != 90 || cfg.Justification != "still needed" {
+		t.Errorf("RenewDays = %d, Justification = %q", cfg.RenewDays, cfg.Justification)
+	}
+
+	for _, args := range [][]string{
+		{"activate", "--days", "30"},
+		{"renew", "--days", "-1"},
+		{"renew", "--time", "1h"},
+	} {
+		if _, err := Parse(args); err == nil {
+			t.Errorf("Parse(%v) expected error, got nil", args)
+		}
+	}
+}
+
+func TestParse_demo(t *testing.T) {
+	cfg, err := Parse([]string{"status", "--demo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.Demo {
+		t.Error("Demo = false, want true")
+	}
+	if _, err := Parse([]string{"--demo", "--tenant", "t-1"}); err == nil {
+		t.Error("expected --demo --tenant to fail")
+	}
+}
+
+func TestParse_debug(t *testing.T) {
+	t.Setenv("PIM_DEBUG", "")
+	if cfg, err := Parse([]string{"status"}); err != nil || cfg.Debug {
+		t.Errorf("Parse(status) Debug = %v, err = %v; want false", cfg.Debug, err)
+	}
+	if cfg, err := Parse([]string{"status", "--debug"}); err != nil || !cfg.Debug {
+		t.Errorf("Parse(status --debug) Debug = %v, err = %v; want true", cfg.Debug, err)
+	}
+	t.Setenv("PIM_DEBUG", "1")
+	if cfg, err := Parse([]string{"status"}); err != nil || !cfg.Debug {
+		t.Errorf("PIM_DEBUG=1 Debug = %v, err = %v; want true", cfg.Debug, err)
+	}
+	if cfg, err := Parse([]string{"status", "--debug=false"}); err != nil || cfg.Debug {
+		t.Errorf("--debug=false Debug = %v, err = %v; want false", cfg.Debug, err)
+	}
+}
+
+func TestConfigRunsTUI(t *testing.T) {
+	for _, tc := range []struct {
+		cfg  Config
+		want bool
+	}{
+		{Config{}, true},
+		{Config{Command: CmdStatus}, true},
+		{Config{Command: CmdStatus, Headless: true}, false},
+		{Config{Command: CmdSearch}, false},
+		{Config{Command: CmdWhoami}, false},
+	} {
+		if got := tc.cfg.RunsTUI(); got != tc.want {
+			t.Errorf("RunsTUI(%q, headless=%v) = %v, want %v", tc.cfg.Command, tc.cfg.Headless, got, tc.want)
+		}
+	}
+}
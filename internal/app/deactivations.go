@@ -0,0 +1,101 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jeircul/pim/internal/azure"
+	"github.com/jeircul/pim/internal/state"
+)
+
+// deactivationRetryDelay pushes back an entry Azure rejects as too early
+// again, e.g. because of clock skew.
+const deactivationRetryDelay = time.Minute
+
+// Deactivator is the client call the deactivation queue needs.
+type Deactivator interface {
+	DeactivateRole(ctx context.Context, assignment azure.ActiveAssignment, principalID string) (*azure.ScheduleResponse, error)
+}
+
+// DeactivationResult is the outcome of retrying one queued deactivation. Err
+// is nil when the role was deactivated or the assignment no longer exists.
+type DeactivationResult struct {
+	Entry state.QueuedDeactivation
+	Err   error
+}
+
+// QueueDeactivation queues assignment, which Azure refused to deactivate
+// because it was activated too recently, for its earliest allowed
+// deactivation time and saves state. tenant is the tenant ID the client
+// targets; empty is the home tenant.
+func (a *App) QueueDeactivation(tenant, principalID string, assignment azure.ActiveAssignment, now time.Time) (state.QueuedDeactivation, error) {
+	q := state.QueuedDeactivation{
+		Tenant:           tenant,
+		PrincipalID:      principalID,
+		Role:             assignment.RoleName,
+		RoleDefinitionID: assignment.RoleDefinitionID,
+		Scope:            assignment.Scope,
+		ScopeDisplay:     assignment.ScopeDisplay,
+		NotBefore:        assignment.EarliestDeactivation(now),
+	}
+	if !q.NotBefore.After(now) {
+		q.NotBefore = now.Add(deactivationRetryDelay)
+	}
+	if end, err := time.Parse(time.RFC3339, assignment.EndDateTime); err == nil {
+		q.ExpiresAt = end
+	}
+	a.Store.QueueDeactivation(q)
+	return q, a.Store.SaveState()
+}
+
+// QueuedDeactivations returns the queued deactivations for tenant and
+// principalID.
+func (a *App) QueuedDeactivations(tenant, principalID string) []state.QueuedDeactivation {
+	var out []state.QueuedDeactivation
+	for _, q := range a.Store.QueuedDeactivations() {
+		if strings.EqualFold(q.Tenant, tenant) && strings.EqualFold(q.PrincipalID, principalID) {
+			out = append(out, q)
+		}
+	}
+	return out
+}
+
+// RunQueuedDeactivations retries the queued deactivations for tenant and
+// principalID that are due at now. Entries leave the queue once deactivated,
+// when Azure reports the assignment gone, or when it has expired on its own.
+// An entry rejected as too early again is pushed back; other failures stay
+// queued with LastError set. State is saved when the queue changed.
+func (a *App) RunQueuedDeactivations(ctx context.Context, client Deactivator, tenant, principalID string, now time.Time) ([]DeactivationResult, error) {
+	var results []DeactivationResult
+	changed := false
+	for _, q := range a.QueuedDeactivations(tenant, principalID) {
+		switch {
+		case !q.ExpiresAt.IsZero() && !now.Before(q.ExpiresAt):
+			a.Store.RemoveQueuedDeactivation(q)
+			changed = true
+			continue
+		case now.Before(q.NotBefore):
+			continue
+		}
+		_, err := client.DeactivateRole(ctx, q.Assignment(), principalID)
+		switch {
+		case err == nil || azure.IsAssignmentNotFound(err):
+			a.Store.RemoveQueuedDeactivation(q)
+			results = append(results, DeactivationResult{Entry: q})
+		case azure.IsActiveDurationTooShort(err):
+			q.NotBefore = now.Add(deactivationRetryDelay)
+			q.LastError = ""
+			a.Store.QueueDeactivation(q)
+		default:
+			q.LastError = err.Error()
+			a.Store.QueueDeactivation(q)
+			results = append(results, DeactivationResult{Entry: q, Err: err})
+		}
+		changed = true
+	}
+	if !changed {
+		return results, nil
+	}
+	return results, a.Store.SaveState()
+}
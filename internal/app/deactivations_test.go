@@ -0,0 +1,98 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jeircul/pim/internal/azure"
+	"github.com/jeircul/pim/internal/state"
+)
+
+type deactivatorFunc func(azure.ActiveAssignment) error
+
+func (f deactivatorFunc) DeactivateRole(_ context.Context, a azure.ActiveAssignment, _ string) (*azure.ScheduleResponse, error) {
+	return &azure.ScheduleResponse{}, f(a)
+}
+
+func TestRunQueuedDeactivations(t *testing.T) {
+	s, err := state.New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &App{Store: s}
+	now := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+
+	assignment := func(role string, started time.Duration) azure.ActiveAssignment {
+		return azure.ActiveAssignment{
+			RoleName: role, RoleDefinitionID: "/rd/" + role, Scope: "/subscriptions/aaa",
+			StartDateTime: now.Add(-started).Format(time.RFC3339),
+			EndDateTime:   now.Add(time.Hour).Format(time.RFC3339),
+		}
+	}
+	q, err := a.QueueDeactivation("", "p-1", assignment("Owner", 2*time.Minute), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := now.Add(3 * time.Minute); !q.NotBefore.Equal(want) {
+		t.Errorf("NotBefore = %v, want %v", q.NotBefore, want)
+	}
+	for _, role := range []string{"Reader", "Contributor", "Gone"} {
+		if _, err := a.QueueDeactivation("", "p-1", assignment(role, 4*time.Minute), now); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := a.QueueDeactivation("other-tenant", "p-1", assignment("Owner", 10*time.Minute), now); err != nil {
+		t.Fatal(err)
+	}
+
+	var called []string
+	client := deactivatorFunc(func(a azure.ActiveAssignment) error {
+		called = append(called, a.RoleName)
+		switch a.RoleName {
+		case "Contributor":
+			return &azure.APIError{StatusCode: 400, Code: "ActiveDurationTooShort"}
+		case "Gone":
+			return &azure.APIError{StatusCode: 400, Code: "RoleAssignmentDoesNotExist"}
+		case "Owner":
+			return errors.New("network down")
+		}
+		return nil
+	})
+
+	results, err := a.RunQueuedDeactivations(t.Context(), client, "", "p-1", now.Add(90*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(called) != 3 || len(results) != 2 {
+		t.Fatalf("called %v, results %+v; want the three due entries tried and two done", called, results)
+	}
+	queued := a.QueuedDeactivations("", "p-1")
+	if len(queued) != 2 {
+		t.Fatalf("queue = %+v, want Owner and Contributor left", queued)
+	}
+	for _, q := range queued {
+		if q.Role == "Contributor" && !q.NotBefore.Equal(now.Add(90*time.Second+deactivationRetryDelay)) {
+			t.Errorf("Contributor NotBefore = %v, want pushed back", q.NotBefore)
+		}
+	}
+
+	results, err = a.RunQueuedDeactivations(t.Context(), client, "", "p-1", now.Add(5*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Entry.Role != "Owner" || results[0].Entry.LastError != "network down" {
+		t.Errorf("results = %+v, want the Owner failure", results)
+	}
+
+	if _, err := a.RunQueuedDeactivations(t.Context(), client, "", "p-1", now.Add(2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if queued := a.QueuedDeactivations("", "p-1"); len(queued) != 0 {
+		t.Errorf("expired entries still queued: %+v", queued)
+	}
+	if len(s.QueuedDeactivations()) != 1 {
+		t.Errorf("other tenant's entry touched: %+v", s.QueuedDeactivations())
+	}
+}
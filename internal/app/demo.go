@@ -0,0 +1,46 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jeircul/pim/internal/azure"
+	"github.com/jeircul/pim/internal/azure/fake"
+)
+
+// connectDemo starts a fake Azure seeded with the built-in demo fixture and
+// points Client at it. Activations and deactivations last as long as the
+// process. debugLog receives the --debug log, if any.
+func (a *App) connectDemo(debugLog io.Writer) error {
+	fx, err := fake.DemoFixture()
+	if err != nil {
+		return fmt.Errorf("demo: %w", err)
+	}
+	srv, err := fake.NewServer(fx)
+	if err != nil {
+		return fmt.Errorf("demo: %w", err)
+	}
+	opts := srv.ClientOptions()
+	opts.DebugLog = debugLog
+	client, err := azure.NewClient(opts)
+	if err != nil {
+		srv.Close()
+		return fmt.Errorf("demo: %w", err)
+	}
+	a.Client = client
+	a.closers = append(a.closers, srv.Close)
+	return nil
+}
+
+// demoConfigDir creates the throwaway config directory used by --demo when
+// --config-dir is not given, so demo favorites and cache entries never reach
+// the real one.
+func (a *App) demoConfigDir() (string, error) {
+	dir, err := os.MkdirTemp("", "pim-demo-")
+	if err != nil {
+		return "", fmt.Errorf("demo: create config dir: %w", err)
+	}
+	a.closers = append(a.closers, func() { _ = os.RemoveAll(dir) })
+	return dir, nil
+}
@@ -0,0 +1,34 @@
+package app
+
+import (
+	"os"
+	"testing"
+)
+
+func TestConnectDemo(t *testing.T) {
+	a, err := New(Config{Demo: true}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := a.Store.Dir()
+	if err := a.Connect(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	user, err := a.Client.GetCurrentUser(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	roles, err := a.Cached(a.Client, user.ID).GetEligibleRoles(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(roles) == 0 {
+		t.Error("demo tenant has no eligible roles")
+	}
+
+	a.Close()
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("demo config dir %s not removed: %v", dir, err)
+	}
+	a.Close()
+}
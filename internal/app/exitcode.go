@@ -0,0 +1,76 @@
+package app
+
+import (
+	"errors"
+
+	"github.com/jeircul/pim/internal/azure"
+)
+
+// Exit codes. Scripts rely on them, so a code never changes meaning; 2 is
+// left out because shells and the flag package use it for usage errors.
+const (
+	ExitOK              = 0
+	ExitError           = 1
+	ExitPartialFailure  = 3
+	ExitNoMatch         = 4
+	ExitAmbiguous       = 5
+	ExitAuth            = 6
+	ExitPolicy          = 7
+	ExitPendingApproval = 8
+	ExitAlreadyActive   = 9
+	ExitCancelled       = 130
+)
+
+// Error kinds ExitCode maps onto exit codes. Mark attaches one to an error.
+var (
+	ErrPartialFailure  = errors.New("some targets failed")
+	ErrNoMatch         = errors.New("nothing matches the filters")
+	ErrAmbiguous       = errors.New("ambiguous filter")
+	ErrPolicyViolation = errors.New("role management policy violation")
+	ErrPendingApproval = errors.New("pending approval")
+	ErrAlreadyActive   = errors.New("already active")
+)
+
+// kindError is an error marked with one of the error kinds above.
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string   { return e.err.Error() }
+func (e *kindError) Unwrap() []error { return []error{e.err, e.kind} }
+
+// Mark returns err marked as kind, so errors.Is(err, kind) reports true,
+// without changing its message. Mark returns nil when err is nil.
+func Mark(kind, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &kindError{kind: kind, err: err}
+}
+
+// ExitCode returns the process exit code for err. A partial failure wins over
+// the kinds of the individual failures it wraps.
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, azure.ErrUserCancelled):
+		return ExitCancelled
+	case errors.Is(err, ErrPartialFailure):
+		return ExitPartialFailure
+	case azure.IsAuthFailure(err):
+		return ExitAuth
+	case errors.Is(err, ErrPolicyViolation), azure.IsPolicyViolation(err):
+		return ExitPolicy
+	case errors.Is(err, ErrAlreadyActive), azure.IsAlreadyActive(err):
+		return ExitAlreadyActive
+	case errors.Is(err, ErrAmbiguous):
+		return ExitAmbiguous
+	case errors.Is(err, ErrNoMatch):
+		return ExitNoMatch
+	case errors.Is(err, ErrPendingApproval):
+		return ExitPendingApproval
+	}
+	return ExitError
+}
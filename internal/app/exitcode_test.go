@@ -0,0 +1,50 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jeircul/pim/internal/azure"
+)
+
+func TestExitCode(t *testing.T) {
+	authErr := &azure.APIError{StatusCode: 401, Code: "InvalidAuthenticationToken"}
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, ExitOK},
+		{"plain", errors.New("boom"), ExitError},
+		{"cancelled", fmt.Errorf("prompt: %w", azure.ErrUserCancelled), ExitCancelled},
+		{"no credential", azure.ErrNoCredential, ExitAuth},
+		{"token", &azure.TokenError{Scope: "arm", Err: errors.New("expired")}, ExitAuth},
+		{"unauthorized", fmt.Errorf("get current user: %w", authErr), ExitAuth},
+		{"step-up", &azure.StepUpError{Err: errors.New("no claims")}, ExitAuth},
+		{"forbidden is not auth", &azure.APIError{StatusCode: 403, Code: "AuthorizationFailed"}, ExitError},
+		{"azure policy", &azure.APIError{StatusCode: 400, Code: "RoleAssignmentRequestPolicyValidationFailed"}, ExitPolicy},
+		{"marked policy", Mark(ErrPolicyViolation, errors.New("too long")), ExitPolicy},
+		{"azure already active", &azure.APIError{StatusCode: 400, Code: "RoleAssignmentExists"}, ExitAlreadyActive},
+		{"no match", Mark(ErrNoMatch, errors.New("none")), ExitNoMatch},
+		{"ambiguous", Mark(ErrAmbiguous, errors.New("two")), ExitAmbiguous},
+		{"pending", Mark(ErrPendingApproval, errors.New("waiting")), ExitPendingApproval},
+		{"partial wins", Mark(ErrPartialFailure, errors.Join(authErr, errors.New("boom"))), ExitPartialFailure},
+	}
+	for _, tc := range tests {
+		if got := ExitCode(tc.err); got != tc.want {
+			t.Errorf("%s: ExitCode(%v) = %d, want %d", tc.name, tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestMarkKeepsMessage(t *testing.T) {
+	inner := errors.New("no eligible roles match")
+	err := Mark(ErrNoMatch, inner)
+	if err.Error() != inner.Error() || !errors.Is(err, inner) || !errors.Is(err, ErrNoMatch) {
+		t.Errorf("Mark = %q; Is(inner) = %v, Is(ErrNoMatch) = %v", err, errors.Is(err, inner), errors.Is(err, ErrNoMatch))
+	}
+	if Mark(ErrNoMatch, nil) != nil {
+		t.Error("Mark(kind, nil) should be nil")
+	}
+}
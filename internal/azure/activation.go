@@ -1,7 +1,6 @@
 package azure
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -9,7 +8,6 @@ import (
 	"io"
 	"net/http"
 	"strings"
-	"time"
 
 	"github.com/google/uuid"
 )
@@ -23,41 +21,47 @@ const errCodePendingRequest = "PendingRoleAssignmentRequest"
 const errCodeAssignmentExists = "RoleAssignmentExists"
 
 // ActivateRole submits an activation or extension request.
-func (c *Client) ActivateRole(ctx context.Context, role Role, principalID, justification string, minutes int, targetScope string) (*ScheduleResponse, error) {
+// Directory roles and group eligibilities are submitted to Microsoft Graph.
+func (c *Client) ActivateRole(ctx context.Context, ar ActivationRequest) (*ScheduleResponse, error) {
+	if ar.Minutes <= 0 {
+		return nil, fmt.Errorf("activation duration must be positive, got %d minutes", ar.Minutes)
+	}
+	switch ar.Role.ScopeKind() {
+	case ScopeDirectory:
+		return c.activateDirectoryRole(ctx, ar)
+	case ScopeGroup:
+		return c.activateGroupAccess(ctx, ar)
+	}
 	tok, err := c.armToken(ctx)
 	if err != nil {
 		return nil, err
 	}
-	minutes = ClampMinutes(minutes)
 
-	scopePath := NormalizeScope(role.Scope)
-	if strings.TrimSpace(targetScope) != "" {
-		scopePath = NormalizeScope(targetScope)
-	}
+	role := ar.Role
+	scopePath := activationScope(ar)
 
-	active, err := c.isRoleActiveAt(ctx, scopePath, role.RoleDefinitionID, principalID)
-	if err != nil {
-		return nil, err
-	}
+	// A scheduled activation is always a new assignment; extension only
+	// applies to an assignment that is active now.
 	requestType := "SelfActivate"
-	if active {
-		requestType = "SelfExtend"
+	if !ar.IsScheduled() {
+		active, err := c.isRoleActiveAt(ctx, scopePath, role.RoleDefinitionID, ar.PrincipalID)
+		if err != nil {
+			return nil, err
+		}
+		if active {
+			requestType = "SelfExtend"
+		}
 	}
 
 	req := ScheduleRequest{
 		Properties: ScheduleProperties{
-			PrincipalID:                     principalID,
+			PrincipalID:                     ar.PrincipalID,
 			RoleDefinitionID:                role.RoleDefinitionID,
 			RequestType:                     requestType,
-			Justification:                   justification,
+			Justification:                   ar.Justification,
 			LinkedRoleEligibilityScheduleID: role.EligibilityScheduleID,
-			ScheduleInfo: &ScheduleInfo{
-				StartDateTime: time.Now().UTC().Format(time.RFC3339),
-				Expiration: Expiration{
-					Type:     "AfterDuration",
-					Duration: FormatDuration(minutes),
-				},
-			},
+			ScheduleInfo:                    ar.scheduleInfo("AfterDuration"),
+			TicketInfo:                      ar.Ticket.ptr(),
 		},
 	}
 
@@ -68,14 +72,14 @@ func (c *Client) ActivateRole(ctx context.Context, role Role, principalID, justi
 
 	requestID := uuid.New().String()
 	reqURL := fmt.Sprintf("%s%s/providers/Microsoft.Authorization/roleAssignmentScheduleRequests/%s?api-version=%s",
-		armEndpoint, scopePath, requestID, apiVersion)
+		c.armURL, scopePath, requestID, apiVersion)
 
-	resp, err := c.doRequest(ctx, http.MethodPut, reqURL, tok, bytes.NewReader(body))
+	resp, err := c.doRequestWithStepUp(ctx, http.MethodPut, reqURL, c.cloud.armScope(), tok, body)
 	if err != nil {
 		var apiErr *APIError
 		if errors.As(err, &apiErr) && apiErr.StatusCode == 400 &&
 			(strings.EqualFold(apiErr.Code, errCodePendingRequest) || strings.EqualFold(apiErr.Code, errCodeAssignmentExists)) {
-			return &ScheduleResponse{}, nil
+			return &ScheduleResponse{AlreadyActive: IsAlreadyActive(err)}, nil
 		}
 		if IsResourceGroupScope(scopePath) && errors.As(err, &apiErr) &&
 			(apiErr.StatusCode == 403 || strings.EqualFold(apiErr.Code, "AuthorizationFailed")) {
@@ -110,14 +114,14 @@ func (c *Client) activateAtSubscriptionScope(ctx context.Context, req ScheduleRe
 
 	requestID := uuid.New().String()
 	reqURL := fmt.Sprintf("%s%s/providers/Microsoft.Authorization/roleAssignmentScheduleRequests/%s?api-version=%s",
-		armEndpoint, subScope, requestID, apiVersion)
+		c.armURL, subScope, requestID, apiVersion)
 
-	resp, err := c.doRequest(ctx, http.MethodPut, reqURL, tok, bytes.NewReader(body))
+	resp, err := c.doRequestWithStepUp(ctx, http.MethodPut, reqURL, c.cloud.armScope(), tok, body)
 	if err != nil {
 		var apiErr *APIError
 		if errors.As(err, &apiErr) && apiErr.StatusCode == 400 &&
 			(strings.EqualFold(apiErr.Code, errCodePendingRequest) || strings.EqualFold(apiErr.Code, errCodeAssignmentExists)) {
-			return &ScheduleResponse{}, nil
+			return &ScheduleResponse{AlreadyActive: IsAlreadyActive(err)}, nil
 		}
 		return nil, fmt.Errorf("submit activation at %s (and fallback to subscription %s): %w", rgScope, subScope, errors.Join(rgErr, err))
 	}
@@ -128,6 +132,12 @@ func (c *Client) activateAtSubscriptionScope(ctx context.Context, req ScheduleRe
 
 // DeactivateRole submits a role deactivation request.
 func (c *Client) DeactivateRole(ctx context.Context, assignment ActiveAssignment, principalID string) (*ScheduleResponse, error) {
+	switch {
+	case IsDirectoryScope(assignment.Scope):
+		return c.deactivateDirectoryRole(ctx, assignment, principalID)
+	case IsGroupScope(assignment.Scope):
+		return c.deactivateGroupAccess(ctx, assignment, principalID)
+	}
 	tok, err := c.armToken(ctx)
 	if err != nil {
 		return nil, err
@@ -147,9 +157,9 @@ func (c *Client) DeactivateRole(ctx context.Context, assignment ActiveAssignment
 
 	requestID := uuid.New().String()
 	reqURL := fmt.Sprintf("%s%s/providers/Microsoft.Authorization/roleAssignmentScheduleRequests/%s?api-version=%s",
-		armEndpoint, assignment.Scope, requestID, apiVersion)
+		c.armURL, assignment.Scope, requestID, apiVersion)
 
-	resp, err := c.doRequest(ctx, http.MethodPut, reqURL, tok, bytes.NewReader(body))
+	resp, err := c.doRequest(ctx, http.MethodPut, reqURL, tok, body)
 	if err != nil {
 		return nil, fmt.Errorf("submit deactivation: %w", err)
 	}
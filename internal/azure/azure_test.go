@@ -1,34 +1,73 @@
 package azure
 
 import (
+	"encoding/json"
+	"fmt"
 	"sort"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestClampMinutes(t *testing.T) {
 	tests := []struct {
 		name  string
 		input int
+		max   int
 		want  int
 	}{
-		{"below min", 0, 30},
-		{"at min", 30, 30},
-		{"round down", 40, 30},
-		{"round up", 50, 60},
-		{"normal", 120, 120},
-		{"at max", 480, 480},
-		{"above max", 600, 480},
-		{"negative", -5, 30},
+		{"below min", 0, 480, 5},
+		{"at min", 5, 480, 5},
+		{"no rounding", 40, 480, 40},
+		{"normal", 120, 480, 120},
+		{"at max", 480, 480, 480},
+		{"above max", 600, 480, 480},
+		{"negative", -5, 480, 5},
+		{"policy max above default", 600, 720, 600},
+		{"policy max below default", 120, 60, 60},
+		{"unknown max uses default", 600, 0, 480},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := ClampMinutes(tt.input); got != tt.want {
-				t.Errorf("ClampMinutes(%d) = %d; want %d", tt.input, got, tt.want)
+			if got := ClampMinutes(tt.input, tt.max); got != tt.want {
+				t.Errorf("ClampMinutes(%d, %d) = %d; want %d", tt.input, tt.max, got, tt.want)
 			}
 		})
 	}
 }
 
+func TestParseISODurationMinutes(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int
+		wantErr bool
+	}{
+		{"PT8H", 480, false},
+		{"PT1H30M", 90, false},
+		{"PT45M", 45, false},
+		{"P1D", 1440, false},
+		{"P1DT2H", 1560, false},
+		{"pt12h", 720, false},
+		{"PT90S", 1, false},
+		{"", 0, true},
+		{"P", 0, true},
+		{"PT", 0, true},
+		{"8h", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseISODurationMinutes(tt.input)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseISODurationMinutes(%q) = %d, want error", tt.input, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseISODurationMinutes(%q) = %d, %v; want %d", tt.input, got, err, tt.want)
+		}
+	}
+}
+
 func TestFormatDuration(t *testing.T) {
 	tests := []struct {
 		minutes int
@@ -111,6 +150,27 @@ func TestTimeRemaining(t *testing.T) {
 	}
 }
 
+func TestEarliestDeactivation(t *testing.T) {
+	now := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	a := ActiveAssignment{StartDateTime: "2026-01-02T09:58:00Z"}
+	if got, want := a.EarliestDeactivation(now), now.Add(3*time.Minute); !got.Equal(want) {
+		t.Errorf("EarliestDeactivation = %v, want %v", got, want)
+	}
+	if got, want := (ActiveAssignment{}).EarliestDeactivation(now), now.Add(MinActiveDuration); !got.Equal(want) {
+		t.Errorf("EarliestDeactivation without start = %v, want %v", got, want)
+	}
+}
+
+func TestIsActiveDurationTooShort(t *testing.T) {
+	err := fmt.Errorf("submit deactivation: %w", &APIError{StatusCode: 400, Code: "ActiveDurationTooShort"})
+	if !IsActiveDurationTooShort(err) {
+		t.Error("wrapped ActiveDurationTooShort not recognised")
+	}
+	if IsActiveDurationTooShort(&APIError{StatusCode: 400, Code: "RoleAssignmentDoesNotExist"}) {
+		t.Error("other API error recognised as ActiveDurationTooShort")
+	}
+}
+
 func TestParseDurationMinutes(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -127,8 +187,9 @@ func TestParseDurationMinutes(t *testing.T) {
 		{"float half hour", "0.5h", 30, false},
 		{"two hours", "2h", 120, false},
 		{"eight hours max", "8h", 480, false},
-		{"over max clamped", "9h", 480, false},
-		{"below min clamped", "10m", 30, false},
+		{"over default max not clamped", "9h", 540, false},
+		{"below 30m not clamped", "10m", 10, false},
+		{"zero rejected", "0m", 0, true},
 		{"empty", "", 0, true},
 		{"garbage", "garbage", 0, true},
 		{"trailing garbage m", "30mph", 0, true},
@@ -155,6 +216,62 @@ func TestParseDurationMinutes(t *testing.T) {
 	}
 }
 
+func TestParseStartTime(t *testing.T) {
+	now := time.Date(2026, 3, 10, 14, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		input   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"empty means now", "", time.Time{}, false},
+		{"now keyword", "now", time.Time{}, false},
+		{"relative hours", "+3h", now.Add(3 * time.Hour), false},
+		{"relative minutes", "+90m", now.Add(90 * time.Minute), false},
+		{"clock later today", "22:00", time.Date(2026, 3, 10, 22, 0, 0, 0, time.UTC), false},
+		{"clock already past rolls to tomorrow", "09:15", time.Date(2026, 3, 11, 9, 15, 0, 0, time.UTC), false},
+		{"local date and time", "2026-03-12 08:00", time.Date(2026, 3, 12, 8, 0, 0, 0, time.UTC), false},
+		{"rfc3339", "2026-03-12T08:00:00Z", time.Date(2026, 3, 12, 8, 0, 0, 0, time.UTC), false},
+		{"past date rejected", "2026-03-01 08:00", time.Time{}, true},
+		{"bad relative", "+soon", time.Time{}, true},
+		{"garbage", "tomorrow", time.Time{}, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseStartTime(tc.input, now)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("ParseStartTime(%q) = %v, want error", tc.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseStartTime(%q) unexpected error: %v", tc.input, err)
+			}
+			if !got.Equal(tc.want) {
+				t.Errorf("ParseStartTime(%q) = %v, want %v", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestActivationRequestScheduleInfo(t *testing.T) {
+	start := time.Date(2030, 1, 2, 3, 4, 0, 0, time.UTC)
+	info := ActivationRequest{Minutes: 90, Start: start}.scheduleInfo("afterDuration")
+	if info.StartDateTime != "2030-01-02T03:04:00Z" {
+		t.Errorf("StartDateTime = %q, want 2030-01-02T03:04:00Z", info.StartDateTime)
+	}
+	if info.Expiration.Duration != "PT1H30M" || info.Expiration.Type != "afterDuration" {
+		t.Errorf("Expiration = %+v", info.Expiration)
+	}
+	if !(ActivationRequest{Start: start}).IsScheduled() {
+		t.Error("future start should be scheduled")
+	}
+	if (ActivationRequest{}).IsScheduled() {
+		t.Error("zero start should not be scheduled")
+	}
+}
+
 func TestGetEligibleRolesSortOrder(t *testing.T) {
 	// Verify that the sort contract applied inside GetEligibleRoles is stable
 	// and deterministic: roles are ordered by Scope then RoleName, so
@@ -189,3 +306,34 @@ func TestGetEligibleRolesSortOrder(t *testing.T) {
 		}
 	}
 }
+
+func TestScheduleRequestTicketInfo(t *testing.T) {
+	tests := []struct {
+		name   string
+		ticket TicketInfo
+		want   string
+	}{
+		{"omitted when empty", TicketInfo{}, ""},
+		{"number only", TicketInfo{TicketNumber: "INC-1"}, `"ticketInfo":{"ticketNumber":"INC-1"}`},
+		{"number and system", TicketInfo{TicketNumber: "CHG0012345", TicketSystem: "ServiceNow"},
+			`"ticketInfo":{"ticketNumber":"CHG0012345","ticketSystem":"ServiceNow"}`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			body, err := json.Marshal(ScheduleRequest{Properties: ScheduleProperties{TicketInfo: tc.ticket.ptr()}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := string(body)
+			if tc.want == "" {
+				if strings.Contains(got, "ticketInfo") {
+					t.Errorf("body %s should not contain ticketInfo", got)
+				}
+				return
+			}
+			if !strings.Contains(got, tc.want) {
+				t.Errorf("body %s does not contain %s", got, tc.want)
+			}
+		})
+	}
+}
@@ -1,12 +1,12 @@
 package azure
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
-	"strconv"
 	"strings"
 	"time"
 
@@ -18,15 +18,53 @@ import (
 const (
 	apiVersion                       = "2020-10-01"
 	eligibleChildResourcesAPIVersion = "2020-10-01"
-	armEndpoint                      = "https://management.azure.com"
-	graphEndpoint                    = "https://graph.microsoft.com/v1.0"
-	httpTimeout                      = 90 * time.Second
+	graphAPIVersion                  = "v1.0"
 )
 
-// Client handles Azure PIM operations.
+// Client handles Azure PIM operations for one tenant. Clients for other
+// tenants are obtained with ForTenant and share its credential cache.
 type Client struct {
 	cred       azcore.TokenCredential
 	httpClient *http.Client
+	cloud      Cloud
+	armURL     string // ARM base URL
+	graphURL   string // Graph base URL including the API version
+	tenantID   string
+	// stepUpCred answers claims challenges the chain cannot; nil unless an
+	// interactive source (device code or browser) is in the chain.
+	stepUpCred azcore.TokenCredential
+	sources    []string
+	fixedCred  azcore.TokenCredential // replaces the chain; see ClientOptions.Credential
+	source     *sourceTracker
+	retry      RetryPolicy
+	debug      *debugLog
+	tenants    *tenantClients
+}
+
+// ClientOptions configures NewClient. The zero value targets AzurePublic and
+// the tenant in AZURE_TENANT_ID (or the signed-in account's home tenant),
+// using DefaultCredentialSources.
+type ClientOptions struct {
+	Cloud    Cloud
+	TenantID string
+	// Sources lists the credential sources to try, in order; see
+	// ParseCredentialSources. Empty uses DefaultCredentialSources.
+	Sources []string
+	// Retry controls retries of failed requests; nil uses DefaultRetryPolicy.
+	Retry *RetryPolicy
+	// Credential, when set, replaces the credential chain for every tenant;
+	// Sources is ignored, and token requests name the client's tenant. Used
+	// to point the client at a fake server.
+	Credential azcore.TokenCredential
+	// Network sets the proxy, extra CA certificates and timeout for requests
+	// to ARM, Graph and the authority host.
+	Network NetworkConfig
+	// HTTPClient, when set, sends every request instead of the client built
+	// from Network.
+	HTTPClient *http.Client
+	// DebugLog, when set, receives a line per request attempt with bearer
+	// tokens and principal IDs redacted.
+	DebugLog io.Writer
 }
 
 type childResource struct {
@@ -38,68 +76,131 @@ type childResource struct {
 	} `json:"properties"`
 }
 
-// NewClient creates a PIM client using the best available delegated credential.
-func NewClient() (*Client, error) {
-	tenantID := os.Getenv("AZURE_TENANT_ID")
-	var chain []azcore.TokenCredential
-
-	if c, err := azidentity.NewAzureCLICredential(&azidentity.AzureCLICredentialOptions{TenantID: tenantID}); err == nil {
-		chain = append(chain, c)
+// NewClient creates a PIM client using the first credential source in the
+// chain that produces a token. Device code, browser, environment and workload
+// identity credentials authenticate against the cloud's authority host through
+// the client's network settings; the Azure CLI and PowerShell credentials use
+// the tool's own active cloud and proxy configuration.
+// Activation requests rejected with a Conditional Access claims challenge are
+// retried with a stepped-up token; see StepUpError.
+func NewClient(opts ClientOptions) (*Client, error) {
+	cl := opts.Cloud
+	if cl.ARMEndpoint == "" {
+		cl = AzurePublic
+	}
+	retry := DefaultRetryPolicy
+	if opts.Retry != nil {
+		retry = opts.Retry.normalize()
 	}
-	if c, err := azidentity.NewAzurePowerShellCredential(&azidentity.AzurePowerShellCredentialOptions{TenantID: tenantID}); err == nil {
-		chain = append(chain, c)
+	tenantID := opts.TenantID
+	if tenantID == "" {
+		tenantID = os.Getenv("AZURE_TENANT_ID")
 	}
-	if allowDeviceLogin() {
-		if c, err := azidentity.NewDeviceCodeCredential(&azidentity.DeviceCodeCredentialOptions{
-			TenantID: tenantID,
-			UserPrompt: func(_ context.Context, msg azidentity.DeviceCodeMessage) error {
-				fmt.Fprintln(os.Stderr, msg.Message)
-				return nil
-			},
-		}); err == nil {
-			chain = append(chain, c)
+	httpClient := opts.HTTPClient
+	if httpClient == nil {
+		var err error
+		if httpClient, err = opts.Network.HTTPClient(); err != nil {
+			return nil, err
 		}
 	}
-
-	if len(chain) == 0 {
-		return nil, ErrNoCredential
+	base := &Client{
+		httpClient: httpClient,
+		cloud:      cl,
+		armURL:     cl.ARMEndpoint,
+		graphURL:   cl.GraphEndpoint + "/" + graphAPIVersion,
+		sources:    opts.Sources,
+		fixedCred:  opts.Credential,
+		retry:      retry,
+		debug:      newDebugLog(opts.DebugLog),
+		tenants:    &tenantClients{byID: map[string]*Client{}},
 	}
-
-	cred, err := azidentity.NewChainedTokenCredential(chain, nil)
+	client, err := base.newTenantClient(tenantID)
 	if err != nil {
-		return nil, fmt.Errorf("create credential chain: %w", err)
+		return nil, err
 	}
+	base.tenants.home = client
+	return client, nil
+}
 
-	return &Client{
+// newTenantClient builds the credential chain for tenantID and returns a
+// client sharing c's cloud, HTTP client, sources and tenant cache.
+func (c *Client) newTenantClient(tenantID string) (*Client, error) {
+	tracker := &sourceTracker{}
+	var (
+		cred   azcore.TokenCredential
+		stepUp azcore.TokenCredential
+	)
+	if c.fixedCred != nil {
+		cred = namedCredential{source: sourceCustom, cred: c.fixedCred, tracker: tracker, tenantID: tenantID}
+	} else {
+		chain, up, err := buildChain(c.sources, c.cloud, tenantID, c.httpClient, tracker)
+		if err != nil {
+			return nil, err
+		}
+		if cred, err = azidentity.NewChainedTokenCredential(chain, nil); err != nil {
+			return nil, fmt.Errorf("create credential chain: %w", err)
+		}
+		stepUp = up
+	}
+
+	client := &Client{
 		cred:       cred,
-		httpClient: &http.Client{Timeout: httpTimeout},
-	}, nil
+		httpClient: c.httpClient,
+		cloud:      c.cloud,
+		armURL:     c.armURL,
+		graphURL:   c.graphURL,
+		tenantID:   tenantID,
+		stepUpCred: stepUp,
+		sources:    c.sources,
+		fixedCred:  c.fixedCred,
+		source:     tracker,
+		retry:      c.retry,
+		debug:      c.debug,
+		tenants:    c.tenants,
+	}
+	c.tenants.mu.Lock()
+	c.tenants.byID[strings.ToLower(tenantID)] = client
+	c.tenants.mu.Unlock()
+	return client, nil
 }
 
+// CredentialSource returns the name of the credential source that produced
+// the most recent token, or "" before the first token is acquired.
+func (c *Client) CredentialSource() string { return c.source.get() }
+
+// Cloud returns the cloud the client targets.
+func (c *Client) Cloud() Cloud { return c.cloud }
+
 func (c *Client) getToken(ctx context.Context, scope string) (string, error) {
 	tok, err := c.cred.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{scope}})
 	if err != nil {
-		return "", fmt.Errorf("acquire token for %s: %w", scope, err)
+		return "", &TokenError{Scope: scope, Err: err}
 	}
 	return tok.Token, nil
 }
 
 // armToken returns a fresh ARM token. azidentity handles caching and refresh internally.
 func (c *Client) armToken(ctx context.Context) (string, error) {
-	return c.getToken(ctx, "https://management.azure.com/.default")
+	return c.getToken(ctx, c.cloud.armScope())
 }
 
 // graphToken returns a fresh Graph token. azidentity handles caching and refresh internally.
 func (c *Client) graphToken(ctx context.Context) (string, error) {
-	return c.getToken(ctx, "https://graph.microsoft.com/.default")
+	return c.getToken(ctx, c.cloud.graphScope())
 }
 
-// doRequest executes an HTTP request and returns the response.
-// body must be nil or re-readable for retries; all current callers pass nil.
-func (c *Client) doRequest(ctx context.Context, method, reqURL, token string, body io.Reader) (*http.Response, error) {
-	const maxRetries = 4
-	for attempt := 0; attempt <= maxRetries; attempt++ {
-		req, err := http.NewRequestWithContext(ctx, method, reqURL, body)
+// doRequest executes an HTTP request and returns the response. body, when
+// non-nil, is sent as JSON and replayed on every attempt. Failed attempts are
+// retried according to the client's RetryPolicy; see retryable. Every attempt
+// is written to the client's debug log, if any.
+func (c *Client) doRequest(ctx context.Context, method, reqURL, token string, body []byte) (*http.Response, error) {
+	policy := c.retry
+	for attempt := 0; ; attempt++ {
+		var reader io.Reader
+		if body != nil {
+			reader = bytes.NewReader(body)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, reqURL, reader)
 		if err != nil {
 			return nil, fmt.Errorf("create request: %w", err)
 		}
@@ -107,40 +208,33 @@ func (c *Client) doRequest(ctx context.Context, method, reqURL, token string, bo
 		req.Header.Set("Content-Type", "application/json")
 		req.Header.Set("User-Agent", "pim/2")
 
+		start := time.Now()
 		resp, err := c.httpClient.Do(req)
-		if err != nil {
-			return nil, fmt.Errorf("execute request: %w", err)
+		c.debug.attempt(method, reqURL, attempt, resp, err, time.Since(start))
+		if err == nil && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return resp, nil
 		}
-		if resp.StatusCode == http.StatusTooManyRequests {
-			if attempt == maxRetries {
-				return nil, errorFromResponse(resp)
-			}
-			resp.Body.Close()
-			wait := retryAfter(resp, attempt)
-			select {
-			case <-ctx.Done():
-				return nil, ctx.Err()
-			case <-time.After(wait):
+		if attempt >= policy.MaxRetries || !retryable(ctx, method, resp, err) {
+			if err != nil {
+				return nil, fmt.Errorf("execute request: %w", err)
 			}
-			continue
-		}
-		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 			return nil, errorFromResponse(resp)
 		}
-		return resp, nil
-	}
-	return nil, fmt.Errorf("request to %s exceeded retry limit after 429 responses", reqURL)
-}
 
-// retryAfter returns the duration to wait before retrying a 429 response.
-// It reads the Retry-After header (seconds) and falls back to exponential backoff.
-func retryAfter(resp *http.Response, attempt int) time.Duration {
-	if s := resp.Header.Get("Retry-After"); s != "" {
-		if n, err := strconv.Atoi(s); err == nil && n > 0 {
-			return time.Duration(n) * time.Second
+		wait := policy.backoff(attempt)
+		if resp != nil {
+			if d, ok := retryAfter(resp, time.Now()); ok {
+				wait = min(d, policy.MaxDelay)
+			}
+			resp.Body.Close()
+		}
+		c.debug.retry(method, reqURL, wait)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
 		}
 	}
-	return time.Duration(1<<attempt) * time.Second
 }
 
 func allowDeviceLogin() bool {
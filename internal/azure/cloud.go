@@ -0,0 +1,139 @@
+package azure
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+)
+
+// Cloud names accepted by LookupCloud.
+const (
+	CloudPublic       = "AzurePublic"
+	CloudUSGovernment = "AzureUSGovernment"
+	CloudChina        = "AzureChina"
+	CloudCustom       = "Custom"
+)
+
+// Cloud holds the endpoints of an Azure cloud. Endpoints have no trailing
+// slash; GraphEndpoint excludes the API version path.
+type Cloud struct {
+	Name          string
+	AuthorityHost string
+	ARMEndpoint   string
+	GraphEndpoint string
+}
+
+// Built-in cloud profiles.
+var (
+	AzurePublic = Cloud{
+		Name:          CloudPublic,
+		AuthorityHost: "https://login.microsoftonline.com",
+		ARMEndpoint:   "https://management.azure.com",
+		GraphEndpoint: "https://graph.microsoft.com",
+	}
+	AzureUSGovernment = Cloud{
+		Name:          CloudUSGovernment,
+		AuthorityHost: "https://login.microsoftonline.us",
+		ARMEndpoint:   "https://management.usgovcloudapi.net",
+		GraphEndpoint: "https://graph.microsoft.us",
+	}
+	AzureChina = Cloud{
+		Name:          CloudChina,
+		AuthorityHost: "https://login.chinacloudapi.cn",
+		ARMEndpoint:   "https://management.chinacloudapi.cn",
+		GraphEndpoint: "https://microsoftgraph.chinacloudapi.cn",
+	}
+)
+
+// cloudAliases maps lower-cased names, including the Azure CLI cloud names,
+// to the built-in profiles.
+var cloudAliases = map[string]Cloud{
+	"azurepublic":       AzurePublic,
+	"azurecloud":        AzurePublic,
+	"public":            AzurePublic,
+	"azureusgovernment": AzureUSGovernment,
+	"usgovernment":      AzureUSGovernment,
+	"usgov":             AzureUSGovernment,
+	"azurechina":        AzureChina,
+	"azurechinacloud":   AzureChina,
+	"china":             AzureChina,
+}
+
+// CloudNames returns the accepted cloud names for help text and completion.
+func CloudNames() []string {
+	return []string{CloudPublic, CloudUSGovernment, CloudChina, CloudCustom}
+}
+
+// LookupCloud resolves a cloud by name (case-insensitive; Azure CLI names such
+// as AzureCloud are accepted) and applies any non-empty endpoint in overrides.
+// An empty name selects AzurePublic. "Custom" starts from no endpoints, so
+// overrides must supply all three.
+func LookupCloud(name string, overrides Cloud) (Cloud, error) {
+	key := strings.ToLower(strings.TrimSpace(name))
+	var c Cloud
+	switch key {
+	case "":
+		c = AzurePublic
+	case strings.ToLower(CloudCustom):
+		c = Cloud{Name: CloudCustom}
+	default:
+		var ok bool
+		if c, ok = cloudAliases[key]; !ok {
+			return Cloud{}, fmt.Errorf("unknown cloud %q: must be one of %s", name, strings.Join(CloudNames(), ", "))
+		}
+	}
+
+	if v := strings.TrimSpace(overrides.AuthorityHost); v != "" {
+		c.AuthorityHost = v
+	}
+	if v := strings.TrimSpace(overrides.ARMEndpoint); v != "" {
+		c.ARMEndpoint = v
+	}
+	if v := strings.TrimSpace(overrides.GraphEndpoint); v != "" {
+		c.GraphEndpoint = v
+	}
+	c.AuthorityHost = strings.TrimRight(c.AuthorityHost, "/")
+	c.ARMEndpoint = strings.TrimRight(c.ARMEndpoint, "/")
+	c.GraphEndpoint = strings.TrimSuffix(strings.TrimRight(c.GraphEndpoint, "/"), "/v1.0")
+
+	if err := c.validate(); err != nil {
+		return Cloud{}, err
+	}
+	return c, nil
+}
+
+// validate checks that every endpoint is an absolute https URL.
+func (c Cloud) validate() error {
+	for _, e := range []struct{ name, value string }{
+		{"authority host", c.AuthorityHost},
+		{"ARM endpoint", c.ARMEndpoint},
+		{"Graph endpoint", c.GraphEndpoint},
+	} {
+		if e.value == "" {
+			return fmt.Errorf("cloud %s: missing %s", c.Name, e.name)
+		}
+		u, err := url.Parse(e.value)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("cloud %s: %s %q must be an https URL", c.Name, e.name, e.value)
+		}
+	}
+	return nil
+}
+
+// configuration returns the azcore cloud configuration for credentials.
+func (c Cloud) configuration() cloud.Configuration {
+	return cloud.Configuration{
+		ActiveDirectoryAuthorityHost: c.AuthorityHost + "/",
+		Services: map[cloud.ServiceName]cloud.ServiceConfiguration{
+			cloud.ResourceManager: {Audience: c.ARMEndpoint, Endpoint: c.ARMEndpoint},
+		},
+	}
+}
+
+// armScope is the OAuth scope for ARM tokens.
+func (c Cloud) armScope() string { return c.ARMEndpoint + "/.default" }
+
+// graphScope is the OAuth scope for Microsoft Graph tokens.
+func (c Cloud) graphScope() string { return c.GraphEndpoint + "/.default" }
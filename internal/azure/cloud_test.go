@@ -0,0 +1,57 @@
+package azure
+
+import "testing"
+
+func TestLookupCloud(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		overrides Cloud
+		wantARM   string
+		wantGraph string
+		wantErr   bool
+	}{
+		{"empty defaults to public", "", Cloud{}, "https://management.azure.com", "https://graph.microsoft.com", false},
+		{"cli name", "AzureCloud", Cloud{}, "https://management.azure.com", "https://graph.microsoft.com", false},
+		{"us government", "azureusgovernment", Cloud{}, "https://management.usgovcloudapi.net", "https://graph.microsoft.us", false},
+		{"china", "AzureChina", Cloud{}, "https://management.chinacloudapi.cn", "https://microsoftgraph.chinacloudapi.cn", false},
+		{"override one endpoint", "AzurePublic", Cloud{GraphEndpoint: "https://graph.example.com/v1.0/"}, "https://management.azure.com", "https://graph.example.com", false},
+		{"custom with all endpoints", "Custom", Cloud{
+			AuthorityHost: "https://login.example.com/",
+			ARMEndpoint:   "https://arm.example.com/",
+			GraphEndpoint: "https://graph.example.com",
+		}, "https://arm.example.com", "https://graph.example.com", false},
+		{"custom missing endpoints", "custom", Cloud{ARMEndpoint: "https://arm.example.com"}, "", "", true},
+		{"non-https endpoint", "AzurePublic", Cloud{ARMEndpoint: "http://arm.example.com"}, "", "", true},
+		{"unknown", "AzureGermany", Cloud{}, "", "", true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := LookupCloud(tc.input, tc.overrides)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("LookupCloud(%q) = %+v, want error", tc.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LookupCloud(%q) unexpected error: %v", tc.input, err)
+			}
+			if got.ARMEndpoint != tc.wantARM || got.GraphEndpoint != tc.wantGraph {
+				t.Errorf("LookupCloud(%q) = %s / %s, want %s / %s", tc.input, got.ARMEndpoint, got.GraphEndpoint, tc.wantARM, tc.wantGraph)
+			}
+		})
+	}
+}
+
+func TestCloudScopes(t *testing.T) {
+	if got := AzureUSGovernment.armScope(); got != "https://management.usgovcloudapi.net/.default" {
+		t.Errorf("armScope = %q", got)
+	}
+	if got := AzureChina.graphScope(); got != "https://microsoftgraph.chinacloudapi.cn/.default" {
+		t.Errorf("graphScope = %q", got)
+	}
+	if got := AzureUSGovernment.configuration().ActiveDirectoryAuthorityHost; got != "https://login.microsoftonline.us/" {
+		t.Errorf("authority host = %q", got)
+	}
+}
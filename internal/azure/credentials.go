@@ -0,0 +1,198 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+// Credential sources accepted by ParseCredentialSources, in the order of
+// the default chain.
+const (
+	SourceAzureCLI         = "azure-cli"
+	SourcePowerShell       = "powershell"
+	SourceDeviceCode       = "device-code"
+	SourceBrowser          = "browser"
+	SourceEnvironment      = "environment"
+	SourceWorkloadIdentity = "workload-identity"
+)
+
+// sourceCustom is reported by CredentialSource for a credential passed in
+// ClientOptions.Credential.
+const sourceCustom = "custom"
+
+// DefaultCredentialSources is the chain used when no sources are configured.
+// device-code is only attempted when PIM_ALLOW_DEVICE_LOGIN is set.
+var DefaultCredentialSources = []string{SourceAzureCLI, SourcePowerShell, SourceDeviceCode}
+
+// CredentialSources returns every accepted source name for help text and completion.
+func CredentialSources() []string {
+	return []string{SourceAzureCLI, SourcePowerShell, SourceDeviceCode, SourceBrowser, SourceEnvironment, SourceWorkloadIdentity}
+}
+
+// ParseCredentialSources splits a comma-separated source list, e.g.
+// "azure-cli,device-code", validating and de-duplicating the names while
+// keeping their order. An empty string returns nil.
+func ParseCredentialSources(s string) ([]string, error) {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		name := strings.ToLower(strings.TrimSpace(part))
+		if name == "" {
+			continue
+		}
+		if err := validateSource(name); err != nil {
+			return nil, err
+		}
+		if !containsFold(out, name) {
+			out = append(out, name)
+		}
+	}
+	return out, nil
+}
+
+func validateSource(name string) error {
+	if containsFold(CredentialSources(), name) {
+		return nil
+	}
+	return fmt.Errorf("unknown credential source %q: must be one of %s", name, strings.Join(CredentialSources(), ", "))
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// newCredential constructs the credential for one source. The Azure CLI and
+// PowerShell use their own active cloud and proxy settings; the others
+// authenticate against the cloud's authority host through transport, or the
+// SDK's default transport when nil.
+func newCredential(source string, cl Cloud, tenantID string, transport policy.Transporter) (azcore.TokenCredential, error) {
+	clientOpts := azcore.ClientOptions{Cloud: cl.configuration(), Transport: transport}
+	switch source {
+	case SourceAzureCLI:
+		return azidentity.NewAzureCLICredential(&azidentity.AzureCLICredentialOptions{TenantID: tenantID})
+	case SourcePowerShell:
+		return azidentity.NewAzurePowerShellCredential(&azidentity.AzurePowerShellCredentialOptions{TenantID: tenantID})
+	case SourceDeviceCode:
+		return azidentity.NewDeviceCodeCredential(&azidentity.DeviceCodeCredentialOptions{
+			ClientOptions: clientOpts,
+			TenantID:      tenantID,
+			UserPrompt: func(_ context.Context, msg azidentity.DeviceCodeMessage) error {
+				fmt.Fprintln(os.Stderr, msg.Message)
+				return nil
+			},
+		})
+	case SourceBrowser:
+		return azidentity.NewInteractiveBrowserCredential(&azidentity.InteractiveBrowserCredentialOptions{
+			ClientOptions: clientOpts,
+			TenantID:      tenantID,
+		})
+	case SourceEnvironment:
+		// EnvironmentCredential reads AZURE_TENANT_ID itself and has no
+		// tenant override.
+		return azidentity.NewEnvironmentCredential(&azidentity.EnvironmentCredentialOptions{ClientOptions: clientOpts})
+	case SourceWorkloadIdentity:
+		// Reads AZURE_CLIENT_ID and AZURE_FEDERATED_TOKEN_FILE.
+		return azidentity.NewWorkloadIdentityCredential(&azidentity.WorkloadIdentityCredentialOptions{
+			ClientOptions: clientOpts,
+			TenantID:      tenantID,
+		})
+	}
+	return nil, validateSource(source)
+}
+
+// isInteractiveSource reports whether source can prompt the user and so
+// answer claims challenges the other sources cannot.
+func isInteractiveSource(source string) bool {
+	return source == SourceDeviceCode || source == SourceBrowser
+}
+
+// sourceTracker records which credential source last produced a token.
+type sourceTracker struct {
+	mu   sync.Mutex
+	last string
+}
+
+func (t *sourceTracker) set(source string) {
+	t.mu.Lock()
+	t.last = source
+	t.mu.Unlock()
+}
+
+func (t *sourceTracker) get() string {
+	if t == nil {
+		return ""
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.last
+}
+
+// namedCredential wraps a credential so successful token requests record
+// its source name. Errors pass through unchanged so the chain still moves on
+// when a source is unavailable. tenantID, when set, is requested for tokens
+// that name no tenant; it is set only for ClientOptions.Credential, since the
+// chain's credentials are built for their tenant.
+type namedCredential struct {
+	source   string
+	cred     azcore.TokenCredential
+	tracker  *sourceTracker
+	tenantID string
+}
+
+func (n namedCredential) GetToken(ctx context.Context, opts policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	if opts.TenantID == "" {
+		opts.TenantID = n.tenantID
+	}
+	tok, err := n.cred.GetToken(ctx, opts)
+	if err == nil {
+		n.tracker.set(n.source)
+	}
+	return tok, err
+}
+
+// buildChain constructs the credentials for sources in order. Sources that
+// cannot be constructed (e.g. missing environment variables) are skipped;
+// when none remain, the construction errors are returned with ErrNoCredential.
+// With the default sources, device-code is skipped unless device login is
+// allowed. stepUp is the first interactive credential, if any.
+func buildChain(sources []string, cl Cloud, tenantID string, transport policy.Transporter, tracker *sourceTracker) (chain []azcore.TokenCredential, stepUp azcore.TokenCredential, err error) {
+	explicit := len(sources) > 0
+	if !explicit {
+		sources = DefaultCredentialSources
+	}
+	var errs []error
+	for _, source := range sources {
+		if source == SourceDeviceCode && !explicit && !allowDeviceLogin() {
+			continue
+		}
+		cred, err := newCredential(source, cl, tenantID, transport)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", source, err))
+			continue
+		}
+		named := namedCredential{source: source, cred: cred, tracker: tracker}
+		chain = append(chain, named)
+		if stepUp == nil && isInteractiveSource(source) {
+			stepUp = named
+		}
+	}
+	if len(chain) == 0 {
+		if explicit && len(errs) > 0 {
+			return nil, nil, fmt.Errorf("%w: %w", ErrNoCredential, errors.Join(errs...))
+		}
+		return nil, nil, ErrNoCredential
+	}
+	return chain, stepUp, nil
+}
@@ -0,0 +1,107 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+func TestParseCredentialSources(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"azure-cli", []string{"azure-cli"}, false},
+		{" Device-Code , azure-cli ,device-code", []string{"device-code", "azure-cli"}, false},
+		{"workload-identity,environment", []string{"workload-identity", "environment"}, false},
+		{"azure-cli,managed-identity", nil, true},
+	}
+	for _, tc := range tests {
+		got, err := ParseCredentialSources(tc.input)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("ParseCredentialSources(%q) = %v, want error", tc.input, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("ParseCredentialSources(%q) unexpected error: %v", tc.input, err)
+		}
+		if len(got) != len(tc.want) {
+			t.Fatalf("ParseCredentialSources(%q) = %v, want %v", tc.input, got, tc.want)
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("ParseCredentialSources(%q)[%d] = %q, want %q", tc.input, i, got[i], tc.want[i])
+			}
+		}
+	}
+}
+
+func TestBuildChainDeviceCodeGate(t *testing.T) {
+	t.Setenv("PIM_ALLOW_DEVICE_LOGIN", "")
+	tracker := &sourceTracker{}
+
+	// Default chain: device code needs PIM_ALLOW_DEVICE_LOGIN.
+	chain, stepUp, err := buildChain(nil, AzurePublic, "", nil, tracker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range chain {
+		if c.(namedCredential).source == SourceDeviceCode {
+			t.Errorf("default chain includes device-code without PIM_ALLOW_DEVICE_LOGIN")
+		}
+	}
+	if stepUp != nil {
+		t.Errorf("stepUp = %v, want nil without an interactive source", stepUp)
+	}
+
+	// Explicit selection always includes it and uses it for step-up.
+	chain, stepUp, err = buildChain([]string{SourceDeviceCode}, AzurePublic, "", nil, tracker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chain) != 1 || chain[0].(namedCredential).source != SourceDeviceCode || stepUp == nil {
+		t.Errorf("explicit device-code chain = %v, stepUp = %v", chain, stepUp)
+	}
+}
+
+func TestBuildChainNoUsableSource(t *testing.T) {
+	t.Setenv("AZURE_CLIENT_ID", "")
+	t.Setenv("AZURE_FEDERATED_TOKEN_FILE", "")
+	t.Setenv("AZURE_TENANT_ID", "")
+	_, _, err := buildChain([]string{SourceWorkloadIdentity}, AzurePublic, "", nil, &sourceTracker{})
+	if !errors.Is(err, ErrNoCredential) {
+		t.Fatalf("error = %v, want ErrNoCredential", err)
+	}
+}
+
+type staticCred struct{ err error }
+
+func (s staticCred) GetToken(context.Context, policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "t"}, s.err
+}
+
+func TestNamedCredentialRecordsSource(t *testing.T) {
+	tracker := &sourceTracker{}
+	failing := namedCredential{source: SourceAzureCLI, cred: staticCred{err: errors.New("no login")}, tracker: tracker}
+	working := namedCredential{source: SourcePowerShell, cred: staticCred{}, tracker: tracker}
+
+	if _, err := failing.GetToken(context.Background(), policy.TokenRequestOptions{}); err == nil {
+		t.Fatal("expected error from failing credential")
+	}
+	if got := tracker.get(); got != "" {
+		t.Errorf("source after failure = %q, want empty", got)
+	}
+	if _, err := working.GetToken(context.Background(), policy.TokenRequestOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := (&Client{source: tracker}).CredentialSource(); got != SourcePowerShell {
+		t.Errorf("CredentialSource() = %q, want %q", got, SourcePowerShell)
+	}
+}
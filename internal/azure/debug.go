@@ -0,0 +1,92 @@
+package azure
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// debugLog writes one line per HTTP attempt doRequest makes: method, URL,
+// status or transport error, latency, attempt number and the request IDs
+// Azure support asks for. A nil *debugLog logs nothing.
+type debugLog struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func newDebugLog(w io.Writer) *debugLog {
+	if w == nil {
+		return nil
+	}
+	return &debugLog{w: w}
+}
+
+// attempt logs the outcome of attempt (0-based) of a request.
+func (l *debugLog) attempt(method, reqURL string, attempt int, resp *http.Response, err error, elapsed time.Duration) {
+	if l == nil {
+		return
+	}
+	line := fmt.Sprintf("%s %s %s", time.Now().UTC().Format(time.RFC3339Nano), method, redact(reqURL))
+	if err != nil {
+		line += fmt.Sprintf(" error=%q", redact(err.Error()))
+	} else {
+		line += fmt.Sprintf(" status=%d", resp.StatusCode)
+	}
+	line += fmt.Sprintf(" latency=%s attempt=%d", elapsed.Round(time.Millisecond), attempt+1)
+	if resp != nil {
+		if id := requestID(resp.Header); id != "" {
+			line += " request-id=" + id
+		}
+		if id := resp.Header.Get(headerCorrelationID); id != "" {
+			line += " correlation-id=" + id
+		}
+	}
+	l.printf("%s\n", line)
+}
+
+// retry logs the wait before the next attempt.
+func (l *debugLog) retry(method, reqURL string, wait time.Duration) {
+	if l == nil {
+		return
+	}
+	l.printf("%s %s %s retrying in %s\n", time.Now().UTC().Format(time.RFC3339Nano), method, redact(reqURL), wait.Round(time.Millisecond))
+}
+
+func (l *debugLog) printf(format string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fmt.Fprintf(l.w, format, args...)
+}
+
+// Request ID headers. ARM sets x-ms-request-id and echoes or assigns
+// x-ms-correlation-request-id; Graph sets request-id instead.
+const (
+	headerRequestID      = "x-ms-request-id"
+	headerGraphRequestID = "request-id"
+	headerCorrelationID  = "x-ms-correlation-request-id"
+)
+
+func requestID(h http.Header) string {
+	if id := h.Get(headerRequestID); id != "" {
+		return id
+	}
+	return h.Get(headerGraphRequestID)
+}
+
+var (
+	bearerPattern = regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9\-_.~+/]+=*`)
+	jwtPattern    = regexp.MustCompile(`eyJ[A-Za-z0-9\-_]+\.[A-Za-z0-9\-_]+\.[A-Za-z0-9\-_]*`)
+	// principalPattern matches principal IDs in $filter expressions, raw or
+	// query-escaped.
+	principalPattern = regexp.MustCompile(`(?i)(principalId(?:\s|\+|%20)+eq(?:\s|\+|%20)+(?:'|%27))[^'%&]+`)
+)
+
+// redact removes bearer tokens, JWTs and principal IDs from s.
+func redact(s string) string {
+	s = bearerPattern.ReplaceAllString(s, "${1}REDACTED")
+	s = jwtPattern.ReplaceAllString(s, "REDACTED")
+	return principalPattern.ReplaceAllString(s, "${1}REDACTED")
+}
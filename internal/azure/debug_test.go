@@ -0,0 +1,72 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Authorization: Bearer abc.def-ghi", "Authorization: Bearer REDACTED"},
+		{"token eyJhbGciOi.eyJ0aWQiOi.c2ln here", "token REDACTED here"},
+		{"$filter=principalId eq 'uid-1' and roleDefinitionId eq 'rd-1'", "$filter=principalId eq 'REDACTED' and roleDefinitionId eq 'rd-1'"},
+		{"$filter=principalId+eq+%27uid-1%27+and+roleDefinitionId", "$filter=principalId+eq+%27REDACTED%27+and+roleDefinitionId"},
+		{"$filter=asTarget()", "$filter=asTarget()"},
+	}
+	for _, tc := range tests {
+		if got := redact(tc.in); got != tc.want {
+			t.Errorf("redact(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestDoRequestDebugLog(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(headerRequestID, "req-1")
+		w.Header().Set(headerCorrelationID, "corr-1")
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error":{"code":"AuthorizationFailed","message":"denied"}}`))
+	}))
+	defer srv.Close()
+
+	var log strings.Builder
+	client := testRetryClient(srv)
+	client.debug = newDebugLog(&log)
+	_, err := client.doRequest(context.Background(), http.MethodGet, srv.URL+"/x?$filter=principalId+eq+%27uid-1%27", "secret-token", nil)
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.RequestID != "req-1" || apiErr.CorrelationID != "corr-1" {
+		t.Fatalf("error = %#v, want request and correlation IDs", err)
+	}
+	if !strings.Contains(err.Error(), "(request ID req-1, correlation ID corr-1)") {
+		t.Errorf("Error() = %q, want the request IDs", err.Error())
+	}
+
+	lines := strings.Split(strings.TrimSpace(log.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("log has %d lines, want attempt, retry, attempt:\n%s", len(lines), log.String())
+	}
+	for _, want := range []string{"GET ", "status=503", "attempt=1", "request-id=req-1", "correlation-id=corr-1"} {
+		if !strings.Contains(lines[0], want) {
+			t.Errorf("first line %q lacks %q", lines[0], want)
+		}
+	}
+	if !strings.Contains(lines[1], "retrying in") || !strings.Contains(lines[2], "status=403 ") || !strings.Contains(lines[2], "attempt=2") {
+		t.Errorf("log =\n%s", log.String())
+	}
+	if strings.Contains(log.String(), "uid-1") || strings.Contains(log.String(), "secret-token") {
+		t.Errorf("log leaks the principal ID or token:\n%s", log.String())
+	}
+}
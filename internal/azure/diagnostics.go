@@ -0,0 +1,163 @@
+package azure
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+// credentialCheckTimeout bounds each source CheckCredentials tries; the Azure
+// CLI and PowerShell credentials start a process.
+const credentialCheckTimeout = 30 * time.Second
+
+// TokenInfo describes an ARM access token. The claims are decoded without
+// verifying the signature and are only meant for display.
+type TokenInfo struct {
+	// Source is the credential source that produced the token.
+	Source string
+	// TenantID is the tid claim, or the client's tenant when the token
+	// carries no readable claims.
+	TenantID  string
+	ObjectID  string // oid claim
+	UPN       string // upn, unique_name or preferred_username claim
+	ExpiresOn time.Time
+}
+
+// ARMTokenInfo requests an ARM token through the client's credential chain
+// and decodes its claims. ExpiresOn is the exp claim, or the expiry the
+// credential reported for opaque tokens.
+func (c *Client) ARMTokenInfo(ctx context.Context) (TokenInfo, error) {
+	scope := c.cloud.armScope()
+	tok, err := c.cred.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{scope}})
+	if err != nil {
+		return TokenInfo{}, &TokenError{Scope: scope, Err: err}
+	}
+	info := tokenInfo(tok)
+	info.Source = c.CredentialSource()
+	if info.TenantID == "" {
+		info.TenantID = c.tenantID
+	}
+	return info, nil
+}
+
+// tokenClaims are the access token claims TokenInfo reports.
+type tokenClaims struct {
+	TenantID          string `json:"tid"`
+	ObjectID          string `json:"oid"`
+	UPN               string `json:"upn"`
+	UniqueName        string `json:"unique_name"`
+	PreferredUsername string `json:"preferred_username"`
+	Expiry            int64  `json:"exp"`
+}
+
+func tokenInfo(tok azcore.AccessToken) TokenInfo {
+	info := TokenInfo{ExpiresOn: tok.ExpiresOn}
+	parts := strings.Split(tok.Token, ".")
+	if len(parts) != 3 {
+		return info
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return info
+	}
+	var claims tokenClaims
+	if json.Unmarshal(payload, &claims) != nil {
+		return info
+	}
+	info.TenantID, info.ObjectID = claims.TenantID, claims.ObjectID
+	for _, name := range []string{claims.UPN, claims.UniqueName, claims.PreferredUsername} {
+		if name != "" {
+			info.UPN = name
+			break
+		}
+	}
+	if claims.Expiry > 0 {
+		info.ExpiresOn = time.Unix(claims.Expiry, 0)
+	}
+	return info
+}
+
+// CredentialCheck is the result of requesting an ARM token from one
+// credential source on its own.
+type CredentialCheck struct {
+	Source string
+	// Skipped says why the source was not tried; Err and ExpiresOn are
+	// unset then.
+	Skipped   string
+	ExpiresOn time.Time
+	Err       error
+}
+
+// CheckCredentials requests an ARM token from every source in the client's
+// chain separately, in chain order, so a failing source is reported even when
+// a later one works. Interactive sources are skipped rather than prompting.
+func (c *Client) CheckCredentials(ctx context.Context) []CredentialCheck {
+	opts := policy.TokenRequestOptions{Scopes: []string{c.cloud.armScope()}}
+	try := func(check CredentialCheck, cred azcore.TokenCredential) CredentialCheck {
+		tctx, cancel := context.WithTimeout(ctx, credentialCheckTimeout)
+		defer cancel()
+		tok, err := cred.GetToken(tctx, opts)
+		check.ExpiresOn, check.Err = tok.ExpiresOn, err
+		return check
+	}
+	if c.fixedCred != nil {
+		return []CredentialCheck{try(CredentialCheck{Source: sourceCustom}, c.fixedCred)}
+	}
+
+	sources := c.sources
+	explicit := len(sources) > 0
+	if !explicit {
+		sources = DefaultCredentialSources
+	}
+	var checks []CredentialCheck
+	for _, source := range sources {
+		check := CredentialCheck{Source: source}
+		switch {
+		case source == SourceDeviceCode && !explicit && !allowDeviceLogin():
+			check.Skipped = "not in the chain; set PIM_ALLOW_DEVICE_LOGIN=1 to add it"
+		case isInteractiveSource(source):
+			check.Skipped = "interactive; not tried"
+		default:
+			cred, err := newCredential(source, c.cloud, c.tenantID, c.httpClient)
+			if err != nil {
+				check.Err = err
+				break
+			}
+			check = try(check, cred)
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// EndpointCheck is the result of one eligibility endpoint CheckEndpoints calls.
+type EndpointCheck struct {
+	Name  string
+	Roles int // eligibilities returned
+	Err   error
+}
+
+// CheckEndpoints lists eligibilities from ARM, Graph directory roles and PIM
+// for Groups separately. Unlike GetEligibleRoles it reports access denied
+// from Graph instead of skipping that source.
+func (c *Client) CheckEndpoints(ctx context.Context) []EndpointCheck {
+	probes := []struct {
+		name string
+		list func(context.Context) ([]Role, error)
+	}{
+		{"ARM role eligibility", c.getEligibleResourceRoles},
+		{"Graph directory role eligibility", c.getEligibleDirectoryRoles},
+		{"Graph group eligibility", c.getEligibleGroupRoles},
+	}
+	checks := make([]EndpointCheck, len(probes))
+	for i, p := range probes {
+		roles, err := p.list(ctx)
+		checks[i] = EndpointCheck{Name: p.name, Roles: len(roles), Err: err}
+	}
+	return checks
+}
@@ -0,0 +1,28 @@
+package azure
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+)
+
+func TestTokenInfo(t *testing.T) {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(
+		`{"tid":"tid-1","oid":"oid-1","unique_name":"user@example.com","exp":1893456000}`))
+	expiresOn := time.Date(2029, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	info := tokenInfo(azcore.AccessToken{Token: "e30." + payload + ".sig", ExpiresOn: expiresOn})
+	if info.TenantID != "tid-1" || info.ObjectID != "oid-1" || info.UPN != "user@example.com" {
+		t.Errorf("claims = %+v", info)
+	}
+	if want := time.Unix(1893456000, 0); !info.ExpiresOn.Equal(want) {
+		t.Errorf("ExpiresOn = %v, want %v", info.ExpiresOn, want)
+	}
+
+	opaque := tokenInfo(azcore.AccessToken{Token: "opaque-token", ExpiresOn: expiresOn})
+	if opaque.TenantID != "" || !opaque.ExpiresOn.Equal(expiresOn) {
+		t.Errorf("opaque token = %+v", opaque)
+	}
+}
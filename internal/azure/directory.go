@@ -0,0 +1,251 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// directoryRequest is the Graph unifiedRoleAssignmentScheduleRequest body.
+type directoryRequest struct {
+	Action           string        `json:"action"`
+	PrincipalID      string        `json:"principalId"`
+	RoleDefinitionID string        `json:"roleDefinitionId"`
+	DirectoryScopeID string        `json:"directoryScopeId"`
+	Justification    string        `json:"justification,omitempty"`
+	ScheduleInfo     *ScheduleInfo `json:"scheduleInfo,omitempty"`
+	TicketInfo       *TicketInfo   `json:"ticketInfo,omitempty"`
+}
+
+// getEligibleDirectoryRoles fetches the caller's eligible Microsoft Entra ID
+// directory roles from Microsoft Graph, following pagination.
+func (c *Client) getEligibleDirectoryRoles(ctx context.Context) ([]Role, error) {
+	tok, err := c.graphToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reqURL := c.graphURL + "/roleManagement/directory/roleEligibilityScheduleInstances/filterByCurrentUser(on='principal')?$expand=roleDefinition"
+
+	var roles []Role
+	for reqURL != "" {
+		resp, err := c.doRequest(ctx, http.MethodGet, reqURL, tok, nil)
+		if err != nil {
+			return nil, fmt.Errorf("get eligible directory roles: %w", err)
+		}
+
+		var result struct {
+			Value []struct {
+				RoleDefinitionID          string `json:"roleDefinitionId"`
+				DirectoryScopeID          string `json:"directoryScopeId"`
+				RoleEligibilityScheduleID string `json:"roleEligibilityScheduleId"`
+				MemberType                string `json:"memberType"`
+				StartDateTime             string `json:"startDateTime"`
+				EndDateTime               string `json:"endDateTime"`
+				RoleDefinition            struct {
+					DisplayName string `json:"displayName"`
+				} `json:"roleDefinition"`
+			} `json:"value"`
+			NextLink string `json:"@odata.nextLink"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			resp.Body.Close()
+			return nil, fmt.Errorf("decode directory roles: %w", err)
+		}
+		resp.Body.Close()
+
+		for _, item := range result.Value {
+			scope := DirectoryScope(item.DirectoryScopeID)
+			roles = append(roles, Role{
+				Scope:                 scope,
+				ScopeDisplay:          DefaultScopeDisplay(scope, ""),
+				RoleName:              item.RoleDefinition.DisplayName,
+				RoleDefinitionID:      item.RoleDefinitionID,
+				EligibilityScheduleID: item.RoleEligibilityScheduleID,
+				EligibilityStart:      item.StartDateTime,
+				EligibilityEnd:        item.EndDateTime,
+				MemberType:            item.MemberType,
+			})
+		}
+		reqURL = result.NextLink
+	}
+	return roles, nil
+}
+
+// getActiveDirectoryAssignments fetches the caller's active Microsoft Entra ID
+// directory role assignments from Microsoft Graph, following pagination.
+func (c *Client) getActiveDirectoryAssignments(ctx context.Context) ([]ActiveAssignment, error) {
+	tok, err := c.graphToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reqURL := c.graphURL + "/roleManagement/directory/roleAssignmentScheduleInstances/filterByCurrentUser(on='principal')?$expand=roleDefinition"
+
+	var out []ActiveAssignment
+	for reqURL != "" {
+		resp, err := c.doRequest(ctx, http.MethodGet, reqURL, tok, nil)
+		if err != nil {
+			return nil, fmt.Errorf("get active directory assignments: %w", err)
+		}
+
+		var result struct {
+			Value []struct {
+				ID               string `json:"id"`
+				RoleDefinitionID string `json:"roleDefinitionId"`
+				DirectoryScopeID string `json:"directoryScopeId"`
+				MemberType       string `json:"memberType"`
+				StartDateTime    string `json:"startDateTime"`
+				EndDateTime      string `json:"endDateTime"`
+				RoleDefinition   struct {
+					DisplayName string `json:"displayName"`
+				} `json:"roleDefinition"`
+			} `json:"value"`
+			NextLink string `json:"@odata.nextLink"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			resp.Body.Close()
+			return nil, fmt.Errorf("decode active directory assignments: %w", err)
+		}
+		resp.Body.Close()
+
+		for _, item := range result.Value {
+			scope := DirectoryScope(item.DirectoryScopeID)
+			out = append(out, ActiveAssignment{
+				Name:             item.ID,
+				Scope:            scope,
+				ScopeDisplay:     DefaultScopeDisplay(scope, ""),
+				RoleName:         item.RoleDefinition.DisplayName,
+				RoleDefinitionID: item.RoleDefinitionID,
+				StartDateTime:    item.StartDateTime,
+				EndDateTime:      item.EndDateTime,
+				MemberType:       item.MemberType,
+			})
+		}
+		reqURL = result.NextLink
+	}
+	return out, nil
+}
+
+// isDirectoryRoleActive reports whether the caller already holds the directory
+// role at the given directory scope.
+func (c *Client) isDirectoryRoleActive(ctx context.Context, scope, roleDefinitionID string) (bool, error) {
+	tok, err := c.graphToken(ctx)
+	if err != nil {
+		return false, err
+	}
+	filter := fmt.Sprintf("roleDefinitionId eq '%s' and directoryScopeId eq '%s'", roleDefinitionID, DirectoryScopeIDFromScope(scope))
+	reqURL := c.graphURL + "/roleManagement/directory/roleAssignmentScheduleInstances/filterByCurrentUser(on='principal')?$filter=" + url.QueryEscape(filter)
+
+	resp, err := c.doRequest(ctx, http.MethodGet, reqURL, tok, nil)
+	if err != nil {
+		return false, fmt.Errorf("check active directory role: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Value []any `json:"value"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return false, fmt.Errorf("decode active directory role check: %w", err)
+	}
+	return len(result.Value) > 0, nil
+}
+
+// activateDirectoryRole submits a self-activation or extension request for a
+// directory role via Microsoft Graph.
+func (c *Client) activateDirectoryRole(ctx context.Context, ar ActivationRequest) (*ScheduleResponse, error) {
+	role := ar.Role
+	action := "selfActivate"
+	if !ar.IsScheduled() {
+		active, err := c.isDirectoryRoleActive(ctx, role.Scope, role.RoleDefinitionID)
+		if err != nil {
+			return nil, err
+		}
+		if active {
+			action = "selfExtend"
+		}
+	}
+	req := directoryRequest{
+		Action:           action,
+		PrincipalID:      ar.PrincipalID,
+		RoleDefinitionID: role.RoleDefinitionID,
+		DirectoryScopeID: DirectoryScopeIDFromScope(role.Scope),
+		Justification:    ar.Justification,
+		TicketInfo:       ar.Ticket.ptr(),
+		ScheduleInfo:     ar.scheduleInfo("afterDuration"),
+	}
+	resp, err := c.submitDirectoryRequest(ctx, req)
+	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == 400 &&
+			(strings.EqualFold(apiErr.Code, errCodePendingRequest) || strings.EqualFold(apiErr.Code, errCodeAssignmentExists)) {
+			return &ScheduleResponse{AlreadyActive: IsAlreadyActive(err)}, nil
+		}
+		return nil, fmt.Errorf("submit directory activation: %w", err)
+	}
+	return resp, nil
+}
+
+// deactivateDirectoryRole submits a self-deactivation request for a directory role.
+func (c *Client) deactivateDirectoryRole(ctx context.Context, assignment ActiveAssignment, principalID string) (*ScheduleResponse, error) {
+	req := directoryRequest{
+		Action:           "selfDeactivate",
+		PrincipalID:      principalID,
+		RoleDefinitionID: assignment.RoleDefinitionID,
+		DirectoryScopeID: DirectoryScopeIDFromScope(assignment.Scope),
+	}
+	resp, err := c.submitDirectoryRequest(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("submit directory deactivation: %w", err)
+	}
+	return resp, nil
+}
+
+func (c *Client) submitDirectoryRequest(ctx context.Context, req directoryRequest) (*ScheduleResponse, error) {
+	return c.postGraphScheduleRequest(ctx, "/roleManagement/directory/roleAssignmentScheduleRequests", req)
+}
+
+// postGraphScheduleRequest POSTs a Graph schedule request body to path,
+// relative to the Graph base URL, answering claims challenges with a
+// stepped-up token.
+func (c *Client) postGraphScheduleRequest(ctx context.Context, path string, req any) (*ScheduleResponse, error) {
+	tok, err := c.graphToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+	resp, err := c.doRequestWithStepUp(ctx, http.MethodPost, c.graphURL+path, c.cloud.graphScope(), tok, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return decodeGraphScheduleResponse(resp.Body)
+}
+
+// decodeGraphScheduleResponse maps a Graph schedule request response onto ScheduleResponse.
+func decodeGraphScheduleResponse(r io.Reader) (*ScheduleResponse, error) {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	if len(body) == 0 {
+		return &ScheduleResponse{}, nil
+	}
+	var result struct {
+		ID     string `json:"id"`
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	out := &ScheduleResponse{Name: result.ID}
+	out.Properties.Status = result.Status
+	return out, nil
+}
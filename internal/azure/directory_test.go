@@ -0,0 +1,198 @@
+package azure
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// graphCall is a request recorded by newGraphTestClient.
+type graphCall struct {
+	method string
+	path   string
+	body   map[string]any
+}
+
+// newGraphTestClient returns a client whose Graph requests go to a test
+// server. GETs are answered with list; POSTs with a pending schedule request.
+// Every request is recorded in calls.
+func newGraphTestClient(t *testing.T, list string) (c *Client, calls *[]graphCall) {
+	t.Helper()
+	calls = &[]graphCall{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := graphCall{method: r.Method, path: r.URL.Path}
+		if r.Method == http.MethodPost {
+			if err := json.NewDecoder(r.Body).Decode(&call.body); err != nil {
+				t.Errorf("decode body: %v", err)
+			}
+			*calls = append(*calls, call)
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":"req-1","status":"PendingProvisioning"}`))
+			return
+		}
+		*calls = append(*calls, call)
+		_, _ = w.Write([]byte(list))
+	}))
+	t.Cleanup(srv.Close)
+
+	c = testRetryClient(srv)
+	c.cred = &claimsCred{}
+	c.graphURL = srv.URL + "/v1.0"
+	return c, calls
+}
+
+// checkScheduleBody compares the fields of a Graph schedule request body
+// with want; a nil want["scheduleInfo"] means no schedule may be sent.
+func checkScheduleBody(t *testing.T, body, want map[string]any) {
+	t.Helper()
+	for k, v := range want {
+		if k == "scheduleInfo" {
+			continue
+		}
+		if body[k] != v {
+			t.Errorf("body[%q] = %v, want %v", k, body[k], v)
+		}
+	}
+	wantInfo, _ := want["scheduleInfo"].(map[string]any)
+	info, ok := body["scheduleInfo"].(map[string]any)
+	if wantInfo == nil {
+		if ok {
+			t.Errorf("scheduleInfo = %v, want none", info)
+		}
+		return
+	}
+	if !ok {
+		t.Fatalf("body %v has no scheduleInfo", body)
+	}
+	exp, _ := info["expiration"].(map[string]any)
+	if exp["type"] != "afterDuration" || exp["duration"] != wantInfo["duration"] {
+		t.Errorf("expiration = %v, want afterDuration %v", exp, wantInfo["duration"])
+	}
+	start, err := time.Parse(time.RFC3339, info["startDateTime"].(string))
+	if err != nil {
+		t.Fatalf("startDateTime: %v", err)
+	}
+	if want, ok := wantInfo["start"].(time.Time); ok && !start.Equal(want) {
+		t.Errorf("startDateTime = %s, want %s", start, want)
+	} else if !ok && time.Since(start).Abs() > time.Minute {
+		t.Errorf("startDateTime = %s, want now", start)
+	}
+}
+
+func TestDirectoryRoleRequests(t *testing.T) {
+	const requestsPath = "/v1.0/roleManagement/directory/roleAssignmentScheduleRequests"
+	role := Role{Scope: DirectoryScope("/administrativeUnits/au-1"), RoleDefinitionID: "rd-1", RoleName: "User Administrator"}
+	start := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	activation := ActivationRequest{Role: role, PrincipalID: "p-1", Justification: "ticket", Minutes: 90}
+	scheduled := activation
+	scheduled.Start = start
+
+	tests := []struct {
+		name      string
+		active    string
+		run       func(*Client) (*ScheduleResponse, error)
+		wantCheck bool
+		want      map[string]any
+	}{
+		{
+			name:      "activate",
+			active:    `{"value":[]}`,
+			run:       func(c *Client) (*ScheduleResponse, error) { return c.ActivateRole(t.Context(), activation) },
+			wantCheck: true,
+			want: map[string]any{
+				"action": "selfActivate", "principalId": "p-1", "roleDefinitionId": "rd-1",
+				"directoryScopeId": "/administrativeUnits/au-1", "justification": "ticket",
+				"scheduleInfo": map[string]any{"duration": "PT1H30M"},
+			},
+		},
+		{
+			name:      "extend an active role",
+			active:    `{"value":[{"id":"inst-1"}]}`,
+			run:       func(c *Client) (*ScheduleResponse, error) { return c.ActivateRole(t.Context(), activation) },
+			wantCheck: true,
+			want: map[string]any{
+				"action": "selfExtend", "principalId": "p-1", "roleDefinitionId": "rd-1",
+				"directoryScopeId": "/administrativeUnits/au-1",
+				"scheduleInfo":     map[string]any{"duration": "PT1H30M"},
+			},
+		},
+		{
+			name: "scheduled activation skips the active check",
+			run:  func(c *Client) (*ScheduleResponse, error) { return c.ActivateRole(t.Context(), scheduled) },
+			want: map[string]any{
+				"action": "selfActivate", "roleDefinitionId": "rd-1",
+				"scheduleInfo": map[string]any{"duration": "PT1H30M", "start": start},
+			},
+		},
+		{
+			name: "deactivate",
+			run: func(c *Client) (*ScheduleResponse, error) {
+				return c.DeactivateRole(t.Context(), ActiveAssignment{Scope: role.Scope, RoleDefinitionID: "rd-1"}, "p-1")
+			},
+			want: map[string]any{
+				"action": "selfDeactivate", "principalId": "p-1", "roleDefinitionId": "rd-1",
+				"directoryScopeId": "/administrativeUnits/au-1", "justification": nil,
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c, calls := newGraphTestClient(t, tc.active)
+			resp, err := tc.run(c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.Name != "req-1" || resp.Status() != "PendingProvisioning" {
+				t.Errorf("response = %+v", resp)
+			}
+
+			wantCalls := 1
+			if tc.wantCheck {
+				wantCalls = 2
+			}
+			if len(*calls) != wantCalls {
+				t.Fatalf("calls = %+v, want %d", *calls, wantCalls)
+			}
+			if tc.wantCheck && !strings.HasPrefix((*calls)[0].path, "/v1.0/roleManagement/directory/roleAssignmentScheduleInstances/") {
+				t.Errorf("active check path = %q", (*calls)[0].path)
+			}
+			post := (*calls)[len(*calls)-1]
+			if post.method != http.MethodPost || post.path != requestsPath {
+				t.Errorf("request = %s %s, want POST %s", post.method, post.path, requestsPath)
+			}
+			checkScheduleBody(t, post.body, tc.want)
+		})
+	}
+}
+
+func TestGetEligibleDirectoryRoles(t *testing.T) {
+	c, calls := newGraphTestClient(t, `{"value":[{
+		"roleDefinitionId":"rd-1","directoryScopeId":"/","roleEligibilityScheduleId":"sched-1","memberType":"Direct",
+		"startDateTime":"2024-01-01T00:00:00Z","endDateTime":"2025-01-01T00:00:00Z",
+		"roleDefinition":{"displayName":"Global Reader"}
+	}]}`)
+
+	roles, err := c.getEligibleDirectoryRoles(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := (*calls)[0].path; got != "/v1.0/roleManagement/directory/roleEligibilityScheduleInstances/filterByCurrentUser(on='principal')" {
+		t.Errorf("path = %q", got)
+	}
+	want := Role{
+		Scope:                 DirectoryScope("/"),
+		ScopeDisplay:          DefaultScopeDisplay(DirectoryScope("/"), ""),
+		RoleName:              "Global Reader",
+		RoleDefinitionID:      "rd-1",
+		EligibilityScheduleID: "sched-1",
+		EligibilityStart:      "2024-01-01T00:00:00Z",
+		EligibilityEnd:        "2025-01-01T00:00:00Z",
+		MemberType:            "Direct",
+	}
+	if len(roles) != 1 || roles[0] != want {
+		t.Errorf("roles = %+v, want [%+v]", roles, want)
+	}
+}
@@ -34,13 +34,17 @@ func (c *Client) ListManagementGroupChildren(ctx context.Context, mgID string) (
 	return mgs, subs, nil
 }
 
+// classifyChildResources splits management group children into child
+// management groups and subscriptions. Resource groups and individual
+// resources are listed per subscription and resource group instead; see
+// ListEligibleResourceGroups and ListEligibleResources.
 func classifyChildResources(resources []childResource) ([]ManagementGroup, []Subscription) {
 	var mgs []ManagementGroup
 	var subs []Subscription
 	for _, item := range resources {
 		lower := strings.ToLower(item.Type)
 		switch {
-		case strings.Contains(lower, "resourcegroup"):
+		case strings.Contains(lower, "resourcegroup"), IsResourceScope(item.ID):
 		case strings.Contains(lower, "managementgroup"):
 			mgs = append(mgs, ManagementGroup{ID: item.Name, DisplayName: displayOr(item)})
 		case strings.Contains(lower, "subscription"):
@@ -165,7 +169,7 @@ func (c *Client) listManagementGroupChildrenWithToken(ctx context.Context, mgID,
 
 func (c *Client) fetchEligibleChildResourcesWithToken(ctx context.Context, scope, token string) ([]childResource, error) {
 	reqURL := fmt.Sprintf("%s%s/providers/Microsoft.Authorization/eligibleChildResources?api-version=%s&$getAllChildren=true",
-		armEndpoint, scope, eligibleChildResourcesAPIVersion)
+		c.armURL, scope, eligibleChildResourcesAPIVersion)
 
 	var out []childResource
 	for reqURL != "" {
@@ -206,7 +210,7 @@ func (c *Client) ListEligibleResourceGroups(ctx context.Context, subscriptionID
 	}
 	out := make([]ResourceGroup, 0, len(resources))
 	for _, item := range resources {
-		if !strings.Contains(strings.ToLower(item.Type), "resourcegroup") {
+		if !strings.Contains(strings.ToLower(item.Type), "resourcegroup") || IsResourceScope(item.ID) {
 			continue
 		}
 		_, name := ResourceGroupNameFromScope(item.ID)
@@ -218,6 +222,45 @@ func (c *Client) ListEligibleResourceGroups(ctx context.Context, subscriptionID
 	return out, nil
 }
 
+// ListEligibleResources lists individual resources (Key Vaults, storage
+// accounts, …) the caller is eligible to manage via PIM inside a resource
+// group, using the eligibleChildResources API at the resource group scope.
+func (c *Client) ListEligibleResources(ctx context.Context, subscriptionID, resourceGroup string) ([]Resource, error) {
+	if strings.TrimSpace(subscriptionID) == "" || strings.TrimSpace(resourceGroup) == "" {
+		return nil, fmt.Errorf("subscription id and resource group cannot be empty")
+	}
+	scope := ResourceGroup{SubscriptionID: subscriptionID, Name: resourceGroup}.Scope()
+	tok, err := c.armToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := c.fetchEligibleChildResourcesWithToken(ctx, scope, tok)
+	if err != nil {
+		return nil, err
+	}
+	return classifyResources(resources), nil
+}
+
+// classifyResources keeps the individual resources among eligible child
+// resources, dropping the container scopes.
+func classifyResources(resources []childResource) []Resource {
+	var out []Resource
+	for _, item := range resources {
+		if !IsResourceScope(item.ID) {
+			continue
+		}
+		typ, name := ResourceFromScope(item.ID)
+		if item.Type != "" {
+			typ = item.Type
+		}
+		if item.Name != "" {
+			name = item.Name
+		}
+		out = append(out, Resource{ID: item.ID, Name: name, Type: typ, DisplayName: displayOr(item)})
+	}
+	return out
+}
+
 func displayOr(item childResource) string {
 	if item.Properties.DisplayName != "" {
 		return item.Properties.DisplayName
@@ -1,6 +1,8 @@
 package azure
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -93,3 +95,40 @@ func TestClassifyChildResourcesRGsSkipped(t *testing.T) {
 		t.Fatalf("expected no results, got mgs=%d subs=%d", len(mgs), len(subs))
 	}
 }
+
+func TestListEligibleResources(t *testing.T) {
+	const rg = "/subscriptions/sub-1/resourceGroups/rg-app"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if want := rg + "/providers/Microsoft.Authorization/eligibleChildResources"; r.URL.Path != want {
+			t.Errorf("path = %q; want %q", r.URL.Path, want)
+		}
+		_, _ = w.Write([]byte(`{"value":[
+			{"id":"` + rg + `","name":"rg-app","type":"resourcegroup"},
+			{"id":"` + rg + `/providers/Microsoft.KeyVault/vaults/kv-prod","name":"kv-prod","type":"Microsoft.KeyVault/vaults","properties":{"displayName":"kv-prod"}},
+			{"id":"` + rg + `/providers/Microsoft.EventGrid/eventSubscriptions/sub-events","name":"sub-events","type":"Microsoft.EventGrid/eventSubscriptions"}
+		]}`))
+	}))
+	defer srv.Close()
+
+	c := testRetryClient(srv)
+	c.cred = &claimsCred{}
+	c.armURL = srv.URL
+
+	got, err := c.ListEligibleResources(t.Context(), "sub-1", "rg-app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d resources, want 2: %+v", len(got), got)
+	}
+	if got[0].Name != "kv-prod" || got[0].Type != "Microsoft.KeyVault/vaults" || got[0].Scope() != rg+"/providers/Microsoft.KeyVault/vaults/kv-prod" {
+		t.Errorf("resource = %+v", got[0])
+	}
+
+	// Resources returned under a management group are not mistaken for
+	// subscriptions, even when their type mentions one.
+	_, subs := classifyChildResources([]childResource{{ID: rg + "/providers/Microsoft.EventGrid/eventSubscriptions/sub-events", Type: "Microsoft.EventGrid/eventSubscriptions"}})
+	if len(subs) != 0 {
+		t.Errorf("classifyChildResources subs = %+v; want none", subs)
+	}
+}
@@ -2,24 +2,32 @@ package azure
 
 import (
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const (
-	minMinutes = 30
-	maxMinutes = 480
+	minMinutes = 5
+	// DefaultMaxMinutes is the activation ceiling assumed when no role
+	// management policy could be read: the Azure default of 8 hours.
+	DefaultMaxMinutes = 480
 )
 
-// ClampMinutes clamps minutes to [30, 480] rounded to 30-minute increments.
-func ClampMinutes(minutes int) int {
+// ClampMinutes clamps minutes to [5, maxMinutes]. A maxMinutes of zero or less
+// means the policy maximum is unknown and DefaultMaxMinutes is used.
+func ClampMinutes(minutes, maxMinutes int) int {
+	if maxMinutes <= 0 {
+		maxMinutes = DefaultMaxMinutes
+	}
 	if minutes < minMinutes {
-		return minMinutes
+		return min(minMinutes, maxMinutes)
 	}
 	if minutes > maxMinutes {
 		return maxMinutes
 	}
-	return ((minutes + 15) / 30) * 30
+	return minutes
 }
 
 // FormatDuration converts minutes to ISO 8601 duration (PT1H30M).
@@ -37,7 +45,8 @@ func FormatDuration(minutes int) string {
 }
 
 // ParseDurationMinutes parses a human duration string (1h, 30m, 1h30m, 1.5h) into minutes.
-// Input is case-insensitive. Negative values are rejected.
+// Input is case-insensitive. Negative and zero values are rejected; the upper
+// bound is enforced by ActivationPolicy.Validate, not here.
 func ParseDurationMinutes(s string) (int, error) {
 	if s == "" {
 		return 0, fmt.Errorf("empty duration")
@@ -52,7 +61,7 @@ func ParseDurationMinutes(s string) (int, error) {
 				if h < 0 || m < 0 {
 					return 0, fmt.Errorf("negative duration not allowed")
 				}
-				return ClampMinutes(h*60 + m), nil
+				return positiveMinutes(h*60 + m)
 			}
 		}
 	}
@@ -64,13 +73,13 @@ func ParseDurationMinutes(s string) (int, error) {
 			if h < 0 {
 				return 0, fmt.Errorf("negative duration not allowed")
 			}
-			return ClampMinutes(h * 60), nil
+			return positiveMinutes(h * 60)
 		}
 		if f, err := strconv.ParseFloat(numPart, 64); err == nil {
 			if f < 0 {
 				return 0, fmt.Errorf("negative duration not allowed")
 			}
-			return ClampMinutes(int(f * 60)), nil
+			return positiveMinutes(int(f * 60))
 		}
 	}
 
@@ -81,9 +90,91 @@ func ParseDurationMinutes(s string) (int, error) {
 			if m < 0 {
 				return 0, fmt.Errorf("negative duration not allowed")
 			}
-			return ClampMinutes(m), nil
+			return positiveMinutes(m)
 		}
 	}
 
 	return 0, fmt.Errorf("unrecognised duration %q; expected a duration like 30m, 1h, 1h30m, or 1.5h", s)
 }
+
+func positiveMinutes(m int) (int, error) {
+	if m <= 0 {
+		return 0, fmt.Errorf("duration must be at least 1m")
+	}
+	return m, nil
+}
+
+var reISODuration = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// ParseISODurationMinutes parses an ISO 8601 duration (PT8H, PT1H30M, P1D) into
+// whole minutes, as returned by role management policy rules.
+func ParseISODurationMinutes(s string) (int, error) {
+	norm := strings.ToUpper(strings.TrimSpace(s))
+	m := reISODuration.FindStringSubmatch(norm)
+	if m == nil || norm == "P" || strings.HasSuffix(norm, "T") {
+		return 0, fmt.Errorf("unrecognised ISO 8601 duration %q", s)
+	}
+	var parts [4]int
+	for i, v := range m[1:] {
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, fmt.Errorf("parse ISO 8601 duration %q: %w", s, err)
+		}
+		parts[i] = n
+	}
+	return parts[0]*24*60 + parts[1]*60 + parts[2] + parts[3]/60, nil
+}
+
+// startLayouts are the absolute start-time forms accepted by ParseStartTime,
+// interpreted in the local time zone.
+var startLayouts = []string{
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"2006-01-02T15:04:05",
+}
+
+// ParseStartTime parses an activation start time: relative ("+3h", "+90m"),
+// a clock time ("22:00", today or tomorrow if already past), a local date and
+// time ("2006-01-02 15:04"), or RFC 3339. An empty string or "now" returns the
+// zero time, meaning start immediately. Times in the past are rejected.
+func ParseStartTime(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.EqualFold(s, "now") {
+		return time.Time{}, nil
+	}
+
+	if rel, ok := strings.CutPrefix(s, "+"); ok {
+		mins, err := ParseDurationMinutes(rel)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("parse start time %q: %w", s, err)
+		}
+		return now.Add(time.Duration(mins) * time.Minute), nil
+	}
+
+	if clock, err := time.ParseInLocation("15:04", s, now.Location()); err == nil {
+		t := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+		if !t.After(now) {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		for _, layout := range startLayouts {
+			if t, err = time.ParseInLocation(layout, s, now.Location()); err == nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unrecognised start time %q; expected +3h, 22:00, 2006-01-02 15:04, or RFC 3339", s)
+	}
+	if !t.After(now) {
+		return time.Time{}, fmt.Errorf("start time %s is in the past", t.Format("2006-01-02 15:04"))
+	}
+	return t, nil
+}
@@ -0,0 +1,266 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultRenewalDays is the eligibility length requested by RenewEligibility
+// when RenewalRequest.Days is not set: the Azure default maximum of one year.
+const DefaultRenewalDays = 365
+
+// RenewalRequest describes a self-extension of an eligibility that is about
+// to expire, or a self-renewal of one that has expired. Both wait for an
+// administrator to approve them.
+type RenewalRequest struct {
+	Role          Role
+	PrincipalID   string
+	Justification string
+	// Days is the requested eligibility length from now; zero uses DefaultRenewalDays.
+	Days int
+}
+
+// IsRenew reports whether the request renews an expired eligibility rather
+// than extending a current one. Expired eligibilities come from
+// GetExpiredEligibilities.
+func (rr RenewalRequest) IsRenew() bool { return rr.Role.IsEligibilityExpired() }
+
+// scheduleInfo returns the requested eligibility schedule; expirationType is
+// "AfterDuration" for ARM and "afterDuration" for Graph.
+func (rr RenewalRequest) scheduleInfo(expirationType string) *ScheduleInfo {
+	days := rr.Days
+	if days <= 0 {
+		days = DefaultRenewalDays
+	}
+	return &ScheduleInfo{
+		StartDateTime: time.Now().UTC().Format(time.RFC3339),
+		Expiration: Expiration{
+			Type:     expirationType,
+			Duration: fmt.Sprintf("P%dD", days),
+		},
+	}
+}
+
+// ExpiredRenewWindow is how long after an eligibility expired Azure still
+// accepts a self-renew request for it.
+const ExpiredRenewWindow = 30 * 24 * time.Hour
+
+// GetExpiredEligibilities lists the caller's Azure resource eligibilities that
+// ended within ExpiredRenewWindow. ARM stops listing eligibility schedules
+// once they end, so they are rebuilt from the roleEligibilityScheduleRequests
+// history: the latest provisioned request per role and scope whose schedule
+// has ended. Eligibilities removed by an administrator are left out, as are
+// directory role and group eligibilities.
+func (c *Client) GetExpiredEligibilities(ctx context.Context) ([]Role, error) {
+	tok, err := c.armToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reqURL := fmt.Sprintf("%s/providers/Microsoft.Authorization/roleEligibilityScheduleRequests?api-version=%s&$filter=asTarget()",
+		c.armURL, apiVersion)
+
+	type latest struct {
+		created time.Time
+		remove  bool
+		role    Role
+	}
+	byKey := map[string]*latest{}
+	var order []string
+	for reqURL != "" {
+		resp, err := c.doRequest(ctx, http.MethodGet, reqURL, tok, nil)
+		if err != nil {
+			return nil, fmt.Errorf("get eligibility requests: %w", err)
+		}
+		var result struct {
+			Value []struct {
+				Properties struct {
+					Scope                           string `json:"scope"`
+					RoleDefinitionID                string `json:"roleDefinitionId"`
+					RequestType                     string `json:"requestType"`
+					Status                          string `json:"status"`
+					CreatedOn                       string `json:"createdOn"`
+					TargetRoleEligibilityScheduleID string `json:"targetRoleEligibilityScheduleId"`
+					ScheduleInfo                    struct {
+						StartDateTime string `json:"startDateTime"`
+						Expiration    struct {
+							Type        string `json:"type"`
+							EndDateTime string `json:"endDateTime"`
+							Duration    string `json:"duration"`
+						} `json:"expiration"`
+					} `json:"scheduleInfo"`
+					ExpandedProps struct {
+						Scope struct {
+							DisplayName string `json:"displayName"`
+						} `json:"scope"`
+						RoleDefinition struct {
+							DisplayName string `json:"displayName"`
+						} `json:"roleDefinition"`
+					} `json:"expandedProperties"`
+				} `json:"properties"`
+			} `json:"value"`
+			NextLink string `json:"nextLink"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			resp.Body.Close()
+			return nil, fmt.Errorf("decode eligibility requests: %w", err)
+		}
+		resp.Body.Close()
+
+		for _, item := range result.Value {
+			p := item.Properties
+			if !strings.EqualFold(p.Status, StatusProvisioned) {
+				continue
+			}
+			key := strings.ToLower(NormalizeScope(p.Scope) + "|" + p.RoleDefinitionID)
+			created, _ := time.Parse(time.RFC3339Nano, p.CreatedOn)
+			cur := byKey[key]
+			if cur != nil && !created.After(cur.created) {
+				continue
+			}
+			if cur == nil {
+				cur = &latest{}
+				byKey[key] = cur
+				order = append(order, key)
+			}
+			info := p.ScheduleInfo
+			*cur = latest{
+				created: created,
+				remove:  strings.HasSuffix(strings.ToLower(p.RequestType), "remove"),
+				role: Role{
+					Scope:                 p.Scope,
+					ScopeDisplay:          DefaultScopeDisplay(p.Scope, p.ExpandedProps.Scope.DisplayName),
+					RoleName:              p.ExpandedProps.RoleDefinition.DisplayName,
+					RoleDefinitionID:      p.RoleDefinitionID,
+					EligibilityScheduleID: p.TargetRoleEligibilityScheduleID,
+					EligibilityStart:      info.StartDateTime,
+					EligibilityEnd:        scheduleEnd(info.StartDateTime, info.Expiration.EndDateTime, info.Expiration.Duration),
+				},
+			}
+		}
+		reqURL = result.NextLink
+	}
+
+	now := time.Now()
+	var out []Role
+	for _, key := range order {
+		l := byKey[key]
+		end, err := time.Parse(time.RFC3339, l.role.EligibilityEnd)
+		if l.remove || err != nil || end.After(now) || now.Sub(end) > ExpiredRenewWindow {
+			continue
+		}
+		out = append(out, l.role)
+	}
+	return out, nil
+}
+
+// scheduleEnd returns the end of a schedule given either its end date or its
+// ISO 8601 duration from start, or "" when it does not expire.
+func scheduleEnd(start, end, duration string) string {
+	if end != "" {
+		return end
+	}
+	t, err := time.Parse(time.RFC3339, start)
+	if err != nil || duration == "" {
+		return ""
+	}
+	mins, err := ParseISODurationMinutes(duration)
+	if err != nil {
+		return ""
+	}
+	return t.Add(time.Duration(mins) * time.Minute).UTC().Format(time.RFC3339)
+}
+
+// eligibilityScheduleRequest is the ARM roleEligibilityScheduleRequests body.
+type eligibilityScheduleRequest struct {
+	Properties eligibilityScheduleProperties `json:"properties"`
+}
+
+type eligibilityScheduleProperties struct {
+	PrincipalID                     string        `json:"principalId"`
+	RoleDefinitionID                string        `json:"roleDefinitionId"`
+	RequestType                     string        `json:"requestType"`
+	Justification                   string        `json:"justification,omitempty"`
+	TargetRoleEligibilityScheduleID string        `json:"targetRoleEligibilityScheduleId,omitempty"`
+	ScheduleInfo                    *ScheduleInfo `json:"scheduleInfo,omitempty"`
+}
+
+// RenewEligibility submits a SelfExtend request for a current eligibility or
+// a SelfRenew request for an expired one. Directory roles and group
+// eligibilities are submitted to Microsoft Graph.
+func (c *Client) RenewEligibility(ctx context.Context, rr RenewalRequest) (*ScheduleResponse, error) {
+	if strings.TrimSpace(rr.Justification) == "" {
+		return nil, fmt.Errorf("renewal requires a justification")
+	}
+	role := rr.Role
+	// ARM and Graph spell the request type differently.
+	requestType, action := "SelfExtend", "selfExtend"
+	if rr.IsRenew() {
+		requestType, action = "SelfRenew", "selfRenew"
+	}
+
+	switch role.ScopeKind() {
+	case ScopeDirectory:
+		resp, err := c.postGraphScheduleRequest(ctx, "/roleManagement/directory/roleEligibilityScheduleRequests", directoryRequest{
+			Action:           action,
+			PrincipalID:      rr.PrincipalID,
+			RoleDefinitionID: role.RoleDefinitionID,
+			DirectoryScopeID: DirectoryScopeIDFromScope(role.Scope),
+			Justification:    rr.Justification,
+			ScheduleInfo:     rr.scheduleInfo("afterDuration"),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("submit directory eligibility %s: %w", action, err)
+		}
+		return resp, nil
+	case ScopeGroup:
+		resp, err := c.postGraphScheduleRequest(ctx, "/identityGovernance/privilegedAccess/group/eligibilityScheduleRequests", groupRequest{
+			Action:        action,
+			PrincipalID:   rr.PrincipalID,
+			GroupID:       GroupIDFromScope(role.Scope),
+			AccessID:      strings.ToLower(role.RoleDefinitionID),
+			Justification: rr.Justification,
+			ScheduleInfo:  rr.scheduleInfo("afterDuration"),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("submit group eligibility %s: %w", action, err)
+		}
+		return resp, nil
+	}
+
+	tok, err := c.armToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req := eligibilityScheduleRequest{
+		Properties: eligibilityScheduleProperties{
+			PrincipalID:                     rr.PrincipalID,
+			RoleDefinitionID:                role.RoleDefinitionID,
+			RequestType:                     requestType,
+			Justification:                   rr.Justification,
+			TargetRoleEligibilityScheduleID: role.EligibilityScheduleID,
+			ScheduleInfo:                    rr.scheduleInfo("AfterDuration"),
+		},
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	requestID := uuid.New().String()
+	reqURL := fmt.Sprintf("%s%s/providers/Microsoft.Authorization/roleEligibilityScheduleRequests/%s?api-version=%s",
+		c.armURL, NormalizeScope(role.Scope), requestID, apiVersion)
+
+	resp, err := c.doRequestWithStepUp(ctx, http.MethodPut, reqURL, c.cloud.armScope(), tok, body)
+	if err != nil {
+		return nil, fmt.Errorf("submit eligibility %s: %w", action, err)
+	}
+	defer resp.Body.Close()
+
+	return decodeScheduleResponse(resp.Body, "decode response")
+}
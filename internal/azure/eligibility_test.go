@@ -0,0 +1,175 @@
+package azure
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRoleEligibilityExpiry(t *testing.T) {
+	day := 24 * time.Hour
+	at := func(d time.Duration) string { return time.Now().Add(d).UTC().Format(time.RFC3339) }
+	tests := []struct {
+		name      string
+		end       string
+		display   string
+		expired   bool
+		expiring  bool
+		permanent bool
+	}{
+		{"permanent", "", "", false, false, true},
+		{"expires soon", at(5*day + 2*time.Hour), "eligibility expires in 5d 1h", false, true, false},
+		{"expires later", at(90*day + 2*time.Hour), "eligibility expires in 90d 1h", false, false, false},
+		{"expired", at(-day), "eligibility expired", true, true, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := Role{EligibilityEnd: tc.end}
+			if got := r.EligibilityExpiryDisplay(); !strings.HasPrefix(got, tc.display) || (tc.display == "" && got != "") {
+				t.Errorf("EligibilityExpiryDisplay() = %q, want %q", got, tc.display)
+			}
+			if got := r.IsEligibilityExpired(); got != tc.expired {
+				t.Errorf("IsEligibilityExpired() = %v, want %v", got, tc.expired)
+			}
+			if got := r.IsEligibilityExpiring(); got != tc.expiring {
+				t.Errorf("IsEligibilityExpiring() = %v, want %v", got, tc.expiring)
+			}
+			if got := r.IsEligibilityPermanent(); got != tc.permanent {
+				t.Errorf("IsEligibilityPermanent() = %v, want %v", got, tc.permanent)
+			}
+		})
+	}
+}
+
+func TestRenewEligibility(t *testing.T) {
+	soon := time.Now().Add(48 * time.Hour).UTC().Format(time.RFC3339)
+	past := time.Now().Add(-48 * time.Hour).UTC().Format(time.RFC3339)
+	tests := []struct {
+		name     string
+		role     Role
+		wantPath string
+		wantType string
+	}{
+		{
+			name:     "arm extend",
+			role:     Role{Scope: "/subscriptions/sub-1", RoleDefinitionID: "rd-1", EligibilityScheduleID: "sched-1", EligibilityEnd: soon},
+			wantPath: "/subscriptions/sub-1/providers/Microsoft.Authorization/roleEligibilityScheduleRequests/",
+			wantType: "SelfExtend",
+		},
+		{
+			name:     "arm renew",
+			role:     Role{Scope: "/subscriptions/sub-1", RoleDefinitionID: "rd-1", EligibilityEnd: past},
+			wantPath: "/subscriptions/sub-1/providers/Microsoft.Authorization/roleEligibilityScheduleRequests/",
+			wantType: "SelfRenew",
+		},
+		{
+			name:     "directory extend",
+			role:     Role{Scope: DirectoryScope("/"), RoleDefinitionID: "rd-2", EligibilityEnd: soon},
+			wantPath: "/v1.0/roleManagement/directory/roleEligibilityScheduleRequests",
+			wantType: "selfExtend",
+		},
+		{
+			name:     "group renew",
+			role:     Role{Scope: GroupScope("g-1"), RoleDefinitionID: "member", EligibilityEnd: past},
+			wantPath: "/v1.0/identityGovernance/privilegedAccess/group/eligibilityScheduleRequests",
+			wantType: "selfRenew",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotPath string
+			var body map[string]any
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath = r.URL.Path
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("decode body: %v", err)
+				}
+				w.WriteHeader(http.StatusCreated)
+				_, _ = w.Write([]byte(`{"status":"PendingAdminDecision","properties":{"status":"PendingAdminDecision"}}`))
+			}))
+			defer srv.Close()
+
+			c := testRetryClient(srv)
+			c.cred = &claimsCred{}
+			c.armURL = srv.URL
+			c.graphURL = srv.URL + "/v1.0"
+
+			resp, err := c.RenewEligibility(t.Context(), RenewalRequest{Role: tc.role, PrincipalID: "p-1", Justification: "renew", Days: 30})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !IsAwaitingApproval(resp.Status()) {
+				t.Errorf("Status() = %q, want pending approval", resp.Status())
+			}
+			if !strings.HasPrefix(gotPath, tc.wantPath) {
+				t.Errorf("path = %q, want prefix %q", gotPath, tc.wantPath)
+			}
+			props := body
+			if p, ok := body["properties"].(map[string]any); ok {
+				props = p
+			}
+			gotType, _ := props["requestType"].(string)
+			if gotType == "" {
+				gotType, _ = props["action"].(string)
+			}
+			if gotType != tc.wantType {
+				t.Errorf("request type = %q, want %q", gotType, tc.wantType)
+			}
+			info, _ := props["scheduleInfo"].(map[string]any)
+			exp, _ := info["expiration"].(map[string]any)
+			if exp["duration"] != "P30D" {
+				t.Errorf("duration = %v, want P30D", exp["duration"])
+			}
+		})
+	}
+}
+
+func TestRenewEligibilityRequiresJustification(t *testing.T) {
+	c := &Client{}
+	if _, err := c.RenewEligibility(t.Context(), RenewalRequest{Role: Role{Scope: "/subscriptions/sub-1"}}); err == nil {
+		t.Fatal("expected error without justification")
+	}
+}
+
+func TestGetExpiredEligibilities(t *testing.T) {
+	ended := time.Now().Add(-48 * time.Hour).UTC().Format(time.RFC3339)
+	// The newer request carries fractional seconds, which sort before "Z"
+	// as strings; only a parsed comparison keeps it over the removal.
+	body := `{"value":[
+		{"properties":{"scope":"/subscriptions/sub-1","roleDefinitionId":"rd-1","requestType":"AdminUpdate","status":"Provisioned",
+			"createdOn":"2026-01-02T00:00:00.5Z","targetRoleEligibilityScheduleId":"sched-1",
+			"scheduleInfo":{"startDateTime":"2025-01-01T00:00:00Z","expiration":{"type":"AfterDateTime","endDateTime":"` + ended + `"}},
+			"expandedProperties":{"roleDefinition":{"displayName":"Owner"}}}},
+		{"properties":{"scope":"/subscriptions/sub-1","roleDefinitionId":"rd-1","requestType":"AdminRemove","status":"Provisioned",
+			"createdOn":"2026-01-02T00:00:00Z"}}
+	]}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/roleEligibilityScheduleRequests") {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	c := testRetryClient(srv)
+	c.cred = &claimsCred{}
+	c.armURL = srv.URL
+
+	roles, err := c.GetExpiredEligibilities(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(roles) != 1 {
+		t.Fatalf("roles = %+v, want the newer update", roles)
+	}
+	r := roles[0]
+	if r.RoleName != "Owner" || r.EligibilityScheduleID != "sched-1" || r.EligibilityEnd != ended {
+		t.Errorf("role = %+v", r)
+	}
+	if r.ScopeDisplay != "sub-1" {
+		t.Errorf("ScopeDisplay = %q, want the default display %q", r.ScopeDisplay, "sub-1")
+	}
+}
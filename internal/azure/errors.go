@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"time"
 )
 
 var (
@@ -15,20 +16,122 @@ var (
 )
 
 // APIError is a structured HTTP error returned by the Azure REST API.
+// Claims holds the decoded claims challenge when the request was rejected for
+// missing Conditional Access claims (e.g. an authentication context).
+// RequestID and CorrelationID identify the request to Microsoft support.
 type APIError struct {
-	StatusCode int
-	Code       string
-	Message    string
+	StatusCode    int
+	Code          string
+	Message       string
+	Claims        string
+	RequestID     string // x-ms-request-id, or Graph's request-id
+	CorrelationID string // x-ms-correlation-request-id
 }
 
 func (e *APIError) Error() string {
-	if e.Code == "" {
-		return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Message)
+	msg := fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Message)
+	if e.Code != "" {
+		msg = fmt.Sprintf("HTTP %d: %s - %s", e.StatusCode, e.Code, e.Message)
 	}
-	return fmt.Sprintf("HTTP %d: %s - %s", e.StatusCode, e.Code, e.Message)
+	var ids []string
+	if e.RequestID != "" {
+		ids = append(ids, "request ID "+e.RequestID)
+	}
+	if e.CorrelationID != "" {
+		ids = append(ids, "correlation ID "+e.CorrelationID)
+	}
+	if len(ids) > 0 {
+		msg += " (" + strings.Join(ids, ", ") + ")"
+	}
+	return msg
+}
+
+// isAccessDenied reports whether err is an HTTP 401 or 403 API error.
+func isAccessDenied(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden)
+}
+
+// isBadRequest reports whether err is an HTTP 400 API error.
+func isBadRequest(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest
+}
+
+// errCodeActiveDurationTooShort is the Azure PIM error code returned when a
+// role is deactivated less than MinActiveDuration after it was activated.
+const errCodeActiveDurationTooShort = "ActiveDurationTooShort"
+
+// MinActiveDuration is how long an activation must have been active before
+// Azure accepts its self-deactivation.
+const MinActiveDuration = 5 * time.Minute
+
+// IsActiveDurationTooShort reports whether err is Azure rejecting a
+// deactivation because the activation is less than MinActiveDuration old.
+func IsActiveDurationTooShort(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && strings.EqualFold(apiErr.Code, errCodeActiveDurationTooShort)
+}
+
+// errCodeAssignmentNotFound is the Azure PIM error code returned when the
+// assignment to deactivate or extend no longer exists.
+const errCodeAssignmentNotFound = "RoleAssignmentDoesNotExist"
+
+// IsAssignmentNotFound reports whether err is Azure reporting that the
+// assignment a request targets does not exist, e.g. because it already ended.
+func IsAssignmentNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && strings.EqualFold(apiErr.Code, errCodeAssignmentNotFound)
+}
+
+// IsAlreadyActive reports whether err is Azure rejecting an activation
+// because the assignment already exists.
+func IsAlreadyActive(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && strings.EqualFold(apiErr.Code, errCodeAssignmentExists)
+}
+
+// IsPolicyViolation reports whether err is Azure rejecting a schedule request
+// that breaks the role management policy, e.g.
+// RoleAssignmentRequestPolicyValidationFailed.
+func IsPolicyViolation(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && strings.Contains(strings.ToLower(apiErr.Code), "policyvalidationfailed")
+}
+
+// TokenError is returned when the credential chain cannot produce a token
+// for Scope.
+type TokenError struct {
+	Scope string
+	Err   error
+}
+
+func (e *TokenError) Error() string {
+	return fmt.Sprintf("acquire token for %s: %v", e.Scope, e.Err)
+}
+
+func (e *TokenError) Unwrap() error { return e.Err }
+
+// IsAuthFailure reports whether err means the caller could not be
+// authenticated: no credential, no token, an unanswered step-up challenge or
+// a token Azure rejected with HTTP 401.
+func IsAuthFailure(err error) bool {
+	var tokErr *TokenError
+	var stepUp *StepUpError
+	if errors.Is(err, ErrNoCredential) || errors.As(err, &tokErr) || errors.As(err, &stepUp) {
+		return true
+	}
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusUnauthorized || isAcrsValidationCode(apiErr.Code))
 }
 
 func errorFromResponse(resp *http.Response) *APIError {
+	apiErr := apiErrorFromResponse(resp)
+	apiErr.RequestID, apiErr.CorrelationID = requestID(resp.Header), resp.Header.Get(headerCorrelationID)
+	return apiErr
+}
+
+func apiErrorFromResponse(resp *http.Response) *APIError {
 	defer resp.Body.Close()
 	body, readErr := io.ReadAll(resp.Body)
 	if readErr != nil {
@@ -44,7 +147,12 @@ func errorFromResponse(resp *http.Response) *APIError {
 		} `json:"error"`
 	}
 	if json.Unmarshal(body, &azErr) == nil && azErr.Error.Code != "" {
-		return &APIError{StatusCode: resp.StatusCode, Code: azErr.Error.Code, Message: azErr.Error.Message}
+		apiErr := &APIError{StatusCode: resp.StatusCode, Code: azErr.Error.Code, Message: azErr.Error.Message}
+		apiErr.Claims = challengeClaims(resp.Header)
+		if apiErr.Claims == "" && isAcrsValidationCode(apiErr.Code) {
+			apiErr.Claims = claimsFromMessage(apiErr.Message)
+		}
+		return apiErr
 	}
-	return &APIError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(body))}
+	return &APIError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(body)), Claims: challengeClaims(resp.Header)}
 }
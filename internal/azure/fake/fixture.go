@@ -0,0 +1,133 @@
+package fake
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jeircul/pim/internal/azure"
+)
+
+//go:embed demo.json
+var demoFixture []byte
+
+// Fixture is the tenant a Server emulates. Times are RFC 3339, or a Go
+// duration relative to when the server starts ("+2h", "-30m"), so fixtures
+// stay current; an empty end time never expires.
+type Fixture struct {
+	Tenant azure.Tenant `json:"tenant"`
+	User   azure.User   `json:"user"`
+	// Policy is the activation policy of every eligible role.
+	Policy   Policy       `json:"policy"`
+	Eligible []Assignment `json:"eligible"`
+	Active   []Assignment `json:"active"`
+	// Children lists the eligible child resources of each scope, as returned
+	// by eligibleChildResources with $getAllChildren=true.
+	Children map[string][]Child `json:"children"`
+	// RoleDefinitions holds permissions keyed by role definition GUID.
+	RoleDefinitions map[string]RoleDefinition `json:"roleDefinitions"`
+	// Requests seeds the schedule request history.
+	Requests []Request `json:"requests"`
+}
+
+// Policy is the end-user activation policy applied to eligible roles.
+type Policy struct {
+	MaxDuration          string `json:"maxDuration"` // ISO 8601, default PT8H
+	RequireJustification bool   `json:"requireJustification"`
+	RequireTicket        bool   `json:"requireTicket"`
+}
+
+// Assignment is an eligibility or an active assignment. RoleDefinitionID may
+// be a bare GUID; it is expanded to the ARM ID for Scope.
+type Assignment struct {
+	ID               string `json:"id,omitempty"`
+	Role             string `json:"role"`
+	RoleDefinitionID string `json:"roleDefinitionId"`
+	Scope            string `json:"scope"`
+	ScopeDisplayName string `json:"scopeDisplayName,omitempty"`
+	MemberType       string `json:"memberType,omitempty"`
+	Start            string `json:"startDateTime,omitempty"`
+	End              string `json:"endDateTime,omitempty"`
+	// RequireApproval leaves activations of this eligibility pending.
+	RequireApproval bool `json:"requireApproval,omitempty"`
+}
+
+// Child is an eligible child resource.
+type Child struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	DisplayName string `json:"displayName,omitempty"`
+}
+
+// RoleDefinition is the description and permissions of a role.
+type RoleDefinition struct {
+	Description    string   `json:"description,omitempty"`
+	Custom         bool     `json:"custom,omitempty"`
+	Actions        []string `json:"actions,omitempty"`
+	NotActions     []string `json:"notActions,omitempty"`
+	DataActions    []string `json:"dataActions,omitempty"`
+	NotDataActions []string `json:"notDataActions,omitempty"`
+}
+
+// Request is a past schedule request.
+type Request struct {
+	Role             string `json:"role"`
+	RoleDefinitionID string `json:"roleDefinitionId"`
+	Scope            string `json:"scope"`
+	ScopeDisplayName string `json:"scopeDisplayName,omitempty"`
+	RequestType      string `json:"requestType"`
+	Status           string `json:"status"`
+	Justification    string `json:"justification,omitempty"`
+	CreatedOn        string `json:"createdOn"`
+	Duration         string `json:"duration,omitempty"` // ISO 8601
+}
+
+// LoadFixture reads a JSON fixture file.
+func LoadFixture(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read fixture: %w", err)
+	}
+	return ParseFixture(data)
+}
+
+// ParseFixture decodes a JSON fixture.
+func ParseFixture(data []byte) (*Fixture, error) {
+	var fx Fixture
+	if err := json.Unmarshal(data, &fx); err != nil {
+		return nil, fmt.Errorf("decode fixture: %w", err)
+	}
+	return &fx, nil
+}
+
+// DemoFixture returns the built-in fixture used by pim --demo: a small
+// tenant with management group, subscription, resource group and resource
+// eligibilities, one active assignment and some request history.
+func DemoFixture() (*Fixture, error) {
+	return ParseFixture(demoFixture)
+}
+
+// resolveTime parses a fixture time relative to base. An empty string is the
+// zero time.
+func resolveTime(s string, base time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case s == "":
+		return time.Time{}, nil
+	case strings.HasPrefix(s, "+"), strings.HasPrefix(s, "-"):
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid relative time %q: %w", s, err)
+		}
+		return base.Add(d), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q: must be RFC 3339 or a relative duration such as +2h", s)
+	}
+	return t, nil
+}
@@ -0,0 +1,812 @@
+// Package fake is an in-process stand-in for the ARM and Microsoft Graph
+// endpoints pim calls: role eligibility schedules, assignment schedules and
+// instances, schedule requests, eligible child resources, role management
+// policies, role definitions, tenants and Graph /me. It serves a Fixture
+// over TLS and keeps activations, extensions, deactivations and renewals in
+// memory, so the client, the headless commands and the TUI can be exercised
+// offline. Directory role and group endpoints answer 403, which the client
+// treats as "not available in this tenant".
+package fake
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/google/uuid"
+	"github.com/jeircul/pim/internal/azure"
+)
+
+// Token is the access token Credential issues.
+const Token = "fake-token"
+
+// Credential issues Token for every scope.
+type Credential struct{}
+
+// GetToken implements azcore.TokenCredential.
+func (Credential) GetToken(context.Context, policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: Token, ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+const authzProvider = "/providers/microsoft.authorization/"
+
+var reRoleDefinitionFilter = regexp.MustCompile(`roleDefinitionId eq '([^']*)'`)
+var reRoleNameFilter = regexp.MustCompile(`roleName eq '((?:[^']|'')*)'`)
+
+// assignment is an eligibility or assignment schedule held by the server.
+type assignment struct {
+	id           string
+	role         string
+	roleDefID    string
+	scope        string
+	scopeDisplay string
+	memberType   string
+	start        time.Time
+	end          time.Time // zero never expires
+	approval     bool
+}
+
+// request is a schedule request held by the server.
+type request struct {
+	name          string
+	scope         string
+	role          string
+	roleDefID     string
+	scopeDisplay  string
+	requestType   string
+	status        string
+	justification string
+	created       time.Time
+	start         time.Time
+	duration      string
+}
+
+func (r *request) id() string {
+	return r.scope + "/providers/Microsoft.Authorization/roleAssignmentScheduleRequests/" + r.name
+}
+
+// Server emulates ARM and Graph for one tenant and user.
+type Server struct {
+	mu       sync.Mutex
+	tenant   azure.Tenant
+	user     azure.User
+	policy   Policy
+	eligible []*assignment
+	active   []*assignment
+	children map[string][]Child        // keyed by lower-cased scope
+	roleDefs map[string]RoleDefinition // keyed by lower-cased GUID
+	requests []*request
+	srv      *httptest.Server
+}
+
+// NewServer starts a TLS server seeded with fx. Close it when done.
+func NewServer(fx *Fixture) (*Server, error) {
+	now := time.Now()
+	s := &Server{
+		tenant:   fx.Tenant,
+		user:     fx.User,
+		policy:   fx.Policy,
+		children: map[string][]Child{},
+		roleDefs: map[string]RoleDefinition{},
+	}
+	if s.policy.MaxDuration == "" {
+		s.policy.MaxDuration = "PT8H"
+	}
+	if _, err := azure.ParseISODurationMinutes(s.policy.MaxDuration); err != nil {
+		return nil, fmt.Errorf("fixture policy: %w", err)
+	}
+	for i, a := range fx.Eligible {
+		e, err := newAssignment(a, now, "roleEligibilitySchedules")
+		if err != nil {
+			return nil, fmt.Errorf("fixture eligible[%d]: %w", i, err)
+		}
+		s.eligible = append(s.eligible, e)
+	}
+	for i, a := range fx.Active {
+		e, err := newAssignment(a, now, "roleAssignmentSchedules")
+		if err != nil {
+			return nil, fmt.Errorf("fixture active[%d]: %w", i, err)
+		}
+		if e.start.IsZero() {
+			e.start = now
+		}
+		s.active = append(s.active, e)
+	}
+	for i, r := range fx.Requests {
+		created, err := resolveTime(r.CreatedOn, now)
+		if err != nil {
+			return nil, fmt.Errorf("fixture requests[%d]: %w", i, err)
+		}
+		s.requests = append(s.requests, &request{
+			name:          uuid.NewString(),
+			scope:         r.Scope,
+			role:          r.Role,
+			roleDefID:     roleDefinitionID(r.Scope, r.RoleDefinitionID),
+			scopeDisplay:  r.ScopeDisplayName,
+			requestType:   r.RequestType,
+			status:        r.Status,
+			justification: r.Justification,
+			created:       created,
+			start:         created,
+			duration:      r.Duration,
+		})
+	}
+	for scope, children := range fx.Children {
+		s.children[strings.ToLower(scope)] = children
+	}
+	for id, def := range fx.RoleDefinitions {
+		s.roleDefs[strings.ToLower(id)] = def
+	}
+
+	s.srv = httptest.NewUnstartedServer(s)
+	s.srv.Config.ErrorLog = log.New(io.Discard, "", 0)
+	s.srv.StartTLS()
+	return s, nil
+}
+
+func newAssignment(a Assignment, now time.Time, kind string) (*assignment, error) {
+	if a.Scope == "" || a.RoleDefinitionID == "" {
+		return nil, fmt.Errorf("scope and roleDefinitionId are required")
+	}
+	start, err := resolveTime(a.Start, now)
+	if err != nil {
+		return nil, err
+	}
+	end, err := resolveTime(a.End, now)
+	if err != nil {
+		return nil, err
+	}
+	id := a.ID
+	if id == "" {
+		id = a.Scope + "/providers/Microsoft.Authorization/" + kind + "/" + uuid.NewString()
+	}
+	memberType := a.MemberType
+	if memberType == "" {
+		memberType = "Direct"
+	}
+	return &assignment{
+		id:           id,
+		role:         a.Role,
+		roleDefID:    roleDefinitionID(a.Scope, a.RoleDefinitionID),
+		scope:        a.Scope,
+		scopeDisplay: a.ScopeDisplayName,
+		memberType:   memberType,
+		start:        start,
+		end:          end,
+		approval:     a.RequireApproval,
+	}, nil
+}
+
+// roleDefinitionID expands a bare role definition GUID to the ARM ID used at
+// scope: subscription-qualified below a subscription, tenant-level otherwise.
+func roleDefinitionID(scope, id string) string {
+	if strings.HasPrefix(id, "/") {
+		return id
+	}
+	if sub := azure.SubscriptionIDFromScope(scope); sub != "" {
+		return "/subscriptions/" + sub + "/providers/Microsoft.Authorization/roleDefinitions/" + id
+	}
+	return "/providers/Microsoft.Authorization/roleDefinitions/" + id
+}
+
+// URL returns the server's base URL, which serves both ARM and Graph.
+func (s *Server) URL() string { return s.srv.URL }
+
+// Cloud returns a cloud whose endpoints all point at the server.
+func (s *Server) Cloud() azure.Cloud {
+	return azure.Cloud{Name: "Fake", AuthorityHost: s.srv.URL, ARMEndpoint: s.srv.URL, GraphEndpoint: s.srv.URL}
+}
+
+// ClientOptions returns options for an azure.Client talking to the server:
+// its cloud, tenant, a Credential and an HTTP client trusting its
+// certificate. Failed requests are not retried.
+func (s *Server) ClientOptions() azure.ClientOptions {
+	return azure.ClientOptions{
+		Cloud:      s.Cloud(),
+		TenantID:   s.tenant.ID,
+		Credential: Credential{},
+		HTTPClient: s.srv.Client(),
+		Retry:      &azure.RetryPolicy{},
+	}
+}
+
+// Close shuts the server down.
+func (s *Server) Close() { s.srv.Close() }
+
+// ServeHTTP routes Graph requests under /v1.0 and /beta, /tenants, and ARM
+// Microsoft.Authorization requests at any scope. Every response carries
+// request IDs the way ARM and Graph set them.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, "/v1.0/") || strings.HasPrefix(r.URL.Path, "/beta/") {
+		w.Header().Set("request-id", uuid.NewString())
+	} else {
+		w.Header().Set("x-ms-request-id", uuid.NewString())
+		w.Header().Set("x-ms-correlation-request-id", uuid.NewString())
+	}
+	if r.Header.Get("Authorization") != "Bearer "+Token {
+		writeError(w, http.StatusUnauthorized, "InvalidAuthenticationToken", "missing or invalid bearer token")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := r.URL.Path
+	switch {
+	case p == "/v1.0/me" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, s.user)
+	case strings.HasPrefix(p, "/v1.0/"), strings.HasPrefix(p, "/beta/"):
+		writeError(w, http.StatusForbidden, "Authorization_RequestDenied", "Insufficient privileges to complete the operation.")
+	case p == "/tenants" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]any{"value": []azure.Tenant{s.tenant}})
+	default:
+		s.serveAuthorization(w, r)
+	}
+}
+
+func (s *Server) serveAuthorization(w http.ResponseWriter, r *http.Request) {
+	p := r.URL.Path
+	i := strings.Index(strings.ToLower(p), authzProvider)
+	if i < 0 {
+		notFound(w, r)
+		return
+	}
+	scope := p[:i]
+	parts := strings.Split(p[i+len(authzProvider):], "/")
+	filter := r.URL.Query().Get("$filter")
+	now := time.Now()
+
+	switch resource := strings.ToLower(parts[0]); {
+	case r.Method == http.MethodGet && len(parts) == 1 &&
+		(resource == "roleeligibilityschedules" || resource == "roleeligibilityscheduleinstances"):
+		writeList(w, s.listAssignments(s.eligible, scope, filter, func(a *assignment) bool {
+			return a.end.IsZero() || a.end.After(now)
+		}))
+	case r.Method == http.MethodGet && len(parts) == 1 && resource == "roleassignmentscheduleinstances":
+		writeList(w, s.listAssignments(s.active, scope, filter, func(a *assignment) bool {
+			return !a.start.After(now) && (a.end.IsZero() || a.end.After(now))
+		}))
+	case r.Method == http.MethodGet && len(parts) == 1 && resource == "roleassignmentschedules":
+		writeList(w, s.listAssignments(s.active, scope, filter, func(a *assignment) bool {
+			return a.end.IsZero() || a.end.After(now)
+		}))
+	case resource == "roleassignmentschedulerequests":
+		s.serveRequests(w, r, scope, parts[1:])
+	case r.Method == http.MethodGet && len(parts) == 1 && resource == "roleeligibilityschedulerequests":
+		writeList(w, s.listEligibilityRequests(scope))
+	case r.Method == http.MethodPut && len(parts) == 2 && resource == "roleeligibilityschedulerequests":
+		s.renewEligibility(w, r, scope, parts[1])
+	case r.Method == http.MethodGet && len(parts) == 1 && resource == "eligiblechildresources":
+		s.listChildren(w, scope)
+	case r.Method == http.MethodGet && len(parts) == 1 && resource == "rolemanagementpolicyassignments":
+		s.listPolicies(w, scope)
+	case r.Method == http.MethodGet && resource == "roledefinitions":
+		s.serveRoleDefinitions(w, r, parts[1:])
+	default:
+		notFound(w, r)
+	}
+}
+
+// listAssignments returns the assignments at scope (all of them when scope
+// is empty, as for asTarget() queries) matching keep and any roleDefinitionId
+// in filter.
+func (s *Server) listAssignments(all []*assignment, scope, filter string, keep func(*assignment) bool) []any {
+	var want string
+	if m := reRoleDefinitionFilter.FindStringSubmatch(filter); m != nil {
+		want = m[1]
+	}
+	out := []any{}
+	for _, a := range all {
+		if scope != "" && !strings.EqualFold(a.scope, scope) {
+			continue
+		}
+		if want != "" && !sameRoleDefinition(a.roleDefID, want) {
+			continue
+		}
+		if keep(a) {
+			out = append(out, a.resource(s.user.ID))
+		}
+	}
+	return out
+}
+
+func (a *assignment) resource(principalID string) map[string]any {
+	props := map[string]any{
+		"principalId":      principalID,
+		"scope":            a.scope,
+		"roleDefinitionId": a.roleDefID,
+		"memberType":       a.memberType,
+		"startDateTime":    formatTime(a.start),
+		"endDateTime":      formatTime(a.end),
+		"expandedProperties": map[string]any{
+			"scope":          map[string]string{"id": a.scope, "displayName": a.scopeDisplay},
+			"roleDefinition": map[string]string{"id": a.roleDefID, "displayName": a.role},
+		},
+	}
+	return map[string]any{"id": a.id, "name": path.Base(a.id), "properties": props}
+}
+
+func (s *Server) serveRequests(w http.ResponseWriter, r *http.Request, scope string, parts []string) {
+	switch {
+	case r.Method == http.MethodGet && len(parts) == 0:
+		out := []any{}
+		for _, req := range s.requests {
+			out = append(out, req.resource(s.user.ID))
+		}
+		writeList(w, out)
+	case r.Method == http.MethodPut && len(parts) == 1:
+		s.submitRequest(w, r, scope, parts[0])
+	case r.Method == http.MethodGet && len(parts) == 1:
+		if req := s.findRequest(parts[0]); req != nil {
+			writeJSON(w, http.StatusOK, req.resource(s.user.ID))
+			return
+		}
+		notFound(w, r)
+	case r.Method == http.MethodPost && len(parts) == 2 && strings.EqualFold(parts[1], "cancel"):
+		req := s.findRequest(parts[0])
+		if req == nil {
+			notFound(w, r)
+			return
+		}
+		if !azure.IsOutstandingStatus(req.status) {
+			writeError(w, http.StatusBadRequest, "InvalidRequestStatus", "only pending requests can be canceled")
+			return
+		}
+		req.status = azure.StatusCanceled
+		w.WriteHeader(http.StatusOK)
+	default:
+		notFound(w, r)
+	}
+}
+
+func (s *Server) findRequest(name string) *request {
+	for _, req := range s.requests {
+		if strings.EqualFold(req.name, name) {
+			return req
+		}
+	}
+	return nil
+}
+
+func (r *request) resource(principalID string) map[string]any {
+	props := map[string]any{
+		"principalId":      principalID,
+		"scope":            r.scope,
+		"roleDefinitionId": r.roleDefID,
+		"requestType":      r.requestType,
+		"status":           r.status,
+		"justification":    r.justification,
+		"createdOn":        formatTime(r.created),
+		"scheduleInfo": map[string]any{
+			"startDateTime": formatTime(r.start),
+			"expiration":    map[string]string{"type": "AfterDuration", "duration": r.duration},
+		},
+		"expandedProperties": map[string]any{
+			"scope":          map[string]string{"id": r.scope, "displayName": r.scopeDisplay},
+			"roleDefinition": map[string]string{"id": r.roleDefID, "displayName": r.role},
+		},
+	}
+	return map[string]any{"id": r.id(), "name": r.name, "properties": props}
+}
+
+// submitRequest applies a SelfActivate, SelfExtend or SelfDeactivate request
+// at scope, enforcing the fixture policy the way PIM does.
+func (s *Server) submitRequest(w http.ResponseWriter, r *http.Request, scope, name string) {
+	var body azure.ScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "InvalidRequestContent", err.Error())
+		return
+	}
+	p := body.Properties
+	now := time.Now()
+	req := &request{
+		name:          name,
+		scope:         scope,
+		roleDefID:     p.RoleDefinitionID,
+		requestType:   p.RequestType,
+		justification: p.Justification,
+		created:       now,
+		start:         now,
+	}
+	existing := s.findActive(scope, p.RoleDefinitionID, now)
+
+	switch p.RequestType {
+	case "SelfDeactivate":
+		if existing == nil {
+			writeError(w, http.StatusBadRequest, "RoleAssignmentDoesNotExist", "The role assignment does not exist.")
+			return
+		}
+		if now.Sub(existing.start) < azure.MinActiveDuration {
+			writeError(w, http.StatusBadRequest, "ActiveDurationTooShort",
+				"The role assignment cannot be deactivated until it has been active for at least 5 minutes.")
+			return
+		}
+		s.removeActive(existing)
+		req.role, req.scopeDisplay, req.status = existing.role, existing.scopeDisplay, azure.StatusRevoked
+
+	case "SelfActivate", "SelfExtend":
+		elig := s.findEligibility(scope, p)
+		if elig == nil {
+			writeError(w, http.StatusBadRequest, "RoleEligibilityNotFound",
+				fmt.Sprintf("No eligibility for role %s at scope %s.", p.RoleDefinitionID, scope))
+			return
+		}
+		req.role, req.scopeDisplay = elig.role, scopeDisplay(elig, scope)
+		if p.RequestType == "SelfActivate" && existing != nil && (p.ScheduleInfo == nil || !isFuture(p.ScheduleInfo.StartDateTime, now)) {
+			writeError(w, http.StatusBadRequest, "RoleAssignmentExists", "The role assignment already exists.")
+			return
+		}
+		if p.RequestType == "SelfExtend" && existing == nil {
+			writeError(w, http.StatusBadRequest, "RoleAssignmentDoesNotExist", "The role assignment does not exist.")
+			return
+		}
+		minutes, err := s.validate(p)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "RoleAssignmentRequestPolicyValidationFailed", err.Error())
+			return
+		}
+		if p.ScheduleInfo != nil {
+			req.duration = p.ScheduleInfo.Expiration.Duration
+			if start, err := time.Parse(time.RFC3339, p.ScheduleInfo.StartDateTime); err == nil && start.After(now) {
+				req.start = start
+			}
+		}
+		end := req.start.Add(time.Duration(minutes) * time.Minute)
+		switch {
+		case elig.approval:
+			req.status = azure.StatusPendingApproval
+		case p.RequestType == "SelfExtend":
+			existing.end = end
+			req.status = azure.StatusProvisioned
+		default:
+			s.active = append(s.active, &assignment{
+				id:           scope + "/providers/Microsoft.Authorization/roleAssignmentSchedules/" + uuid.NewString(),
+				role:         elig.role,
+				roleDefID:    p.RoleDefinitionID,
+				scope:        scope,
+				scopeDisplay: req.scopeDisplay,
+				memberType:   "Direct",
+				start:        req.start,
+				end:          end,
+			})
+			req.status = azure.StatusProvisioned
+			if req.start.After(now) {
+				req.status = azure.StatusScheduleCreated
+			}
+		}
+
+	default:
+		writeError(w, http.StatusBadRequest, "InvalidRequestType", fmt.Sprintf("Unsupported request type %q.", p.RequestType))
+		return
+	}
+
+	s.requests = append(s.requests, req)
+	writeJSON(w, http.StatusCreated, req.resource(s.user.ID))
+}
+
+// validate checks an activation request against the fixture policy and
+// returns its duration in minutes.
+func (s *Server) validate(p azure.ScheduleProperties) (int, error) {
+	if p.ScheduleInfo == nil {
+		return 0, fmt.Errorf("ExpirationRule: scheduleInfo is required")
+	}
+	minutes, err := azure.ParseISODurationMinutes(p.ScheduleInfo.Expiration.Duration)
+	if err != nil || minutes <= 0 {
+		return 0, fmt.Errorf("ExpirationRule: invalid duration %q", p.ScheduleInfo.Expiration.Duration)
+	}
+	maxMinutes, _ := azure.ParseISODurationMinutes(s.policy.MaxDuration)
+	if minutes > maxMinutes {
+		return 0, fmt.Errorf("ExpirationRule: duration %s exceeds the maximum of %s", p.ScheduleInfo.Expiration.Duration, s.policy.MaxDuration)
+	}
+	if s.policy.RequireJustification && strings.TrimSpace(p.Justification) == "" {
+		return 0, fmt.Errorf("JustificationRule: a justification is required")
+	}
+	if s.policy.RequireTicket && (p.TicketInfo == nil || strings.TrimSpace(p.TicketInfo.TicketNumber) == "") {
+		return 0, fmt.Errorf("TicketingRule: a ticket number is required")
+	}
+	return minutes, nil
+}
+
+// findActive returns the current or scheduled assignment of the role at scope.
+func (s *Server) findActive(scope, roleDefID string, now time.Time) *assignment {
+	for _, a := range s.active {
+		if strings.EqualFold(a.scope, scope) && sameRoleDefinition(a.roleDefID, roleDefID) && (a.end.IsZero() || a.end.After(now)) {
+			return a
+		}
+	}
+	return nil
+}
+
+func (s *Server) removeActive(target *assignment) {
+	for i, a := range s.active {
+		if a == target {
+			s.active = append(s.active[:i], s.active[i+1:]...)
+			return
+		}
+	}
+}
+
+// findEligibility returns the eligibility a request at scope activates: the
+// linked schedule when given, else any eligibility for the role covering scope.
+func (s *Server) findEligibility(scope string, p azure.ScheduleProperties) *assignment {
+	for _, e := range s.eligible {
+		if !sameRoleDefinition(e.roleDefID, p.RoleDefinitionID) || !s.covers(e.scope, scope) {
+			continue
+		}
+		if p.LinkedRoleEligibilityScheduleID == "" || strings.EqualFold(e.id, p.LinkedRoleEligibilityScheduleID) {
+			return e
+		}
+	}
+	return nil
+}
+
+// covers reports whether scope is parent or lies below it. Subscriptions
+// below a management group are found through the fixture's child resources.
+func (s *Server) covers(parent, scope string) bool {
+	lp, ls := strings.ToLower(parent), strings.ToLower(scope)
+	if ls == lp || strings.HasPrefix(ls, lp+"/") {
+		return true
+	}
+	if !azure.IsManagementGroupScope(parent) {
+		return false
+	}
+	for _, c := range s.children[lp] {
+		if !strings.EqualFold(c.ID, parent) && s.covers(c.ID, scope) {
+			return true
+		}
+	}
+	return false
+}
+
+// scopeDisplay names scope for an activation of elig, which may be below the
+// eligibility scope.
+func scopeDisplay(elig *assignment, scope string) string {
+	if strings.EqualFold(elig.scope, scope) {
+		return elig.scopeDisplay
+	}
+	return ""
+}
+
+// listEligibilityRequests returns the request history behind the
+// eligibilities at scope (all of them when scope is empty): one provisioned
+// AdminAssign request per eligibility, expired ones included, the way ARM
+// keeps requests after their schedule ends.
+func (s *Server) listEligibilityRequests(scope string) []any {
+	out := []any{}
+	for _, a := range s.eligible {
+		if scope != "" && !strings.EqualFold(a.scope, scope) {
+			continue
+		}
+		expiration := map[string]any{"type": "NoExpiration"}
+		if !a.end.IsZero() {
+			expiration = map[string]any{"type": "AfterDateTime", "endDateTime": formatTime(a.end)}
+		}
+		name := path.Base(a.id)
+		out = append(out, map[string]any{
+			"id":   a.scope + "/providers/Microsoft.Authorization/roleEligibilityScheduleRequests/" + name,
+			"name": name,
+			"properties": map[string]any{
+				"principalId":                     s.user.ID,
+				"scope":                           a.scope,
+				"roleDefinitionId":                a.roleDefID,
+				"requestType":                     "AdminAssign",
+				"status":                          azure.StatusProvisioned,
+				"createdOn":                       formatTime(a.start),
+				"targetRoleEligibilityScheduleId": a.id,
+				"scheduleInfo": map[string]any{
+					"startDateTime": formatTime(a.start),
+					"expiration":    expiration,
+				},
+				"expandedProperties": map[string]any{
+					"scope":          map[string]string{"id": a.scope, "displayName": a.scopeDisplay},
+					"roleDefinition": map[string]string{"id": a.roleDefID, "displayName": a.role},
+				},
+			},
+		})
+	}
+	return out
+}
+
+// renewEligibility applies a SelfExtend or SelfRenew eligibility request,
+// moving the end date out by the requested duration.
+func (s *Server) renewEligibility(w http.ResponseWriter, r *http.Request, scope, name string) {
+	var body struct {
+		Properties struct {
+			RoleDefinitionID                string              `json:"roleDefinitionId"`
+			RequestType                     string              `json:"requestType"`
+			Justification                   string              `json:"justification"`
+			TargetRoleEligibilityScheduleID string              `json:"targetRoleEligibilityScheduleId"`
+			ScheduleInfo                    *azure.ScheduleInfo `json:"scheduleInfo"`
+		} `json:"properties"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "InvalidRequestContent", err.Error())
+		return
+	}
+	p := body.Properties
+	var elig *assignment
+	for _, e := range s.eligible {
+		if strings.EqualFold(e.id, p.TargetRoleEligibilityScheduleID) ||
+			(strings.EqualFold(e.scope, scope) && sameRoleDefinition(e.roleDefID, p.RoleDefinitionID)) {
+			elig = e
+			break
+		}
+	}
+	if elig == nil {
+		writeError(w, http.StatusBadRequest, "RoleEligibilityNotFound", "The role eligibility does not exist.")
+		return
+	}
+	minutes := 0
+	if p.ScheduleInfo != nil {
+		minutes, _ = azure.ParseISODurationMinutes(p.ScheduleInfo.Expiration.Duration)
+	}
+	if minutes <= 0 {
+		writeError(w, http.StatusBadRequest, "RoleEligibilityRequestPolicyValidationFailed", "ExpirationRule: a positive duration is required")
+		return
+	}
+	now := time.Now()
+	expired := !elig.end.IsZero() && elig.end.Before(now)
+	if expired != strings.EqualFold(p.RequestType, "SelfRenew") {
+		writeError(w, http.StatusBadRequest, "InvalidResourceRequest",
+			fmt.Sprintf("%s is not valid for this role eligibility; expired eligibilities are renewed, current ones extended.", p.RequestType))
+		return
+	}
+	if !elig.end.IsZero() {
+		if expired {
+			elig.end = now
+		}
+		elig.end = elig.end.Add(time.Duration(minutes) * time.Minute)
+	}
+	writeJSON(w, http.StatusCreated, map[string]any{
+		"id":         scope + "/providers/Microsoft.Authorization/roleEligibilityScheduleRequests/" + name,
+		"name":       name,
+		"properties": map[string]string{"status": azure.StatusProvisioned, "requestType": p.RequestType},
+	})
+}
+
+func (s *Server) listChildren(w http.ResponseWriter, scope string) {
+	out := []any{}
+	for _, c := range s.children[strings.ToLower(scope)] {
+		out = append(out, map[string]any{
+			"id":         c.ID,
+			"name":       c.Name,
+			"type":       c.Type,
+			"properties": map[string]string{"displayName": c.DisplayName},
+		})
+	}
+	writeList(w, out)
+}
+
+// listPolicies returns a policy assignment per eligible role definition.
+func (s *Server) listPolicies(w http.ResponseWriter, scope string) {
+	var enabled []string
+	if s.policy.RequireJustification {
+		enabled = append(enabled, "Justification")
+	}
+	if s.policy.RequireTicket {
+		enabled = append(enabled, "Ticketing")
+	}
+	seen := map[string]bool{}
+	out := []any{}
+	for _, e := range s.eligible {
+		guid := strings.ToLower(path.Base(e.roleDefID))
+		if seen[guid] {
+			continue
+		}
+		seen[guid] = true
+		out = append(out, map[string]any{
+			"properties": map[string]any{
+				"scope":            scope,
+				"roleDefinitionId": roleDefinitionID(scope, guid),
+				"effectiveRules": []map[string]any{
+					{"id": "Expiration_EndUser_Assignment", "maximumDuration": s.policy.MaxDuration},
+					{"id": "Enablement_EndUser_Assignment", "enabledRules": enabled},
+					{"id": "Approval_EndUser_Assignment", "setting": map[string]bool{"isApprovalRequired": e.approval}},
+				},
+			},
+		})
+	}
+	writeList(w, out)
+}
+
+// serveRoleDefinitions returns one role definition by GUID, or lists those
+// matching a roleName filter. Names come from the eligibilities and
+// permissions from the fixture's roleDefinitions.
+func (s *Server) serveRoleDefinitions(w http.ResponseWriter, r *http.Request, parts []string) {
+	names := map[string]string{}
+	for _, e := range append(append([]*assignment{}, s.eligible...), s.active...) {
+		names[strings.ToLower(path.Base(e.roleDefID))] = e.role
+	}
+	definition := func(guid string) map[string]any {
+		def := s.roleDefs[guid]
+		typ := "BuiltInRole"
+		if def.Custom {
+			typ = "CustomRole"
+		}
+		return map[string]any{
+			"id":   "/providers/Microsoft.Authorization/roleDefinitions/" + guid,
+			"name": guid,
+			"properties": map[string]any{
+				"roleName":    names[guid],
+				"description": def.Description,
+				"type":        typ,
+				"permissions": []map[string][]string{{
+					"actions":        def.Actions,
+					"notActions":     def.NotActions,
+					"dataActions":    def.DataActions,
+					"notDataActions": def.NotDataActions,
+				}},
+			},
+		}
+	}
+
+	switch len(parts) {
+	case 0:
+		out := []any{}
+		if m := reRoleNameFilter.FindStringSubmatch(r.URL.Query().Get("$filter")); m != nil {
+			want := strings.ReplaceAll(m[1], "''", "'")
+			for guid, name := range names {
+				if strings.EqualFold(name, want) {
+					out = append(out, definition(guid))
+					break
+				}
+			}
+		}
+		writeList(w, out)
+	case 1:
+		guid := strings.ToLower(parts[0])
+		if _, ok := names[guid]; !ok {
+			notFound(w, r)
+			return
+		}
+		writeJSON(w, http.StatusOK, definition(guid))
+	default:
+		notFound(w, r)
+	}
+}
+
+// sameRoleDefinition compares role definition IDs on their trailing GUID,
+// as the ID prefix depends on the scope.
+func sameRoleDefinition(a, b string) bool {
+	return strings.EqualFold(path.Base(a), path.Base(b))
+}
+
+func isFuture(ts string, now time.Time) bool {
+	t, err := time.Parse(time.RFC3339, ts)
+	return err == nil && t.After(now)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func writeList(w http.ResponseWriter, value []any) {
+	writeJSON(w, http.StatusOK, map[string]any{"value": value})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, map[string]any{"error": map[string]string{"code": code, "message": message}})
+}
+
+func notFound(w http.ResponseWriter, r *http.Request) {
+	writeError(w, http.StatusNotFound, "NotFound", fmt.Sprintf("%s %s is not emulated", r.Method, r.URL.Path))
+}
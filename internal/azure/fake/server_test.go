@@ -0,0 +1,324 @@
+package fake
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jeircul/pim/internal/azure"
+)
+
+func newDemoClient(t *testing.T) (*azure.Client, *Server) {
+	t.Helper()
+	fx, err := DemoFixture()
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv, err := NewServer(fx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(srv.Close)
+	client, err := azure.NewClient(srv.ClientOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, srv
+}
+
+func findRole(roles []azure.Role, name, scope string) (azure.Role, bool) {
+	for _, r := range roles {
+		if r.RoleName == name && strings.EqualFold(r.Scope, scope) {
+			return r, true
+		}
+	}
+	return azure.Role{}, false
+}
+
+func TestServerActivationLifecycle(t *testing.T) {
+	client, _ := newDemoClient(t)
+	ctx := t.Context()
+
+	user, err := client.GetCurrentUser(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.UserPrincipalName != "alex@contoso.example" {
+		t.Errorf("user = %+v", user)
+	}
+	if client.CredentialSource() != "custom" {
+		t.Errorf("CredentialSource() = %q, want custom", client.CredentialSource())
+	}
+
+	roles, err := client.GetEligibleRoles(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(roles) != 5 {
+		t.Fatalf("got %d eligible roles, want 5", len(roles))
+	}
+	const prod = "/subscriptions/00000000-0000-4000-8000-0000000000a1"
+	role, ok := findRole(roles, "Contributor", prod)
+	if !ok || role.ScopeDisplay != "sub-production" || role.EligibilityEnd == "" {
+		t.Fatalf("production Contributor = %+v (found %v)", role, ok)
+	}
+
+	active, err := client.GetActiveAssignments(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(active) != 1 || active[0].RoleName != "Reader" {
+		t.Fatalf("active = %+v, want the seeded Reader", active)
+	}
+
+	pol, err := client.GetActivationPolicy(ctx, role, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pol.MaxMinutes != 480 || !pol.RequireJustification || pol.RequireApproval {
+		t.Errorf("policy = %+v", pol)
+	}
+
+	ar := azure.ActivationRequest{Role: role, PrincipalID: user.ID, Minutes: 60, Justification: "test",
+		TargetScope: prod + "/resourceGroups/rg-web"}
+	resp, err := client.ActivateRole(ctx, ar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Status() != azure.StatusProvisioned {
+		t.Errorf("activation status = %q", resp.Status())
+	}
+	if err := client.WaitForActivation(ctx, ar, resp, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// A second activation of the same role extends it.
+	ar.Minutes = 120
+	if _, err := client.ActivateRole(ctx, ar); err != nil {
+		t.Fatal(err)
+	}
+	active, err = client.GetActiveAssignments(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(active) != 2 {
+		t.Fatalf("got %d active assignments after activation, want 2", len(active))
+	}
+	var rg azure.ActiveAssignment
+	for _, a := range active {
+		if a.RoleName == "Contributor" {
+			rg = a
+		}
+	}
+	if rg.TimeRemaining() < 110*time.Minute {
+		t.Errorf("extended assignment has %s left, want about 2h", rg.TimeRemaining())
+	}
+
+	// PIM rejects deactivating a fresh activation; the seeded Reader started
+	// half an hour ago.
+	if _, err := client.DeactivateRole(ctx, rg, user.ID); !azure.IsActiveDurationTooShort(err) {
+		t.Fatalf("early deactivation: err = %v, want ActiveDurationTooShort", err)
+	}
+	var reader azure.ActiveAssignment
+	for _, a := range active {
+		if a.RoleName == "Reader" {
+			reader = a
+		}
+	}
+	if _, err := client.DeactivateRole(ctx, reader, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	active, err = client.GetActiveAssignments(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(active) != 1 {
+		t.Errorf("got %d active assignments after deactivation, want 1", len(active))
+	}
+
+	history, err := client.GetRequestHistory(ctx, azure.HistoryFilter{Since: time.Now().Add(-time.Minute)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var types []string
+	for _, r := range history {
+		types = append(types, r.RequestType)
+	}
+	if got := strings.Join(types, ","); !strings.Contains(got, "SelfActivate") || !strings.Contains(got, "SelfExtend") || !strings.Contains(got, "SelfDeactivate") {
+		t.Errorf("recent request types = %s", got)
+	}
+}
+
+func TestServerPolicyAndApproval(t *testing.T) {
+	client, _ := newDemoClient(t)
+	ctx := t.Context()
+	roles, err := client.GetEligibleRoles(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner, ok := findRole(roles, "Owner", "/providers/Microsoft.Management/managementGroups/mg-platform")
+	if !ok {
+		t.Fatal("Owner eligibility missing")
+	}
+
+	if _, err := client.ActivateRole(ctx, azure.ActivationRequest{Role: owner, Minutes: 60}); err == nil ||
+		!strings.Contains(err.Error(), "JustificationRule") {
+		t.Errorf("activation without justification: err = %v", err)
+	}
+	if _, err := client.ActivateRole(ctx, azure.ActivationRequest{Role: owner, Minutes: 600, Justification: "x"}); err == nil ||
+		!strings.Contains(err.Error(), "ExpirationRule") {
+		t.Errorf("activation over the maximum: err = %v", err)
+	}
+
+	// Owner needs approval: the request stays pending until cancelled.
+	ar := azure.ActivationRequest{Role: owner, Minutes: 60, Justification: "change"}
+	resp, err := client.ActivateRole(ctx, ar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Status() != azure.StatusPendingApproval {
+		t.Errorf("status = %q, want PendingApproval", resp.Status())
+	}
+	pending, err := client.GetPendingRequests(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 {
+		t.Fatalf("got %d pending requests, want 1", len(pending))
+	}
+	if err := client.CancelRequest(ctx, pending[0]); err != nil {
+		t.Fatal(err)
+	}
+	if pending, _ = client.GetPendingRequests(ctx); len(pending) != 0 {
+		t.Errorf("request still pending after cancel: %+v", pending)
+	}
+}
+
+func TestServerRenewExpiredEligibility(t *testing.T) {
+	fx, err := DemoFixture()
+	if err != nil {
+		t.Fatal(err)
+	}
+	const scope = "/subscriptions/00000000-0000-4000-8000-0000000000a1"
+	fx.Eligible = append(fx.Eligible,
+		Assignment{Role: "Key Vault Administrator", RoleDefinitionID: "00482a5a-887f-4fb3-b363-3b7fe8e74483",
+			Scope: scope, Start: "-2160h", End: "-72h"},
+		Assignment{Role: "Backup Operator", RoleDefinitionID: "00c29273-979b-4161-815c-10b084fb9324",
+			Scope: scope, Start: "-4320h", End: "-1440h"})
+	srv, err := NewServer(fx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(srv.Close)
+	client, err := azure.NewClient(srv.ClientOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := t.Context()
+
+	roles, err := client.GetEligibleRoles(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := findRole(roles, "Key Vault Administrator", scope); ok {
+		t.Fatal("expired eligibility listed as eligible")
+	}
+	expired, err := client.GetExpiredEligibilities(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(expired) != 1 {
+		t.Fatalf("got %d expired eligibilities, want 1 (the other is past the renew window): %+v", len(expired), expired)
+	}
+	kv := expired[0]
+	if kv.RoleName != "Key Vault Administrator" || !kv.IsEligibilityExpired() {
+		t.Fatalf("expired = %+v", kv)
+	}
+
+	user, err := client.GetCurrentUser(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := azure.RenewalRequest{Role: kv, PrincipalID: user.ID, Justification: "still needed", Days: 30}
+	if !rr.IsRenew() {
+		t.Fatal("IsRenew() = false for an expired eligibility")
+	}
+	if _, err := client.RenewEligibility(ctx, rr); err != nil {
+		t.Fatal(err)
+	}
+	roles, err = client.GetEligibleRoles(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := findRole(roles, "Key Vault Administrator", scope); !ok {
+		t.Error("eligibility not listed after renewal")
+	}
+	if expired, _ = client.GetExpiredEligibilities(ctx); len(expired) != 0 {
+		t.Errorf("still expired after renewal: %+v", expired)
+	}
+}
+
+func TestServerDiscovery(t *testing.T) {
+	client, _ := newDemoClient(t)
+	ctx := t.Context()
+
+	subs, parents, _, err := client.ListAllSubscriptionsUnderMG(ctx, "mg-platform")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subs) != 2 || parents["00000000-0000-4000-8000-0000000000b2"] != "mg-connectivity" {
+		t.Errorf("subscriptions = %+v, parents = %v", subs, parents)
+	}
+
+	rgs, err := client.ListEligibleResourceGroups(ctx, "00000000-0000-4000-8000-0000000000a1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rgs) != 2 {
+		t.Errorf("resource groups = %+v", rgs)
+	}
+	res, err := client.ListEligibleResources(ctx, "00000000-0000-4000-8000-0000000000a2", "rg-app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 2 || res[0].Name != "kv-app-dev" {
+		t.Errorf("resources = %+v", res)
+	}
+
+	def, err := client.GetRoleDefinitionByName(ctx, "", "Key Vault Secrets Officer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(def.DataActions) != 1 || def.Custom {
+		t.Errorf("definition = %+v", def)
+	}
+
+	tenants, err := client.ListTenants(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tenants) != 1 || tenants[0].DisplayName != "Contoso (demo)" {
+		t.Errorf("tenants = %+v", tenants)
+	}
+}
+
+func TestResolveTime(t *testing.T) {
+	base := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, tc := range []struct {
+		in   string
+		want time.Time
+		err  bool
+	}{
+		{"", time.Time{}, false},
+		{"+2h", base.Add(2 * time.Hour), false},
+		{"-30m", base.Add(-30 * time.Minute), false},
+		{"2026-03-01T00:00:00Z", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), false},
+		{"+2d", time.Time{}, true},
+		{"tomorrow", time.Time{}, true},
+	} {
+		got, err := resolveTime(tc.in, base)
+		if (err != nil) != tc.err || !got.Equal(tc.want) {
+			t.Errorf("resolveTime(%q) = %v, %v", tc.in, got, err)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// groupRequest is the Graph privilegedAccessGroupAssignmentScheduleRequest body.
+type groupRequest struct {
+	Action        string        `json:"action"`
+	PrincipalID   string        `json:"principalId"`
+	GroupID       string        `json:"groupId"`
+	AccessID      string        `json:"accessId"`
+	Justification string        `json:"justification,omitempty"`
+	ScheduleInfo  *ScheduleInfo `json:"scheduleInfo,omitempty"`
+	TicketInfo    *TicketInfo   `json:"ticketInfo,omitempty"`
+}
+
+// GetEligibleGroups fetches the caller's eligible PIM for Groups memberships
+// and ownerships from Microsoft Graph, following pagination.
+func (c *Client) GetEligibleGroups(ctx context.Context) ([]GroupEligibility, error) {
+	tok, err := c.graphToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reqURL := c.graphURL + "/identityGovernance/privilegedAccess/group/eligibilityScheduleInstances/filterByCurrentUser(on='principal')?$expand=group"
+
+	var out []GroupEligibility
+	for reqURL != "" {
+		resp, err := c.doRequest(ctx, http.MethodGet, reqURL, tok, nil)
+		if err != nil {
+			return nil, fmt.Errorf("get eligible groups: %w", err)
+		}
+
+		var result struct {
+			Value []struct {
+				GroupID               string `json:"groupId"`
+				AccessID              string `json:"accessId"`
+				EligibilityScheduleID string `json:"eligibilityScheduleId"`
+				MemberType            string `json:"memberType"`
+				StartDateTime         string `json:"startDateTime"`
+				EndDateTime           string `json:"endDateTime"`
+				Group                 struct {
+					DisplayName string `json:"displayName"`
+				} `json:"group"`
+			} `json:"value"`
+			NextLink string `json:"@odata.nextLink"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			resp.Body.Close()
+			return nil, fmt.Errorf("decode eligible groups: %w", err)
+		}
+		resp.Body.Close()
+
+		for _, item := range result.Value {
+			out = append(out, GroupEligibility{
+				GroupID:               item.GroupID,
+				GroupDisplay:          item.Group.DisplayName,
+				AccessID:              item.AccessID,
+				EligibilityScheduleID: item.EligibilityScheduleID,
+				StartDateTime:         item.StartDateTime,
+				EndDateTime:           item.EndDateTime,
+				MemberType:            item.MemberType,
+			})
+		}
+		reqURL = result.NextLink
+	}
+	return out, nil
+}
+
+// getEligibleGroupRoles returns the caller's group eligibilities as roles.
+func (c *Client) getEligibleGroupRoles(ctx context.Context) ([]Role, error) {
+	groups, err := c.GetEligibleGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	roles := make([]Role, 0, len(groups))
+	for _, g := range groups {
+		roles = append(roles, g.Role())
+	}
+	return roles, nil
+}
+
+// getActiveGroupAssignments fetches the caller's active PIM for Groups
+// memberships and ownerships from Microsoft Graph, following pagination.
+func (c *Client) getActiveGroupAssignments(ctx context.Context) ([]ActiveAssignment, error) {
+	tok, err := c.graphToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reqURL := c.graphURL + "/identityGovernance/privilegedAccess/group/assignmentScheduleInstances/filterByCurrentUser(on='principal')?$expand=group"
+
+	var out []ActiveAssignment
+	for reqURL != "" {
+		resp, err := c.doRequest(ctx, http.MethodGet, reqURL, tok, nil)
+		if err != nil {
+			return nil, fmt.Errorf("get active group assignments: %w", err)
+		}
+
+		var result struct {
+			Value []struct {
+				ID            string `json:"id"`
+				GroupID       string `json:"groupId"`
+				AccessID      string `json:"accessId"`
+				MemberType    string `json:"memberType"`
+				StartDateTime string `json:"startDateTime"`
+				EndDateTime   string `json:"endDateTime"`
+				Group         struct {
+					DisplayName string `json:"displayName"`
+				} `json:"group"`
+			} `json:"value"`
+			NextLink string `json:"@odata.nextLink"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			resp.Body.Close()
+			return nil, fmt.Errorf("decode active group assignments: %w", err)
+		}
+		resp.Body.Close()
+
+		for _, item := range result.Value {
+			scope := GroupScope(item.GroupID)
+			out = append(out, ActiveAssignment{
+				Name:             item.ID,
+				Scope:            scope,
+				ScopeDisplay:     DefaultScopeDisplay(scope, item.Group.DisplayName),
+				RoleName:         GroupAccessRoleName(item.AccessID),
+				RoleDefinitionID: strings.ToLower(item.AccessID),
+				StartDateTime:    item.StartDateTime,
+				EndDateTime:      item.EndDateTime,
+				MemberType:       item.MemberType,
+			})
+		}
+		reqURL = result.NextLink
+	}
+	return out, nil
+}
+
+// isGroupAccessActive reports whether the caller already holds the given
+// access on the group.
+func (c *Client) isGroupAccessActive(ctx context.Context, scope, accessID string) (bool, error) {
+	tok, err := c.graphToken(ctx)
+	if err != nil {
+		return false, err
+	}
+	filter := fmt.Sprintf("groupId eq '%s' and accessId eq '%s'", GroupIDFromScope(scope), strings.ToLower(accessID))
+	reqURL := c.graphURL + "/identityGovernance/privilegedAccess/group/assignmentScheduleInstances/filterByCurrentUser(on='principal')?$filter=" + url.QueryEscape(filter)
+
+	resp, err := c.doRequest(ctx, http.MethodGet, reqURL, tok, nil)
+	if err != nil {
+		return false, fmt.Errorf("check active group access: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Value []any `json:"value"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return false, fmt.Errorf("decode active group access check: %w", err)
+	}
+	return len(result.Value) > 0, nil
+}
+
+// activateGroupAccess submits a self-activation or extension request for a
+// group membership or ownership via Microsoft Graph.
+func (c *Client) activateGroupAccess(ctx context.Context, ar ActivationRequest) (*ScheduleResponse, error) {
+	role := ar.Role
+	action := "selfActivate"
+	if !ar.IsScheduled() {
+		active, err := c.isGroupAccessActive(ctx, role.Scope, role.RoleDefinitionID)
+		if err != nil {
+			return nil, err
+		}
+		if active {
+			action = "selfExtend"
+		}
+	}
+	req := groupRequest{
+		Action:        action,
+		PrincipalID:   ar.PrincipalID,
+		GroupID:       GroupIDFromScope(role.Scope),
+		AccessID:      strings.ToLower(role.RoleDefinitionID),
+		Justification: ar.Justification,
+		TicketInfo:    ar.Ticket.ptr(),
+		ScheduleInfo:  ar.scheduleInfo("afterDuration"),
+	}
+	resp, err := c.submitGroupRequest(ctx, req)
+	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == 400 &&
+			(strings.EqualFold(apiErr.Code, errCodePendingRequest) || strings.EqualFold(apiErr.Code, errCodeAssignmentExists)) {
+			return &ScheduleResponse{AlreadyActive: IsAlreadyActive(err)}, nil
+		}
+		return nil, fmt.Errorf("submit group activation: %w", err)
+	}
+	return resp, nil
+}
+
+// deactivateGroupAccess submits a self-deactivation request for a group membership or ownership.
+func (c *Client) deactivateGroupAccess(ctx context.Context, assignment ActiveAssignment, principalID string) (*ScheduleResponse, error) {
+	req := groupRequest{
+		Action:      "selfDeactivate",
+		PrincipalID: principalID,
+		GroupID:     GroupIDFromScope(assignment.Scope),
+		AccessID:    strings.ToLower(assignment.RoleDefinitionID),
+	}
+	resp, err := c.submitGroupRequest(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("submit group deactivation: %w", err)
+	}
+	return resp, nil
+}
+
+func (c *Client) submitGroupRequest(ctx context.Context, req groupRequest) (*ScheduleResponse, error) {
+	return c.postGraphScheduleRequest(ctx, "/identityGovernance/privilegedAccess/group/assignmentScheduleRequests", req)
+}
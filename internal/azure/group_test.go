@@ -0,0 +1,124 @@
+package azure
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGroupAccessRequests(t *testing.T) {
+	const requestsPath = "/v1.0/identityGovernance/privilegedAccess/group/assignmentScheduleRequests"
+	role := Role{Scope: GroupScope("g-1"), RoleDefinitionID: "Member", RoleName: "Member"}
+	start := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	activation := ActivationRequest{Role: role, PrincipalID: "p-1", Justification: "ticket", Minutes: 120}
+	scheduled := activation
+	scheduled.Start = start
+
+	tests := []struct {
+		name      string
+		active    string
+		run       func(*Client) (*ScheduleResponse, error)
+		wantCheck bool
+		want      map[string]any
+	}{
+		{
+			name:      "activate",
+			active:    `{"value":[]}`,
+			run:       func(c *Client) (*ScheduleResponse, error) { return c.ActivateRole(t.Context(), activation) },
+			wantCheck: true,
+			want: map[string]any{
+				"action": "selfActivate", "principalId": "p-1", "groupId": "g-1", "accessId": "member",
+				"justification": "ticket",
+				"scheduleInfo":  map[string]any{"duration": "PT2H"},
+			},
+		},
+		{
+			name:      "extend active access",
+			active:    `{"value":[{"id":"inst-1"}]}`,
+			run:       func(c *Client) (*ScheduleResponse, error) { return c.ActivateRole(t.Context(), activation) },
+			wantCheck: true,
+			want: map[string]any{
+				"action": "selfExtend", "principalId": "p-1", "groupId": "g-1", "accessId": "member",
+				"scheduleInfo": map[string]any{"duration": "PT2H"},
+			},
+		},
+		{
+			name: "scheduled activation skips the active check",
+			run:  func(c *Client) (*ScheduleResponse, error) { return c.ActivateRole(t.Context(), scheduled) },
+			want: map[string]any{
+				"action": "selfActivate", "groupId": "g-1", "accessId": "member",
+				"scheduleInfo": map[string]any{"duration": "PT2H", "start": start},
+			},
+		},
+		{
+			name: "deactivate",
+			run: func(c *Client) (*ScheduleResponse, error) {
+				return c.DeactivateRole(t.Context(), ActiveAssignment{Scope: role.Scope, RoleDefinitionID: "Member"}, "p-1")
+			},
+			want: map[string]any{
+				"action": "selfDeactivate", "principalId": "p-1", "groupId": "g-1", "accessId": "member",
+				"justification": nil,
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c, calls := newGraphTestClient(t, tc.active)
+			resp, err := tc.run(c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.Name != "req-1" || resp.Status() != "PendingProvisioning" {
+				t.Errorf("response = %+v", resp)
+			}
+
+			wantCalls := 1
+			if tc.wantCheck {
+				wantCalls = 2
+			}
+			if len(*calls) != wantCalls {
+				t.Fatalf("calls = %+v, want %d", *calls, wantCalls)
+			}
+			if tc.wantCheck && !strings.HasPrefix((*calls)[0].path, "/v1.0/identityGovernance/privilegedAccess/group/assignmentScheduleInstances/") {
+				t.Errorf("active check path = %q", (*calls)[0].path)
+			}
+			post := (*calls)[len(*calls)-1]
+			if post.method != http.MethodPost || post.path != requestsPath {
+				t.Errorf("request = %s %s, want POST %s", post.method, post.path, requestsPath)
+			}
+			checkScheduleBody(t, post.body, tc.want)
+		})
+	}
+}
+
+func TestGetEligibleGroups(t *testing.T) {
+	c, calls := newGraphTestClient(t, `{"value":[{
+		"groupId":"g-1","accessId":"owner","eligibilityScheduleId":"sched-1","memberType":"Direct",
+		"startDateTime":"2024-01-01T00:00:00Z","endDateTime":"2025-01-01T00:00:00Z",
+		"group":{"displayName":"Prod Admins"}
+	}]}`)
+
+	groups, err := c.GetEligibleGroups(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := (*calls)[0].path; got != "/v1.0/identityGovernance/privilegedAccess/group/eligibilityScheduleInstances/filterByCurrentUser(on='principal')" {
+		t.Errorf("path = %q", got)
+	}
+	want := GroupEligibility{
+		GroupID:               "g-1",
+		GroupDisplay:          "Prod Admins",
+		AccessID:              "owner",
+		EligibilityScheduleID: "sched-1",
+		StartDateTime:         "2024-01-01T00:00:00Z",
+		EndDateTime:           "2025-01-01T00:00:00Z",
+		MemberType:            "Direct",
+	}
+	if len(groups) != 1 || groups[0] != want {
+		t.Fatalf("groups = %+v, want [%+v]", groups, want)
+	}
+	if r := groups[0].Role(); r.Scope != GroupScope("g-1") || r.RoleDefinitionID != "owner" || r.ScopeDisplay != "Prod Admins" {
+		t.Errorf("Role() = %+v", r)
+	}
+}
@@ -0,0 +1,235 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// approvalAPIVersion is the ARM API version serving roleAssignmentApprovals.
+const approvalAPIVersion = "2021-01-01-preview"
+
+// HistoryFilter bounds GetRequestHistory by request creation time. A zero
+// Since or Until leaves that end of the range open; Until is exclusive.
+type HistoryFilter struct {
+	Since time.Time
+	Until time.Time
+}
+
+// contains reports whether created (RFC 3339) falls inside the range.
+// Requests without a parseable creation time are kept only when the range is
+// unbounded.
+func (f HistoryFilter) contains(created string) bool {
+	t, err := time.Parse(time.RFC3339Nano, created)
+	if err != nil {
+		return f.Since.IsZero() && f.Until.IsZero()
+	}
+	if !f.Since.IsZero() && t.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !t.Before(f.Until) {
+		return false
+	}
+	return true
+}
+
+// approvalWorkers bounds the approvals GetRequestHistory reads at once.
+const approvalWorkers = 8
+
+// GetRequestHistory lists every schedule request that targets the caller —
+// activations, extensions, deactivations, and admin assignments, whatever
+// their outcome — created within f: Azure resource requests from ARM
+// (asTarget), followed by directory role and group requests from Graph.
+// f.Since is sent as a creation-time $filter; f.Until is applied here.
+// Graph sources are skipped when access is denied. Approvers are resolved,
+// up to approvalWorkers at a time, for requests that were reviewed or are
+// awaiting review, where the caller may read the approval; ApprovedBy is
+// left empty otherwise. Results are ordered newest first.
+func (c *Client) GetRequestHistory(ctx context.Context, f HistoryFilter) ([]AssignmentRequest, error) {
+	all, err := c.getAllRequests(ctx, "asTarget()", f.Since)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []AssignmentRequest
+	for _, r := range all {
+		if f.contains(r.CreatedOn) {
+			out = append(out, r)
+		}
+	}
+	c.resolveApprovers(ctx, out)
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Created().After(out[j].Created()) })
+	return out, nil
+}
+
+// isReviewed reports whether a request with status has been through, or is
+// in, an approval review: awaiting approval, denied, or approved and applied.
+func isReviewed(status string) bool {
+	if IsAwaitingApproval(status) {
+		return true
+	}
+	switch {
+	case strings.EqualFold(status, StatusDenied),
+		strings.EqualFold(status, StatusProvisioned),
+		strings.EqualFold(status, StatusGranted):
+		return true
+	}
+	return false
+}
+
+// resolveApprovers sets ApprovedBy on the reviewed requests in reqs, reading
+// each distinct approval once with at most approvalWorkers in flight.
+func (c *Client) resolveApprovers(ctx context.Context, reqs []AssignmentRequest) {
+	first := map[string]AssignmentRequest{}
+	for _, r := range reqs {
+		if r.ApprovalID == "" || !isReviewed(r.Status) {
+			continue
+		}
+		if _, ok := first[r.ApprovalID]; !ok {
+			first[r.ApprovalID] = r
+		}
+	}
+	if len(first) == 0 {
+		return
+	}
+
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		approvers = make(map[string]string, len(first))
+		sem       = make(chan struct{}, approvalWorkers)
+	)
+	for id, r := range first {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			name := c.approvedBy(ctx, r)
+			mu.Lock()
+			approvers[id] = name
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	for i := range reqs {
+		if name, ok := approvers[reqs[i].ApprovalID]; ok {
+			reqs[i].ApprovedBy = name
+		}
+	}
+}
+
+// approvedBy returns the comma-separated names of the reviewers who approved
+// r, or "" when the approval cannot be read.
+func (c *Client) approvedBy(ctx context.Context, r AssignmentRequest) string {
+	var (
+		names []string
+		err   error
+	)
+	switch {
+	case IsDirectoryScope(r.Scope):
+		// Directory role approvals are only exposed by the Graph beta API.
+		names, err = c.graphApprovers(ctx, c.cloud.GraphEndpoint+"/beta/roleManagement/directory/roleAssignmentApprovals/"+url.PathEscape(r.ApprovalID)+"?$expand=steps", "steps")
+	case IsGroupScope(r.Scope):
+		names, err = c.graphApprovers(ctx, c.graphURL+"/identityGovernance/privilegedAccess/group/assignmentApprovals/"+url.PathEscape(r.ApprovalID)+"?$expand=stages", "stages")
+	default:
+		names, err = c.armApprovers(ctx, r.ApprovalID)
+	}
+	if err != nil {
+		return ""
+	}
+	return strings.Join(names, ", ")
+}
+
+// armApprovers reads an ARM role assignment approval.
+func (c *Client) armApprovers(ctx context.Context, approvalID string) ([]string, error) {
+	tok, err := c.armToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	path := approvalID
+	if !strings.HasPrefix(path, "/") {
+		path = "/providers/Microsoft.Authorization/roleAssignmentApprovals/" + url.PathEscape(approvalID)
+	}
+	resp, err := c.doRequest(ctx, http.MethodGet, fmt.Sprintf("%s%s?api-version=%s", c.armURL, path, approvalAPIVersion), tok, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get approval: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Properties struct {
+			Stages []struct {
+				Properties struct {
+					ReviewResult string `json:"reviewResult"`
+					ReviewedBy   struct {
+						PrincipalName     string `json:"principalName"`
+						UserPrincipalName string `json:"userPrincipalName"`
+					} `json:"reviewedBy"`
+				} `json:"properties"`
+			} `json:"stages"`
+		} `json:"properties"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode approval: %w", err)
+	}
+	var names []string
+	for _, st := range result.Properties.Stages {
+		p := st.Properties
+		if !strings.EqualFold(p.ReviewResult, "Approve") {
+			continue
+		}
+		name := p.ReviewedBy.UserPrincipalName
+		if name == "" {
+			name = p.ReviewedBy.PrincipalName
+		}
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// graphApprovers reads a Graph approval whose stages are returned under
+// field ("stages" for groups, "steps" for directory roles).
+func (c *Client) graphApprovers(ctx context.Context, reqURL, field string) ([]string, error) {
+	tok, err := c.graphToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.doRequest(ctx, http.MethodGet, reqURL, tok, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get approval: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result map[string]json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode approval: %w", err)
+	}
+	var stages []struct {
+		ReviewResult string `json:"reviewResult"`
+		ReviewedBy   struct {
+			DisplayName string `json:"displayName"`
+		} `json:"reviewedBy"`
+	}
+	if raw, ok := result[field]; ok {
+		if err := json.Unmarshal(raw, &stages); err != nil {
+			return nil, fmt.Errorf("decode approval %s: %w", field, err)
+		}
+	}
+	var names []string
+	for _, st := range stages {
+		if strings.EqualFold(st.ReviewResult, "Approve") && st.ReviewedBy.DisplayName != "" {
+			names = append(names, st.ReviewedBy.DisplayName)
+		}
+	}
+	return names, nil
+}
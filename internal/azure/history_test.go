@@ -0,0 +1,140 @@
+package azure
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetRequestHistory(t *testing.T) {
+	var approvalCalls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/roleAssignmentScheduleRequests"):
+			if got, want := r.URL.Query().Get("$filter"), "asTarget() and createdOn ge '2024-04-01T00:00:00Z'"; got != want {
+				t.Errorf("$filter = %q, want %q", got, want)
+			}
+			_, _ = w.Write([]byte(`{"value":[
+				{"id":"r-5","properties":{"scope":"/subscriptions/sub-1","requestType":"SelfDeactivate","status":"Revoked","createdOn":"2024-05-04T10:00:00.5Z","expandedProperties":{"roleDefinition":{"displayName":"Owner"}}}},
+				{"id":"r-4","properties":{"scope":"/subscriptions/sub-1","requestType":"SelfActivate","status":"Canceled","createdOn":"2024-05-04T10:00:00Z","approvalId":"/providers/Microsoft.Authorization/roleAssignmentApprovals/ap-2","expandedProperties":{"roleDefinition":{"displayName":"Owner"}}}},
+				{"id":"r-1","properties":{"scope":"/subscriptions/sub-1","requestType":"SelfActivate","status":"Provisioned","createdOn":"2024-05-02T10:00:00Z","approvalId":"/providers/Microsoft.Authorization/roleAssignmentApprovals/ap-1","scheduleInfo":{"expiration":{"duration":"PT4H"}},"expandedProperties":{"roleDefinition":{"displayName":"Owner"}}}},
+				{"id":"r-2","properties":{"scope":"/subscriptions/sub-1","requestType":"SelfActivate","status":"Provisioned","createdOn":"2024-05-03T10:00:00Z","approvalId":"/providers/Microsoft.Authorization/roleAssignmentApprovals/ap-1","expandedProperties":{"roleDefinition":{"displayName":"Owner"}}}},
+				{"id":"r-3","properties":{"scope":"/subscriptions/sub-1","requestType":"SelfActivate","status":"Provisioned","createdOn":"2024-03-01T10:00:00Z","expandedProperties":{"roleDefinition":{"displayName":"Reader"}}}}
+			]}`))
+		case strings.HasPrefix(r.URL.Path, "/providers/Microsoft.Authorization/roleAssignmentApprovals/"):
+			approvalCalls.Add(1)
+			_, _ = w.Write([]byte(`{"properties":{"stages":[
+				{"properties":{"reviewResult":"Approve","reviewedBy":{"userPrincipalName":"ada@example.com"}}},
+				{"properties":{"reviewResult":"NotReviewed"}}
+			]}}`))
+		default:
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"error":{"code":"Forbidden","message":"denied"}}`))
+		}
+	}))
+	defer srv.Close()
+
+	c := testRetryClient(srv)
+	c.cred = &claimsCred{}
+	c.armURL = srv.URL
+	c.graphURL = srv.URL + "/v1.0"
+	c.cloud.GraphEndpoint = srv.URL
+
+	got, err := c.GetRequestHistory(t.Context(), HistoryFilter{
+		Since: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+		Until: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 4 {
+		t.Fatalf("got %d requests, want 4 in range: %+v", len(got), got)
+	}
+	if got[0].ID != "r-5" || got[1].ID != "r-4" || got[2].ID != "r-2" || got[3].ID != "r-1" {
+		t.Errorf("order = %s, %s, %s, %s; want newest first", got[0].ID, got[1].ID, got[2].ID, got[3].ID)
+	}
+	for _, r := range got[2:] {
+		if r.ApprovedBy != "ada@example.com" {
+			t.Errorf("%s ApprovedBy = %q, want ada@example.com", r.ID, r.ApprovedBy)
+		}
+	}
+	if got[1].ApprovedBy != "" {
+		t.Errorf("canceled request ApprovedBy = %q, want empty", got[1].ApprovedBy)
+	}
+	if n := approvalCalls.Load(); n != 1 {
+		t.Errorf("approval fetched %d times, want 1 (shared, and none for the canceled request)", n)
+	}
+	if d := got[3].DurationDisplay(); d != "4h" {
+		t.Errorf("DurationDisplay() = %q, want 4h", d)
+	}
+}
+
+func TestGetRequestHistorySinceUnsupported(t *testing.T) {
+	var filters []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/roleAssignmentScheduleRequests"):
+			filter := r.URL.Query().Get("$filter")
+			filters = append(filters, filter)
+			if filter != "asTarget()" {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error":{"code":"InvalidFilter","message":"unsupported filter"}}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"value":[
+				{"id":"r-1","properties":{"scope":"/subscriptions/sub-1","requestType":"SelfActivate","status":"Provisioned","createdOn":"2024-05-02T10:00:00Z","expandedProperties":{"roleDefinition":{"displayName":"Owner"}}}},
+				{"id":"r-2","properties":{"scope":"/subscriptions/sub-1","requestType":"SelfActivate","status":"Provisioned","createdOn":"2024-03-01T10:00:00Z","expandedProperties":{"roleDefinition":{"displayName":"Reader"}}}}
+			]}`))
+		default:
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"error":{"code":"Forbidden","message":"denied"}}`))
+		}
+	}))
+	defer srv.Close()
+
+	c := testRetryClient(srv)
+	c.cred = &claimsCred{}
+	c.armURL = srv.URL
+	c.graphURL = srv.URL + "/v1.0"
+
+	got, err := c.GetRequestHistory(t.Context(), HistoryFilter{Since: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].ID != "r-1" {
+		t.Errorf("got %+v, want only r-1", got)
+	}
+	if len(filters) != 2 {
+		t.Errorf("ARM listed %d times (%q), want the filtered attempt then the fallback", len(filters), filters)
+	}
+}
+
+func TestHistoryFilterContains(t *testing.T) {
+	f := HistoryFilter{
+		Since: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Until: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+	}
+	tests := []struct {
+		created string
+		want    bool
+	}{
+		{"2024-01-01T00:00:00Z", true},
+		{"2024-01-31T23:59:59Z", true},
+		{"2024-02-01T00:00:00Z", false},
+		{"2023-12-31T23:59:59Z", false},
+		{"2024-01-31T23:59:59.999Z", true},
+		{"", false},
+		{"yesterday", false},
+	}
+	for _, tc := range tests {
+		if got := f.contains(tc.created); got != tc.want {
+			t.Errorf("contains(%q) = %v, want %v", tc.created, got, tc.want)
+		}
+	}
+	if !(HistoryFilter{}).contains("") {
+		t.Error("unbounded filter dropped a request without a creation time")
+	}
+}
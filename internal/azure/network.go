@@ -0,0 +1,84 @@
+package azure
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// DefaultHTTPTimeout bounds each request when NetworkConfig.Timeout is unset.
+const DefaultHTTPTimeout = 90 * time.Second
+
+// NetworkConfig configures how the client reaches ARM, Graph and the
+// authority host. The zero value uses HTTPS_PROXY / NO_PROXY from the
+// environment, the system certificate pool and DefaultHTTPTimeout.
+type NetworkConfig struct {
+	// Proxy is the proxy URL for every request, e.g.
+	// "http://proxy.corp:3128"; empty defers to HTTPS_PROXY / HTTP_PROXY.
+	Proxy string
+	// NoProxy lists hosts, domain suffixes (".corp.example") and CIDRs
+	// reached directly; empty defers to NO_PROXY.
+	NoProxy []string
+	// CABundle is a PEM file of certificates trusted in addition to the
+	// system pool, e.g. the root of a TLS-inspecting proxy.
+	CABundle string
+	// Timeout bounds each request; zero uses DefaultHTTPTimeout.
+	Timeout time.Duration
+}
+
+// HTTPClient returns an HTTP client applying the proxy, CA bundle and
+// timeout settings.
+func (n NetworkConfig) HTTPClient() (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	proxy := httpproxy.FromEnvironment()
+	if n.Proxy != "" {
+		u, err := url.Parse(n.Proxy)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid proxy %q: must be a URL such as http://proxy.example:3128", n.Proxy)
+		}
+		proxy.HTTPProxy, proxy.HTTPSProxy = n.Proxy, n.Proxy
+	}
+	if len(n.NoProxy) > 0 {
+		proxy.NoProxy = strings.Join(n.NoProxy, ",")
+	}
+	proxyFunc := proxy.ProxyFunc()
+	transport.Proxy = func(r *http.Request) (*url.URL, error) { return proxyFunc(r.URL) }
+
+	if n.CABundle != "" {
+		pool, err := certPool(n.CABundle)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+
+	timeout := n.Timeout
+	if timeout <= 0 {
+		timeout = DefaultHTTPTimeout
+	}
+	return &http.Client{Transport: transport, Timeout: timeout}, nil
+}
+
+// certPool returns the system pool with the PEM certificates in path added.
+func certPool(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read CA bundle: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("CA bundle %s contains no PEM certificates", path)
+	}
+	return pool, nil
+}
@@ -0,0 +1,96 @@
+package azure
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNetworkConfigProxy(t *testing.T) {
+	t.Setenv("HTTPS_PROXY", "")
+	t.Setenv("NO_PROXY", "")
+	client, err := NetworkConfig{
+		Proxy:   "http://proxy.example:3128",
+		NoProxy: []string{".internal.example", "10.0.0.0/8"},
+	}.HTTPClient()
+	if err != nil {
+		t.Fatalf("HTTPClient: %v", err)
+	}
+	proxy := client.Transport.(*http.Transport).Proxy
+	for _, tc := range []struct {
+		url  string
+		want string
+	}{
+		{"https://management.azure.com/", "http://proxy.example:3128"},
+		{"https://graph.internal.example/", ""},
+		{"https://10.1.2.3/", ""},
+	} {
+		req, _ := http.NewRequest(http.MethodGet, tc.url, nil)
+		u, err := proxy(req)
+		if err != nil {
+			t.Fatalf("proxy(%s): %v", tc.url, err)
+		}
+		got := ""
+		if u != nil {
+			got = u.String()
+		}
+		if got != tc.want {
+			t.Errorf("proxy(%s) = %q, want %q", tc.url, got, tc.want)
+		}
+	}
+
+	if _, err := (NetworkConfig{Proxy: "proxy.example"}).HTTPClient(); err == nil {
+		t.Error("expected an error for a proxy without a scheme")
+	}
+}
+
+func TestNetworkConfigTimeout(t *testing.T) {
+	client, err := NetworkConfig{}.HTTPClient()
+	if err != nil || client.Timeout != DefaultHTTPTimeout {
+		t.Errorf("default Timeout = %v, err = %v; want %v", client.Timeout, err, DefaultHTTPTimeout)
+	}
+	client, err = NetworkConfig{Timeout: 2 * time.Minute}.HTTPClient()
+	if err != nil || client.Timeout != 2*time.Minute {
+		t.Errorf("Timeout = %v, err = %v; want 2m", client.Timeout, err)
+	}
+}
+
+func TestNetworkConfigCABundle(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	untrusted, err := NetworkConfig{}.HTTPClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := untrusted.Get(srv.URL); err == nil {
+		t.Fatal("expected the test server's certificate to be untrusted without the bundle")
+	}
+
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(bundle, cert, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	client, err := NetworkConfig{CABundle: bundle}.HTTPClient()
+	if err != nil {
+		t.Fatalf("HTTPClient: %v", err)
+	}
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("GET with CA bundle: %v", err)
+	}
+	resp.Body.Close()
+
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	if err := os.WriteFile(empty, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (NetworkConfig{CABundle: empty}).HTTPClient(); err == nil {
+		t.Error("expected an error for a bundle without certificates")
+	}
+}
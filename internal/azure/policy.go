@@ -0,0 +1,237 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// Role management policy rule IDs that govern end-user activation.
+const (
+	ruleExpirationEndUser = "Expiration_EndUser_Assignment"
+	ruleEnablementEndUser = "Enablement_EndUser_Assignment"
+	ruleApprovalEndUser   = "Approval_EndUser_Assignment"
+)
+
+// ActivationPolicy is the effective end-user activation policy for a role at a scope.
+// A zero MaxMinutes means the maximum duration is unknown.
+type ActivationPolicy struct {
+	MaxMinutes           int
+	RequireJustification bool
+	RequireTicket        bool
+	RequireMFA           bool
+	RequireApproval      bool
+}
+
+// Merge combines two policies, keeping the strictest value of each rule.
+func (p ActivationPolicy) Merge(o ActivationPolicy) ActivationPolicy {
+	out := ActivationPolicy{
+		MaxMinutes:           p.MaxMinutes,
+		RequireJustification: p.RequireJustification || o.RequireJustification,
+		RequireTicket:        p.RequireTicket || o.RequireTicket,
+		RequireMFA:           p.RequireMFA || o.RequireMFA,
+		RequireApproval:      p.RequireApproval || o.RequireApproval,
+	}
+	if o.MaxMinutes > 0 && (out.MaxMinutes == 0 || o.MaxMinutes < out.MaxMinutes) {
+		out.MaxMinutes = o.MaxMinutes
+	}
+	return out
+}
+
+// EffectiveMaxMinutes returns MaxMinutes, or DefaultMaxMinutes when unknown.
+func (p ActivationPolicy) EffectiveMaxMinutes() int {
+	if p.MaxMinutes > 0 {
+		return p.MaxMinutes
+	}
+	return DefaultMaxMinutes
+}
+
+// Validate reports why an activation request would be rejected by the policy.
+// Returns nil when it satisfies every rule pim can check locally.
+func (p ActivationPolicy) Validate(ar ActivationRequest) error {
+	var errs []error
+	if ar.Minutes <= 0 {
+		errs = append(errs, fmt.Errorf("duration must be positive"))
+	} else if ar.Minutes > p.EffectiveMaxMinutes() {
+		errs = append(errs, fmt.Errorf("duration %s exceeds the policy maximum of %s",
+			FormatDuration(ar.Minutes), FormatDuration(p.EffectiveMaxMinutes())))
+	}
+	if p.RequireJustification && strings.TrimSpace(ar.Justification) == "" {
+		errs = append(errs, fmt.Errorf("policy requires a justification"))
+	}
+	if p.RequireTicket && strings.TrimSpace(ar.Ticket.TicketNumber) == "" {
+		errs = append(errs, fmt.Errorf("policy requires a ticket number (--ticket)"))
+	}
+	return errors.Join(errs...)
+}
+
+// Summary returns a short human-readable description of the policy, e.g.
+// "max 4h · justification · MFA · approval".
+func (p ActivationPolicy) Summary() string {
+	parts := []string{"max " + humanizeMinutes(p.EffectiveMaxMinutes())}
+	if p.RequireJustification {
+		parts = append(parts, "justification")
+	}
+	if p.RequireTicket {
+		parts = append(parts, "ticket")
+	}
+	if p.RequireMFA {
+		parts = append(parts, "MFA")
+	}
+	if p.RequireApproval {
+		parts = append(parts, "approval")
+	}
+	return strings.Join(parts, " · ")
+}
+
+func humanizeMinutes(minutes int) string {
+	h, m := minutes/60, minutes%60
+	switch {
+	case h == 0:
+		return fmt.Sprintf("%dm", m)
+	case m == 0:
+		return fmt.Sprintf("%dh", h)
+	default:
+		return fmt.Sprintf("%dh%dm", h, m)
+	}
+}
+
+// policyRule is the subset of a role management policy rule that pim reads.
+// ARM and Graph share these property names.
+type policyRule struct {
+	ID              string   `json:"id"`
+	MaximumDuration string   `json:"maximumDuration"`
+	EnabledRules    []string `json:"enabledRules"`
+	Setting         *struct {
+		IsApprovalRequired bool `json:"isApprovalRequired"`
+	} `json:"setting"`
+}
+
+// policyFromRules derives an ActivationPolicy from role management policy rules.
+func policyFromRules(rules []policyRule) ActivationPolicy {
+	var p ActivationPolicy
+	for _, r := range rules {
+		switch r.ID {
+		case ruleExpirationEndUser:
+			if mins, err := ParseISODurationMinutes(r.MaximumDuration); err == nil && mins > 0 {
+				p.MaxMinutes = mins
+			}
+		case ruleEnablementEndUser:
+			for _, e := range r.EnabledRules {
+				switch strings.ToLower(e) {
+				case "justification":
+					p.RequireJustification = true
+				case "ticketing":
+					p.RequireTicket = true
+				case "multifactorauthentication":
+					p.RequireMFA = true
+				}
+			}
+		case ruleApprovalEndUser:
+			if r.Setting != nil && r.Setting.IsApprovalRequired {
+				p.RequireApproval = true
+			}
+		}
+	}
+	return p
+}
+
+// GetActivationPolicy fetches the effective activation policy for role at scope.
+// An empty scope uses the role's eligibility scope. Directory roles and group
+// eligibilities are read from Microsoft Graph; Azure resource roles from ARM.
+func (c *Client) GetActivationPolicy(ctx context.Context, role Role, scope string) (ActivationPolicy, error) {
+	switch role.ScopeKind() {
+	case ScopeDirectory:
+		filter := fmt.Sprintf("scopeId eq '%s' and scopeType eq 'DirectoryRole' and roleDefinitionId eq '%s'",
+			DirectoryScopeIDFromScope(role.Scope), role.RoleDefinitionID)
+		return c.getGraphPolicy(ctx, filter)
+	case ScopeGroup:
+		filter := fmt.Sprintf("scopeId eq '%s' and scopeType eq 'Group' and roleDefinitionId eq '%s'",
+			GroupIDFromScope(role.Scope), strings.ToLower(role.RoleDefinitionID))
+		return c.getGraphPolicy(ctx, filter)
+	}
+	if strings.TrimSpace(scope) == "" {
+		scope = role.Scope
+	}
+	return c.getResourcePolicy(ctx, NormalizeScope(scope), role.RoleDefinitionID)
+}
+
+// getResourcePolicy lists the policy assignments at scope and returns the one
+// for the role definition. Role definition IDs are matched on their trailing
+// GUID because the ID prefix differs between a role's eligibility scope and
+// the child scope it is activated at.
+func (c *Client) getResourcePolicy(ctx context.Context, scope, roleDefinitionID string) (ActivationPolicy, error) {
+	tok, err := c.armToken(ctx)
+	if err != nil {
+		return ActivationPolicy{}, err
+	}
+	want := strings.ToLower(path.Base(roleDefinitionID))
+	reqURL := fmt.Sprintf("%s%s/providers/Microsoft.Authorization/roleManagementPolicyAssignments?api-version=%s",
+		c.armURL, scope, apiVersion)
+
+	for reqURL != "" {
+		resp, err := c.doRequest(ctx, http.MethodGet, reqURL, tok, nil)
+		if err != nil {
+			return ActivationPolicy{}, fmt.Errorf("get role management policy: %w", err)
+		}
+
+		var result struct {
+			Value []struct {
+				Properties struct {
+					RoleDefinitionID string       `json:"roleDefinitionId"`
+					EffectiveRules   []policyRule `json:"effectiveRules"`
+				} `json:"properties"`
+			} `json:"value"`
+			NextLink string `json:"nextLink"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			resp.Body.Close()
+			return ActivationPolicy{}, fmt.Errorf("decode role management policy: %w", err)
+		}
+		resp.Body.Close()
+
+		for _, item := range result.Value {
+			if strings.ToLower(path.Base(item.Properties.RoleDefinitionID)) == want {
+				return policyFromRules(item.Properties.EffectiveRules), nil
+			}
+		}
+		reqURL = result.NextLink
+	}
+	return ActivationPolicy{}, fmt.Errorf("no role management policy found for %s at %s", roleDefinitionID, scope)
+}
+
+// getGraphPolicy fetches the Graph role management policy matching filter.
+func (c *Client) getGraphPolicy(ctx context.Context, filter string) (ActivationPolicy, error) {
+	tok, err := c.graphToken(ctx)
+	if err != nil {
+		return ActivationPolicy{}, err
+	}
+	reqURL := c.graphURL + "/policies/roleManagementPolicyAssignments?$filter=" + url.QueryEscape(filter) +
+		"&$expand=" + url.QueryEscape("policy($expand=rules)")
+
+	resp, err := c.doRequest(ctx, http.MethodGet, reqURL, tok, nil)
+	if err != nil {
+		return ActivationPolicy{}, fmt.Errorf("get role management policy: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Value []struct {
+			Policy struct {
+				Rules []policyRule `json:"rules"`
+			} `json:"policy"`
+		} `json:"value"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return ActivationPolicy{}, fmt.Errorf("decode role management policy: %w", err)
+	}
+	if len(result.Value) == 0 {
+		return ActivationPolicy{}, fmt.Errorf("no role management policy found for %s", filter)
+	}
+	return policyFromRules(result.Value[0].Policy.Rules), nil
+}
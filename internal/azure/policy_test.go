@@ -0,0 +1,73 @@
+package azure
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPolicyFromRules(t *testing.T) {
+	const body = `[
+		{"id": "Expiration_EndUser_Assignment", "isExpirationRequired": true, "maximumDuration": "PT4H"},
+		{"id": "Enablement_EndUser_Assignment", "enabledRules": ["Justification", "MultiFactorAuthentication", "Ticketing"]},
+		{"id": "Approval_EndUser_Assignment", "setting": {"isApprovalRequired": true}},
+		{"id": "Expiration_Admin_Eligibility", "maximumDuration": "P365D"}
+	]`
+	var rules []policyRule
+	if err := json.Unmarshal([]byte(body), &rules); err != nil {
+		t.Fatal(err)
+	}
+	got := policyFromRules(rules)
+	want := ActivationPolicy{MaxMinutes: 240, RequireJustification: true, RequireTicket: true, RequireMFA: true, RequireApproval: true}
+	if got != want {
+		t.Errorf("policyFromRules() = %+v; want %+v", got, want)
+	}
+}
+
+func TestActivationPolicyMerge(t *testing.T) {
+	a := ActivationPolicy{MaxMinutes: 480, RequireJustification: true}
+	b := ActivationPolicy{MaxMinutes: 60, RequireMFA: true}
+	got := a.Merge(b)
+	want := ActivationPolicy{MaxMinutes: 60, RequireJustification: true, RequireMFA: true}
+	if got != want {
+		t.Errorf("Merge() = %+v; want %+v", got, want)
+	}
+	if got := (ActivationPolicy{}).Merge(ActivationPolicy{MaxMinutes: 120}); got.MaxMinutes != 120 {
+		t.Errorf("Merge() with unknown max = %d; want 120", got.MaxMinutes)
+	}
+	if got := (ActivationPolicy{MaxMinutes: 120}).Merge(ActivationPolicy{}); got.MaxMinutes != 120 {
+		t.Errorf("Merge() keeping known max = %d; want 120", got.MaxMinutes)
+	}
+}
+
+func TestActivationPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		policy        ActivationPolicy
+		minutes       int
+		justification string
+		ticket        string
+		wantErr       bool
+	}{
+		{"within max", ActivationPolicy{MaxMinutes: 120}, 120, "", "", false},
+		{"over max", ActivationPolicy{MaxMinutes: 60}, 90, "x", "", true},
+		{"unknown max uses default", ActivationPolicy{}, 540, "x", "", true},
+		{"long policy max", ActivationPolicy{MaxMinutes: 720}, 600, "x", "", false},
+		{"justification required", ActivationPolicy{RequireJustification: true}, 60, " ", "", true},
+		{"justification given", ActivationPolicy{RequireJustification: true}, 60, "ticket", "", false},
+		{"ticket required", ActivationPolicy{RequireTicket: true}, 60, "x", "", true},
+		{"ticket given", ActivationPolicy{RequireTicket: true}, 60, "x", "INC-1", false},
+		{"mfa and approval not checked locally", ActivationPolicy{RequireMFA: true, RequireApproval: true}, 60, "", "", false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.policy.Validate(ActivationRequest{
+				Minutes:       tc.minutes,
+				Justification: tc.justification,
+				Ticket:        TicketInfo{TicketNumber: tc.ticket},
+			})
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Validate(%d, %q) err = %v; wantErr %v", tc.minutes, tc.justification, err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,422 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Schedule request statuses that mean the request has not finished yet.
+// ARM and Graph share these values.
+const (
+	StatusPendingApproval             = "PendingApproval"
+	StatusPendingApprovalProvisioning = "PendingApprovalProvisioning"
+	StatusPendingAdminDecision        = "PendingAdminDecision"
+	StatusPendingProvisioning         = "PendingProvisioning"
+	StatusPendingScheduleCreation     = "PendingScheduleCreation"
+	StatusScheduleCreated             = "ScheduleCreated"
+	StatusGranted                     = "Granted"
+)
+
+// IsAwaitingApproval reports whether status means the request waits on an approver.
+func IsAwaitingApproval(status string) bool {
+	switch {
+	case strings.EqualFold(status, StatusPendingApproval),
+		strings.EqualFold(status, StatusPendingApprovalProvisioning),
+		strings.EqualFold(status, StatusPendingAdminDecision):
+		return true
+	}
+	return false
+}
+
+// IsOutstandingStatus reports whether a request with status is still pending
+// approval, pending provisioning, or scheduled to start later.
+func IsOutstandingStatus(status string) bool {
+	if IsAwaitingApproval(status) {
+		return true
+	}
+	switch {
+	case strings.EqualFold(status, StatusPendingProvisioning),
+		strings.EqualFold(status, StatusPendingScheduleCreation),
+		strings.EqualFold(status, StatusScheduleCreated),
+		strings.EqualFold(status, StatusGranted):
+		return true
+	}
+	return false
+}
+
+// AssignmentRequest is one of the caller's role assignment schedule requests.
+// ID is the full ARM resource ID for Azure resource roles and the Graph
+// request ID for directory roles and group eligibilities.
+type AssignmentRequest struct {
+	ID               string `json:"id"`
+	Scope            string `json:"scope"`
+	ScopeDisplay     string `json:"scopeDisplay"`
+	RoleName         string `json:"roleName"`
+	RoleDefinitionID string `json:"roleDefinitionId"`
+	RequestType      string `json:"requestType"`
+	Status           string `json:"status"`
+	Justification    string `json:"justification,omitempty"`
+	CreatedOn        string `json:"createdOn,omitempty"`
+	StartDateTime    string `json:"startDateTime,omitempty"`
+	// Duration is the requested ISO 8601 duration, e.g. "PT8H".
+	Duration   string `json:"duration,omitempty"`
+	ApprovalID string `json:"approvalId,omitempty"`
+	// ApprovedBy names the approvers; set only by GetRequestHistory.
+	ApprovedBy string `json:"approvedBy,omitempty"`
+}
+
+// DurationDisplay returns the requested duration in short form, e.g. "1h 30m",
+// or "" when the request carried none.
+func (r AssignmentRequest) DurationDisplay() string {
+	if r.Duration == "" {
+		return ""
+	}
+	mins, err := ParseISODurationMinutes(r.Duration)
+	if err != nil {
+		return r.Duration
+	}
+	return humanizeDuration(time.Duration(mins) * time.Minute)
+}
+
+// Created returns when the request was created, or the zero time when
+// CreatedOn is missing or malformed.
+func (r AssignmentRequest) Created() time.Time {
+	t, _ := time.Parse(time.RFC3339Nano, r.CreatedOn)
+	return t
+}
+
+// IsScheduled reports whether the request starts in the future.
+func (r AssignmentRequest) IsScheduled() bool {
+	start, err := time.Parse(time.RFC3339, r.StartDateTime)
+	return err == nil && start.After(time.Now())
+}
+
+// StatusDisplay returns a short human-readable status, e.g. "pending approval".
+func (r AssignmentRequest) StatusDisplay() string {
+	switch {
+	case IsAwaitingApproval(r.Status):
+		return "pending approval"
+	case r.IsScheduled():
+		start, _ := time.Parse(time.RFC3339, r.StartDateTime)
+		return "scheduled " + start.Local().Format("2006-01-02 15:04")
+	case strings.EqualFold(r.Status, StatusGranted):
+		return "granted"
+	case strings.HasPrefix(strings.ToLower(r.Status), "pending"), strings.EqualFold(r.Status, StatusScheduleCreated):
+		return "pending provisioning"
+	default:
+		return r.Status
+	}
+}
+
+// GetPendingRequests lists the caller's outstanding activation and extension
+// requests: Azure resource requests from ARM, followed by directory role and
+// group requests from Graph. Graph sources are skipped when access is denied.
+// Results are ordered newest first.
+func (c *Client) GetPendingRequests(ctx context.Context) ([]AssignmentRequest, error) {
+	all, err := c.getAllRequests(ctx, "asRequestor()", time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	var out []AssignmentRequest
+	for _, r := range all {
+		if IsOutstandingStatus(r.Status) {
+			out = append(out, r)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Created().After(out[j].Created()) })
+	return out, nil
+}
+
+// getAllRequests lists ARM requests matching armFilter ("asRequestor()" or
+// "asTarget()"), followed by the caller's directory role and group requests
+// from Graph. A non-zero since limits each source to requests created at or
+// after it. Graph sources are skipped when access is denied.
+func (c *Client) getAllRequests(ctx context.Context, armFilter string, since time.Time) ([]AssignmentRequest, error) {
+	all, err := sinceOrAll(since, func(since time.Time) ([]AssignmentRequest, error) {
+		filter := armFilter
+		if !since.IsZero() {
+			filter += " and createdOn ge '" + since.UTC().Format(time.RFC3339) + "'"
+		}
+		return c.getResourceRequests(ctx, filter)
+	})
+	if err != nil {
+		return nil, err
+	}
+	dirReqs, err := sinceOrAll(since, func(since time.Time) ([]AssignmentRequest, error) {
+		return c.getDirectoryRequests(ctx, graphCreatedFilter(since))
+	})
+	if err != nil && !isAccessDenied(err) {
+		return nil, err
+	}
+	all = append(all, dirReqs...)
+	groupReqs, err := sinceOrAll(since, func(since time.Time) ([]AssignmentRequest, error) {
+		return c.getGroupRequests(ctx, graphCreatedFilter(since))
+	})
+	if err != nil && !isAccessDenied(err) {
+		return nil, err
+	}
+	return append(all, groupReqs...), nil
+}
+
+// sinceOrAll calls list with since, and again with no lower bound when the
+// service rejects the creation-time filter as a bad request; callers filter
+// by creation time themselves.
+func sinceOrAll(since time.Time, list func(since time.Time) ([]AssignmentRequest, error)) ([]AssignmentRequest, error) {
+	out, err := list(since)
+	if err != nil && !since.IsZero() && isBadRequest(err) {
+		return list(time.Time{})
+	}
+	return out, err
+}
+
+// graphCreatedFilter returns the Graph $filter selecting requests created at
+// or after since, or "" when since is zero.
+func graphCreatedFilter(since time.Time) string {
+	if since.IsZero() {
+		return ""
+	}
+	return "createdDateTime ge " + since.UTC().Format(time.RFC3339)
+}
+
+// CancelRequest cancels an outstanding schedule request.
+func (c *Client) CancelRequest(ctx context.Context, req AssignmentRequest) error {
+	var (
+		tok    string
+		err    error
+		reqURL string
+	)
+	switch {
+	case IsDirectoryScope(req.Scope):
+		tok, err = c.graphToken(ctx)
+		reqURL = c.graphURL + "/roleManagement/directory/roleAssignmentScheduleRequests/" + url.PathEscape(req.ID) + "/cancel"
+	case IsGroupScope(req.Scope):
+		tok, err = c.graphToken(ctx)
+		reqURL = c.graphURL + "/identityGovernance/privilegedAccess/group/assignmentScheduleRequests/" + url.PathEscape(req.ID) + "/cancel"
+	default:
+		tok, err = c.armToken(ctx)
+		reqURL = fmt.Sprintf("%s%s/cancel?api-version=%s", c.armURL, req.ID, apiVersion)
+	}
+	if err != nil {
+		return err
+	}
+	resp, err := c.doRequest(ctx, http.MethodPost, reqURL, tok, nil)
+	if err != nil {
+		return fmt.Errorf("cancel request: %w", err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// getResourceRequests lists the ARM role assignment schedule requests matching
+// filter, following pagination.
+func (c *Client) getResourceRequests(ctx context.Context, filter string) ([]AssignmentRequest, error) {
+	tok, err := c.armToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reqURL := fmt.Sprintf("%s/providers/Microsoft.Authorization/roleAssignmentScheduleRequests?api-version=%s&$filter=%s",
+		c.armURL, apiVersion, url.QueryEscape(filter))
+
+	var out []AssignmentRequest
+	for reqURL != "" {
+		resp, err := c.doRequest(ctx, http.MethodGet, reqURL, tok, nil)
+		if err != nil {
+			return nil, fmt.Errorf("get schedule requests: %w", err)
+		}
+
+		var result struct {
+			Value []struct {
+				ID         string `json:"id"`
+				Properties struct {
+					Scope            string `json:"scope"`
+					RoleDefinitionID string `json:"roleDefinitionId"`
+					RequestType      string `json:"requestType"`
+					Status           string `json:"status"`
+					Justification    string `json:"justification"`
+					CreatedOn        string `json:"createdOn"`
+					ApprovalID       string `json:"approvalId"`
+					ScheduleInfo     struct {
+						StartDateTime string `json:"startDateTime"`
+						Expiration    struct {
+							Duration string `json:"duration"`
+						} `json:"expiration"`
+					} `json:"scheduleInfo"`
+					ExpandedProps struct {
+						Scope struct {
+							DisplayName string `json:"displayName"`
+						} `json:"scope"`
+						RoleDefinition struct {
+							DisplayName string `json:"displayName"`
+						} `json:"roleDefinition"`
+					} `json:"expandedProperties"`
+				} `json:"properties"`
+			} `json:"value"`
+			NextLink string `json:"nextLink"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			resp.Body.Close()
+			return nil, fmt.Errorf("decode schedule requests: %w", err)
+		}
+		resp.Body.Close()
+
+		for _, item := range result.Value {
+			p := item.Properties
+			out = append(out, AssignmentRequest{
+				ID:               item.ID,
+				Scope:            p.Scope,
+				ScopeDisplay:     DefaultScopeDisplay(p.Scope, p.ExpandedProps.Scope.DisplayName),
+				RoleName:         p.ExpandedProps.RoleDefinition.DisplayName,
+				RoleDefinitionID: p.RoleDefinitionID,
+				RequestType:      p.RequestType,
+				Status:           p.Status,
+				Justification:    p.Justification,
+				CreatedOn:        p.CreatedOn,
+				StartDateTime:    p.ScheduleInfo.StartDateTime,
+				Duration:         p.ScheduleInfo.Expiration.Duration,
+				ApprovalID:       p.ApprovalID,
+			})
+		}
+		reqURL = result.NextLink
+	}
+	return out, nil
+}
+
+// graphRequestItem holds the fields shared by Graph directory and group
+// schedule requests.
+type graphRequestItem struct {
+	ID              string `json:"id"`
+	Action          string `json:"action"`
+	Status          string `json:"status"`
+	Justification   string `json:"justification"`
+	CreatedDateTime string `json:"createdDateTime"`
+	ApprovalID      string `json:"approvalId"`
+	ScheduleInfo    struct {
+		StartDateTime string `json:"startDateTime"`
+		Expiration    struct {
+			Duration string `json:"duration"`
+		} `json:"expiration"`
+	} `json:"scheduleInfo"`
+}
+
+// getDirectoryRequests lists the caller's Microsoft Entra ID directory role
+// schedule requests matching filter (all when empty), following pagination.
+func (c *Client) getDirectoryRequests(ctx context.Context, filter string) ([]AssignmentRequest, error) {
+	tok, err := c.graphToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reqURL := c.graphURL + "/roleManagement/directory/roleAssignmentScheduleRequests/filterByCurrentUser(on='principal')?$expand=roleDefinition"
+	if filter != "" {
+		reqURL += "&$filter=" + url.QueryEscape(filter)
+	}
+
+	var out []AssignmentRequest
+	for reqURL != "" {
+		resp, err := c.doRequest(ctx, http.MethodGet, reqURL, tok, nil)
+		if err != nil {
+			return nil, fmt.Errorf("get directory schedule requests: %w", err)
+		}
+
+		var result struct {
+			Value []struct {
+				graphRequestItem
+				RoleDefinitionID string `json:"roleDefinitionId"`
+				DirectoryScopeID string `json:"directoryScopeId"`
+				RoleDefinition   struct {
+					DisplayName string `json:"displayName"`
+				} `json:"roleDefinition"`
+			} `json:"value"`
+			NextLink string `json:"@odata.nextLink"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			resp.Body.Close()
+			return nil, fmt.Errorf("decode directory schedule requests: %w", err)
+		}
+		resp.Body.Close()
+
+		for _, item := range result.Value {
+			scope := DirectoryScope(item.DirectoryScopeID)
+			out = append(out, AssignmentRequest{
+				ID:               item.ID,
+				Scope:            scope,
+				ScopeDisplay:     DefaultScopeDisplay(scope, ""),
+				RoleName:         item.RoleDefinition.DisplayName,
+				RoleDefinitionID: item.RoleDefinitionID,
+				RequestType:      item.Action,
+				Status:           item.Status,
+				Justification:    item.Justification,
+				CreatedOn:        item.CreatedDateTime,
+				StartDateTime:    item.ScheduleInfo.StartDateTime,
+				Duration:         item.ScheduleInfo.Expiration.Duration,
+				ApprovalID:       item.ApprovalID,
+			})
+		}
+		reqURL = result.NextLink
+	}
+	return out, nil
+}
+
+// getGroupRequests lists the caller's PIM for Groups assignment schedule
+// requests matching filter (all when empty), following pagination.
+func (c *Client) getGroupRequests(ctx context.Context, filter string) ([]AssignmentRequest, error) {
+	tok, err := c.graphToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reqURL := c.graphURL + "/identityGovernance/privilegedAccess/group/assignmentScheduleRequests/filterByCurrentUser(on='principal')?$expand=group"
+	if filter != "" {
+		reqURL += "&$filter=" + url.QueryEscape(filter)
+	}
+
+	var out []AssignmentRequest
+	for reqURL != "" {
+		resp, err := c.doRequest(ctx, http.MethodGet, reqURL, tok, nil)
+		if err != nil {
+			return nil, fmt.Errorf("get group schedule requests: %w", err)
+		}
+
+		var result struct {
+			Value []struct {
+				graphRequestItem
+				GroupID  string `json:"groupId"`
+				AccessID string `json:"accessId"`
+				Group    struct {
+					DisplayName string `json:"displayName"`
+				} `json:"group"`
+			} `json:"value"`
+			NextLink string `json:"@odata.nextLink"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			resp.Body.Close()
+			return nil, fmt.Errorf("decode group schedule requests: %w", err)
+		}
+		resp.Body.Close()
+
+		for _, item := range result.Value {
+			scope := GroupScope(item.GroupID)
+			out = append(out, AssignmentRequest{
+				ID:               item.ID,
+				Scope:            scope,
+				ScopeDisplay:     DefaultScopeDisplay(scope, item.Group.DisplayName),
+				RoleName:         GroupAccessRoleName(item.AccessID),
+				RoleDefinitionID: strings.ToLower(item.AccessID),
+				RequestType:      item.Action,
+				Status:           item.Status,
+				Justification:    item.Justification,
+				CreatedOn:        item.CreatedDateTime,
+				StartDateTime:    item.ScheduleInfo.StartDateTime,
+				Duration:         item.ScheduleInfo.Expiration.Duration,
+				ApprovalID:       item.ApprovalID,
+			})
+		}
+		reqURL = result.NextLink
+	}
+	return out, nil
+}
@@ -0,0 +1,83 @@
+package azure
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsOutstandingStatus(t *testing.T) {
+	tests := []struct {
+		status      string
+		outstanding bool
+		approval    bool
+	}{
+		{"PendingApproval", true, true},
+		{"pendingapproval", true, true},
+		{"PendingApprovalProvisioning", true, true},
+		{"PendingAdminDecision", true, true},
+		{"PendingProvisioning", true, false},
+		{"ScheduleCreated", true, false},
+		{"Granted", true, false},
+		{"Provisioned", false, false},
+		{"Canceled", false, false},
+		{"Denied", false, false},
+		{"", false, false},
+	}
+	for _, tc := range tests {
+		if got := IsOutstandingStatus(tc.status); got != tc.outstanding {
+			t.Errorf("IsOutstandingStatus(%q) = %v, want %v", tc.status, got, tc.outstanding)
+		}
+		if got := IsAwaitingApproval(tc.status); got != tc.approval {
+			t.Errorf("IsAwaitingApproval(%q) = %v, want %v", tc.status, got, tc.approval)
+		}
+	}
+}
+
+func TestAssignmentRequestStatusDisplay(t *testing.T) {
+	future := time.Now().Add(2 * time.Hour).UTC().Format(time.RFC3339)
+	past := time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339)
+	tests := []struct {
+		name string
+		req  AssignmentRequest
+		want string
+	}{
+		{"approval", AssignmentRequest{Status: StatusPendingApproval, StartDateTime: future}, "pending approval"},
+		{"scheduled", AssignmentRequest{Status: StatusGranted, StartDateTime: future}, "scheduled "},
+		{"granted", AssignmentRequest{Status: StatusGranted, StartDateTime: past}, "granted"},
+		{"provisioning", AssignmentRequest{Status: StatusPendingProvisioning}, "pending provisioning"},
+		{"other", AssignmentRequest{Status: "Accepted"}, "Accepted"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.req.StatusDisplay(); !strings.HasPrefix(got, tc.want) {
+				t.Errorf("StatusDisplay() = %q, want prefix %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestScheduleResponseStatus(t *testing.T) {
+	var nilResp *ScheduleResponse
+	if got := nilResp.Status(); got != "" {
+		t.Errorf("nil Status() = %q, want empty", got)
+	}
+	resp := &ScheduleResponse{}
+	resp.Properties.Status = StatusPendingApproval
+	if got := resp.Status(); got != StatusPendingApproval {
+		t.Errorf("Status() = %q, want %q", got, StatusPendingApproval)
+	}
+}
+
+func TestIsFailedStatus(t *testing.T) {
+	for _, s := range []string{"Denied", "failed", StatusCanceled, StatusRevoked, StatusTimedOut} {
+		if !IsFailedStatus(s) {
+			t.Errorf("IsFailedStatus(%q) = false, want true", s)
+		}
+	}
+	for _, s := range []string{"", StatusProvisioned, StatusGranted, StatusPendingProvisioning, StatusPendingApproval} {
+		if IsFailedStatus(s) {
+			t.Errorf("IsFailedStatus(%q) = true, want false", s)
+		}
+	}
+}
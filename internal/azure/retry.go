@@ -0,0 +1,119 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy controls how failed requests are retried. Delays grow
+// exponentially from BaseDelay, capped at MaxDelay, with random jitter; a
+// Retry-After header takes precedence (still capped at MaxDelay).
+type RetryPolicy struct {
+	MaxRetries int // retries after the first attempt; 0 disables retries
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+// DefaultRetryPolicy retries up to four times, waiting about 1s, 2s, 4s, 8s.
+var DefaultRetryPolicy = RetryPolicy{MaxRetries: 4, BaseDelay: time.Second, MaxDelay: 30 * time.Second}
+
+// normalize fills unset delays from DefaultRetryPolicy and clamps negative
+// values.
+func (p RetryPolicy) normalize() RetryPolicy {
+	p.MaxRetries = max(p.MaxRetries, 0)
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = DefaultRetryPolicy.BaseDelay
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = DefaultRetryPolicy.MaxDelay
+	}
+	p.MaxDelay = max(p.MaxDelay, p.BaseDelay)
+	return p
+}
+
+// backoff returns the jittered delay before retry number attempt (0-based):
+// a random duration between half and all of BaseDelay*2^attempt, capped at
+// MaxDelay.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.MaxDelay
+	if attempt < 30 {
+		d = min(p.BaseDelay<<attempt, p.MaxDelay)
+	}
+	half := d / 2
+	return half + rand.N(half+1)
+}
+
+// retryable reports whether a failed attempt may be retried. Throttling (429)
+// is always retried because the server did not process the request. Server
+// errors (408, 500, 502, 503, 504) and network failures are only retried for
+// idempotent methods; activation PUTs qualify because every request carries a
+// fresh request ID, while Graph POSTs do not.
+func retryable(ctx context.Context, method string, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	if !idempotent(method) {
+		return false
+	}
+	if err != nil {
+		return isTransientNetworkError(err)
+	}
+	switch resp.StatusCode {
+	case http.StatusRequestTimeout, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// isTransientNetworkError reports whether err is a timeout, reset or
+// dropped connection rather than a permanent failure such as a DNS error.
+func isTransientNetworkError(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return true
+	}
+	return strings.Contains(err.Error(), "connection reset")
+}
+
+// retryAfter parses the Retry-After header as delay-seconds or an HTTP date
+// relative to now. ok is false when the header is absent or invalid.
+func retryAfter(resp *http.Response, now time.Time) (d time.Duration, ok bool) {
+	s := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if s == "" {
+		return 0, false
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		if n < 0 {
+			return 0, false
+		}
+		return time.Duration(n) * time.Second, true
+	}
+	if t, err := http.ParseTime(s); err == nil {
+		return max(t.Sub(now), 0), true
+	}
+	return 0, false
+}
@@ -0,0 +1,187 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		header string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"", 0, false},
+		{"7", 7 * time.Second, true},
+		{"0", 0, true},
+		{"-3", 0, false},
+		{now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second, true},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+		{"soon", 0, false},
+	}
+	for _, tc := range tests {
+		resp := &http.Response{Header: http.Header{}}
+		if tc.header != "" {
+			resp.Header.Set("Retry-After", tc.header)
+		}
+		got, ok := retryAfter(resp, now)
+		if got != tc.want || ok != tc.wantOK {
+			t.Errorf("retryAfter(%q) = %v, %v; want %v, %v", tc.header, got, ok, tc.want, tc.wantOK)
+		}
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{MaxRetries: 5, BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	for attempt, ceiling := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		for range 20 {
+			d := p.backoff(attempt)
+			if d < ceiling/2 || d > ceiling {
+				t.Fatalf("backoff(%d) = %v, want within [%v, %v]", attempt, d, ceiling/2, ceiling)
+			}
+		}
+	}
+}
+
+func TestRetryPolicyNormalize(t *testing.T) {
+	got := RetryPolicy{MaxRetries: -1, MaxDelay: time.Millisecond}.normalize()
+	want := RetryPolicy{MaxRetries: 0, BaseDelay: time.Second, MaxDelay: time.Second}
+	if got != want {
+		t.Errorf("normalize() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	ctx := context.Background()
+	status := func(code int) *http.Response { return &http.Response{StatusCode: code} }
+	tests := []struct {
+		name   string
+		method string
+		resp   *http.Response
+		err    error
+		want   bool
+	}{
+		{"429 get", http.MethodGet, status(429), nil, true},
+		{"429 post", http.MethodPost, status(429), nil, true},
+		{"503 put", http.MethodPut, status(503), nil, true},
+		{"502 get", http.MethodGet, status(502), nil, true},
+		{"503 post", http.MethodPost, status(503), nil, false},
+		{"400 put", http.MethodPut, status(400), nil, false},
+		{"403 get", http.MethodGet, status(403), nil, false},
+		{"reset get", http.MethodGet, nil, errors.New("read tcp: connection reset by peer"), true},
+		{"eof put", http.MethodPut, nil, io.ErrUnexpectedEOF, true},
+		{"dns get", http.MethodGet, nil, errors.New("dial tcp: lookup example.invalid: no such host"), false},
+		{"reset post", http.MethodPost, nil, io.ErrUnexpectedEOF, false},
+	}
+	for _, tc := range tests {
+		if got := retryable(ctx, tc.method, tc.resp, tc.err); got != tc.want {
+			t.Errorf("%s: retryable() = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if retryable(cancelled, http.MethodGet, status(503), nil) {
+		t.Error("retryable() = true after context cancellation")
+	}
+}
+
+func testRetryClient(srv *httptest.Server) *Client {
+	return &Client{
+		httpClient: srv.Client(),
+		retry:      RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond},
+	}
+}
+
+func TestDoRequestReplaysBody(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"a":1}` {
+			t.Errorf("attempt %d body = %q, want replayed body", calls.Load()+1, body)
+		}
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	resp, err := testRetryClient(srv).doRequest(context.Background(), http.MethodPut, srv.URL, "tok", []byte(`{"a":1}`))
+	if err != nil {
+		t.Fatalf("doRequest() error: %v", err)
+	}
+	resp.Body.Close()
+	if n := calls.Load(); n != 3 {
+		t.Errorf("server called %d times, want 3", n)
+	}
+}
+
+func TestDoRequestDoesNotRetryPostOnServerError(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	_, err := testRetryClient(srv).doRequest(context.Background(), http.MethodPost, srv.URL, "tok", []byte(`{}`))
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway {
+		t.Fatalf("error = %v, want 502 APIError", err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("server called %d times, want 1", n)
+	}
+}
+
+func TestDoRequestRetriesDroppedConnection(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				conn.Close()
+			}
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	resp, err := testRetryClient(srv).doRequest(context.Background(), http.MethodGet, srv.URL, "tok", nil)
+	if err != nil {
+		t.Fatalf("doRequest() error: %v", err)
+	}
+	resp.Body.Close()
+	if n := calls.Load(); n != 2 {
+		t.Errorf("server called %d times, want 2", n)
+	}
+}
+
+func TestDoRequestGivesUpAfterMaxRetries(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	_, err := testRetryClient(srv).doRequest(context.Background(), http.MethodGet, srv.URL, "tok", nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("error = %v, want 429 APIError", err)
+	}
+	if n := calls.Load(); n != 4 {
+		t.Errorf("server called %d times, want 4 (1 + 3 retries)", n)
+	}
+}
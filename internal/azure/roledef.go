@@ -0,0 +1,232 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// roleDefinitionAPIVersion is the ARM API version serving roleDefinitions.
+const roleDefinitionAPIVersion = "2022-04-01"
+
+// RoleDefinition is what a role grants. Directory role permissions are
+// mapped onto Actions (allowedResourceActions) and NotActions
+// (excludedResourceActions); they have no data actions.
+type RoleDefinition struct {
+	ID             string   `json:"id"`
+	Name           string   `json:"roleName"`
+	Description    string   `json:"description,omitempty"`
+	Custom         bool     `json:"custom"`
+	Actions        []string `json:"actions"`
+	NotActions     []string `json:"notActions"`
+	DataActions    []string `json:"dataActions"`
+	NotDataActions []string `json:"notDataActions"`
+}
+
+// TypeDisplay returns "custom" or "built-in".
+func (d RoleDefinition) TypeDisplay() string {
+	if d.Custom {
+		return "custom"
+	}
+	return "built-in"
+}
+
+// Filter returns a copy of d keeping only the permissions that contain
+// substr (case-insensitive). An empty substr returns d unchanged.
+func (d RoleDefinition) Filter(substr string) RoleDefinition {
+	lower := strings.ToLower(strings.TrimSpace(substr))
+	if lower == "" {
+		return d
+	}
+	keep := func(perms []string) []string {
+		var out []string
+		for _, p := range perms {
+			if strings.Contains(strings.ToLower(p), lower) {
+				out = append(out, p)
+			}
+		}
+		return out
+	}
+	d.Actions = keep(d.Actions)
+	d.NotActions = keep(d.NotActions)
+	d.DataActions = keep(d.DataActions)
+	d.NotDataActions = keep(d.NotDataActions)
+	return d
+}
+
+// armRoleDefinition is the ARM roleDefinitions resource.
+type armRoleDefinition struct {
+	ID         string `json:"id"`
+	Properties struct {
+		RoleName    string `json:"roleName"`
+		Description string `json:"description"`
+		Type        string `json:"type"`
+		Permissions []struct {
+			Actions        []string `json:"actions"`
+			NotActions     []string `json:"notActions"`
+			DataActions    []string `json:"dataActions"`
+			NotDataActions []string `json:"notDataActions"`
+		} `json:"permissions"`
+	} `json:"properties"`
+}
+
+func (a armRoleDefinition) definition() *RoleDefinition {
+	p := a.Properties
+	d := &RoleDefinition{
+		ID:          a.ID,
+		Name:        p.RoleName,
+		Description: p.Description,
+		Custom:      strings.EqualFold(p.Type, "CustomRole"),
+	}
+	for _, perm := range p.Permissions {
+		d.Actions = append(d.Actions, perm.Actions...)
+		d.NotActions = append(d.NotActions, perm.NotActions...)
+		d.DataActions = append(d.DataActions, perm.DataActions...)
+		d.NotDataActions = append(d.NotDataActions, perm.NotDataActions...)
+	}
+	return d
+}
+
+// graphRoleDefinition is the Graph unifiedRoleDefinition resource.
+type graphRoleDefinition struct {
+	ID              string `json:"id"`
+	DisplayName     string `json:"displayName"`
+	Description     string `json:"description"`
+	IsBuiltIn       bool   `json:"isBuiltIn"`
+	RolePermissions []struct {
+		AllowedResourceActions  []string `json:"allowedResourceActions"`
+		ExcludedResourceActions []string `json:"excludedResourceActions"`
+	} `json:"rolePermissions"`
+}
+
+func (g graphRoleDefinition) definition() *RoleDefinition {
+	d := &RoleDefinition{
+		ID:          g.ID,
+		Name:        g.DisplayName,
+		Description: g.Description,
+		Custom:      !g.IsBuiltIn,
+	}
+	for _, perm := range g.RolePermissions {
+		d.Actions = append(d.Actions, perm.AllowedResourceActions...)
+		d.NotActions = append(d.NotActions, perm.ExcludedResourceActions...)
+	}
+	return d
+}
+
+// GetRoleDefinition fetches the definition of the role roleDefinitionID
+// eligible or assigned at scope: from ARM for Azure resource roles and from
+// Graph for directory roles. PIM for Groups access has no role definition;
+// a description of the membership or ownership is returned instead.
+func (c *Client) GetRoleDefinition(ctx context.Context, scope, roleDefinitionID string) (*RoleDefinition, error) {
+	switch {
+	case IsGroupScope(scope):
+		return groupAccessDefinition(roleDefinitionID), nil
+	case IsDirectoryScope(scope):
+		var def graphRoleDefinition
+		reqURL := c.graphURL + "/roleManagement/directory/roleDefinitions/" + url.PathEscape(roleDefinitionID)
+		if err := c.getGraphJSON(ctx, reqURL, &def); err != nil {
+			return nil, fmt.Errorf("get directory role definition: %w", err)
+		}
+		return def.definition(), nil
+	}
+
+	tok, err := c.armToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	id := roleDefinitionID
+	if !strings.HasPrefix(id, "/") {
+		id = "/providers/Microsoft.Authorization/roleDefinitions/" + url.PathEscape(id)
+	}
+	resp, err := c.doRequest(ctx, http.MethodGet, fmt.Sprintf("%s%s?api-version=%s", c.armURL, id, roleDefinitionAPIVersion), tok, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get role definition: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var def armRoleDefinition
+	if err := json.NewDecoder(resp.Body).Decode(&def); err != nil {
+		return nil, fmt.Errorf("decode role definition: %w", err)
+	}
+	return def.definition(), nil
+}
+
+// GetRoleDefinitionByName looks a role up by exact name among the Azure
+// roles available at scope, then among directory roles (skipped when access
+// is denied). At an Azure resource scope (a management group, subscription
+// or below) ARM returns built-in roles and the custom roles assignable
+// there; with an empty scope it returns built-in roles only. Use it for roles
+// the caller is not eligible for.
+func (c *Client) GetRoleDefinitionByName(ctx context.Context, scope, name string) (*RoleDefinition, error) {
+	filter := "roleName eq '" + strings.ReplaceAll(name, "'", "''") + "'"
+	tok, err := c.armToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if IsDirectoryScope(scope) || IsGroupScope(scope) {
+		scope = ""
+	}
+	reqURL := fmt.Sprintf("%s%s/providers/Microsoft.Authorization/roleDefinitions?api-version=%s&$filter=%s",
+		c.armURL, strings.TrimSuffix(NormalizeScope(scope), "/"), roleDefinitionAPIVersion, url.QueryEscape(filter))
+	resp, err := c.doRequest(ctx, http.MethodGet, reqURL, tok, nil)
+	if err != nil {
+		return nil, fmt.Errorf("list role definitions: %w", err)
+	}
+	var arm struct {
+		Value []armRoleDefinition `json:"value"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&arm)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("decode role definitions: %w", err)
+	}
+	if len(arm.Value) > 0 {
+		return arm.Value[0].definition(), nil
+	}
+
+	var graph struct {
+		Value []graphRoleDefinition `json:"value"`
+	}
+	reqURL = c.graphURL + "/roleManagement/directory/roleDefinitions?$filter=" +
+		url.QueryEscape("displayName eq '"+strings.ReplaceAll(name, "'", "''")+"'")
+	if err := c.getGraphJSON(ctx, reqURL, &graph); err != nil && !isAccessDenied(err) {
+		return nil, fmt.Errorf("list directory role definitions: %w", err)
+	}
+	if len(graph.Value) > 0 {
+		return graph.Value[0].definition(), nil
+	}
+	return nil, fmt.Errorf("role definition %q not found", name)
+}
+
+// getGraphJSON issues a Graph GET and decodes the response into v.
+func (c *Client) getGraphJSON(ctx context.Context, reqURL string, v any) error {
+	tok, err := c.graphToken(ctx)
+	if err != nil {
+		return err
+	}
+	resp, err := c.doRequest(ctx, http.MethodGet, reqURL, tok, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+// groupAccessDefinition describes PIM for Groups access, which grants
+// whatever the group itself is assigned rather than a fixed permission set.
+func groupAccessDefinition(accessID string) *RoleDefinition {
+	d := &RoleDefinition{ID: accessID, Name: GroupAccessRoleName(accessID)}
+	switch strings.ToLower(accessID) {
+	case GroupAccessOwner:
+		d.Description = "Owner of the group: manage its members and settings. Permissions come from the group's own role assignments."
+	default:
+		d.Description = "Member of the group: inherits the roles and access assigned to the group."
+	}
+	return d
+}
@@ -0,0 +1,128 @@
+package azure
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGetRoleDefinition(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/v1.0/roleManagement/directory/roleDefinitions/"):
+			_, _ = w.Write([]byte(`{"id":"dir-1","displayName":"Groups Administrator","isBuiltIn":true,
+				"rolePermissions":[{"allowedResourceActions":["microsoft.directory/groups/create"],"excludedResourceActions":[]}]}`))
+		case strings.HasSuffix(r.URL.Path, "/roleDefinitions/rd-1"):
+			_, _ = w.Write([]byte(`{"id":"/providers/Microsoft.Authorization/roleDefinitions/rd-1","properties":{
+				"roleName":"App Operator","description":"Restarts apps","type":"CustomRole",
+				"permissions":[{"actions":["Microsoft.Web/sites/restart/action","Microsoft.Web/sites/read"],"notActions":[],
+				"dataActions":["Microsoft.KeyVault/vaults/secrets/getSecret/action"],"notDataActions":[]}]}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c := testRetryClient(srv)
+	c.cred = &claimsCred{}
+	c.armURL = srv.URL
+	c.graphURL = srv.URL + "/v1.0"
+
+	def, err := c.GetRoleDefinition(t.Context(), "/subscriptions/sub-1", "/subscriptions/sub-1/providers/Microsoft.Authorization/roleDefinitions/rd-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if def.Name != "App Operator" || !def.Custom || def.TypeDisplay() != "custom" {
+		t.Errorf("arm definition = %+v", def)
+	}
+	if len(def.Actions) != 2 || len(def.DataActions) != 1 {
+		t.Errorf("arm permissions = %v / %v", def.Actions, def.DataActions)
+	}
+	filtered := def.Filter("RESTART")
+	if len(filtered.Actions) != 1 || len(filtered.DataActions) != 0 {
+		t.Errorf("Filter(RESTART) = %v / %v", filtered.Actions, filtered.DataActions)
+	}
+	if got := def.Filter(" "); len(got.Actions) != 2 {
+		t.Errorf("Filter(blank) dropped permissions: %v", got.Actions)
+	}
+
+	dir, err := c.GetRoleDefinition(t.Context(), DirectoryScope("/"), "dir-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dir.Name != "Groups Administrator" || dir.Custom || len(dir.Actions) != 1 {
+		t.Errorf("directory definition = %+v", dir)
+	}
+
+	grp, err := c.GetRoleDefinition(t.Context(), GroupScope("g-1"), GroupAccessOwner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if grp.Description == "" {
+		t.Error("group access definition has no description")
+	}
+}
+
+func TestGetRoleDefinitionByName(t *testing.T) {
+	var armFilter, armPath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/v1.0/"):
+			if strings.Contains(r.URL.Query().Get("$filter"), "Global Reader") {
+				_, _ = w.Write([]byte(`{"value":[{"id":"dir-2","displayName":"Global Reader","isBuiltIn":true}]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"value":[]}`))
+		default:
+			armFilter, armPath = r.URL.Query().Get("$filter"), r.URL.Path
+			if strings.Contains(armFilter, "Deployer") && strings.HasPrefix(armPath, "/subscriptions/sub-1/") {
+				_, _ = w.Write([]byte(`{"value":[{"id":"rd-c","properties":{"roleName":"Deployer","type":"CustomRole"}}]}`))
+				return
+			}
+			if strings.Contains(armFilter, "Reader") && !strings.Contains(armFilter, "Global") {
+				_, _ = w.Write([]byte(`{"value":[{"id":"rd-r","properties":{"roleName":"Reader","type":"BuiltInRole","permissions":[{"actions":["*/read"]}]}}]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"value":[]}`))
+		}
+	}))
+	defer srv.Close()
+
+	c := testRetryClient(srv)
+	c.cred = &claimsCred{}
+	c.armURL = srv.URL
+	c.graphURL = srv.URL + "/v1.0"
+
+	def, err := c.GetRoleDefinitionByName(t.Context(), "", "Reader")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if def.Name != "Reader" || armFilter != "roleName eq 'Reader'" || armPath != "/providers/Microsoft.Authorization/roleDefinitions" {
+		t.Errorf("got %q with filter %q at %s", def.Name, armFilter, armPath)
+	}
+
+	// Custom roles are listed only at a scope they are assignable at.
+	if _, err := c.GetRoleDefinitionByName(t.Context(), "", "Deployer"); err == nil {
+		t.Error("custom role found without a scope")
+	}
+	def, err = c.GetRoleDefinitionByName(t.Context(), "/Subscriptions/sub-1", "Deployer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if def.ID != "rd-c" || armPath != "/subscriptions/sub-1/providers/Microsoft.Authorization/roleDefinitions" {
+		t.Errorf("got %q at %s", def.ID, armPath)
+	}
+
+	def, err = c.GetRoleDefinitionByName(t.Context(), "/", "Global Reader")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if def.ID != "dir-2" {
+		t.Errorf("directory fallback ID = %q, want dir-2", def.ID)
+	}
+
+	if _, err := c.GetRoleDefinitionByName(t.Context(), "", "Nope"); err == nil {
+		t.Error("expected not found error")
+	}
+}
@@ -17,7 +17,7 @@ func (c *Client) GetCurrentUser(ctx context.Context) (*User, error) {
 	if err != nil {
 		return nil, err
 	}
-	resp, err := c.doRequest(ctx, http.MethodGet, graphEndpoint+"/me", tok, nil)
+	resp, err := c.doRequest(ctx, http.MethodGet, c.graphURL+"/me", tok, nil)
 	if err != nil {
 		return nil, fmt.Errorf("get current user: %w", err)
 	}
@@ -30,14 +30,43 @@ func (c *Client) GetCurrentUser(ctx context.Context) (*User, error) {
 	return &u, nil
 }
 
-// GetEligibleRoles fetches all eligible PIM roles for the current user, following pagination.
+// GetEligibleRoles fetches all eligible PIM roles for the current user: Azure
+// resource roles from ARM, Microsoft Entra ID directory roles, and PIM for
+// Groups eligibilities from Graph. Graph sources are skipped when access is
+// denied, so tenants without directory or group PIM still list their
+// resource roles.
 func (c *Client) GetEligibleRoles(ctx context.Context) ([]Role, error) {
+	roles, err := c.getEligibleResourceRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dirRoles, err := c.getEligibleDirectoryRoles(ctx)
+	if err != nil && !isAccessDenied(err) {
+		return nil, err
+	}
+	roles = append(roles, dirRoles...)
+	groupRoles, err := c.getEligibleGroupRoles(ctx)
+	if err != nil && !isAccessDenied(err) {
+		return nil, err
+	}
+	roles = append(roles, groupRoles...)
+	sort.Slice(roles, func(i, j int) bool {
+		if roles[i].Scope != roles[j].Scope {
+			return roles[i].Scope < roles[j].Scope
+		}
+		return roles[i].RoleName < roles[j].RoleName
+	})
+	return roles, nil
+}
+
+// getEligibleResourceRoles fetches eligible Azure resource roles from ARM, following pagination.
+func (c *Client) getEligibleResourceRoles(ctx context.Context) ([]Role, error) {
 	tok, err := c.armToken(ctx)
 	if err != nil {
 		return nil, err
 	}
 	reqURL := fmt.Sprintf("%s/providers/Microsoft.Authorization/roleEligibilitySchedules?api-version=%s&$filter=asTarget()",
-		armEndpoint, apiVersion)
+		c.armURL, apiVersion)
 
 	var roles []Role
 	for reqURL != "" {
@@ -52,6 +81,9 @@ func (c *Client) GetEligibleRoles(ctx context.Context) ([]Role, error) {
 				Properties struct {
 					Scope            string `json:"scope"`
 					RoleDefinitionID string `json:"roleDefinitionId"`
+					MemberType       string `json:"memberType"`
+					StartDateTime    string `json:"startDateTime"`
+					EndDateTime      string `json:"endDateTime"`
 					ExpandedProps    struct {
 						Scope struct {
 							DisplayName string `json:"displayName"`
@@ -71,33 +103,51 @@ func (c *Client) GetEligibleRoles(ctx context.Context) ([]Role, error) {
 		resp.Body.Close()
 
 		for _, item := range result.Value {
+			p := item.Properties
 			roles = append(roles, Role{
-				Scope:                 item.Properties.Scope,
-				ScopeDisplay:          DefaultScopeDisplay(item.Properties.Scope, item.Properties.ExpandedProps.Scope.DisplayName),
-				RoleName:              item.Properties.ExpandedProps.RoleDefinition.DisplayName,
-				RoleDefinitionID:      item.Properties.RoleDefinitionID,
+				Scope:                 p.Scope,
+				ScopeDisplay:          DefaultScopeDisplay(p.Scope, p.ExpandedProps.Scope.DisplayName),
+				RoleName:              p.ExpandedProps.RoleDefinition.DisplayName,
+				RoleDefinitionID:      p.RoleDefinitionID,
 				EligibilityScheduleID: item.ID,
+				EligibilityStart:      p.StartDateTime,
+				EligibilityEnd:        p.EndDateTime,
+				MemberType:            p.MemberType,
 			})
 		}
 		reqURL = result.NextLink
 	}
-	sort.Slice(roles, func(i, j int) bool {
-		if roles[i].Scope != roles[j].Scope {
-			return roles[i].Scope < roles[j].Scope
-		}
-		return roles[i].RoleName < roles[j].RoleName
-	})
 	return roles, nil
 }
 
-// GetActiveAssignments fetches all active PIM assignments for the calling user, following pagination.
+// GetActiveAssignments fetches all active PIM assignments for the calling user:
+// Azure resource assignments followed by Microsoft Entra ID directory and PIM
+// for Groups assignments. Graph sources are skipped when access is denied.
 func (c *Client) GetActiveAssignments(ctx context.Context) ([]ActiveAssignment, error) {
+	out, err := c.getActiveResourceAssignments(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := c.getActiveDirectoryAssignments(ctx)
+	if err != nil && !isAccessDenied(err) {
+		return nil, err
+	}
+	out = append(out, dir...)
+	groups, err := c.getActiveGroupAssignments(ctx)
+	if err != nil && !isAccessDenied(err) {
+		return nil, err
+	}
+	return append(out, groups...), nil
+}
+
+// getActiveResourceAssignments fetches active Azure resource assignments from ARM, following pagination.
+func (c *Client) getActiveResourceAssignments(ctx context.Context) ([]ActiveAssignment, error) {
 	tok, err := c.armToken(ctx)
 	if err != nil {
 		return nil, err
 	}
 	reqURL := fmt.Sprintf("%s/providers/Microsoft.Authorization/roleAssignmentScheduleInstances?api-version=%s&$filter=asTarget()",
-		armEndpoint, apiVersion)
+		c.armURL, apiVersion)
 
 	var out []ActiveAssignment
 	for reqURL != "" {
@@ -140,6 +190,7 @@ func (c *Client) GetActiveAssignments(ctx context.Context) ([]ActiveAssignment,
 				ScopeDisplay:     DefaultScopeDisplay(p.Scope, p.ExpandedProps.Scope.DisplayName),
 				RoleName:         p.ExpandedProps.RoleDefinition.DisplayName,
 				RoleDefinitionID: p.RoleDefinitionID,
+				StartDateTime:    p.StartDateTime,
 				EndDateTime:      p.EndDateTime,
 				MemberType:       p.MemberType,
 			})
@@ -151,6 +202,12 @@ func (c *Client) GetActiveAssignments(ctx context.Context) ([]ActiveAssignment,
 
 // IsRoleActive checks if the given role is currently active at its scope.
 func (c *Client) IsRoleActive(ctx context.Context, role Role, principalID string) (bool, error) {
+	switch role.ScopeKind() {
+	case ScopeDirectory:
+		return c.isDirectoryRoleActive(ctx, role.Scope, role.RoleDefinitionID)
+	case ScopeGroup:
+		return c.isGroupAccessActive(ctx, role.Scope, role.RoleDefinitionID)
+	}
 	return c.isRoleActiveAt(ctx, role.Scope, role.RoleDefinitionID, principalID)
 }
 
@@ -161,7 +218,7 @@ func (c *Client) isRoleActiveAt(ctx context.Context, scope, roleDefinitionID, pr
 	}
 	filter := fmt.Sprintf("principalId eq '%s' and roleDefinitionId eq '%s'", principalID, roleDefinitionID)
 	reqURL := fmt.Sprintf("%s%s/providers/Microsoft.Authorization/roleAssignmentSchedules?api-version=%s&$filter=%s",
-		armEndpoint, scope, apiVersion, url.QueryEscape(filter))
+		c.armURL, scope, apiVersion, url.QueryEscape(filter))
 
 	resp, err := c.doRequest(ctx, http.MethodGet, reqURL, tok, nil)
 	if err != nil {
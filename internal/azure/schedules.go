@@ -0,0 +1,215 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// GetScheduledAssignments lists the caller's assignments that are scheduled to
+// start in the future: Azure resource roles from ARM, followed by directory
+// roles and group access from Graph. Graph sources are skipped when access is
+// denied. Every returned assignment reports IsScheduled.
+func (c *Client) GetScheduledAssignments(ctx context.Context) ([]ActiveAssignment, error) {
+	all, err := c.getResourceSchedules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := c.getDirectorySchedules(ctx)
+	if err != nil && !isAccessDenied(err) {
+		return nil, err
+	}
+	all = append(all, dir...)
+	groups, err := c.getGroupSchedules(ctx)
+	if err != nil && !isAccessDenied(err) {
+		return nil, err
+	}
+	all = append(all, groups...)
+
+	var out []ActiveAssignment
+	for _, a := range all {
+		if a.IsScheduled() {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+// getResourceSchedules lists the caller's ARM role assignment schedules,
+// following pagination.
+func (c *Client) getResourceSchedules(ctx context.Context) ([]ActiveAssignment, error) {
+	tok, err := c.armToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reqURL := fmt.Sprintf("%s/providers/Microsoft.Authorization/roleAssignmentSchedules?api-version=%s&$filter=asTarget()",
+		c.armURL, apiVersion)
+
+	var out []ActiveAssignment
+	for reqURL != "" {
+		resp, err := c.doRequest(ctx, http.MethodGet, reqURL, tok, nil)
+		if err != nil {
+			return nil, fmt.Errorf("get assignment schedules: %w", err)
+		}
+
+		var result struct {
+			Value []struct {
+				ID         string `json:"id"`
+				Properties struct {
+					Scope            string `json:"scope"`
+					RoleDefinitionID string `json:"roleDefinitionId"`
+					MemberType       string `json:"memberType"`
+					StartDateTime    string `json:"startDateTime"`
+					EndDateTime      string `json:"endDateTime"`
+					ExpandedProps    struct {
+						Scope struct {
+							DisplayName string `json:"displayName"`
+						} `json:"scope"`
+						RoleDefinition struct {
+							DisplayName string `json:"displayName"`
+						} `json:"roleDefinition"`
+					} `json:"expandedProperties"`
+				} `json:"properties"`
+			} `json:"value"`
+			NextLink string `json:"nextLink"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			resp.Body.Close()
+			return nil, fmt.Errorf("decode assignment schedules: %w", err)
+		}
+		resp.Body.Close()
+
+		for _, item := range result.Value {
+			p := item.Properties
+			out = append(out, ActiveAssignment{
+				Name:             item.ID,
+				Scope:            p.Scope,
+				ScopeDisplay:     DefaultScopeDisplay(p.Scope, p.ExpandedProps.Scope.DisplayName),
+				RoleName:         p.ExpandedProps.RoleDefinition.DisplayName,
+				RoleDefinitionID: p.RoleDefinitionID,
+				StartDateTime:    p.StartDateTime,
+				EndDateTime:      p.EndDateTime,
+				MemberType:       p.MemberType,
+			})
+		}
+		reqURL = result.NextLink
+	}
+	return out, nil
+}
+
+// graphScheduleItem holds the fields shared by Graph directory and group
+// assignment schedules.
+type graphScheduleItem struct {
+	ID           string `json:"id"`
+	MemberType   string `json:"memberType"`
+	ScheduleInfo struct {
+		StartDateTime string `json:"startDateTime"`
+		Expiration    struct {
+			EndDateTime string `json:"endDateTime"`
+		} `json:"expiration"`
+	} `json:"scheduleInfo"`
+}
+
+// getDirectorySchedules lists the caller's Microsoft Entra ID directory role
+// assignment schedules, following pagination.
+func (c *Client) getDirectorySchedules(ctx context.Context) ([]ActiveAssignment, error) {
+	tok, err := c.graphToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reqURL := c.graphURL + "/roleManagement/directory/roleAssignmentSchedules/filterByCurrentUser(on='principal')?$expand=roleDefinition"
+
+	var out []ActiveAssignment
+	for reqURL != "" {
+		resp, err := c.doRequest(ctx, http.MethodGet, reqURL, tok, nil)
+		if err != nil {
+			return nil, fmt.Errorf("get directory assignment schedules: %w", err)
+		}
+
+		var result struct {
+			Value []struct {
+				graphScheduleItem
+				RoleDefinitionID string `json:"roleDefinitionId"`
+				DirectoryScopeID string `json:"directoryScopeId"`
+				RoleDefinition   struct {
+					DisplayName string `json:"displayName"`
+				} `json:"roleDefinition"`
+			} `json:"value"`
+			NextLink string `json:"@odata.nextLink"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			resp.Body.Close()
+			return nil, fmt.Errorf("decode directory assignment schedules: %w", err)
+		}
+		resp.Body.Close()
+
+		for _, item := range result.Value {
+			scope := DirectoryScope(item.DirectoryScopeID)
+			out = append(out, ActiveAssignment{
+				Name:             item.ID,
+				Scope:            scope,
+				ScopeDisplay:     DefaultScopeDisplay(scope, ""),
+				RoleName:         item.RoleDefinition.DisplayName,
+				RoleDefinitionID: item.RoleDefinitionID,
+				StartDateTime:    item.ScheduleInfo.StartDateTime,
+				EndDateTime:      item.ScheduleInfo.Expiration.EndDateTime,
+				MemberType:       item.MemberType,
+			})
+		}
+		reqURL = result.NextLink
+	}
+	return out, nil
+}
+
+// getGroupSchedules lists the caller's PIM for Groups assignment schedules,
+// following pagination.
+func (c *Client) getGroupSchedules(ctx context.Context) ([]ActiveAssignment, error) {
+	tok, err := c.graphToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reqURL := c.graphURL + "/identityGovernance/privilegedAccess/group/assignmentSchedules/filterByCurrentUser(on='principal')?$expand=group"
+
+	var out []ActiveAssignment
+	for reqURL != "" {
+		resp, err := c.doRequest(ctx, http.MethodGet, reqURL, tok, nil)
+		if err != nil {
+			return nil, fmt.Errorf("get group assignment schedules: %w", err)
+		}
+
+		var result struct {
+			Value []struct {
+				graphScheduleItem
+				GroupID  string `json:"groupId"`
+				AccessID string `json:"accessId"`
+				Group    struct {
+					DisplayName string `json:"displayName"`
+				} `json:"group"`
+			} `json:"value"`
+			NextLink string `json:"@odata.nextLink"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			resp.Body.Close()
+			return nil, fmt.Errorf("decode group assignment schedules: %w", err)
+		}
+		resp.Body.Close()
+
+		for _, item := range result.Value {
+			scope := GroupScope(item.GroupID)
+			out = append(out, ActiveAssignment{
+				Name:             item.ID,
+				Scope:            scope,
+				ScopeDisplay:     DefaultScopeDisplay(scope, item.Group.DisplayName),
+				RoleName:         GroupAccessRoleName(item.AccessID),
+				RoleDefinitionID: strings.ToLower(item.AccessID),
+				StartDateTime:    item.ScheduleInfo.StartDateTime,
+				EndDateTime:      item.ScheduleInfo.Expiration.EndDateTime,
+				MemberType:       item.MemberType,
+			})
+		}
+		reqURL = result.NextLink
+	}
+	return out, nil
+}
@@ -16,9 +16,110 @@ func IsSubscriptionScope(scope string) bool {
 	return strings.HasPrefix(lower, "/subscriptions/") && !strings.Contains(lower, "/resourcegroups/")
 }
 
-// IsResourceGroupScope reports whether the scope is a resource group.
+// IsResourceGroupScope reports whether the scope is a resource group (not a
+// resource inside one).
 func IsResourceGroupScope(scope string) bool {
-	return strings.Contains(strings.ToLower(scope), "/resourcegroups/")
+	return strings.Contains(strings.ToLower(scope), "/resourcegroups/") && !IsResourceScope(scope)
+}
+
+// IsResourceScope reports whether the scope is an individual resource below a
+// resource group, e.g.
+// /subscriptions/{sub}/resourceGroups/{rg}/providers/Microsoft.KeyVault/vaults/{name}.
+func IsResourceScope(scope string) bool {
+	lower := strings.ToLower(strings.TrimRight(scope, "/"))
+	idx := strings.Index(lower, "/resourcegroups/")
+	if !strings.HasPrefix(lower, "/subscriptions/") || idx == -1 {
+		return false
+	}
+	rest := lower[idx+len("/resourcegroups/"):]
+	slash := strings.Index(rest, "/providers/")
+	if slash <= 0 {
+		return false
+	}
+	// providers/{namespace}/{type}/{name} needs at least three segments.
+	return strings.Count(rest[slash+len("/providers/"):], "/") >= 2
+}
+
+// ResourceFromScope extracts the resource type (e.g. "Microsoft.KeyVault/vaults")
+// and name from a resource scope path. Nested resources return the full type
+// chain and the last name. Returns "", "" when the scope is not a resource scope.
+func ResourceFromScope(scope string) (resourceType, name string) {
+	if !IsResourceScope(scope) {
+		return "", ""
+	}
+	trimmed := strings.TrimRight(scope, "/")
+	idx := strings.LastIndex(strings.ToLower(trimmed), "/providers/")
+	segs := strings.Split(trimmed[idx+len("/providers/"):], "/")
+	types := []string{segs[0]}
+	for i := 1; i+1 < len(segs); i += 2 {
+		types = append(types, segs[i])
+		name = segs[i+1]
+	}
+	return strings.Join(types, "/"), name
+}
+
+// directoryScopePrefix marks Microsoft Entra ID directory scopes. Graph
+// directoryScopeId values ("/" or "/administrativeUnits/{id}") are appended to
+// it so directory roles share the path-based scope helpers used for ARM.
+const directoryScopePrefix = "/directory"
+
+// DirectoryScope returns the scope path for a Graph directoryScopeId.
+func DirectoryScope(directoryScopeID string) string {
+	id := strings.TrimRight(strings.TrimSpace(directoryScopeID), "/")
+	if id == "" {
+		return directoryScopePrefix
+	}
+	if !strings.HasPrefix(id, "/") {
+		id = "/" + id
+	}
+	return directoryScopePrefix + id
+}
+
+// IsDirectoryScope reports whether the scope is a Microsoft Entra ID directory scope.
+func IsDirectoryScope(scope string) bool {
+	lower := strings.ToLower(scope)
+	return lower == directoryScopePrefix || strings.HasPrefix(lower, directoryScopePrefix+"/")
+}
+
+// DirectoryScopeIDFromScope returns the Graph directoryScopeId for a directory scope path.
+// Returns "" when the scope is not a directory scope.
+func DirectoryScopeIDFromScope(scope string) string {
+	if !IsDirectoryScope(scope) {
+		return ""
+	}
+	rest := strings.TrimRight(scope[len(directoryScopePrefix):], "/")
+	if rest == "" {
+		return "/"
+	}
+	return rest
+}
+
+// groupScopePrefix marks PIM for Groups scopes ("/groups/{groupId}") so group
+// eligibilities share the path-based scope helpers used for ARM.
+const groupScopePrefix = "/groups/"
+
+// GroupScope returns the scope path for a PIM-enabled group.
+func GroupScope(groupID string) string {
+	return groupScopePrefix + strings.Trim(strings.TrimSpace(groupID), "/")
+}
+
+// IsGroupScope reports whether the scope is a PIM for Groups scope.
+func IsGroupScope(scope string) bool {
+	lower := strings.ToLower(scope)
+	return strings.HasPrefix(lower, groupScopePrefix) && len(strings.Trim(lower[len(groupScopePrefix):], "/")) > 0
+}
+
+// GroupIDFromScope extracts the group ID from a group scope path.
+// Returns "" when the scope is not a group scope.
+func GroupIDFromScope(scope string) string {
+	if !IsGroupScope(scope) {
+		return ""
+	}
+	rest := strings.Trim(scope[len(groupScopePrefix):], "/")
+	if idx := strings.Index(rest, "/"); idx >= 0 {
+		rest = rest[:idx]
+	}
+	return rest
 }
 
 // ManagementGroupIDFromScope extracts the management group ID from a scope path.
@@ -81,6 +182,17 @@ func DefaultScopeDisplay(scope, display string) string {
 		return display
 	}
 	switch {
+	case IsDirectoryScope(scope):
+		if id := DirectoryScopeIDFromScope(scope); id != "/" {
+			return "Directory " + id
+		}
+		return "Directory"
+	case IsGroupScope(scope):
+		return "Group " + GroupIDFromScope(scope)
+	case IsResourceScope(scope):
+		if _, name := ResourceFromScope(scope); name != "" {
+			return name
+		}
 	case IsResourceGroupScope(scope):
 		_, rg := ResourceGroupNameFromScope(scope)
 		if rg != "" {
@@ -104,3 +104,135 @@ func TestScopeMatchesBareMGName(t *testing.T) {
 		t.Error("ScopeMatches: bare MG name should not match different MG scope")
 	}
 }
+
+func TestDirectoryScope(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		want   string
+		wantID string
+	}{
+		{"tenant root", "/", "/directory", "/"},
+		{"empty", "", "/directory", "/"},
+		{"administrative unit", "/administrativeUnits/abc", "/directory/administrativeUnits/abc", "/administrativeUnits/abc"},
+		{"missing slash", "administrativeUnits/abc", "/directory/administrativeUnits/abc", "/administrativeUnits/abc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DirectoryScope(tt.input)
+			if got != tt.want {
+				t.Errorf("DirectoryScope(%q) = %q; want %q", tt.input, got, tt.want)
+			}
+			if !IsDirectoryScope(got) {
+				t.Errorf("IsDirectoryScope(%q) = false; want true", got)
+			}
+			if id := DirectoryScopeIDFromScope(got); id != tt.wantID {
+				t.Errorf("DirectoryScopeIDFromScope(%q) = %q; want %q", got, id, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestIsDirectoryScope(t *testing.T) {
+	tests := []struct {
+		scope string
+		want  bool
+	}{
+		{"/directory", true},
+		{"/Directory/administrativeUnits/abc", true},
+		{"/directoryX", false},
+		{"/subscriptions/00000000-0000-0000-0000-000000000000", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsDirectoryScope(tt.scope); got != tt.want {
+			t.Errorf("IsDirectoryScope(%q) = %v; want %v", tt.scope, got, tt.want)
+		}
+	}
+}
+
+func TestDirectoryScopeKindAndDisplay(t *testing.T) {
+	r := Role{Scope: DirectoryScope("/")}
+	if r.ScopeKind() != ScopeDirectory {
+		t.Errorf("ScopeKind() = %v; want ScopeDirectory", r.ScopeKind())
+	}
+	if got := DefaultScopeDisplay(r.Scope, ""); got != "Directory" {
+		t.Errorf("DefaultScopeDisplay(%q) = %q; want Directory", r.Scope, got)
+	}
+	if got := DefaultScopeDisplay("/directory/administrativeUnits/abc", ""); got != "Directory /administrativeUnits/abc" {
+		t.Errorf("DefaultScopeDisplay(au) = %q", got)
+	}
+}
+
+func TestGroupScope(t *testing.T) {
+	const id = "00000000-0000-0000-0000-0000000000aa"
+	scope := GroupScope(id)
+	if scope != "/groups/"+id {
+		t.Fatalf("GroupScope(%q) = %q", id, scope)
+	}
+	if !IsGroupScope(scope) {
+		t.Errorf("IsGroupScope(%q) = false; want true", scope)
+	}
+	if got := GroupIDFromScope(scope); got != id {
+		t.Errorf("GroupIDFromScope(%q) = %q; want %q", scope, got, id)
+	}
+	for _, s := range []string{"/groups/", "/groups", "/subscriptions/" + id, "/directory"} {
+		if IsGroupScope(s) {
+			t.Errorf("IsGroupScope(%q) = true; want false", s)
+		}
+	}
+}
+
+func TestGroupEligibilityRole(t *testing.T) {
+	g := GroupEligibility{GroupID: "abc", GroupDisplay: "sg-prod-admins", AccessID: "Owner", EligibilityScheduleID: "sched"}
+	r := g.Role()
+	if r.ScopeKind() != ScopeGroup {
+		t.Errorf("ScopeKind() = %v; want ScopeGroup", r.ScopeKind())
+	}
+	if r.RoleName != "Group Owner" || r.RoleDefinitionID != GroupAccessOwner {
+		t.Errorf("Role() = %+v; want Group Owner / owner", r)
+	}
+	if r.ScopeDisplay != "sg-prod-admins" {
+		t.Errorf("ScopeDisplay = %q; want sg-prod-admins", r.ScopeDisplay)
+	}
+	if got := (GroupEligibility{GroupID: "abc", AccessID: "member"}).Role(); got.RoleName != "Group Member" || got.ScopeDisplay != "Group abc" {
+		t.Errorf("member Role() = %+v", got)
+	}
+}
+
+func TestResourceScope(t *testing.T) {
+	const sub = "/subscriptions/00000000-0000-0000-0000-000000000001"
+	tests := []struct {
+		scope    string
+		resource bool
+		rg       bool
+		typ      string
+		name     string
+	}{
+		{sub + "/resourceGroups/rg-app/providers/Microsoft.KeyVault/vaults/kv-prod", true, false, "Microsoft.KeyVault/vaults", "kv-prod"},
+		{sub + "/resourcegroups/rg-app/providers/Microsoft.Sql/servers/sql1/databases/db1", true, false, "Microsoft.Sql/servers/databases", "db1"},
+		{sub + "/resourceGroups/rg-app", false, true, "", ""},
+		{sub + "/resourceGroups/rg-app/providers/Microsoft.KeyVault", false, true, "", ""},
+		{sub, false, false, "", ""},
+		{"/providers/Microsoft.Management/managementGroups/root", false, false, "", ""},
+	}
+	for _, tc := range tests {
+		if got := IsResourceScope(tc.scope); got != tc.resource {
+			t.Errorf("IsResourceScope(%q) = %v; want %v", tc.scope, got, tc.resource)
+		}
+		if got := IsResourceGroupScope(tc.scope); got != tc.rg {
+			t.Errorf("IsResourceGroupScope(%q) = %v; want %v", tc.scope, got, tc.rg)
+		}
+		typ, name := ResourceFromScope(tc.scope)
+		if typ != tc.typ || name != tc.name {
+			t.Errorf("ResourceFromScope(%q) = %q, %q; want %q, %q", tc.scope, typ, name, tc.typ, tc.name)
+		}
+	}
+	vault := Role{Scope: sub + "/resourceGroups/rg-app/providers/Microsoft.KeyVault/vaults/kv-prod"}
+	if got := vault.ScopeKind(); got != ScopeResource {
+		t.Errorf("ScopeKind() = %v; want ScopeResource", got)
+	}
+	if got := DefaultScopeDisplay(vault.Scope, ""); got != "kv-prod" {
+		t.Errorf("DefaultScopeDisplay = %q; want kv-prod", got)
+	}
+}
@@ -0,0 +1,136 @@
+package azure
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+// StepUpError is returned when a request needs a token carrying additional
+// Conditional Access claims (MFA or another authentication context) and no
+// available credential could obtain one.
+type StepUpError struct {
+	Claims   string // decoded claims challenge
+	Scope    string // token scope the claims apply to
+	TenantID string
+	Err      error
+}
+
+func (e *StepUpError) Error() string {
+	tenant := ""
+	if e.TenantID != "" {
+		tenant = " --tenant " + e.TenantID
+	}
+	return fmt.Sprintf("activation requires Conditional Access step-up authentication; "+
+		"sign in again with 'az login%s --scope %s --claims-challenge %s', "+
+		"or set PIM_ALLOW_DEVICE_LOGIN=1 (or add device-code to --auth) to sign in with a device code: %v",
+		tenant, e.Scope, base64.StdEncoding.EncodeToString([]byte(e.Claims)), e.Err)
+}
+
+func (e *StepUpError) Unwrap() error { return e.Err }
+
+// isAcrsValidationCode reports whether code is an authentication context
+// validation failure such as RoleAssignmentRequestAcrsValidationFailed.
+func isAcrsValidationCode(code string) bool {
+	return strings.Contains(strings.ToLower(code), "acrsvalidationfailed")
+}
+
+// challengeClaims returns the decoded claims parameter of a WWW-Authenticate
+// Bearer challenge, or "" when there is none.
+func challengeClaims(h http.Header) string {
+	for _, v := range h.Values("WWW-Authenticate") {
+		_, rest, ok := strings.Cut(v, `claims="`)
+		if !ok {
+			continue
+		}
+		raw, _, _ := strings.Cut(rest, `"`)
+		for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+			if b, err := enc.DecodeString(raw); err == nil && json.Valid(b) {
+				return string(b)
+			}
+		}
+		if json.Valid([]byte(raw)) {
+			return raw
+		}
+	}
+	return ""
+}
+
+// claimsFromMessage extracts the claims JSON that ARM and Graph embed in the
+// message of an ACRS validation error, either as a URL-encoded "claims="
+// parameter or as a literal {"access_token":...} object.
+func claimsFromMessage(msg string) string {
+	if _, rest, ok := strings.Cut(msg, "claims="); ok {
+		raw := rest
+		if i := strings.IndexAny(raw, " \t\n&\""); i >= 0 {
+			raw = raw[:i]
+		}
+		if dec, err := url.QueryUnescape(raw); err == nil && json.Valid([]byte(dec)) {
+			return dec
+		}
+	}
+	start := strings.Index(msg, `{"access_token"`)
+	if start < 0 {
+		return ""
+	}
+	depth := 0
+	for i := start; i < len(msg); i++ {
+		switch msg[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				if claims := msg[start : i+1]; json.Valid([]byte(claims)) {
+					return claims
+				}
+				return ""
+			}
+		}
+	}
+	return ""
+}
+
+// doRequestWithStepUp behaves like doRequest but answers a claims challenge:
+// it acquires a token for scope carrying the requested claims and retries
+// the request once with it.
+func (c *Client) doRequestWithStepUp(ctx context.Context, method, reqURL, scope, token string, body []byte) (*http.Response, error) {
+	resp, err := c.doRequest(ctx, method, reqURL, token, body)
+	var apiErr *APIError
+	if err == nil || !errors.As(err, &apiErr) || apiErr.Claims == "" {
+		return resp, err
+	}
+	stepped, err := c.stepUpToken(ctx, scope, apiErr.Claims)
+	if err != nil {
+		return nil, err
+	}
+	return c.doRequest(ctx, method, reqURL, stepped, body)
+}
+
+// stepUpToken requests a token for scope satisfying claims, first through the
+// credential chain and then, if allowed, through device code sign-in. The
+// Azure CLI and PowerShell credentials cannot answer claims challenges
+// themselves, so the caller gets a StepUpError explaining how to do it.
+func (c *Client) stepUpToken(ctx context.Context, scope, claims string) (string, error) {
+	opts := policy.TokenRequestOptions{Scopes: []string{scope}, Claims: claims}
+	tok, err := c.cred.GetToken(ctx, opts)
+	if err == nil {
+		return tok.Token, nil
+	}
+	errs := []error{err}
+	if c.stepUpCred != nil {
+		tok, err := c.stepUpCred.GetToken(ctx, opts)
+		if err == nil {
+			return tok.Token, nil
+		}
+		errs = append(errs, err)
+	}
+	return "", &StepUpError{Claims: claims, Scope: scope, TenantID: c.tenantID, Err: errors.Join(errs...)}
+}
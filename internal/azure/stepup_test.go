@@ -0,0 +1,133 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+const testClaims = `{"access_token":{"acrs":{"essential":true,"value":"c1"}}}`
+
+func TestClaimsFromMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  string
+		want string
+	}{
+		{"url encoded", "The Role assignment request ACRS validation failed: &claims=%7B%22access_token%22%3A%7B%22acrs%22%3A%7B%22essential%22%3Atrue%2C%22value%22%3A%22c1%22%7D%7D%7D", testClaims},
+		{"literal json", `ACRS validation failed. Claims: ` + testClaims + `.`, testClaims},
+		{"no claims", "The role assignment request failed.", ""},
+		{"truncated", `Claims: {"access_token":{"acrs"`, ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := claimsFromMessage(tc.msg); got != tc.want {
+				t.Errorf("claimsFromMessage() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestChallengeClaims(t *testing.T) {
+	h := http.Header{}
+	h.Set("WWW-Authenticate", `Bearer realm="", error="insufficient_claims", claims="eyJhY2Nlc3NfdG9rZW4iOnsiYWNycyI6eyJlc3NlbnRpYWwiOnRydWUsInZhbHVlIjoiYzEifX19"`)
+	if got := challengeClaims(h); got != testClaims {
+		t.Errorf("challengeClaims() = %q, want %q", got, testClaims)
+	}
+	if got := challengeClaims(http.Header{}); got != "" {
+		t.Errorf("challengeClaims(empty) = %q, want empty", got)
+	}
+}
+
+// claimsCred issues "stepped" only for requests carrying claims; fail makes
+// it reject those requests the way the Azure CLI credential does.
+type claimsCred struct {
+	fail   bool
+	claims []string
+}
+
+func (c *claimsCred) GetToken(_ context.Context, opts policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	if opts.Claims == "" {
+		return azcore.AccessToken{Token: "plain"}, nil
+	}
+	c.claims = append(c.claims, opts.Claims)
+	if c.fail {
+		return azcore.AccessToken{}, errors.New("claims not supported")
+	}
+	return azcore.AccessToken{Token: "stepped"}, nil
+}
+
+func acrsServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"x":1}` {
+			t.Errorf("request body = %q, want it re-sent unchanged", body)
+		}
+		if r.Header.Get("Authorization") == "Bearer stepped" {
+			w.Write([]byte(`{"name":"req-1"}`))
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":{"code":"RoleAssignmentRequestAcrsValidationFailed","message":"ACRS validation failed. Claims: ` +
+			strings.ReplaceAll(testClaims, `"`, `\"`) + `"}}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestDoRequestWithStepUp(t *testing.T) {
+	srv := acrsServer(t)
+	cred := &claimsCred{}
+	c := &Client{cred: cred, httpClient: srv.Client()}
+
+	resp, err := c.doRequestWithStepUp(context.Background(), http.MethodPut, srv.URL, "scope/.default", "plain", []byte(`{"x":1}`))
+	if err != nil {
+		t.Fatalf("doRequestWithStepUp() error: %v", err)
+	}
+	resp.Body.Close()
+	if len(cred.claims) != 1 || cred.claims[0] != testClaims {
+		t.Errorf("token requested with claims %q, want %q", cred.claims, testClaims)
+	}
+}
+
+func TestDoRequestWithStepUpFallsBackToDeviceCode(t *testing.T) {
+	srv := acrsServer(t)
+	device := &claimsCred{}
+	c := &Client{cred: &claimsCred{fail: true}, stepUpCred: device, httpClient: srv.Client()}
+
+	resp, err := c.doRequestWithStepUp(context.Background(), http.MethodPut, srv.URL, "scope/.default", "plain", []byte(`{"x":1}`))
+	if err != nil {
+		t.Fatalf("doRequestWithStepUp() error: %v", err)
+	}
+	resp.Body.Close()
+	if len(device.claims) != 1 {
+		t.Errorf("device code credential called %d times, want 1", len(device.claims))
+	}
+}
+
+func TestDoRequestWithStepUpUnavailable(t *testing.T) {
+	srv := acrsServer(t)
+	c := &Client{cred: &claimsCred{fail: true}, httpClient: srv.Client(), tenantID: "tenant-1"}
+
+	_, err := c.doRequestWithStepUp(context.Background(), http.MethodPut, srv.URL, "scope/.default", "plain", []byte(`{"x":1}`))
+	var stepUp *StepUpError
+	if !errors.As(err, &stepUp) {
+		t.Fatalf("error = %v, want *StepUpError", err)
+	}
+	if stepUp.Claims != testClaims {
+		t.Errorf("Claims = %q, want %q", stepUp.Claims, testClaims)
+	}
+	for _, want := range []string{"az login --tenant tenant-1 --scope scope/.default --claims-challenge", "PIM_ALLOW_DEVICE_LOGIN=1"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q missing %q", err.Error(), want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// tenantsAPIVersion is the ARM API version for listing tenants.
+const tenantsAPIVersion = "2022-12-01"
+
+// Tenant is a Microsoft Entra tenant the signed-in account can access,
+// including tenants where it is a guest.
+type Tenant struct {
+	ID            string `json:"tenantId"`
+	DisplayName   string `json:"displayName"`
+	DefaultDomain string `json:"defaultDomain"`
+}
+
+// tenantClients caches one Client per tenant so credentials for several
+// tenants can be held at once. Keys are lower-cased tenant IDs; home is the
+// client NewClient returned.
+type tenantClients struct {
+	mu   sync.Mutex
+	home *Client
+	byID map[string]*Client
+}
+
+// TenantID returns the tenant the client authenticates against, or "" for
+// the credential's default tenant.
+func (c *Client) TenantID() string { return c.tenantID }
+
+// ForTenant returns a client for tenantID, creating its credentials on first
+// use. Clients are cached, so switching back and forth reuses tokens. An empty
+// tenantID returns the client NewClient created.
+func (c *Client) ForTenant(tenantID string) (*Client, error) {
+	tenantID = strings.TrimSpace(tenantID)
+	if strings.EqualFold(tenantID, c.tenantID) {
+		return c, nil
+	}
+	c.tenants.mu.Lock()
+	cached, ok := c.tenants.byID[strings.ToLower(tenantID)]
+	if tenantID == "" {
+		cached, ok = c.tenants.home, true
+	}
+	c.tenants.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+	return c.newTenantClient(tenantID)
+}
+
+// ListTenants returns the tenants the signed-in account can access, sorted
+// by display name.
+func (c *Client) ListTenants(ctx context.Context) ([]Tenant, error) {
+	tok, err := c.armToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reqURL := fmt.Sprintf("%s/tenants?api-version=%s", c.armURL, tenantsAPIVersion)
+
+	var out []Tenant
+	for reqURL != "" {
+		resp, err := c.doRequest(ctx, http.MethodGet, reqURL, tok, nil)
+		if err != nil {
+			return nil, fmt.Errorf("list tenants: %w", err)
+		}
+		var result struct {
+			Value    []Tenant `json:"value"`
+			NextLink string   `json:"nextLink"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			resp.Body.Close()
+			return nil, fmt.Errorf("decode tenants: %w", err)
+		}
+		resp.Body.Close()
+		out = append(out, result.Value...)
+		reqURL = result.NextLink
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return strings.ToLower(out[i].DisplayName) < strings.ToLower(out[j].DisplayName)
+	})
+	return out, nil
+}
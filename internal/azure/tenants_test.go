@@ -0,0 +1,77 @@
+package azure
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+// tenantCred records the tenant and scopes of every token request.
+type tenantCred struct {
+	mu       sync.Mutex
+	requests []policy.TokenRequestOptions
+}
+
+func (c *tenantCred) GetToken(_ context.Context, opts policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests = append(c.requests, opts)
+	return azcore.AccessToken{Token: "token-" + opts.TenantID}, nil
+}
+
+func TestForTenant(t *testing.T) {
+	cred := &tenantCred{}
+	home, err := NewClient(ClientOptions{TenantID: "t-home", Credential: cred})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c, err := home.ForTenant("T-HOME"); err != nil || c != home {
+		t.Errorf("ForTenant(home) = %p, %v; want the home client", c, err)
+	}
+	a, err := home.ForTenant("t-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := home.ForTenant(" T-A "); again != a {
+		t.Error("ForTenant(t-a) built a second client; want the cached one")
+	}
+	b, err := a.ForTenant("t-b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b == a || b == home {
+		t.Fatal("ForTenant(t-b) returned another tenant's client")
+	}
+	if c, _ := b.ForTenant(""); c != home {
+		t.Error("ForTenant(\"\") did not return the home client")
+	}
+	if a.TenantID() != "t-a" || b.TenantID() != "t-b" {
+		t.Errorf("TenantID() = %q, %q; want t-a, t-b", a.TenantID(), b.TenantID())
+	}
+
+	for _, c := range []*Client{a, b, home} {
+		tok, err := c.armToken(t.Context())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "token-" + c.TenantID(); tok != want {
+			t.Errorf("%s token = %q, want %q", c.TenantID(), tok, want)
+		}
+	}
+	if len(cred.requests) != 3 {
+		t.Fatalf("token requests = %d, want 3", len(cred.requests))
+	}
+	for i, want := range []string{"t-a", "t-b", "t-home"} {
+		got := cred.requests[i]
+		if got.TenantID != want {
+			t.Errorf("request %d tenant = %q, want %q", i, got.TenantID, want)
+		}
+		if len(got.Scopes) != 1 || got.Scopes[0] != AzurePublic.armScope() {
+			t.Errorf("request %d scopes = %v, want the ARM scope", i, got.Scopes)
+		}
+	}
+}
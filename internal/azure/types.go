@@ -13,13 +13,69 @@ type User struct {
 	DisplayName       string `json:"displayName"`
 }
 
-// Role represents an eligible PIM role.
+// Role represents an eligible PIM role. Azure resource roles carry an ARM
+// scope; Microsoft Entra ID directory roles carry a DirectoryScope path and
+// their unified role definition ID; PIM for Groups eligibilities carry a
+// GroupScope path and their access ID ("member" or "owner").
 type Role struct {
 	Scope                 string
 	ScopeDisplay          string
 	RoleName              string
 	RoleDefinitionID      string
 	EligibilityScheduleID string
+	// EligibilityStart and EligibilityEnd bound the eligibility (RFC 3339);
+	// an empty EligibilityEnd means the eligibility is permanent.
+	EligibilityStart string
+	EligibilityEnd   string
+	// MemberType is "Direct", "Group", or "Inherited" as returned by the API.
+	MemberType string
+}
+
+// EligibilityRenewWindow is how close to expiry an eligibility must be before
+// Azure accepts a self-extend request for it.
+const EligibilityRenewWindow = 14 * 24 * time.Hour
+
+// IsEligibilityPermanent reports whether the eligibility has no end date.
+func (r Role) IsEligibilityPermanent() bool {
+	return strings.TrimSpace(r.EligibilityEnd) == ""
+}
+
+// EligibilityRemaining returns the time left until the eligibility ends. Zero
+// if permanent, expired, or the end date cannot be parsed.
+func (r Role) EligibilityRemaining() time.Duration {
+	end, err := time.Parse(time.RFC3339, r.EligibilityEnd)
+	if err != nil {
+		return 0
+	}
+	return max(time.Until(end), 0)
+}
+
+// IsEligibilityExpired reports whether the eligibility has an end date in the past.
+func (r Role) IsEligibilityExpired() bool {
+	end, err := time.Parse(time.RFC3339, r.EligibilityEnd)
+	return err == nil && !end.After(time.Now())
+}
+
+// IsEligibilityExpiring reports whether the eligibility ends within
+// EligibilityRenewWindow, i.e. it can be self-extended now.
+func (r Role) IsEligibilityExpiring() bool {
+	return !r.IsEligibilityPermanent() && r.EligibilityRemaining() <= EligibilityRenewWindow
+}
+
+// EligibilityExpiryDisplay returns e.g. "eligibility expires in 5d",
+// "eligibility expired", or "" for a permanent eligibility.
+func (r Role) EligibilityExpiryDisplay() string {
+	if r.IsEligibilityPermanent() {
+		return ""
+	}
+	if r.IsEligibilityExpired() {
+		return "eligibility expired"
+	}
+	end, err := time.Parse(time.RFC3339, r.EligibilityEnd)
+	if err != nil {
+		return "eligibility ends " + r.EligibilityEnd
+	}
+	return "eligibility expires in " + humanizeDuration(time.Until(end))
 }
 
 // ManagementGroup represents an Azure management group.
@@ -40,23 +96,75 @@ const (
 	ScopeManagementGroup ScopeType = iota
 	ScopeSubscription
 	ScopeResourceGroup
+	ScopeResource
+	ScopeDirectory
+	ScopeGroup
 	ScopeUnknown
 )
 
 // ScopeKind returns the type of scope for this role.
 func (r Role) ScopeKind() ScopeType {
 	switch {
+	case IsDirectoryScope(r.Scope):
+		return ScopeDirectory
+	case IsGroupScope(r.Scope):
+		return ScopeGroup
 	case IsManagementGroupScope(r.Scope):
 		return ScopeManagementGroup
 	case IsSubscriptionScope(r.Scope):
 		return ScopeSubscription
 	case IsResourceGroupScope(r.Scope):
 		return ScopeResourceGroup
+	case IsResourceScope(r.Scope):
+		return ScopeResource
 	default:
 		return ScopeUnknown
 	}
 }
 
+// Group access IDs used by PIM for Groups.
+const (
+	GroupAccessMember = "member"
+	GroupAccessOwner  = "owner"
+)
+
+// GroupEligibility represents an eligible PIM for Groups membership or ownership.
+type GroupEligibility struct {
+	GroupID               string
+	GroupDisplay          string
+	AccessID              string
+	EligibilityScheduleID string
+	StartDateTime         string
+	EndDateTime           string
+	MemberType            string
+}
+
+// Role returns the eligibility as a Role so it can flow through the role list,
+// wizard, and headless filters alongside Azure and directory roles.
+func (g GroupEligibility) Role() Role {
+	scope := GroupScope(g.GroupID)
+	return Role{
+		Scope:                 scope,
+		ScopeDisplay:          DefaultScopeDisplay(scope, g.GroupDisplay),
+		RoleName:              GroupAccessRoleName(g.AccessID),
+		RoleDefinitionID:      strings.ToLower(g.AccessID),
+		EligibilityScheduleID: g.EligibilityScheduleID,
+		EligibilityStart:      g.StartDateTime,
+		EligibilityEnd:        g.EndDateTime,
+		MemberType:            g.MemberType,
+	}
+}
+
+// GroupAccessRoleName returns the display name for a group access ID.
+func GroupAccessRoleName(accessID string) string {
+	switch strings.ToLower(accessID) {
+	case GroupAccessOwner:
+		return "Group Owner"
+	default:
+		return "Group Member"
+	}
+}
+
 // ActiveAssignment represents an active PIM role assignment.
 type ActiveAssignment struct {
 	Name             string
@@ -64,11 +172,31 @@ type ActiveAssignment struct {
 	ScopeDisplay     string
 	RoleName         string
 	RoleDefinitionID string
-	EndDateTime      string
+	// StartDateTime is set for schedules; a future value means the assignment
+	// has not started yet.
+	StartDateTime string
+	EndDateTime   string
 	// MemberType is "Direct", "Group", or "Inherited" as returned by the API.
 	MemberType string
 }
 
+// IsScheduled reports whether the assignment starts in the future.
+func (a ActiveAssignment) IsScheduled() bool {
+	start, err := time.Parse(time.RFC3339, a.StartDateTime)
+	return err == nil && start.After(time.Now())
+}
+
+// EarliestDeactivation returns when Azure first accepts deactivating the
+// assignment: MinActiveDuration after it started, or after now when the start
+// time is unknown.
+func (a ActiveAssignment) EarliestDeactivation(now time.Time) time.Time {
+	start, err := time.Parse(time.RFC3339, a.StartDateTime)
+	if err != nil {
+		start = now
+	}
+	return start.Add(MinActiveDuration)
+}
+
 // IsPermanent reports whether the assignment has no expiry.
 func (a ActiveAssignment) IsPermanent() bool {
 	return strings.TrimSpace(a.EndDateTime) == ""
@@ -90,8 +218,13 @@ func (a ActiveAssignment) TimeRemaining() time.Duration {
 	return d
 }
 
-// ExpiryDisplay returns a short human-readable time-remaining string.
+// ExpiryDisplay returns a short human-readable time-remaining string, or the
+// local start time for scheduled assignments.
 func (a ActiveAssignment) ExpiryDisplay() string {
+	if a.IsScheduled() {
+		start, _ := time.Parse(time.RFC3339, a.StartDateTime)
+		return "starts " + start.Local().Format("2006-01-02 15:04")
+	}
 	if a.EndDateTime == "" {
 		return "permanent"
 	}
@@ -149,6 +282,72 @@ func (rg ResourceGroup) Scope() string {
 	return fmt.Sprintf("/subscriptions/%s/resourceGroups/%s", rg.SubscriptionID, rg.Name)
 }
 
+// Resource represents an individual Azure resource inside a resource group,
+// such as a Key Vault or storage account.
+type Resource struct {
+	ID          string // full ARM resource ID
+	Name        string
+	Type        string // e.g. "Microsoft.KeyVault/vaults"
+	DisplayName string
+}
+
+// Scope returns the resource scope path.
+func (r Resource) Scope() string { return r.ID }
+
+// TicketInfo carries the ticket reference required by some activation policies.
+type TicketInfo struct {
+	TicketNumber string `json:"ticketNumber,omitempty"`
+	TicketSystem string `json:"ticketSystem,omitempty"`
+}
+
+// IsZero reports whether no ticket information is set.
+func (t TicketInfo) IsZero() bool {
+	return strings.TrimSpace(t.TicketNumber) == "" && strings.TrimSpace(t.TicketSystem) == ""
+}
+
+// ptr returns nil for an empty ticket so it is omitted from request bodies.
+func (t TicketInfo) ptr() *TicketInfo {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// ActivationRequest describes a self-activation of an eligible role.
+// TargetScope may narrow an MG- or subscription-scoped eligibility to a child
+// scope; it is ignored for directory roles and group eligibilities. A zero
+// Start activates immediately.
+type ActivationRequest struct {
+	Role          Role
+	PrincipalID   string
+	Justification string
+	Minutes       int
+	TargetScope   string
+	Ticket        TicketInfo
+	Start         time.Time
+}
+
+// IsScheduled reports whether the request starts in the future.
+func (ar ActivationRequest) IsScheduled() bool {
+	return !ar.Start.IsZero() && ar.Start.After(time.Now())
+}
+
+// scheduleInfo returns the request schedule; expirationType is "AfterDuration"
+// for ARM and "afterDuration" for Graph.
+func (ar ActivationRequest) scheduleInfo(expirationType string) *ScheduleInfo {
+	start := ar.Start
+	if start.IsZero() {
+		start = time.Now()
+	}
+	return &ScheduleInfo{
+		StartDateTime: start.UTC().Format(time.RFC3339),
+		Expiration: Expiration{
+			Type:     expirationType,
+			Duration: FormatDuration(ar.Minutes),
+		},
+	}
+}
+
 // ScheduleRequest is the PIM activation request body.
 type ScheduleRequest struct {
 	Properties ScheduleProperties `json:"properties"`
@@ -162,6 +361,7 @@ type ScheduleProperties struct {
 	Justification                   string        `json:"justification,omitempty"`
 	LinkedRoleEligibilityScheduleID string        `json:"linkedRoleEligibilityScheduleId,omitempty"`
 	ScheduleInfo                    *ScheduleInfo `json:"scheduleInfo,omitempty"`
+	TicketInfo                      *TicketInfo   `json:"ticketInfo,omitempty"`
 }
 
 // ScheduleInfo contains schedule timing.
@@ -178,8 +378,23 @@ type Expiration struct {
 
 // ScheduleResponse is the API response from a schedule request.
 type ScheduleResponse struct {
+	// ID is the full ARM request resource ID; empty for Graph requests, whose
+	// request ID is carried in Name.
+	ID         string `json:"id"`
 	Name       string `json:"name"`
 	Properties struct {
 		Status string `json:"status"`
 	} `json:"properties"`
+	// AlreadyActive is set when Azure rejected the activation because the
+	// role is already active at the target scope.
+	AlreadyActive bool `json:"-"`
+}
+
+// Status returns the request status, e.g. "Provisioned" or "PendingApproval".
+// Empty when the response carried no body.
+func (r *ScheduleResponse) Status() string {
+	if r == nil {
+		return ""
+	}
+	return r.Properties.Status
 }
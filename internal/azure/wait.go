@@ -0,0 +1,169 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Schedule request statuses that mean the request finished without granting
+// the assignment. ARM and Graph share these values.
+const (
+	StatusProvisioned = "Provisioned"
+	StatusDenied      = "Denied"
+	StatusFailed      = "Failed"
+	StatusCanceled    = "Canceled"
+	StatusRevoked     = "Revoked"
+	StatusTimedOut    = "TimedOut"
+)
+
+// waitPollInterval is the delay between provisioning checks in WaitForActivation.
+var waitPollInterval = 5 * time.Second
+
+// IsFailedStatus reports whether status means the request ended without
+// granting the assignment, e.g. "Denied" or "Failed".
+func IsFailedStatus(status string) bool {
+	for _, s := range []string{StatusDenied, StatusFailed, StatusCanceled, StatusRevoked, StatusTimedOut} {
+		if strings.EqualFold(status, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// WaitForActivation polls until the assignment requested by ar is active, the
+// request fails, or ctx is done; callers bound the wait with a context
+// deadline. resp is the response ActivateRole returned; when it carries a
+// request ID the request status is checked as well, so denied or failed
+// requests return early. onStatus, if non-nil, receives each newly observed
+// request status.
+func (c *Client) WaitForActivation(ctx context.Context, ar ActivationRequest, resp *ScheduleResponse, onStatus func(string)) error {
+	last := resp.Status()
+	for {
+		if resp != nil && (resp.ID != "" || resp.Name != "") {
+			status, err := c.requestStatus(ctx, ar, resp)
+			if err != nil && !isAccessDenied(err) {
+				return fmt.Errorf("wait for activation: %w", err)
+			}
+			if status != "" && status != last {
+				last = status
+				if onStatus != nil {
+					onStatus(status)
+				}
+			}
+			if IsFailedStatus(last) {
+				return fmt.Errorf("activation request %s", strings.ToLower(last))
+			}
+		}
+
+		active, err := c.isInstanceActive(ctx, ar)
+		if err != nil {
+			return fmt.Errorf("wait for activation: %w", err)
+		}
+		if active {
+			if onStatus != nil && last != StatusProvisioned {
+				onStatus(StatusProvisioned)
+			}
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			if last == "" {
+				return fmt.Errorf("wait for activation: %w", ctx.Err())
+			}
+			return fmt.Errorf("wait for activation (last status %s): %w", last, ctx.Err())
+		case <-time.After(waitPollInterval):
+		}
+	}
+}
+
+// requestStatus re-reads the status of a submitted schedule request.
+func (c *Client) requestStatus(ctx context.Context, ar ActivationRequest, resp *ScheduleResponse) (string, error) {
+	var (
+		tok    string
+		err    error
+		reqURL string
+	)
+	switch ar.Role.ScopeKind() {
+	case ScopeDirectory:
+		tok, err = c.graphToken(ctx)
+		reqURL = c.graphURL + "/roleManagement/directory/roleAssignmentScheduleRequests/" + url.PathEscape(resp.Name)
+	case ScopeGroup:
+		tok, err = c.graphToken(ctx)
+		reqURL = c.graphURL + "/identityGovernance/privilegedAccess/group/assignmentScheduleRequests/" + url.PathEscape(resp.Name)
+	default:
+		tok, err = c.armToken(ctx)
+		id := resp.ID
+		if id == "" {
+			id = activationScope(ar) + "/providers/Microsoft.Authorization/roleAssignmentScheduleRequests/" + resp.Name
+		}
+		reqURL = fmt.Sprintf("%s%s?api-version=%s", c.armURL, id, apiVersion)
+	}
+	if err != nil {
+		return "", err
+	}
+	httpResp, err := c.doRequest(ctx, http.MethodGet, reqURL, tok, nil)
+	if err != nil {
+		return "", fmt.Errorf("get request status: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	var result struct {
+		Status     string `json:"status"`
+		Properties struct {
+			Status string `json:"status"`
+		} `json:"properties"`
+	}
+	if err := json.NewDecoder(httpResp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("decode request status: %w", err)
+	}
+	if result.Properties.Status != "" {
+		return result.Properties.Status, nil
+	}
+	return result.Status, nil
+}
+
+// isInstanceActive reports whether an assignment instance for ar exists, i.e.
+// the activation has been provisioned.
+func (c *Client) isInstanceActive(ctx context.Context, ar ActivationRequest) (bool, error) {
+	switch ar.Role.ScopeKind() {
+	case ScopeDirectory:
+		return c.isDirectoryRoleActive(ctx, ar.Role.Scope, ar.Role.RoleDefinitionID)
+	case ScopeGroup:
+		return c.isGroupAccessActive(ctx, ar.Role.Scope, ar.Role.RoleDefinitionID)
+	}
+	tok, err := c.armToken(ctx)
+	if err != nil {
+		return false, err
+	}
+	filter := fmt.Sprintf("principalId eq '%s' and roleDefinitionId eq '%s'", ar.PrincipalID, ar.Role.RoleDefinitionID)
+	reqURL := fmt.Sprintf("%s%s/providers/Microsoft.Authorization/roleAssignmentScheduleInstances?api-version=%s&$filter=%s",
+		c.armURL, activationScope(ar), apiVersion, url.QueryEscape(filter))
+
+	resp, err := c.doRequest(ctx, http.MethodGet, reqURL, tok, nil)
+	if err != nil {
+		return false, fmt.Errorf("check assignment instance: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Value []any `json:"value"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return false, fmt.Errorf("decode assignment instance check: %w", err)
+	}
+	return len(result.Value) > 0, nil
+}
+
+// activationScope returns the ARM scope an activation request targets.
+func activationScope(ar ActivationRequest) string {
+	if strings.TrimSpace(ar.TargetScope) != "" {
+		return NormalizeScope(ar.TargetScope)
+	}
+	return NormalizeScope(ar.Role.Scope)
+}
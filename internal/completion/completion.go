@@ -12,16 +12,28 @@ func Bash(w io.Writer) {
     local cur prev words cword
     _init_completion || return
 
-    local commands="activate deactivate status search completion version help"
-    local common_flags="--role -r --scope --time -t --justification -j --yes -y --headless --output -o --config-dir"
+    local commands="activate deactivate status requests renew history role search whoami doctor cache completion version help"
+    local common_flags="--role -r --scope --time -t --justification -j --ticket --ticket-system --at --start --wait --wait-timeout --parallel --yes -y --headless --output -o --config-dir --cloud --tenant --auth --refresh --no-cache --debug"
     local activate_flags="$common_flags"
-    local deactivate_flags="--role -r --scope --headless --output -o --config-dir"
-    local status_flags="--role -r --scope --headless --output -o --config-dir"
-    local search_flags="--output -o --config-dir --mg"
+    local deactivate_flags="--role -r --scope --headless --output -o --config-dir --cloud --tenant --auth --refresh --no-cache --debug"
+    local status_flags="--role -r --scope --headless --output -o --config-dir --cloud --tenant --auth --refresh --no-cache --debug"
+    local requests_flags="--role -r --scope --cancel --parallel --yes -y --headless --output -o --config-dir --cloud --tenant --auth --refresh --no-cache --debug"
+    local renew_flags="--role -r --scope --justification -j --days --parallel --config-dir --cloud --tenant --auth --refresh --no-cache --debug"
+    local history_flags="--role -r --scope --since --until --headless --output -o --config-dir --cloud --tenant --auth --refresh --no-cache --debug"
+    local role_flags="--scope --output -o --config-dir --cloud --tenant --auth --refresh --no-cache --debug"
+    local search_flags="--output -o --config-dir --cloud --tenant --auth --mg --refresh --no-cache --debug"
+    local diag_flags="--output -o --config-dir --cloud --tenant --auth --debug"
 
     case "$prev" in
         --output|-o)
-            COMPREPLY=( $(compgen -W "table json" -- "$cur") )
+            if [[ " ${words[*]} " == *" history "* ]]; then
+                COMPREPLY=( $(compgen -W "table json csv" -- "$cur") )
+            else
+                COMPREPLY=( $(compgen -W "table json" -- "$cur") )
+            fi
+            return ;;
+        --since|--until)
+            COMPREPLY=( $(compgen -W "7d 30d 90d" -- "$cur") )
             return ;;
         --time|-t)
             COMPREPLY=( $(compgen -W "30m 1h 2h 4h 8h" -- "$cur") )
@@ -29,9 +41,23 @@ func Bash(w io.Writer) {
         --config-dir)
             _filedir -d
             return ;;
+        --cloud)
+            COMPREPLY=( $(compgen -W "AzurePublic AzureUSGovernment AzureChina Custom" -- "$cur") )
+            return ;;
+        --tenant)
+            return ;;
+        --auth)
+            COMPREPLY=( $(compgen -W "azure-cli powershell device-code browser environment workload-identity" -- "$cur") )
+            return ;;
         completion)
             COMPREPLY=( $(compgen -W "bash zsh fish" -- "$cur") )
             return ;;
+        role)
+            COMPREPLY=( $(compgen -W "show" -- "$cur") )
+            return ;;
+        cache)
+            COMPREPLY=( $(compgen -W "clear" -- "$cur") )
+            return ;;
     esac
 
     local subcmd=""
@@ -51,10 +77,20 @@ func Bash(w io.Writer) {
                 COMPREPLY=( $(compgen -W "$deactivate_flags" -- "$cur") ) ;;
             status)
                 COMPREPLY=( $(compgen -W "$status_flags" -- "$cur") ) ;;
+            requests)
+                COMPREPLY=( $(compgen -W "$requests_flags" -- "$cur") ) ;;
+            renew)
+                COMPREPLY=( $(compgen -W "$renew_flags" -- "$cur") ) ;;
+            history)
+                COMPREPLY=( $(compgen -W "$history_flags" -- "$cur") ) ;;
+            role)
+                COMPREPLY=( $(compgen -W "$role_flags" -- "$cur") ) ;;
             search)
                 COMPREPLY=( $(compgen -W "$search_flags" -- "$cur") ) ;;
+            whoami|doctor)
+                COMPREPLY=( $(compgen -W "$diag_flags" -- "$cur") ) ;;
             version|help)
-                COMPREPLY=( $(compgen -W "--config-dir" -- "$cur") ) ;;
+                COMPREPLY=( $(compgen -W "--config-dir --cloud --tenant --auth" -- "$cur") ) ;;
             *)
                 COMPREPLY=( $(compgen -W "$common_flags" -- "$cur") ) ;;
         esac
@@ -86,7 +122,14 @@ _pim() {
                 'activate:activate roles via TUI wizard'
                 'deactivate:deactivate active role elevations'
                 'status:view active and eligible roles'
+                'requests:view and cancel pending activation requests'
+                'renew:request extension or renewal of eligibilities'
+                'history:view past activation and assignment requests'
+                'role:show the permissions a role grants'
                 'search:list PIM-eligible subscriptions'
+                'whoami:show the signed-in account, tenant and token'
+                'doctor:check sign-in, permissions and config files'
+                'cache:manage the response cache'
                 'completion:print shell completion script'
                 'version:print version'
                 'help:show help'
@@ -104,11 +147,24 @@ _pim() {
                         '-t[activation duration]:duration:(30m 1h 2h 4h 8h)' \
                         '--justification[justification text]:text' \
                         '-j[justification text]:text' \
+                        '--ticket[ticket number]:ticket' \
+                        '--ticket-system[ticket system name]:system' \
+                        '--at[schedule activation start]:when' \
+                        '--start[schedule activation start]:when' \
+                        '--wait[wait until provisioned]' \
+                        '--wait-timeout[maximum provisioning wait]:duration:(1m 2m 5m 10m)' \
+                        '--parallel[targets activated at once]:count:(1 2 4 8 16)' \
                         '--yes[skip confirmation]' \
                         '-y[skip confirmation]' \
                         '--headless[non-TUI mode]' \
                         '--output[output format]:format:(table json)' \
                         '-o[output format]:format:(table json)' \
+                        '--cloud[Azure cloud]:cloud:(AzurePublic AzureUSGovernment AzureChina Custom)' \
+                        '--tenant[tenant ID]:tenant:' \
+                        '--refresh[ignore cached responses]' \
+                        '--no-cache[do not use the response cache]' \
+                        '--debug[log every HTTP request]' \
+                        '--auth[credential sources]:sources:_sequence compadd - azure-cli powershell device-code browser environment workload-identity' \
                         '--config-dir[override config directory]:dir:_directories'
                     ;;
                 deactivate)
@@ -118,9 +174,16 @@ _pim() {
                         '--scope[scope path (repeatable)]:scope path' \
                         '--yes[deactivate all without prompt]' \
                         '-y[deactivate all without prompt]' \
+                        '--parallel[targets deactivated at once]:count:(1 2 4 8 16)' \
                         '--headless[non-TUI mode]' \
                         '--output[output format]:format:(table json)' \
                         '-o[output format]:format:(table json)' \
+                        '--cloud[Azure cloud]:cloud:(AzurePublic AzureUSGovernment AzureChina Custom)' \
+                        '--tenant[tenant ID]:tenant:' \
+                        '--refresh[ignore cached responses]' \
+                        '--no-cache[do not use the response cache]' \
+                        '--debug[log every HTTP request]' \
+                        '--auth[credential sources]:sources:_sequence compadd - azure-cli powershell device-code browser environment workload-identity' \
                         '--config-dir[override config directory]:dir:_directories'
                     ;;
                 status)
@@ -128,6 +191,82 @@ _pim() {
                         '--headless[non-TUI mode]' \
                         '--output[output format]:format:(table json)' \
                         '-o[output format]:format:(table json)' \
+                        '--cloud[Azure cloud]:cloud:(AzurePublic AzureUSGovernment AzureChina Custom)' \
+                        '--tenant[tenant ID]:tenant:' \
+                        '--refresh[ignore cached responses]' \
+                        '--no-cache[do not use the response cache]' \
+                        '--debug[log every HTTP request]' \
+                        '--auth[credential sources]:sources:_sequence compadd - azure-cli powershell device-code browser environment workload-identity' \
+                        '--config-dir[override config directory]:dir:_directories'
+                    ;;
+                requests)
+                    _arguments \
+                        '--role[role name filter (repeatable)]:role name' \
+                        '-r[role name filter (repeatable)]:role name' \
+                        '--scope[scope path (repeatable)]:scope path' \
+                        '--cancel[cancel matching requests]' \
+                        '--parallel[requests cancelled at once]:count:(1 2 4 8 16)' \
+                        '--yes[cancel all without filters]' \
+                        '-y[cancel all without filters]' \
+                        '--headless[non-TUI mode]' \
+                        '--output[output format]:format:(table json)' \
+                        '-o[output format]:format:(table json)' \
+                        '--cloud[Azure cloud]:cloud:(AzurePublic AzureUSGovernment AzureChina Custom)' \
+                        '--tenant[tenant ID]:tenant:' \
+                        '--refresh[ignore cached responses]' \
+                        '--no-cache[do not use the response cache]' \
+                        '--debug[log every HTTP request]' \
+                        '--auth[credential sources]:sources:_sequence compadd - azure-cli powershell device-code browser environment workload-identity' \
+                        '--config-dir[override config directory]:dir:_directories'
+                    ;;
+                renew)
+                    _arguments \
+                        '--role[role name filter (repeatable)]:role name' \
+                        '-r[role name filter (repeatable)]:role name' \
+                        '--scope[scope path (repeatable)]:scope path' \
+                        '--justification[justification text]:text' \
+                        '-j[justification text]:text' \
+                        '--days[requested eligibility length in days]:days:(30 90 180 365)' \
+                        '--parallel[eligibilities renewed at once]:count:(1 2 4 8 16)' \
+                        '--cloud[Azure cloud]:cloud:(AzurePublic AzureUSGovernment AzureChina Custom)' \
+                        '--tenant[tenant ID]:tenant:' \
+                        '--refresh[ignore cached responses]' \
+                        '--no-cache[do not use the response cache]' \
+                        '--debug[log every HTTP request]' \
+                        '--auth[credential sources]:sources:_sequence compadd - azure-cli powershell device-code browser environment workload-identity' \
+                        '--config-dir[override config directory]:dir:_directories'
+                    ;;
+                history)
+                    _arguments \
+                        '--role[role name filter (repeatable)]:role name' \
+                        '-r[role name filter (repeatable)]:role name' \
+                        '--scope[scope path (repeatable)]:scope path' \
+                        '--since[start of range]:when:(7d 30d 90d)' \
+                        '--until[end of range]:when' \
+                        '--headless[non-TUI mode]' \
+                        '--output[output format]:format:(table json csv)' \
+                        '-o[output format]:format:(table json csv)' \
+                        '--cloud[Azure cloud]:cloud:(AzurePublic AzureUSGovernment AzureChina Custom)' \
+                        '--tenant[tenant ID]:tenant:' \
+                        '--refresh[ignore cached responses]' \
+                        '--no-cache[do not use the response cache]' \
+                        '--debug[log every HTTP request]' \
+                        '--auth[credential sources]:sources:_sequence compadd - azure-cli powershell device-code browser environment workload-identity' \
+                        '--config-dir[override config directory]:dir:_directories'
+                    ;;
+                role)
+                    _arguments \
+                        '1:action:(show)' \
+                        '*:role name' \
+                        '--scope[scope path (repeatable)]:scope path' \
+                        '--output[output format]:format:(table json)' \
+                        '-o[output format]:format:(table json)' \
+                        '--cloud[Azure cloud]:cloud:(AzurePublic AzureUSGovernment AzureChina Custom)' \
+                        '--tenant[tenant ID]:tenant:' \
+                        '--refresh[ignore cached responses]' \
+                        '--no-cache[do not use the response cache]' \
+                        '--debug[log every HTTP request]' \
+                        '--auth[credential sources]:sources:_sequence compadd - azure-cli powershell device-code browser environment workload-identity' \
                         '--config-dir[override config directory]:dir:_directories'
                     ;;
                 search)
@@ -135,13 +274,35 @@ _pim() {
                         '--output[output format]:format:(table json)' \
                         '-o[output format]:format:(table json)' \
                         '--mg[limit to management group]:mg name' \
+                        '--cloud[Azure cloud]:cloud:(AzurePublic AzureUSGovernment AzureChina Custom)' \
+                        '--tenant[tenant ID]:tenant:' \
+                        '--refresh[ignore cached responses]' \
+                        '--no-cache[do not use the response cache]' \
+                        '--debug[log every HTTP request]' \
+                        '--auth[credential sources]:sources:_sequence compadd - azure-cli powershell device-code browser environment workload-identity' \
+                        '--config-dir[override config directory]:dir:_directories'
+                    ;;
+                whoami|doctor)
+                    _arguments \
+                        '--output[output format]:format:(table json)' \
+                        '-o[output format]:format:(table json)' \
+                        '--debug[log every HTTP request]' \
+                        '--cloud[Azure cloud]:cloud:(AzurePublic AzureUSGovernment AzureChina Custom)' \
+                        '--tenant[tenant ID]:tenant:' \
+                        '--auth[credential sources]:sources:_sequence compadd - azure-cli powershell device-code browser environment workload-identity' \
                         '--config-dir[override config directory]:dir:_directories'
                     ;;
                 completion)
                     _values 'shell' bash zsh fish
                     ;;
+                cache)
+                    _values 'action' clear
+                    ;;
                 version|help)
                     _arguments \
+                        '--cloud[Azure cloud]:cloud:(AzurePublic AzureUSGovernment AzureChina Custom)' \
+                        '--tenant[tenant ID]:tenant:' \
+                        '--auth[credential sources]:sources:_sequence compadd - azure-cli powershell device-code browser environment workload-identity' \
                         '--config-dir[override config directory]:dir:_directories'
                     ;;
             esac
@@ -157,7 +318,7 @@ compdef _pim pim
 func Fish(w io.Writer) {
 	fmt.Fprint(w, `# pim fish completions
 
-set -l commands activate deactivate status search completion version help
+set -l commands activate deactivate status requests renew history role search whoami doctor cache completion version help
 
 complete -c pim -f -n "not __fish_seen_subcommand_from $commands" \
     -a activate   -d "activate roles via TUI wizard"
@@ -165,13 +326,31 @@ complete -c pim -f -n "not __fish_seen_subcommand_from $commands" \
     -a deactivate -d "deactivate active role elevations"
 complete -c pim -f -n "not __fish_seen_subcommand_from $commands" \
     -a status     -d "view active and eligible roles"
+complete -c pim -f -n "not __fish_seen_subcommand_from $commands" \
+    -a requests   -d "view and cancel pending activation requests"
+complete -c pim -f -n "not __fish_seen_subcommand_from $commands" \
+    -a renew      -d "request extension or renewal of eligibilities"
+complete -c pim -f -n "not __fish_seen_subcommand_from $commands" \
+    -a history    -d "view past activation and assignment requests"
+complete -c pim -f -n "not __fish_seen_subcommand_from $commands" \
+    -a role       -d "show the permissions a role grants"
 complete -c pim -f -n "not __fish_seen_subcommand_from $commands" \
     -a search     -d "list PIM-eligible subscriptions"
+complete -c pim -f -n "not __fish_seen_subcommand_from $commands" \
+    -a whoami     -d "show the signed-in account, tenant and token"
+complete -c pim -f -n "not __fish_seen_subcommand_from $commands" \
+    -a doctor     -d "check sign-in, permissions and config files"
+complete -c pim -f -n "not __fish_seen_subcommand_from $commands" \
+    -a cache      -d "manage the response cache"
 complete -c pim -f -n "not __fish_seen_subcommand_from $commands" \
     -a completion -d "print shell completion script"
 complete -c pim -f -n "not __fish_seen_subcommand_from $commands" \
     -a version    -d "print version"
 
+# cache subcommand actions
+complete -c pim -f -n "__fish_seen_subcommand_from cache" \
+    -a clear         -d "delete cached responses"
+
 # completion subcommand shells
 complete -c pim -f -n "__fish_seen_subcommand_from completion" \
     -a "bash zsh fish"
@@ -186,6 +365,22 @@ complete -c pim -n "__fish_seen_subcommand_from activate" \
     -a "30m 1h 2h 4h 8h"
 complete -c pim -n "__fish_seen_subcommand_from activate" \
     -l justification -s j -d "justification text"
+complete -c pim -n "__fish_seen_subcommand_from activate" \
+    -l ticket        -d "ticket number"
+complete -c pim -n "__fish_seen_subcommand_from activate" \
+    -l ticket-system -d "ticket system name"
+complete -c pim -n "__fish_seen_subcommand_from activate" \
+    -l at            -d "schedule activation start"
+complete -c pim -n "__fish_seen_subcommand_from activate" \
+    -l start         -d "schedule activation start"
+complete -c pim -n "__fish_seen_subcommand_from activate" \
+    -l wait          -d "wait until provisioned"
+complete -c pim -n "__fish_seen_subcommand_from activate" \
+    -l wait-timeout  -d "maximum provisioning wait" \
+    -a "1m 2m 5m 10m"
+complete -c pim -n "__fish_seen_subcommand_from activate" \
+    -l parallel      -d "targets activated at once" \
+    -a "1 2 4 8 16"
 complete -c pim -n "__fish_seen_subcommand_from activate" \
     -l yes -s y      -d "skip confirmation"
 complete -c pim -n "__fish_seen_subcommand_from activate" \
@@ -201,6 +396,9 @@ complete -c pim -n "__fish_seen_subcommand_from deactivate" \
     -l role -s r     -d "role name filter (repeatable)"
 complete -c pim -n "__fish_seen_subcommand_from deactivate" \
     -l scope         -d "scope path (repeatable)"
+complete -c pim -n "__fish_seen_subcommand_from deactivate" \
+    -l parallel      -d "targets deactivated at once" \
+    -a "1 2 4 8 16"
 complete -c pim -n "__fish_seen_subcommand_from deactivate" \
     -l headless      -d "non-TUI mode"
 complete -c pim -n "__fish_seen_subcommand_from deactivate" \
@@ -222,6 +420,71 @@ complete -c pim -n "__fish_seen_subcommand_from status" \
 complete -c pim -n "__fish_seen_subcommand_from status" \
     -l config-dir    -d "override config directory"
 
+# requests flags
+complete -c pim -n "__fish_seen_subcommand_from requests" \
+    -l role -s r     -d "role name filter (repeatable)"
+complete -c pim -n "__fish_seen_subcommand_from requests" \
+    -l scope         -d "scope path (repeatable)"
+complete -c pim -n "__fish_seen_subcommand_from requests" \
+    -l cancel        -d "cancel matching requests"
+complete -c pim -n "__fish_seen_subcommand_from requests" \
+    -l parallel      -d "requests cancelled at once" \
+    -a "1 2 4 8 16"
+complete -c pim -n "__fish_seen_subcommand_from requests" \
+    -l yes -s y      -d "cancel all without filters"
+complete -c pim -n "__fish_seen_subcommand_from requests" \
+    -l headless      -d "non-TUI mode"
+complete -c pim -n "__fish_seen_subcommand_from requests" \
+    -l output -s o   -d "output format" \
+    -a "table json"
+complete -c pim -n "__fish_seen_subcommand_from requests" \
+    -l config-dir    -d "override config directory"
+
+# renew flags
+complete -c pim -n "__fish_seen_subcommand_from renew" \
+    -l role -s r     -d "role name filter (repeatable)"
+complete -c pim -n "__fish_seen_subcommand_from renew" \
+    -l scope         -d "scope path (repeatable)"
+complete -c pim -n "__fish_seen_subcommand_from renew" \
+    -l justification -s j -d "justification text"
+complete -c pim -n "__fish_seen_subcommand_from renew" \
+    -l days          -d "requested eligibility length in days" \
+    -a "30 90 180 365"
+complete -c pim -n "__fish_seen_subcommand_from renew" \
+    -l parallel      -d "eligibilities renewed at once" \
+    -a "1 2 4 8 16"
+complete -c pim -n "__fish_seen_subcommand_from renew" \
+    -l config-dir    -d "override config directory"
+
+# history flags
+complete -c pim -n "__fish_seen_subcommand_from history" \
+    -l role -s r     -d "role name filter (repeatable)"
+complete -c pim -n "__fish_seen_subcommand_from history" \
+    -l scope         -d "scope path (repeatable)"
+complete -c pim -n "__fish_seen_subcommand_from history" \
+    -l since         -d "start of range" \
+    -a "7d 30d 90d"
+complete -c pim -n "__fish_seen_subcommand_from history" \
+    -l until         -d "end of range"
+complete -c pim -n "__fish_seen_subcommand_from history" \
+    -l headless      -d "non-TUI mode"
+complete -c pim -n "__fish_seen_subcommand_from history" \
+    -l output -s o   -d "output format" \
+    -a "table json csv"
+complete -c pim -n "__fish_seen_subcommand_from history" \
+    -l config-dir    -d "override config directory"
+
+# role flags
+complete -c pim -f -n "__fish_seen_subcommand_from role; and not __fish_seen_subcommand_from show" \
+    -a show          -d "list the permissions a role grants"
+complete -c pim -n "__fish_seen_subcommand_from role" \
+    -l scope         -d "scope path (repeatable)"
+complete -c pim -n "__fish_seen_subcommand_from role" \
+    -l output -s o   -d "output format" \
+    -a "table json"
+complete -c pim -n "__fish_seen_subcommand_from role" \
+    -l config-dir    -d "override config directory"
+
 # search flags
 complete -c pim -n "__fish_seen_subcommand_from search" \
     -l output -s o   -d "output format" \
@@ -231,6 +494,23 @@ complete -c pim -n "__fish_seen_subcommand_from search" \
 complete -c pim -n "__fish_seen_subcommand_from search" \
     -l config-dir    -d "override config directory"
 
+# whoami/doctor flags
+complete -c pim -n "__fish_seen_subcommand_from whoami doctor" \
+    -l output -s o   -d "output format" \
+    -a "table json"
+complete -c pim -n "__fish_seen_subcommand_from whoami doctor" \
+    -l config-dir    -d "override config directory"
+
+# global flags
+complete -c pim -l cloud -x -d "Azure cloud" \
+    -a "AzurePublic AzureUSGovernment AzureChina Custom"
+complete -c pim -l tenant -x -d "tenant ID to sign in to"
+complete -c pim -l auth -x -d "credential sources in order" \
+    -a "azure-cli powershell device-code browser environment workload-identity"
+complete -c pim -l refresh -d "ignore cached responses and fetch fresh ones"
+complete -c pim -l no-cache -d "do not read or write the response cache"
+complete -c pim -l debug -d "log every HTTP request"
+
 # version/help flags
 complete -c pim -n "__fish_seen_subcommand_from version help" \
     -l config-dir    -d "override config directory"
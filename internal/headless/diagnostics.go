@@ -0,0 +1,330 @@
+package headless
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jeircul/pim/internal/app"
+	"github.com/jeircul/pim/internal/azure"
+	"github.com/jeircul/pim/internal/state"
+)
+
+// tokenExpiryWarning is how close to expiry doctor warns about the ARM token.
+const tokenExpiryWarning = 5 * time.Minute
+
+// diagClient is the part of azure.Client pim whoami and pim doctor use.
+type diagClient interface {
+	GetCurrentUser(ctx context.Context) (*azure.User, error)
+	ARMTokenInfo(ctx context.Context) (azure.TokenInfo, error)
+	CheckCredentials(ctx context.Context) []azure.CredentialCheck
+	CheckEndpoints(ctx context.Context) []azure.EndpointCheck
+}
+
+var _ diagClient = (*azure.Client)(nil)
+
+func runWhoami(ctx context.Context, a *app.App, client diagClient, user *azure.User, out io.Writer) error {
+	info, err := client.ARMTokenInfo(ctx)
+	if err != nil {
+		return err
+	}
+	doc := WhoamiOutput{
+		SchemaVersion:     SchemaVersion,
+		Command:           app.CmdWhoami,
+		UserPrincipalName: user.UserPrincipalName,
+		DisplayName:       user.DisplayName,
+		ObjectID:          user.ID,
+		TenantID:          info.TenantID,
+		CredentialSource:  info.Source,
+		TokenExpiresOn:    info.ExpiresOn,
+	}
+	if a.Client != nil {
+		doc.Cloud = a.Client.Cloud().Name
+	}
+	if doc.ObjectID == "" {
+		doc.ObjectID = info.ObjectID
+	}
+	if a.Config.Output == app.OutputJSON {
+		return jsonOut(doc, out)
+	}
+
+	name := doc.UserPrincipalName
+	if doc.DisplayName != "" {
+		name += " (" + doc.DisplayName + ")"
+	}
+	tenant := doc.TenantID
+	if label := a.Store.TenantLabel(tenant); label != "" && label != tenant {
+		tenant += " (" + label + ")"
+	}
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "User:\t%s\n", name)
+	fmt.Fprintf(tw, "Object ID:\t%s\n", doc.ObjectID)
+	fmt.Fprintf(tw, "Tenant:\t%s\n", tenant)
+	fmt.Fprintf(tw, "Credential:\t%s\n", doc.CredentialSource)
+	fmt.Fprintf(tw, "Cloud:\t%s\n", doc.Cloud)
+	fmt.Fprintf(tw, "Token expires:\t%s\n", expiryDisplay(doc.TokenExpiresOn, time.Now()))
+	return tw.Flush()
+}
+
+// expiryDisplay formats a token expiry as "2006-01-02 15:04 (in 54m)".
+func expiryDisplay(t, now time.Time) string {
+	if t.IsZero() {
+		return "unknown"
+	}
+	left := t.Sub(now).Round(time.Minute)
+	if left <= 0 {
+		return t.Local().Format("2006-01-02 15:04") + " (expired)"
+	}
+	return fmt.Sprintf("%s (in %s)", t.Local().Format("2006-01-02 15:04"), strings.TrimSuffix(left.String(), "0s"))
+}
+
+// Doctor runs pim doctor: it checks the config directory and files, every
+// credential source in the chain, the ARM token, Graph /me and the
+// eligibility endpoints, and prints a report. It opens the App itself so
+// that config files which do not load are reported instead of aborting.
+// It returns an error when any check failed.
+func Doctor(ctx context.Context, cfg app.Config, version string, out io.Writer) error {
+	var checks []DoctorCheck
+	a, err := app.New(cfg, version)
+	if err != nil {
+		dir := cfg.ConfigDir
+		if dir == "" {
+			dir, _ = state.DefaultDir()
+		}
+		checks = append(checks, fileChecks(dir)...)
+		checks = append(checks, DoctorCheck{Name: "sign-in", Status: CheckSkip, Detail: "config files do not load: " + firstLine(err.Error())})
+		return writeDoctor(out, cfg.Output, checks)
+	}
+	defer a.Close()
+
+	checks = append(checks, fileChecks(a.Store.Dir())...)
+	if err := a.Connect(ctx); err != nil {
+		checks = append(checks, DoctorCheck{Name: "credential chain", Status: CheckFail, Detail: firstLine(err.Error()),
+			Hint: "check --auth, [auth] sources and the cloud settings; run 'az login' for the default chain"})
+	} else {
+		tenant := cfg.Tenant
+		if tenant == "" && !cfg.Demo {
+			tenant = os.Getenv("AZURE_TENANT_ID")
+		}
+		checks = append(checks, clientChecks(ctx, a.Client, tenant)...)
+	}
+	return writeDoctor(out, cfg.Output, checks)
+}
+
+// fileChecks reports on the config directory, config.toml and state.toml.
+func fileChecks(dir string) []DoctorCheck {
+	var checks []DoctorCheck
+	for _, fc := range state.Check(dir) {
+		name := "config directory"
+		if !fc.Dir {
+			name = fc.Path[strings.LastIndexAny(fc.Path, `/\`)+1:]
+		}
+		c := DoctorCheck{Name: name, Status: CheckPass, Detail: fc.Path}
+		switch {
+		case fc.Err != nil:
+			c.Status, c.Detail = CheckFail, firstLine(fc.Err.Error())
+			switch {
+			case fc.Dir:
+				c.Hint = "check the directory's owner and permissions, or pass --config-dir"
+			case name == "state.toml":
+				c.Hint = "move " + fc.Path + " aside; pim starts a fresh one (recent activations and queued deactivations are lost)"
+			default:
+				c.Hint = "fix the setting, or move " + fc.Path + " aside to use the defaults"
+			}
+		case !fc.Exists && fc.Dir:
+			c.Status, c.Detail = CheckWarn, fc.Path+" does not exist"
+			c.Hint = "pim creates it on the next run"
+		case !fc.Exists:
+			c.Detail = "not present; using defaults"
+		case runtime.GOOS != "windows" && fc.Mode.Perm()&0o022 != 0:
+			c.Status = CheckWarn
+			c.Detail = fmt.Sprintf("%s is writable by other users (%s)", fc.Path, fc.Mode.Perm())
+			if fc.Dir {
+				c.Hint = "chmod 700 " + fc.Path
+			} else {
+				c.Hint = "chmod 600 " + fc.Path
+			}
+		}
+		checks = append(checks, c)
+	}
+	return checks
+}
+
+// clientChecks checks the credential chain, the ARM token, Graph /me and
+// the eligibility endpoints. tenant is the tenant the caller asked for, if
+// any.
+func clientChecks(ctx context.Context, client diagClient, tenant string) []DoctorCheck {
+	var checks []DoctorCheck
+	creds := client.CheckCredentials(ctx)
+	winner := -1
+	for i, cc := range creds {
+		if cc.Skipped == "" && cc.Err == nil {
+			winner = i
+			break
+		}
+	}
+	for i, cc := range creds {
+		c := DoctorCheck{Name: "credential " + cc.Source}
+		switch {
+		case cc.Skipped != "":
+			c.Status, c.Detail = CheckSkip, cc.Skipped
+		case i == winner:
+			c.Status, c.Detail = CheckPass, "signs in; token expires "+expiryDisplay(cc.ExpiresOn, time.Now())
+		case winner >= 0 && i > winner:
+			c.Status, c.Detail = CheckSkip, "unused: "+creds[winner].Source+" comes first in the chain"
+		default:
+			c.Status, c.Detail = CheckFail, firstLine(cc.Err.Error())
+			if winner >= 0 {
+				c.Status = CheckWarn
+			}
+			c.Hint = credentialHint(cc.Source, tenant)
+		}
+		checks = append(checks, c)
+	}
+	if winner < 0 {
+		return append(checks, DoctorCheck{Name: "ARM token", Status: CheckSkip, Detail: "no credential source produced a token"})
+	}
+
+	tokenCheck := DoctorCheck{Name: "ARM token", Status: CheckPass}
+	info, err := client.ARMTokenInfo(ctx)
+	switch {
+	case err != nil:
+		tokenCheck.Status, tokenCheck.Detail = CheckFail, firstLine(err.Error())
+		tokenCheck.Hint = credentialHint(creds[winner].Source, tenant)
+	case isTenantID(tenant) && info.TenantID != "" && !strings.EqualFold(tenant, info.TenantID):
+		tokenCheck.Status = CheckFail
+		tokenCheck.Detail = fmt.Sprintf("token is for tenant %s, not %s", info.TenantID, tenant)
+		tokenCheck.Hint = fmt.Sprintf("sign in to the right tenant ('az login --tenant %s'), or drop --tenant / AZURE_TENANT_ID", tenant)
+	default:
+		tokenCheck.Detail = fmt.Sprintf("tenant %s, expires %s", info.TenantID, expiryDisplay(info.ExpiresOn, time.Now()))
+		if !info.ExpiresOn.IsZero() && time.Until(info.ExpiresOn) < tokenExpiryWarning {
+			tokenCheck.Status = CheckWarn
+			tokenCheck.Hint = "the token is about to expire; sign in again if requests start failing"
+		}
+	}
+	checks = append(checks, tokenCheck)
+	if err != nil {
+		return checks
+	}
+
+	meCheck := DoctorCheck{Name: "Graph /me", Status: CheckPass}
+	if user, err := client.GetCurrentUser(ctx); err != nil {
+		meCheck.Status, meCheck.Detail = CheckFail, firstLine(err.Error())
+		meCheck.Hint = apiHint(err, "the account cannot read its own profile (User.Read); ask an administrator to grant consent")
+	} else {
+		meCheck.Detail = fmt.Sprintf("%s (%s)", user.UserPrincipalName, user.ID)
+	}
+	checks = append(checks, meCheck)
+
+	for i, ec := range client.CheckEndpoints(ctx) {
+		c := DoctorCheck{Name: ec.Name, Status: CheckPass, Detail: fmt.Sprintf("%d eligible roles", ec.Roles)}
+		if ec.Err != nil {
+			c.Status, c.Detail = CheckFail, firstLine(ec.Err.Error())
+			if i == 0 {
+				c.Hint = apiHint(ec.Err, "the account cannot read its role eligibility schedules; check --tenant")
+			} else {
+				// Directory roles and groups are optional: resource roles
+				// still work without them.
+				if isForbidden(ec.Err) {
+					c.Status = CheckWarn
+				}
+				permission := "RoleEligibilitySchedule.Read.Directory"
+				if i == 2 {
+					permission = "PrivilegedEligibilitySchedule.Read.AzureADGroup"
+				}
+				c.Hint = apiHint(ec.Err, "pim skips this source; the Graph token lacks "+permission+
+					" consent (Azure resource roles are unaffected)")
+			}
+		}
+		checks = append(checks, c)
+	}
+	return checks
+}
+
+// isTenantID reports whether s is a tenant GUID rather than empty or a
+// domain name, which the token's tid claim cannot be compared with.
+func isTenantID(s string) bool {
+	_, err := uuid.Parse(s)
+	return err == nil
+}
+
+// credentialHint says how to make source produce a token.
+func credentialHint(source, tenant string) string {
+	switch source {
+	case azure.SourceAzureCLI:
+		if tenant != "" {
+			return "run 'az login --tenant " + tenant + "'"
+		}
+		return "run 'az login'"
+	case azure.SourcePowerShell:
+		if tenant != "" {
+			return "run 'Connect-AzAccount -Tenant " + tenant + "'"
+		}
+		return "run 'Connect-AzAccount'"
+	case azure.SourceEnvironment:
+		return "set AZURE_TENANT_ID, AZURE_CLIENT_ID and AZURE_CLIENT_SECRET (or AZURE_CLIENT_CERTIFICATE_PATH)"
+	case azure.SourceWorkloadIdentity:
+		return "set AZURE_TENANT_ID, AZURE_CLIENT_ID and AZURE_FEDERATED_TOKEN_FILE"
+	}
+	return "remove " + source + " from --auth or [auth] sources if it is not used"
+}
+
+// apiHint returns a hint for an API failure; forbidden is used for HTTP 403.
+func apiHint(err error, forbidden string) string {
+	switch {
+	case azure.IsAuthFailure(err):
+		return "the token was rejected; sign in again ('az login')"
+	case isForbidden(err):
+		return forbidden
+	}
+	return "check network access to Azure and retry"
+}
+
+func isForbidden(err error) bool {
+	var apiErr *azure.APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusForbidden
+}
+
+// writeDoctor prints the report and returns an error when a check failed.
+func writeDoctor(out io.Writer, format app.OutputFormat, checks []DoctorCheck) error {
+	doc := DoctorOutput{SchemaVersion: SchemaVersion, Command: app.CmdDoctor, Checks: checks}
+	for _, c := range checks {
+		switch c.Status {
+		case CheckFail:
+			doc.Failed++
+		case CheckWarn:
+			doc.Warnings++
+		}
+	}
+	if format == app.OutputJSON {
+		if err := jsonOut(doc, out); err != nil {
+			return err
+		}
+	} else {
+		tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		for _, c := range checks {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", strings.ToUpper(c.Status), c.Name, c.Detail)
+			if c.Hint != "" {
+				fmt.Fprintf(tw, "\t\t→ %s\n", c.Hint)
+			}
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		if doc.Failed == 0 {
+			fmt.Fprintf(out, "\nAll checks passed (%d warnings).\n", doc.Warnings)
+		}
+	}
+	if doc.Failed > 0 {
+		return fmt.Errorf("doctor: %d of %d checks failed", doc.Failed, len(checks))
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package headless
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jeircul/pim/internal/app"
+	"github.com/jeircul/pim/internal/azure"
+)
+
+type diagMock struct {
+	user      *azure.User
+	info      azure.TokenInfo
+	creds     []azure.CredentialCheck
+	endpoints []azure.EndpointCheck
+}
+
+func (m *diagMock) GetCurrentUser(context.Context) (*azure.User, error) { return m.user, nil }
+func (m *diagMock) ARMTokenInfo(context.Context) (azure.TokenInfo, error) {
+	return m.info, nil
+}
+func (m *diagMock) CheckCredentials(context.Context) []azure.CredentialCheck { return m.creds }
+func (m *diagMock) CheckEndpoints(context.Context) []azure.EndpointCheck     { return m.endpoints }
+
+func TestRunWhoamiJSON(t *testing.T) {
+	user := &azure.User{ID: "uid-1", UserPrincipalName: "user@example.com", DisplayName: "User One"}
+	client := &diagMock{info: azure.TokenInfo{
+		Source:    azure.SourceAzureCLI,
+		TenantID:  "tid-1",
+		ObjectID:  "oid-from-token",
+		ExpiresOn: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+	}}
+	a := newTestApp(t, app.Config{Command: app.CmdWhoami, Output: app.OutputJSON})
+
+	var out bytes.Buffer
+	if err := runWhoami(t.Context(), a, client, user, &out); err != nil {
+		t.Fatalf("runWhoami: %v", err)
+	}
+	var doc WhoamiOutput
+	if err := json.Unmarshal(out.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out.String(), err)
+	}
+	if doc.SchemaVersion != SchemaVersion || doc.Command != app.CmdWhoami {
+		t.Errorf("header = %d %q", doc.SchemaVersion, doc.Command)
+	}
+	if doc.UserPrincipalName != "user@example.com" || doc.ObjectID != "uid-1" || doc.TenantID != "tid-1" {
+		t.Errorf("identity = %+v", doc)
+	}
+	if doc.CredentialSource != azure.SourceAzureCLI || !doc.TokenExpiresOn.Equal(client.info.ExpiresOn) {
+		t.Errorf("token = %q %v", doc.CredentialSource, doc.TokenExpiresOn)
+	}
+}
+
+func TestClientChecks(t *testing.T) {
+	const tenant = "11111111-1111-1111-1111-111111111111"
+	forbidden := &azure.APIError{StatusCode: http.StatusForbidden, Code: "Forbidden", Message: "denied"}
+	client := &diagMock{
+		user: &azure.User{ID: "uid-1", UserPrincipalName: "user@example.com"},
+		info: azure.TokenInfo{TenantID: tenant, ExpiresOn: time.Now().Add(time.Hour)},
+		creds: []azure.CredentialCheck{
+			{Source: azure.SourceEnvironment, Err: errors.New("missing AZURE_CLIENT_ID")},
+			{Source: azure.SourceAzureCLI, ExpiresOn: time.Now().Add(time.Hour)},
+			{Source: azure.SourcePowerShell},
+		},
+		endpoints: []azure.EndpointCheck{
+			{Name: "ARM role eligibility", Roles: 3},
+			{Name: "Graph directory role eligibility", Err: forbidden},
+			{Name: "Graph group eligibility", Err: errors.New("connection reset")},
+		},
+	}
+
+	got := map[string]string{}
+	for _, c := range clientChecks(t.Context(), client, tenant) {
+		got[c.Name] = c.Status
+	}
+	want := map[string]string{
+		"credential " + azure.SourceEnvironment: CheckWarn,
+		"credential " + azure.SourceAzureCLI:    CheckPass,
+		"credential " + azure.SourcePowerShell:  CheckSkip,
+		"ARM token":                             CheckPass,
+		"Graph /me":                             CheckPass,
+		"ARM role eligibility":                  CheckPass,
+		"Graph directory role eligibility":      CheckWarn,
+		"Graph group eligibility":               CheckFail,
+	}
+	for name, status := range want {
+		if got[name] != status {
+			t.Errorf("%s = %q, want %q", name, got[name], status)
+		}
+	}
+
+	client.info.TenantID = "22222222-2222-2222-2222-222222222222"
+	for _, c := range clientChecks(t.Context(), client, tenant) {
+		if c.Name == "ARM token" && c.Status != CheckFail {
+			t.Errorf("ARM token for another tenant = %q, want %q", c.Status, CheckFail)
+		}
+	}
+}
+
+func TestDoctorDemo(t *testing.T) {
+	cfg := app.Config{Command: app.CmdDoctor, Demo: true, ConfigDir: t.TempDir(), Output: app.OutputJSON}
+	var out bytes.Buffer
+	if err := Doctor(t.Context(), cfg, "test", &out); err != nil {
+		t.Fatalf("Doctor: %v\n%s", err, out.String())
+	}
+	var doc DoctorOutput
+	if err := json.Unmarshal(out.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out.String(), err)
+	}
+	if doc.Failed != 0 || len(doc.Checks) == 0 {
+		t.Errorf("Failed = %d, %d checks", doc.Failed, len(doc.Checks))
+	}
+}
+
+func TestDoctorBadConfig(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "config.toml"), []byte("[retry\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := app.Config{Command: app.CmdDoctor, Demo: true, ConfigDir: dir}
+	var out bytes.Buffer
+	err := Doctor(t.Context(), cfg, "test", &out)
+	if err == nil || !strings.Contains(err.Error(), "checks failed") {
+		t.Fatalf("err = %v, want a failed check", err)
+	}
+	if !strings.Contains(out.String(), "FAIL  config.toml") || !strings.Contains(out.String(), "→ fix the setting") {
+		t.Errorf("output does not report config.toml:\n%s", out.String())
+	}
+}
@@ -0,0 +1,62 @@
+package headless
+
+import (
+	"context"
+
+	"github.com/jeircul/pim/internal/azure"
+)
+
+// fakeClient implements ClientAPI with empty, successful results. Test mocks
+// embed it and override only the methods their tests exercise.
+type fakeClient struct{}
+
+var _ ClientAPI = fakeClient{}
+
+func (fakeClient) GetCurrentUser(context.Context) (*azure.User, error) {
+	return &azure.User{}, nil
+}
+func (fakeClient) GetActiveAssignments(context.Context) ([]azure.ActiveAssignment, error) {
+	return nil, nil
+}
+func (fakeClient) GetEligibleRoles(context.Context) ([]azure.Role, error) {
+	return nil, nil
+}
+func (fakeClient) ActivateRole(context.Context, azure.ActivationRequest) (*azure.ScheduleResponse, error) {
+	return &azure.ScheduleResponse{}, nil
+}
+func (fakeClient) DeactivateRole(context.Context, azure.ActiveAssignment, string) (*azure.ScheduleResponse, error) {
+	return &azure.ScheduleResponse{}, nil
+}
+func (fakeClient) GetActivationPolicy(context.Context, azure.Role, string) (azure.ActivationPolicy, error) {
+	return azure.ActivationPolicy{}, nil
+}
+func (fakeClient) WaitForActivation(context.Context, azure.ActivationRequest, *azure.ScheduleResponse, func(string)) error {
+	return nil
+}
+func (fakeClient) GetScheduledAssignments(context.Context) ([]azure.ActiveAssignment, error) {
+	return nil, nil
+}
+func (fakeClient) GetPendingRequests(context.Context) ([]azure.AssignmentRequest, error) {
+	return nil, nil
+}
+func (fakeClient) CancelRequest(context.Context, azure.AssignmentRequest) error {
+	return nil
+}
+func (fakeClient) GetExpiredEligibilities(context.Context) ([]azure.Role, error) {
+	return nil, nil
+}
+func (fakeClient) RenewEligibility(context.Context, azure.RenewalRequest) (*azure.ScheduleResponse, error) {
+	return &azure.ScheduleResponse{}, nil
+}
+func (fakeClient) GetRequestHistory(context.Context, azure.HistoryFilter) ([]azure.AssignmentRequest, error) {
+	return nil, nil
+}
+func (fakeClient) GetRoleDefinition(context.Context, string, string) (*azure.RoleDefinition, error) {
+	return &azure.RoleDefinition{}, nil
+}
+func (fakeClient) GetRoleDefinitionByName(context.Context, string, string) (*azure.RoleDefinition, error) {
+	return &azure.RoleDefinition{}, nil
+}
+func (fakeClient) ListAllSubscriptionsUnderMG(context.Context, string) ([]azure.Subscription, map[string]string, []string, error) {
+	return nil, nil, nil, nil
+}
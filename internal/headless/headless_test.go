@@ -2,6 +2,7 @@ package headless
 
 import (
 	"context"
+	"io"
 	"testing"
 	"time"
 
@@ -39,7 +40,7 @@ func TestFilterRoles(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			got, err := filterRoles(context.Background(), &mockClient{}, roles, tc.roleFilters, tc.scopeFilters)
+			got, err := filterRoles(context.Background(), &mockClient{}, roles, tc.roleFilters, tc.scopeFilters, io.Discard)
 			if tc.wantErr {
 				if err == nil {
 					t.Errorf("filterRoles() want error, got nil")
@@ -63,7 +64,7 @@ func TestFilterRolesAmbiguous(t *testing.T) {
 		{RoleName: "Administrator (Privileged)", Scope: "/subscriptions/aaa", ScopeDisplay: "My-Sub-A"},
 	}
 
-	_, err := filterRoles(context.Background(), &mockClient{}, roles, []string{"admin"}, nil)
+	_, err := filterRoles(context.Background(), &mockClient{}, roles, []string{"admin"}, nil, io.Discard)
 	if err == nil {
 		t.Fatal("expected ambiguity error for 'admin' matching multiple roles, got nil")
 	}
@@ -75,7 +76,7 @@ func TestFilterRolesSingleSubstringAccepted(t *testing.T) {
 		{RoleName: "Reader", Scope: "/subscriptions/aaa", ScopeDisplay: "My-Sub-A"},
 	}
 
-	got, err := filterRoles(context.Background(), &mockClient{}, roles, []string{"ontrib"}, nil)
+	got, err := filterRoles(context.Background(), &mockClient{}, roles, []string{"ontrib"}, nil, io.Discard)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -90,7 +91,7 @@ func TestFilterRolesExactNotSubstring(t *testing.T) {
 		{RoleName: "Reader (privileged)", Scope: "/subscriptions/aaa", ScopeDisplay: "My-Sub-A"},
 	}
 
-	got, err := filterRoles(context.Background(), &mockClient{}, roles, []string{"Reader"}, nil)
+	got, err := filterRoles(context.Background(), &mockClient{}, roles, []string{"Reader"}, nil, io.Discard)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -105,7 +106,7 @@ func TestFilterScopeAmbiguous(t *testing.T) {
 		{RoleName: "Owner", Scope: "/subscriptions/bbb", ScopeDisplay: "prod-west"},
 	}
 
-	_, err := filterRoles(context.Background(), &mockClient{}, roles, []string{"Owner"}, []string{"prod"})
+	_, err := filterRoles(context.Background(), &mockClient{}, roles, []string{"Owner"}, []string{"prod"}, io.Discard)
 	if err == nil {
 		t.Fatal("expected ambiguity error for 'prod' matching 'prod-east' and 'prod-west', got nil")
 	}
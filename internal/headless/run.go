@@ -2,10 +2,13 @@ package headless
 
 import (
 	"context"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"slices"
 	"strings"
 	"text/tabwriter"
 	"time"
@@ -20,60 +23,155 @@ type ClientAPI interface {
 	GetCurrentUser(ctx context.Context) (*azure.User, error)
 	GetActiveAssignments(ctx context.Context) ([]azure.ActiveAssignment, error)
 	GetEligibleRoles(ctx context.Context) ([]azure.Role, error)
-	ActivateRole(ctx context.Context, role azure.Role, principalID, justification string, minutes int, targetScope string) (*azure.ScheduleResponse, error)
+	ActivateRole(ctx context.Context, req azure.ActivationRequest) (*azure.ScheduleResponse, error)
 	DeactivateRole(ctx context.Context, assignment azure.ActiveAssignment, principalID string) (*azure.ScheduleResponse, error)
+	GetActivationPolicy(ctx context.Context, role azure.Role, scope string) (azure.ActivationPolicy, error)
+	WaitForActivation(ctx context.Context, req azure.ActivationRequest, resp *azure.ScheduleResponse, onStatus func(string)) error
+	GetScheduledAssignments(ctx context.Context) ([]azure.ActiveAssignment, error)
+	GetPendingRequests(ctx context.Context) ([]azure.AssignmentRequest, error)
+	CancelRequest(ctx context.Context, req azure.AssignmentRequest) error
+	GetExpiredEligibilities(ctx context.Context) ([]azure.Role, error)
+	RenewEligibility(ctx context.Context, req azure.RenewalRequest) (*azure.ScheduleResponse, error)
+	GetRequestHistory(ctx context.Context, f azure.HistoryFilter) ([]azure.AssignmentRequest, error)
+	GetRoleDefinition(ctx context.Context, scope, roleDefinitionID string) (*azure.RoleDefinition, error)
+	GetRoleDefinitionByName(ctx context.Context, scope, name string) (*azure.RoleDefinition, error)
 	ListAllSubscriptionsUnderMG(ctx context.Context, mgID string) ([]azure.Subscription, map[string]string, []string, error)
 }
 
-var _ ClientAPI = (*azure.Client)(nil)
+var (
+	_ ClientAPI = (*azure.Client)(nil)
+	_ ClientAPI = (*app.CachedClient)(nil)
+)
 
 // Run executes the requested command without a TUI and returns an exit error if any.
 func Run(ctx context.Context, a *app.App) error {
-	client := a.Client
-
-	user, err := client.GetCurrentUser(ctx)
+	errOut := io.Writer(os.Stderr)
+	user, err := a.Client.GetCurrentUser(ctx)
 	if err != nil {
 		return fmt.Errorf("get current user: %w", err)
 	}
+	if a.Config.Output == app.OutputTable {
+		if src := a.Client.CredentialSource(); src != "" {
+			fmt.Fprintf(errOut, "signed in as %s via %s\n", user.UserPrincipalName, src)
+		}
+	}
+	client := a.Cached(a.Client, user.ID)
+	if retriesQueue(a.Config.Command) {
+		runQueuedDeactivations(ctx, a, client, user, errOut)
+	}
 
 	switch a.Config.Command {
 	case app.CmdStatus:
-		return runStatus(ctx, a, client, user, os.Stdout)
+		return runStatus(ctx, a, client, user, os.Stdout, errOut)
 	case app.CmdDeactivate:
-		return runDeactivate(ctx, a, client, user, os.Stdout)
+		return runDeactivate(ctx, a, client, user, os.Stdout, errOut)
 	case app.CmdActivate:
-		return runActivate(ctx, a, client, user, os.Stdout)
+		return runActivate(ctx, a, client, user, os.Stdout, errOut)
+	case app.CmdRequests:
+		return runRequests(ctx, a, client, os.Stdout)
+	case app.CmdRenew:
+		return runRenew(ctx, a, client, user, os.Stdout, errOut)
+	case app.CmdHistory:
+		return runHistory(ctx, a, client, os.Stdout)
+	case app.CmdRole:
+		return runRoleShow(ctx, a, client, os.Stdout)
 	case app.CmdSearch:
-		return runSearchWithErr(ctx, a, client, os.Stdout, os.Stderr)
+		return runSearchWithErr(ctx, a, client, os.Stdout, errOut)
+	case app.CmdWhoami:
+		return runWhoami(ctx, a, a.Client, user, os.Stdout)
 	default:
-		return runStatus(ctx, a, client, user, os.Stdout)
+		return runStatus(ctx, a, client, user, os.Stdout, errOut)
 	}
 }
 
-func runStatus(ctx context.Context, a *app.App, client ClientAPI, user *azure.User, out io.Writer) error {
+func runStatus(ctx context.Context, a *app.App, client ClientAPI, user *azure.User, out, errOut io.Writer) error {
 	assignments, err := client.GetActiveAssignments(ctx)
 	if err != nil {
 		return fmt.Errorf("get active assignments: %w", err)
 	}
+	scheduled, err := client.GetScheduledAssignments(ctx)
+	if err != nil {
+		fmt.Fprintf(errOut, "warning: get scheduled assignments: %v\n", err)
+	}
+	assignments = append(assignments, scheduled...)
 
+	queued := a.QueuedDeactivations(a.TenantID(), user.ID)
 	if a.Config.Output == app.OutputJSON {
-		return jsonOut(assignments, out)
+		doc := StatusOutput{
+			SchemaVersion:       SchemaVersion,
+			Command:             app.CmdStatus,
+			Assignments:         make([]AssignmentOutput, 0, len(assignments)),
+			QueuedDeactivations: make([]QueuedDeactivationOutput, 0, len(queued)),
+		}
+		for _, as := range assignments {
+			doc.Assignments = append(doc.Assignments, assignmentOutput(as))
+		}
+		for _, q := range queued {
+			doc.QueuedDeactivations = append(doc.QueuedDeactivations, queuedDeactivationOutput(q))
+		}
+		return jsonOut(doc, out)
 	}
 
-	if len(assignments) == 0 {
+	if len(assignments) == 0 && len(queued) == 0 {
 		fmt.Fprintln(out, "No active PIM elevations.")
 		return nil
 	}
 
 	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(tw, "ROLE\tSCOPE\tEXPIRES")
-	for _, a := range assignments {
-		fmt.Fprintf(tw, "%s\t%s\t%s\n", a.RoleName, a.ScopeDisplay, a.ExpiryDisplay())
+	if len(assignments) > 0 {
+		fmt.Fprintln(tw, "ROLE\tSCOPE\tEXPIRES")
+		for _, a := range assignments {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", a.RoleName, a.ScopeDisplay, a.ExpiryDisplay())
+		}
+	}
+	if len(queued) > 0 {
+		if len(assignments) > 0 {
+			fmt.Fprintln(tw)
+		}
+		fmt.Fprintln(tw, "QUEUED DEACTIVATION\tSCOPE\tDEACTIVATES")
+		for _, q := range queued {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", q.Role, q.ScopeDisplay, queuedDisplay(q))
+		}
 	}
 	return tw.Flush()
 }
 
-func runDeactivate(ctx context.Context, a *app.App, client ClientAPI, user *azure.User, out io.Writer) error {
+// queuedDisplay describes when a queued deactivation runs.
+func queuedDisplay(q state.QueuedDeactivation) string {
+	if q.LastError != "" {
+		return "retrying: " + q.LastError
+	}
+	return "after " + q.NotBefore.Local().Format("15:04")
+}
+
+// retriesQueue reports whether command retries due queued deactivations
+// first: status, activate and deactivate change or show assignments, while
+// the read-only commands and diagnostics leave them alone.
+func retriesQueue(command string) bool {
+	switch command {
+	case app.CmdStatus, app.CmdActivate, app.CmdDeactivate, "":
+		return true
+	}
+	return false
+}
+
+// runQueuedDeactivations retries the due deactivations queued by earlier
+// runs and reports the outcome on errOut.
+func runQueuedDeactivations(ctx context.Context, a *app.App, client ClientAPI, user *azure.User, errOut io.Writer) {
+	results, err := a.RunQueuedDeactivations(ctx, client, a.TenantID(), user.ID, time.Now())
+	for _, r := range results {
+		if r.Err != nil {
+			fmt.Fprintf(errOut, "queued deactivation of %s @ %s failed: %v\n", r.Entry.Role, r.Entry.ScopeDisplay, r.Err)
+			continue
+		}
+		fmt.Fprintf(errOut, "deactivated queued %s @ %s\n", r.Entry.Role, r.Entry.ScopeDisplay)
+	}
+	if err != nil {
+		fmt.Fprintf(errOut, "warning: save state: %v\n", err)
+	}
+}
+
+func runDeactivate(ctx context.Context, a *app.App, client ClientAPI, user *azure.User, out, errOut io.Writer) error {
 	if len(a.Config.Roles) == 0 && len(a.Config.Scopes) == 0 && !a.Config.Yes {
 		return fmt.Errorf("--headless deactivate requires --role or --scope; use --yes to deactivate all")
 	}
@@ -85,11 +183,11 @@ func runDeactivate(ctx context.Context, a *app.App, client ClientAPI, user *azur
 
 	all, inherited, permanent := partitionDeactivatable(assignments)
 	for _, inh := range inherited {
-		fmt.Fprintf(os.Stderr, "skipping inherited assignment: %s @ %s (cannot self-deactivate group-inherited roles)\n",
+		fmt.Fprintf(errOut, "skipping inherited assignment: %s @ %s (cannot self-deactivate group-inherited roles)\n",
 			inh.RoleName, inh.ScopeDisplay)
 	}
 	for _, p := range permanent {
-		fmt.Fprintf(os.Stderr, "skipping permanent assignment: %s @ %s (no expiry; not PIM-activated)\n",
+		fmt.Fprintf(errOut, "skipping permanent assignment: %s @ %s (no expiry; not PIM-activated)\n",
 			p.RoleName, p.ScopeDisplay)
 	}
 
@@ -99,25 +197,57 @@ func runDeactivate(ctx context.Context, a *app.App, client ClientAPI, user *azur
 	}
 	if len(targets) == 0 {
 		if len(a.Config.Roles) > 0 || len(a.Config.Scopes) > 0 {
-			return fmt.Errorf("no active assignments match --role / --scope filters")
+			return app.Mark(app.ErrNoMatch, errors.New("no active assignments match --role / --scope filters"))
 		}
 		fmt.Fprintln(out, "No matching active assignments.")
 		return nil
 	}
 
-	var lastErr error
-	for _, assignment := range targets {
-		if _, err := client.DeactivateRole(ctx, assignment, user.ID); err != nil {
-			fmt.Fprintf(os.Stderr, "deactivate %s@%s: %v\n", assignment.RoleName, assignment.ScopeDisplay, err)
-			lastErr = err
-			continue
+	p := newProgress(out, a.Config.Output)
+	results := make([]TargetResult, len(targets))
+	forEachTarget(len(targets), a.Config.Parallel, func(i int) {
+		results[i] = deactivateTarget(ctx, a, client, user, targets[i], p)
+	})
+	if err := writeResults(out, a.Config.Output, app.CmdDeactivate, results); err != nil {
+		return err
+	}
+	return resultsError("deactivate", results)
+}
+
+// deactivateTarget deactivates one assignment. Azure refuses deactivation in
+// the first minutes of an activation; such targets are queued for the next
+// run after that.
+func deactivateTarget(ctx context.Context, a *app.App, client ClientAPI, user *azure.User, assignment azure.ActiveAssignment, p *progress) TargetResult {
+	r := TargetResult{
+		Role:             assignment.RoleName,
+		RoleDefinitionID: assignment.RoleDefinitionID,
+		Scope:            assignment.Scope,
+		ScopeDisplay:     azure.DefaultScopeDisplay(assignment.Scope, assignment.ScopeDisplay),
+		MemberType:       assignment.MemberType,
+		StartDateTime:    assignment.StartDateTime,
+		EndDateTime:      assignment.EndDateTime,
+	}
+	resp, err := client.DeactivateRole(ctx, assignment, user.ID)
+	if err == nil {
+		r.Status, r.RequestID, r.RequestStatus = StatusDeactivated, requestID(resp), resp.Status()
+		p.printf("Deactivated: %s @ %s\n", assignment.RoleName, assignment.ScopeDisplay)
+		return r
+	}
+	if azure.IsActiveDurationTooShort(err) {
+		q, qErr := a.QueueDeactivation(a.TenantID(), user.ID, assignment, time.Now())
+		if qErr == nil {
+			r.Status = StatusQueued
+			p.printf("Queued: %s @ %s (Azure allows deactivation from %s; pim deactivates it on its next run after that)\n",
+				assignment.RoleName, assignment.ScopeDisplay, q.NotBefore.Local().Format("15:04"))
+			return r
 		}
-		fmt.Fprintf(out, "Deactivated: %s @ %s\n", assignment.RoleName, assignment.ScopeDisplay)
+		err = fmt.Errorf("%w (queue deactivation: %v)", err, qErr)
 	}
-	return lastErr
+	r.fail(err)
+	return r
 }
 
-func runActivate(ctx context.Context, a *app.App, client ClientAPI, user *azure.User, out io.Writer) error {
+func runActivate(ctx context.Context, a *app.App, client ClientAPI, user *azure.User, out, errOut io.Writer) error {
 	cfg := a.Config
 	if !cfg.HasRoleFilter() {
 		return fmt.Errorf("--headless activate requires --role")
@@ -134,50 +264,501 @@ func runActivate(ctx context.Context, a *app.App, client ClientAPI, user *azure.
 	if err != nil {
 		return err
 	}
+	start, err := azure.ParseStartTime(cfg.StartStr, time.Now())
+	if err != nil {
+		return err
+	}
 
 	roles, err := client.GetEligibleRoles(ctx)
 	if err != nil {
 		return fmt.Errorf("get eligible roles: %w", err)
 	}
 
-	targets, err := filterRoles(ctx, client, roles, cfg.Roles, cfg.Scopes)
+	targets, err := filterRoles(ctx, client, roles, cfg.Roles, cfg.Scopes, errOut)
 	if err != nil {
 		return err
 	}
 	if len(targets) == 0 {
-		return fmt.Errorf("no eligible roles match the specified --role / --scope filters")
+		return app.Mark(app.ErrNoMatch, errors.New("no eligible roles match the specified --role / --scope filters"))
+	}
+	ticket := azure.TicketInfo{TicketNumber: cfg.Ticket, TicketSystem: cfg.TicketSystem}
+	reqs := make([]azure.ActivationRequest, len(targets))
+	for i, match := range targets {
+		reqs[i] = azure.ActivationRequest{
+			Role:          match.role,
+			PrincipalID:   user.ID,
+			Justification: cfg.Justification,
+			Minutes:       minutes,
+			TargetScope:   azure.NormalizeScope(match.scope),
+			Ticket:        ticket,
+			Start:         start,
+		}
+	}
+	waitCtx := ctx
+	if cfg.Wait {
+		timeout := cfg.WaitTimeout
+		if timeout <= 0 {
+			timeout = app.DefaultWaitTimeout
+		}
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
 	}
 
-	var lastErr error
-	for _, match := range targets {
-		scope := azure.NormalizeScope(match.scope)
-		_, err := client.ActivateRole(ctx, match.role, user.ID, cfg.Justification, minutes, scope)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "activate %s@%s: %v\n", match.role.RoleName, scope, err)
-			lastErr = err
+	p := newProgress(out, cfg.Output)
+	results := make([]TargetResult, len(reqs))
+	forEachTarget(len(reqs), cfg.Parallel, func(i int) {
+		results[i] = activateTarget(ctx, waitCtx, a, client, reqs[i], timeStr, p, errOut)
+	})
+
+	a.Store.AddRecentJustification(cfg.Justification)
+	saveErr := a.Store.SaveState()
+	if err := writeResults(out, cfg.Output, app.CmdActivate, results); err != nil {
+		return err
+	}
+	if err := resultsError("activate", results); err != nil {
+		return err
+	}
+	if saveErr != nil {
+		return saveErr
+	}
+	return outcomeError(results)
+}
+
+// activateTarget checks one activation against its policy, submits it and,
+// with --wait, waits for it to be provisioned. A policy violation fails only
+// this target. Successful activations are recorded as recent activations.
+func activateTarget(ctx, waitCtx context.Context, a *app.App, client ClientAPI, req azure.ActivationRequest, timeStr string, p *progress, errOut io.Writer) TargetResult {
+	cfg := a.Config
+	scope := req.TargetScope
+	start := req.Start
+	if start.IsZero() {
+		start = time.Now()
+	}
+	display := azure.DefaultScopeDisplay(scope, "")
+	if strings.EqualFold(scope, req.Role.Scope) {
+		display = azure.DefaultScopeDisplay(scope, req.Role.ScopeDisplay)
+	}
+	r := TargetResult{
+		Role:             req.Role.RoleName,
+		RoleDefinitionID: req.Role.RoleDefinitionID,
+		Scope:            scope,
+		ScopeDisplay:     display,
+		EligibilityScope: req.Role.Scope,
+		MemberType:       req.Role.MemberType,
+		StartDateTime:    start.UTC().Format(time.RFC3339),
+		EndDateTime:      start.Add(time.Duration(req.Minutes) * time.Minute).UTC().Format(time.RFC3339),
+	}
+	if err := checkPolicy(ctx, client, req, errOut); err != nil {
+		r.fail(err)
+		return r
+	}
+	resp, err := client.ActivateRole(ctx, req)
+	if err != nil {
+		r.fail(err)
+		return r
+	}
+	r.RequestID, r.RequestStatus = requestID(resp), resp.Status()
+	if resp.AlreadyActive {
+		r.Status = StatusAlreadyActive
+		p.printf("Already active: %s @ %s\n", req.Role.RoleName, scope)
+		return r
+	}
+	if azure.IsAwaitingApproval(resp.Status()) {
+		r.Status = StatusPendingApproval
+		p.printf("Pending approval: %s @ %s for %s (see 'pim requests')\n", req.Role.RoleName, scope, timeStr)
+		return r
+	}
+	if cfg.Wait && req.Start.IsZero() {
+		onStatus := func(status string) {
+			fmt.Fprintf(errOut, "%s @ %s: %s\n", req.Role.RoleName, scope, status)
+		}
+		if err := client.WaitForActivation(waitCtx, req, resp, onStatus); err != nil {
+			r.fail(fmt.Errorf("wait: %w", err))
+			return r
+		}
+		r.RequestStatus = azure.StatusProvisioned
+	}
+	if !req.Start.IsZero() {
+		r.Status = StatusScheduled
+		p.printf("Scheduled: %s @ %s from %s for %s\n", req.Role.RoleName, scope, req.Start.Format("2006-01-02 15:04"), timeStr)
+	} else {
+		r.Status = StatusActivated
+		p.printf("Activated: %s @ %s for %s\n", req.Role.RoleName, scope, timeStr)
+	}
+	a.Store.AddRecentActivation(state.RecentActivation{
+		Tenant:           a.TenantID(),
+		Role:             req.Role.RoleName,
+		Scope:            scope,
+		ScopeDisplay:     azure.DefaultScopeDisplay(scope, ""),
+		EligibilityScope: req.Role.Scope,
+		ScheduleID:       req.Role.EligibilityScheduleID,
+		Duration:         timeStr,
+		Justification:    cfg.Justification,
+		Ticket:           req.Ticket.TicketNumber,
+		TicketSystem:     req.Ticket.TicketSystem,
+		ActivatedAt:      time.Now(),
+	})
+	return r
+}
+
+func runRequests(ctx context.Context, a *app.App, client ClientAPI, out io.Writer) error {
+	cfg := a.Config
+	if cfg.Cancel && len(cfg.Roles) == 0 && len(cfg.Scopes) == 0 && !cfg.Yes {
+		return fmt.Errorf("--cancel requires --role or --scope; use --yes to cancel all")
+	}
+
+	reqs, err := client.GetPendingRequests(ctx)
+	if err != nil {
+		return fmt.Errorf("get pending requests: %w", err)
+	}
+	targets, err := filterRequests(reqs, cfg.Roles, cfg.Scopes)
+	if err != nil {
+		return err
+	}
+
+	if !cfg.Cancel {
+		if cfg.Output == app.OutputJSON {
+			if targets == nil {
+				targets = []azure.AssignmentRequest{}
+			}
+			return jsonOut(targets, out)
+		}
+		if len(targets) == 0 {
+			fmt.Fprintln(out, "No pending requests.")
+			return nil
+		}
+		tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ROLE\tSCOPE\tTYPE\tSTATUS")
+		for _, r := range targets {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.RoleName, r.ScopeDisplay, r.RequestType, r.StatusDisplay())
+		}
+		return tw.Flush()
+	}
+
+	if len(targets) == 0 {
+		if len(cfg.Roles) > 0 || len(cfg.Scopes) > 0 {
+			return app.Mark(app.ErrNoMatch, errors.New("no pending requests match --role / --scope filters"))
+		}
+		fmt.Fprintln(out, "No pending requests.")
+		return nil
+	}
+
+	p := newProgress(out, cfg.Output)
+	results := make([]TargetResult, len(targets))
+	forEachTarget(len(targets), cfg.Parallel, func(i int) {
+		results[i] = cancelTarget(ctx, client, targets[i], p)
+	})
+	if err := writeResults(out, cfg.Output, app.CmdRequests, results); err != nil {
+		return err
+	}
+	return resultsError("cancel", results)
+}
+
+// cancelTarget cancels one pending request.
+func cancelTarget(ctx context.Context, client ClientAPI, req azure.AssignmentRequest, p *progress) TargetResult {
+	r := TargetResult{
+		Role:             req.RoleName,
+		RoleDefinitionID: req.RoleDefinitionID,
+		Scope:            req.Scope,
+		ScopeDisplay:     req.ScopeDisplay,
+		RequestID:        req.ID,
+		StartDateTime:    req.StartDateTime,
+	}
+	if err := client.CancelRequest(ctx, req); err != nil {
+		r.fail(err)
+		return r
+	}
+	r.Status, r.RequestStatus = StatusCancelled, azure.StatusCanceled
+	p.printf("Cancelled: %s @ %s\n", req.RoleName, req.ScopeDisplay)
+	return r
+}
+
+// runHistory prints the caller's schedule requests created in the configured
+// range, filtered by --role / --scope.
+func runHistory(ctx context.Context, a *app.App, client ClientAPI, out io.Writer) error {
+	cfg := a.Config
+	reqs, err := client.GetRequestHistory(ctx, azure.HistoryFilter{Since: cfg.Since, Until: cfg.Until})
+	if err != nil {
+		return fmt.Errorf("get request history: %w", err)
+	}
+	targets, err := filterRequests(reqs, cfg.Roles, cfg.Scopes)
+	if err != nil {
+		return err
+	}
+
+	switch cfg.Output {
+	case app.OutputJSON:
+		if targets == nil {
+			targets = []azure.AssignmentRequest{}
+		}
+		return jsonOut(targets, out)
+	case app.OutputCSV:
+		return historyCSV(targets, out)
+	}
+
+	if len(targets) == 0 {
+		fmt.Fprintln(out, "No requests in range.")
+		return nil
+	}
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CREATED\tTYPE\tROLE\tSCOPE\tSTATUS\tDURATION\tAPPROVED BY\tJUSTIFICATION")
+	for _, r := range targets {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			localTime(r.CreatedOn), r.RequestType, r.RoleName, r.ScopeDisplay, r.StatusDisplay(),
+			r.DurationDisplay(), r.ApprovedBy, r.Justification)
+	}
+	return tw.Flush()
+}
+
+// lookupScope returns the Azure resource scope to look role definitions up
+// at: the first --scope given as a resource ID, or the scope of an
+// eligibility matching a --scope name. It returns "" when none resolves.
+func lookupScope(filters []string, roles []azure.Role) string {
+	for _, sf := range filters {
+		if strings.HasPrefix(sf, "/") {
+			return sf
+		}
+		for _, r := range roles {
+			if !azure.IsDirectoryScope(r.Scope) && !azure.IsGroupScope(r.Scope) && azure.ScopeMatches(sf, r.Scope, r.ScopeDisplay) {
+				return r.Scope
+			}
+		}
+	}
+	return ""
+}
+
+// runRoleShow prints the permissions granted by the role named in
+// cfg.RoleQuery. Eligible roles are matched first (exact-first,
+// substring-fallback, narrowed by --scope); otherwise the name is looked up
+// among the Azure roles at the --scope (built-in roles only without one) and
+// directory roles.
+func runRoleShow(ctx context.Context, a *app.App, client ClientAPI, out io.Writer) error {
+	cfg := a.Config
+	roles, err := client.GetEligibleRoles(ctx)
+	if err != nil {
+		return fmt.Errorf("get eligible roles: %w", err)
+	}
+
+	var names []string
+	seen := map[string]bool{}
+	for _, r := range roles {
+		if k := strings.ToLower(r.RoleName); !seen[k] {
+			seen[k] = true
+			names = append(names, r.RoleName)
+		}
+	}
+	idx, err := selectByFilter(names, []string{cfg.RoleQuery}, "role")
+	if err != nil {
+		return err
+	}
+
+	var def *azure.RoleDefinition
+	if len(idx) > 0 {
+		name := names[idx[0]]
+		var match *azure.Role
+		for i, r := range roles {
+			if !strings.EqualFold(r.RoleName, name) {
+				continue
+			}
+			if len(cfg.Scopes) > 0 && !slices.ContainsFunc(cfg.Scopes, func(sf string) bool {
+				return azure.ScopeMatches(sf, r.Scope, r.ScopeDisplay)
+			}) {
+				continue
+			}
+			match = &roles[i]
+			break
+		}
+		if match == nil {
+			return app.Mark(app.ErrNoMatch, fmt.Errorf("no eligible %s role matches --scope %s", name, strings.Join(cfg.Scopes, ", ")))
+		}
+		def, err = client.GetRoleDefinition(ctx, match.Scope, match.RoleDefinitionID)
+	} else {
+		def, err = client.GetRoleDefinitionByName(ctx, lookupScope(cfg.Scopes, roles), cfg.RoleQuery)
+	}
+	if err != nil {
+		return fmt.Errorf("get role definition: %w", err)
+	}
+
+	if cfg.Output == app.OutputJSON {
+		return jsonOut(def, out)
+	}
+	fmt.Fprintf(out, "%s (%s)\n", def.Name, def.TypeDisplay())
+	if def.Description != "" {
+		fmt.Fprintln(out, def.Description)
+	}
+	for _, sec := range []struct {
+		title string
+		perms []string
+	}{
+		{"Actions", def.Actions},
+		{"Not actions", def.NotActions},
+		{"Data actions", def.DataActions},
+		{"Not data actions", def.NotDataActions},
+	} {
+		fmt.Fprintf(out, "\n%s:\n", sec.title)
+		if len(sec.perms) == 0 {
+			fmt.Fprintln(out, "  (none)")
+		}
+		for _, p := range sec.perms {
+			fmt.Fprintln(out, "  "+p)
+		}
+	}
+	return nil
+}
+
+// historyCSV writes requests as CSV with a header row.
+func historyCSV(reqs []azure.AssignmentRequest, out io.Writer) error {
+	w := csv.NewWriter(out)
+	_ = w.Write([]string{"created", "request_type", "role", "scope", "scope_display", "status", "duration", "approved_by", "justification"})
+	for _, r := range reqs {
+		_ = w.Write([]string{r.CreatedOn, r.RequestType, r.RoleName, r.Scope, r.ScopeDisplay, r.Status, r.Duration, r.ApprovedBy, r.Justification})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// localTime formats an RFC 3339 timestamp in local time, or returns it
+// unchanged if it does not parse.
+func localTime(s string) string {
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return s
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+// mergeExpired appends the expired eligibilities not also listed as current.
+func mergeExpired(roles, expired []azure.Role) []azure.Role {
+	key := func(r azure.Role) string {
+		return strings.ToLower(azure.NormalizeScope(r.Scope) + "|" + r.RoleDefinitionID + "|" + r.RoleName)
+	}
+	current := make(map[string]bool, len(roles))
+	for _, r := range roles {
+		current[key(r)] = true
+	}
+	for _, r := range expired {
+		if !current[key(r)] {
+			roles = append(roles, r)
+		}
+	}
+	return roles
+}
+
+// runRenew requests extension of the eligibilities matching --role / --scope,
+// or renewal of those that have expired. Permanent eligibilities are skipped.
+func runRenew(ctx context.Context, a *app.App, client ClientAPI, user *azure.User, out, errOut io.Writer) error {
+	cfg := a.Config
+	if !cfg.HasRoleFilter() && !cfg.HasScopeFilter() {
+		return fmt.Errorf("renew requires --role or --scope")
+	}
+	if strings.TrimSpace(cfg.Justification) == "" {
+		return fmt.Errorf("renew requires --justification")
+	}
+
+	roles, err := client.GetEligibleRoles(ctx)
+	if err != nil {
+		return fmt.Errorf("get eligible roles: %w", err)
+	}
+	expired, err := client.GetExpiredEligibilities(ctx)
+	if err != nil {
+		fmt.Fprintf(errOut, "warning: list expired eligibilities: %v\n", err)
+	}
+	roles = mergeExpired(roles, expired)
+	targets, err := filterEligibilities(roles, cfg.Roles, cfg.Scopes)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		return app.Mark(app.ErrNoMatch, errors.New("no eligible roles match the specified --role / --scope filters"))
+	}
+
+	var renewable []azure.Role
+	for _, role := range targets {
+		scope := azure.DefaultScopeDisplay(role.Scope, role.ScopeDisplay)
+		if role.IsEligibilityPermanent() {
+			fmt.Fprintf(errOut, "skipping permanent eligibility: %s @ %s (no expiry to extend)\n", role.RoleName, scope)
 			continue
 		}
-		fmt.Fprintf(out, "Activated: %s @ %s for %s\n", match.role.RoleName, scope, timeStr)
-		a.Store.AddRecentActivation(state.RecentActivation{
-			Role:             match.role.RoleName,
-			Scope:            scope,
-			ScopeDisplay:     azure.DefaultScopeDisplay(scope, ""),
-			EligibilityScope: match.role.Scope,
-			ScheduleID:       match.role.EligibilityScheduleID,
-			Duration:         timeStr,
-			Justification:    cfg.Justification,
-			ActivatedAt:      time.Now(),
-		})
+		if !role.IsEligibilityExpiring() {
+			fmt.Fprintf(errOut, "note: %s @ %s: %s; Azure accepts extensions only in the last %d days\n",
+				role.RoleName, scope, role.EligibilityExpiryDisplay(), int(azure.EligibilityRenewWindow.Hours()/24))
+		}
+		renewable = append(renewable, role)
 	}
 
-	if lastErr != nil {
-		a.Store.AddRecentJustification(cfg.Justification)
-		_ = a.Store.SaveState()
-		return lastErr
+	p := newProgress(out, cfg.Output)
+	results := make([]TargetResult, len(renewable))
+	forEachTarget(len(renewable), cfg.Parallel, func(i int) {
+		req := azure.RenewalRequest{
+			Role:          renewable[i],
+			PrincipalID:   user.ID,
+			Justification: cfg.Justification,
+			Days:          cfg.RenewDays,
+		}
+		results[i] = renewTarget(ctx, client, req, p)
+	})
+	if err := writeResults(out, cfg.Output, app.CmdRenew, results); err != nil {
+		return err
 	}
+	return resultsError("renew", results)
+}
 
-	a.Store.AddRecentJustification(cfg.Justification)
-	return a.Store.SaveState()
+// renewTarget submits the extension or renewal of one eligibility.
+func renewTarget(ctx context.Context, client ClientAPI, req azure.RenewalRequest, p *progress) TargetResult {
+	role := req.Role
+	r := TargetResult{
+		Role:             role.RoleName,
+		RoleDefinitionID: role.RoleDefinitionID,
+		Scope:            role.Scope,
+		ScopeDisplay:     azure.DefaultScopeDisplay(role.Scope, role.ScopeDisplay),
+		MemberType:       role.MemberType,
+		StartDateTime:    role.EligibilityStart,
+		EndDateTime:      role.EligibilityEnd,
+	}
+	resp, err := client.RenewEligibility(ctx, req)
+	if err != nil {
+		r.fail(err)
+		return r
+	}
+	r.RequestID, r.RequestStatus = requestID(resp), resp.Status()
+	verb := "Extension"
+	r.Status = StatusExtensionRequested
+	if req.IsRenew() {
+		verb = "Renewal"
+		r.Status = StatusRenewalRequested
+	}
+	status := "submitted"
+	if azure.IsAwaitingApproval(resp.Status()) {
+		status = "pending approval"
+	}
+	p.printf("%s %s: %s @ %s\n", verb, status, role.RoleName, r.ScopeDisplay)
+	return r
+}
+
+// filterEligibilities selects eligible roles by --role and --scope. Unlike
+// filterRoles, scopes match the eligibility itself and are never expanded to
+// child scopes, since an eligibility is extended where it was granted.
+func filterEligibilities(roles []azure.Role, roleFilters, scopeFilters []string) ([]azure.Role, error) {
+	roleNames := make([]string, len(roles))
+	for i, r := range roles {
+		roleNames[i] = r.RoleName
+	}
+	idx, err := selectByFilter(roleNames, roleFilters, "--role")
+	if err != nil {
+		return nil, err
+	}
+	var out []azure.Role
+	for _, i := range idx {
+		ok, err := scopeMatches(roles[i].Scope, roles[i].ScopeDisplay, scopeFilters)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			out = append(out, roles[i])
+		}
+	}
+	return out, nil
 }
 
 type roleTarget struct {
@@ -185,7 +766,29 @@ type roleTarget struct {
 	scope string
 }
 
-func filterRoles(ctx context.Context, client ClientAPI, roles []azure.Role, roleFilters, scopeFilters []string) ([]roleTarget, error) {
+// checkPolicy validates req against its role management policy before it
+// is submitted. A request whose policy cannot be read is left for Azure to
+// validate. Warnings and notes go to errOut.
+func checkPolicy(ctx context.Context, client ClientAPI, req azure.ActivationRequest, errOut io.Writer) error {
+	name, scope := req.Role.RoleName, req.TargetScope
+	policy, err := client.GetActivationPolicy(ctx, req.Role, scope)
+	if err != nil {
+		fmt.Fprintf(errOut, "warning: read policy for %s @ %s: %v\n", name, scope, err)
+		return nil
+	}
+	if err := policy.Validate(req); err != nil {
+		return app.Mark(app.ErrPolicyViolation, fmt.Errorf("activation blocked by role management policy: %w", err))
+	}
+	if policy.RequireApproval {
+		fmt.Fprintf(errOut, "note: %s @ %s requires approval; the request stays pending until approved\n", name, scope)
+	}
+	if policy.RequireMFA {
+		fmt.Fprintf(errOut, "note: %s @ %s requires MFA; sign in with MFA if Azure rejects the request\n", name, scope)
+	}
+	return nil
+}
+
+func filterRoles(ctx context.Context, client ClientAPI, roles []azure.Role, roleFilters, scopeFilters []string, errOut io.Writer) ([]roleTarget, error) {
 	roleNames := make([]string, len(roles))
 	for i, r := range roles {
 		roleNames[i] = r.RoleName
@@ -234,7 +837,15 @@ func filterRoles(ctx context.Context, client ClientAPI, roles []azure.Role, role
 			continue
 		}
 
-		if guid := azure.BareSubscriptionGUID(sf); guid != "" {
+		// A subscription, resource group, or resource below an MG-scoped
+		// eligibility: confirm the subscription sits under the MG.
+		subID, target := azure.BareSubscriptionGUID(sf), ""
+		if subID != "" {
+			target = "/subscriptions/" + subID
+		} else if azure.IsResourceGroupScope(expanded) || azure.IsResourceScope(expanded) {
+			subID, target = azure.SubscriptionIDFromScope(expanded), expanded
+		}
+		if subID != "" {
 			before := len(out)
 			for _, i := range roleIdx {
 				if !azure.IsManagementGroupScope(roles[i].Scope) {
@@ -247,7 +858,7 @@ func filterRoles(ctx context.Context, client ClientAPI, roles []azure.Role, role
 						return nil, fmt.Errorf("list subscriptions under management group %s: %w", mgID, err)
 					}
 					for _, w := range warnings {
-						fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+						fmt.Fprintf(errOut, "warning: %s\n", w)
 					}
 					set := make(map[string]struct{}, len(list))
 					for _, s := range list {
@@ -255,8 +866,8 @@ func filterRoles(ctx context.Context, client ClientAPI, roles []azure.Role, role
 					}
 					mgCache[mgID] = set
 				}
-				if _, ok := mgCache[mgID][strings.ToLower(guid)]; ok {
-					add(roleTarget{role: roles[i], scope: "/subscriptions/" + guid})
+				if _, ok := mgCache[mgID][strings.ToLower(subID)]; ok {
+					add(roleTarget{role: roles[i], scope: target})
 				}
 			}
 			if len(out) > before {
@@ -309,33 +920,64 @@ func filterAssignments(assignments []azure.ActiveAssignment, roleFilters, scopeF
 		if !allowRole[i] {
 			continue
 		}
-		if len(scopeFilters) > 0 {
-			scopeMatch := false
-			for _, sf := range scopeFilters {
-				expanded, _ := azure.ExpandScopeFilter(sf)
-				if azure.ScopeIsChildOf(a.Scope, expanded) || azure.ScopeIsChildOf(expanded, a.Scope) {
-					scopeMatch = true
-					break
-				}
-				displays := []string{a.ScopeDisplay}
-				idx, err := selectByFilter(displays, []string{sf}, "--scope")
-				if err != nil {
-					return nil, err
-				}
-				if len(idx) > 0 {
-					scopeMatch = true
-					break
-				}
-			}
-			if !scopeMatch {
-				continue
-			}
+		ok, err := scopeMatches(a.Scope, a.ScopeDisplay, scopeFilters)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			out = append(out, a)
 		}
-		out = append(out, a)
 	}
 	return out, nil
 }
 
+// filterRequests applies the same --role / --scope matching as filterAssignments
+// to outstanding schedule requests.
+func filterRequests(reqs []azure.AssignmentRequest, roleFilters, scopeFilters []string) ([]azure.AssignmentRequest, error) {
+	roleNames := make([]string, len(reqs))
+	for i, r := range reqs {
+		roleNames[i] = r.RoleName
+	}
+	idx, err := selectByFilter(roleNames, roleFilters, "--role")
+	if err != nil {
+		return nil, err
+	}
+	var out []azure.AssignmentRequest
+	for _, i := range idx {
+		ok, err := scopeMatches(reqs[i].Scope, reqs[i].ScopeDisplay, scopeFilters)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			out = append(out, reqs[i])
+		}
+	}
+	return out, nil
+}
+
+// scopeMatches reports whether scope (or its display name) matches any of the
+// --scope filters. Scopes match when either is a child of the other. An empty
+// filter list matches everything.
+func scopeMatches(scope, display string, scopeFilters []string) (bool, error) {
+	if len(scopeFilters) == 0 {
+		return true, nil
+	}
+	for _, sf := range scopeFilters {
+		expanded, _ := azure.ExpandScopeFilter(sf)
+		if azure.ScopeIsChildOf(scope, expanded) || azure.ScopeIsChildOf(expanded, scope) {
+			return true, nil
+		}
+		idx, err := selectByFilter([]string{display}, []string{sf}, "--scope")
+		if err != nil {
+			return false, err
+		}
+		if len(idx) > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // selectByFilter returns indices of candidates that match any filter using the
 // exact-first, substring-fallback policy. If any candidate exactly matches a
 // filter, only exact matches are returned for that filter. If no exact match
@@ -374,8 +1016,8 @@ func selectByFilter(candidates []string, filters []string, flag string) ([]int,
 			continue
 		}
 		if len(subIdx) > 1 {
-			return nil, fmt.Errorf("%s '%s' is ambiguous: matched '%s' — use exact name",
-				flag, f, strings.Join(subNames, "', '"))
+			return nil, app.Mark(app.ErrAmbiguous, fmt.Errorf("%s '%s' is ambiguous: matched '%s' — use exact name",
+				flag, f, strings.Join(subNames, "', '")))
 		}
 		selected[subIdx[0]] = struct{}{}
 	}
@@ -426,8 +1068,8 @@ func matchBest(s string, filters []string) (bool, error) {
 		return false, nil
 	}
 	if len(subHits) > 1 {
-		return false, fmt.Errorf("'%s' is ambiguous: matched '%s' — use exact name",
-			subHits[0], strings.Join(subHits, "', '"))
+		return false, app.Mark(app.ErrAmbiguous, fmt.Errorf("'%s' is ambiguous: matched '%s' — use exact name",
+			subHits[0], strings.Join(subHits, "', '")))
 	}
 	return true, nil
 }
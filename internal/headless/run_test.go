@@ -3,26 +3,52 @@ package headless
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/jeircul/pim/internal/app"
 	"github.com/jeircul/pim/internal/azure"
 	"github.com/jeircul/pim/internal/state"
 )
 
+// mockClient is the general headless mock: each method returns the fields
+// its test sets and records what it was asked to do.
 type mockClient struct {
+	fakeClient
 	user          *azure.User
 	userErr       error
 	active        []azure.ActiveAssignment
 	activeErr     error
+	scheduled     []azure.ActiveAssignment
+	waitErr       error
+	waitCalls     int
 	eligible      []azure.Role
 	eligibleErr   error
 	activateErr   error
+	activateCalls int
+	lastActivate  azure.ActivationRequest
+	activateState string
+	alreadyActive bool
 	deactivateErr error
+	pending       []azure.AssignmentRequest
+	pendingErr    error
+	cancelErr     error
+	cancelled     []azure.AssignmentRequest
+	renewErr      error
+	expired       []azure.Role
+	expiredErr    error
+	history       []azure.AssignmentRequest
+	historyFilter azure.HistoryFilter
+	roleDefs      map[string]*azure.RoleDefinition // by role definition ID, or name for lookups by name
+	roleDefScope  string
+	renewed       []azure.RenewalRequest
+	policy        azure.ActivationPolicy
+	policyErr     error
 	mgSubs        map[string][]azure.Subscription
 	mgSubsErr     error
 	mgSubsCalls   int
@@ -42,11 +68,15 @@ func (m *mockClient) GetEligibleRoles(_ context.Context) ([]azure.Role, error) {
 	return m.eligible, m.eligibleErr
 }
 
-func (m *mockClient) ActivateRole(_ context.Context, role azure.Role, principalID, justification string, minutes int, targetScope string) (*azure.ScheduleResponse, error) {
+func (m *mockClient) ActivateRole(_ context.Context, req azure.ActivationRequest) (*azure.ScheduleResponse, error) {
+	m.activateCalls++
+	m.lastActivate = req
 	if m.activateErr != nil {
 		return nil, m.activateErr
 	}
-	return &azure.ScheduleResponse{}, nil
+	resp := &azure.ScheduleResponse{AlreadyActive: m.alreadyActive}
+	resp.Properties.Status = m.activateState
+	return resp, nil
 }
 
 func (m *mockClient) DeactivateRole(_ context.Context, assignment azure.ActiveAssignment, principalID string) (*azure.ScheduleResponse, error) {
@@ -56,6 +86,66 @@ func (m *mockClient) DeactivateRole(_ context.Context, assignment azure.ActiveAs
 	return &azure.ScheduleResponse{}, nil
 }
 
+func (m *mockClient) GetActivationPolicy(_ context.Context, _ azure.Role, _ string) (azure.ActivationPolicy, error) {
+	return m.policy, m.policyErr
+}
+
+func (m *mockClient) WaitForActivation(_ context.Context, _ azure.ActivationRequest, _ *azure.ScheduleResponse, _ func(string)) error {
+	m.waitCalls++
+	return m.waitErr
+}
+
+func (m *mockClient) GetScheduledAssignments(_ context.Context) ([]azure.ActiveAssignment, error) {
+	return m.scheduled, nil
+}
+
+func (m *mockClient) GetPendingRequests(_ context.Context) ([]azure.AssignmentRequest, error) {
+	return m.pending, m.pendingErr
+}
+
+func (m *mockClient) CancelRequest(_ context.Context, req azure.AssignmentRequest) error {
+	if m.cancelErr != nil {
+		return m.cancelErr
+	}
+	m.cancelled = append(m.cancelled, req)
+	return nil
+}
+
+func (m *mockClient) GetExpiredEligibilities(_ context.Context) ([]azure.Role, error) {
+	return m.expired, m.expiredErr
+}
+
+func (m *mockClient) RenewEligibility(_ context.Context, req azure.RenewalRequest) (*azure.ScheduleResponse, error) {
+	if m.renewErr != nil {
+		return nil, m.renewErr
+	}
+	m.renewed = append(m.renewed, req)
+	resp := &azure.ScheduleResponse{}
+	resp.Properties.Status = azure.StatusPendingAdminDecision
+	return resp, nil
+}
+
+func (m *mockClient) GetRequestHistory(_ context.Context, f azure.HistoryFilter) ([]azure.AssignmentRequest, error) {
+	m.historyFilter = f
+	return m.history, m.pendingErr
+}
+
+func (m *mockClient) GetRoleDefinition(_ context.Context, scope, roleDefinitionID string) (*azure.RoleDefinition, error) {
+	m.roleDefScope = scope
+	if d, ok := m.roleDefs[roleDefinitionID]; ok {
+		return d, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func (m *mockClient) GetRoleDefinitionByName(_ context.Context, scope, name string) (*azure.RoleDefinition, error) {
+	m.roleDefScope = scope
+	if d, ok := m.roleDefs[name]; ok {
+		return d, nil
+	}
+	return nil, errors.New("not found")
+}
+
 func (m *mockClient) ListAllSubscriptionsUnderMG(_ context.Context, mgID string) ([]azure.Subscription, map[string]string, []string, error) {
 	m.mgSubsCalls++
 	if m.mgSubsErr != nil {
@@ -107,11 +197,12 @@ func TestRunActivate(t *testing.T) {
 	}
 
 	tests := []struct {
-		name    string
-		cfg     app.Config
-		client  *mockClient
-		wantErr string
-		wantOut string
+		name     string
+		cfg      app.Config
+		client   *mockClient
+		wantErr  string
+		wantOut  string
+		wantCode int
 	}{
 		{
 			name: "missing required flags no role",
@@ -154,13 +245,150 @@ func TestRunActivate(t *testing.T) {
 			},
 			wantOut: "Activated:",
 		},
+		{
+			name: "duration above policy maximum fails before activation",
+			cfg: app.Config{
+				Command:       app.CmdActivate,
+				Roles:         []string{"Contributor"},
+				TimeStr:       "4h",
+				Justification: "need access",
+			},
+			client: &mockClient{
+				user:     user,
+				eligible: []azure.Role{eligibleRole},
+				policy:   azure.ActivationPolicy{MaxMinutes: 60},
+			},
+			wantErr: "exceeds the policy maximum",
+		},
+		{
+			name: "policy longer than default maximum",
+			cfg: app.Config{
+				Command:       app.CmdActivate,
+				Roles:         []string{"Contributor"},
+				TimeStr:       "12h",
+				Justification: "need access",
+			},
+			client: &mockClient{
+				user:     user,
+				eligible: []azure.Role{eligibleRole},
+				policy:   azure.ActivationPolicy{MaxMinutes: 720},
+			},
+			wantOut: "Activated:",
+		},
+		{
+			name: "missing justification required by policy",
+			cfg: app.Config{
+				Command: app.CmdActivate,
+				Roles:   []string{"Contributor"},
+				TimeStr: "1h",
+			},
+			client: &mockClient{
+				user:     user,
+				eligible: []azure.Role{eligibleRole},
+				policy:   azure.ActivationPolicy{RequireJustification: true},
+			},
+			wantErr: "requires a justification",
+		},
+		{
+			name: "missing ticket required by policy",
+			cfg: app.Config{
+				Command:       app.CmdActivate,
+				Roles:         []string{"Contributor"},
+				TimeStr:       "1h",
+				Justification: "need access",
+			},
+			client: &mockClient{
+				user:     user,
+				eligible: []azure.Role{eligibleRole},
+				policy:   azure.ActivationPolicy{RequireTicket: true},
+			},
+			wantErr: "requires a ticket number",
+		},
+		{
+			name: "ticket required by policy",
+			cfg: app.Config{
+				Command:       app.CmdActivate,
+				Roles:         []string{"Contributor"},
+				TimeStr:       "1h",
+				Justification: "need access",
+				Ticket:        "CHG0012345",
+				TicketSystem:  "ServiceNow",
+			},
+			client: &mockClient{
+				user:     user,
+				eligible: []azure.Role{eligibleRole},
+				policy:   azure.ActivationPolicy{RequireTicket: true},
+			},
+			wantOut: "Activated:",
+		},
+		{
+			name: "approval-required activation is reported as pending",
+			cfg: app.Config{
+				Command:       app.CmdActivate,
+				Roles:         []string{"Contributor"},
+				TimeStr:       "1h",
+				Justification: "need access",
+			},
+			client: &mockClient{
+				user:          user,
+				eligible:      []azure.Role{eligibleRole},
+				activateState: azure.StatusPendingApproval,
+			},
+			wantOut:  "Pending approval: Contributor",
+			wantCode: app.ExitPendingApproval,
+		},
+		{
+			name: "scheduled activation",
+			cfg: app.Config{
+				Command:       app.CmdActivate,
+				Roles:         []string{"Contributor"},
+				TimeStr:       "1h",
+				Justification: "need access",
+				StartStr:      "+2h",
+			},
+			client: &mockClient{
+				user:     user,
+				eligible: []azure.Role{eligibleRole},
+			},
+			wantOut: "Scheduled: Contributor",
+		},
+		{
+			name: "invalid start time",
+			cfg: app.Config{
+				Command:       app.CmdActivate,
+				Roles:         []string{"Contributor"},
+				TimeStr:       "1h",
+				Justification: "need access",
+				StartStr:      "tomorrow-ish",
+			},
+			client: &mockClient{
+				user:     user,
+				eligible: []azure.Role{eligibleRole},
+			},
+			wantErr: "unrecognised start time",
+		},
+		{
+			name: "unreadable policy is left to Azure",
+			cfg: app.Config{
+				Command:       app.CmdActivate,
+				Roles:         []string{"Contributor"},
+				TimeStr:       "1h",
+				Justification: "need access",
+			},
+			client: &mockClient{
+				user:      user,
+				eligible:  []azure.Role{eligibleRole},
+				policyErr: errors.New("forbidden"),
+			},
+			wantOut: "Activated:",
+		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			a := newTestApp(t, tc.cfg)
 			out, err := captureOutput(t, func(w io.Writer) error {
-				return runActivate(context.Background(), a, tc.client, user, w)
+				return runActivate(context.Background(), a, tc.client, user, w, io.Discard)
 			})
 			if tc.wantErr != "" {
 				if err == nil {
@@ -169,14 +397,62 @@ func TestRunActivate(t *testing.T) {
 				if !strings.Contains(err.Error(), tc.wantErr) {
 					t.Errorf("error %q does not contain %q", err.Error(), tc.wantErr)
 				}
+				if tc.client.activateCalls != 0 {
+					t.Errorf("ActivateRole called %d times, want 0", tc.client.activateCalls)
+				}
 				return
 			}
-			if err != nil {
-				t.Fatalf("unexpected error: %v", err)
+			if code := app.ExitCode(err); code != tc.wantCode {
+				t.Fatalf("exit code = %d (err %v), want %d", code, err, tc.wantCode)
 			}
 			if tc.wantOut != "" && !strings.Contains(out, tc.wantOut) {
 				t.Errorf("output %q does not contain %q", out, tc.wantOut)
 			}
+			got := tc.client.lastActivate.Ticket
+			if got.TicketNumber != tc.cfg.Ticket || got.TicketSystem != tc.cfg.TicketSystem {
+				t.Errorf("ticket = %+v, want %q/%q", got, tc.cfg.Ticket, tc.cfg.TicketSystem)
+			}
+			if scheduled := !tc.client.lastActivate.Start.IsZero(); scheduled != (tc.cfg.StartStr != "") {
+				t.Errorf("scheduled = %v, want %v", scheduled, tc.cfg.StartStr != "")
+			}
+		})
+	}
+}
+
+func TestCheckPolicyWritesToErrOut(t *testing.T) {
+	req := azure.ActivationRequest{Role: azure.Role{RoleName: "Owner"}, TargetScope: "/subscriptions/sub-1", Minutes: 60}
+	tests := []struct {
+		name     string
+		client   *mockClient
+		want     string
+		wantCode int
+	}{
+		{
+			name:   "unreadable policy",
+			client: &mockClient{policyErr: errors.New("forbidden")},
+			want:   "warning: read policy for Owner @ /subscriptions/sub-1: forbidden",
+		},
+		{
+			name:   "approval and MFA notes",
+			client: &mockClient{policy: azure.ActivationPolicy{RequireApproval: true, RequireMFA: true}},
+			want:   "note: Owner @ /subscriptions/sub-1 requires approval",
+		},
+		{
+			name:     "violation",
+			client:   &mockClient{policy: azure.ActivationPolicy{MaxMinutes: 30}},
+			wantCode: app.ExitPolicy,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var errOut strings.Builder
+			err := checkPolicy(context.Background(), tc.client, req, &errOut)
+			if code := app.ExitCode(err); code != tc.wantCode {
+				t.Fatalf("exit code = %d (err %v), want %d", code, err, tc.wantCode)
+			}
+			if !strings.Contains(errOut.String(), tc.want) {
+				t.Errorf("errOut %q does not contain %q", errOut.String(), tc.want)
+			}
 		})
 	}
 }
@@ -236,7 +512,7 @@ func TestRunDeactivate(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			a := newTestApp(t, tc.cfg)
 			out, err := captureOutput(t, func(w io.Writer) error {
-				return runDeactivate(context.Background(), a, tc.client, user, w)
+				return runDeactivate(context.Background(), a, tc.client, user, w, io.Discard)
 			})
 			if tc.wantErr != "" {
 				if err == nil {
@@ -277,7 +553,7 @@ func TestRunDeactivateYesFlag(t *testing.T) {
 	}
 
 	out, err := captureOutput(t, func(w io.Writer) error {
-		return runDeactivate(context.Background(), a, client, user, w)
+		return runDeactivate(context.Background(), a, client, user, w, io.Discard)
 	})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -287,6 +563,185 @@ func TestRunDeactivateYesFlag(t *testing.T) {
 	}
 }
 
+func TestRunDeactivateSkipWarningsGoToErrOut(t *testing.T) {
+	user := &azure.User{ID: "uid-1"}
+	a := newTestApp(t, app.Config{
+		Command: app.CmdDeactivate,
+		Yes:     true,
+	})
+	client := &mockClient{
+		user: user,
+		active: []azure.ActiveAssignment{
+			{RoleName: "Contributor", Scope: "/subscriptions/sub-1", ScopeDisplay: "My Sub", RoleDefinitionID: "rd-1", EndDateTime: "2099-01-01T00:00:00Z"},
+			{RoleName: "Reader", Scope: "/subscriptions/sub-2", ScopeDisplay: "Group Sub", RoleDefinitionID: "rd-2", MemberType: "Inherited", EndDateTime: "2099-01-01T00:00:00Z"},
+			{RoleName: "Owner", Scope: "/subscriptions/sub-3", ScopeDisplay: "Perm Sub", RoleDefinitionID: "rd-3"},
+		},
+	}
+
+	var errOut strings.Builder
+	out, err := captureOutput(t, func(w io.Writer) error {
+		return runDeactivate(context.Background(), a, client, user, w, &errOut)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"skipping inherited assignment: Reader @ Group Sub",
+		"skipping permanent assignment: Owner @ Perm Sub",
+	} {
+		if !strings.Contains(errOut.String(), want) {
+			t.Errorf("errOut %q does not contain %q", errOut.String(), want)
+		}
+		if strings.Contains(out, want) {
+			t.Errorf("stdout %q contains warning %q", out, want)
+		}
+	}
+}
+
+func TestRunRenewWarningsGoToErrOut(t *testing.T) {
+	user := &azure.User{ID: "uid-1"}
+	a := newTestApp(t, app.Config{
+		Command:       app.CmdRenew,
+		Roles:         []string{"Owner"},
+		Justification: "renew",
+	})
+	client := &mockClient{
+		user: user,
+		eligible: []azure.Role{
+			{RoleName: "Owner", Scope: "/subscriptions/sub-1", ScopeDisplay: "Perm Sub", RoleDefinitionID: "rd-1"},
+		},
+		expiredErr: errors.New("boom"),
+	}
+
+	var errOut strings.Builder
+	if _, err := captureOutput(t, func(w io.Writer) error {
+		return runRenew(context.Background(), a, client, user, w, &errOut)
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"warning: list expired eligibilities: boom",
+		"skipping permanent eligibility: Owner @ Perm Sub",
+	} {
+		if !strings.Contains(errOut.String(), want) {
+			t.Errorf("errOut %q does not contain %q", errOut.String(), want)
+		}
+	}
+}
+
+func TestRunDeactivateTooEarlyQueues(t *testing.T) {
+	user := &azure.User{ID: "uid-1"}
+	started := time.Now().Add(-time.Minute)
+	a := newTestApp(t, app.Config{
+		Command: app.CmdDeactivate,
+		Roles:   []string{"Contributor"},
+	})
+	client := &mockClient{
+		user: user,
+		active: []azure.ActiveAssignment{{
+			RoleName:         "Contributor",
+			Scope:            "/subscriptions/sub-1",
+			ScopeDisplay:     "My Sub",
+			RoleDefinitionID: "rd-1",
+			StartDateTime:    started.Format(time.RFC3339),
+			EndDateTime:      "2099-01-01T00:00:00Z",
+		}},
+		deactivateErr: &azure.APIError{StatusCode: 400, Code: "ActiveDurationTooShort"},
+	}
+
+	out, err := captureOutput(t, func(w io.Writer) error {
+		return runDeactivate(context.Background(), a, client, user, w, io.Discard)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "Queued: Contributor @ My Sub") {
+		t.Errorf("output %q does not report the queued deactivation", out)
+	}
+	queued := a.QueuedDeactivations("", user.ID)
+	if len(queued) != 1 || queued[0].RoleDefinitionID != "rd-1" ||
+		!queued[0].NotBefore.Equal(started.Truncate(time.Second).Add(azure.MinActiveDuration)) {
+		t.Fatalf("queue = %+v", queued)
+	}
+
+	a.Config.Command = app.CmdStatus
+	out, err = captureOutput(t, func(w io.Writer) error {
+		return runStatus(context.Background(), a, client, user, w, io.Discard)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "QUEUED DEACTIVATION") || !strings.Contains(out, "after ") {
+		t.Errorf("status output %q does not list the queued deactivation", out)
+	}
+
+	// Once due, the next run deactivates it and empties the queue.
+	client.deactivateErr = nil
+	a.Store.QueueDeactivation(state.QueuedDeactivation{PrincipalID: user.ID, RoleDefinitionID: "rd-1", Scope: "/subscriptions/sub-1",
+		Role: "Contributor", ScopeDisplay: "My Sub", NotBefore: time.Now().Add(-time.Second)})
+	var errOut strings.Builder
+	runQueuedDeactivations(context.Background(), a, client, user, &errOut)
+	if !strings.Contains(errOut.String(), "deactivated queued Contributor @ My Sub") {
+		t.Errorf("stderr %q does not report the deactivation", errOut.String())
+	}
+	if queued := a.QueuedDeactivations("", user.ID); len(queued) != 0 {
+		t.Errorf("queue not emptied: %+v", queued)
+	}
+}
+
+// TestRunDeactivateQueueSharedWithTUI checks that a deactivation queued
+// without --tenant is keyed by the client's tenant, which is what the TUI
+// reads the queue with.
+func TestRunDeactivateQueueSharedWithTUI(t *testing.T) {
+	a, err := app.New(app.Config{Command: app.CmdDeactivate, Roles: []string{"Contributor"}, Demo: true, ConfigDir: t.TempDir()}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	if err := a.Connect(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if a.Client.TenantID() == "" {
+		t.Fatal("demo client has no tenant ID")
+	}
+
+	user := &azure.User{ID: "uid-1"}
+	client := &mockClient{
+		user: user,
+		active: []azure.ActiveAssignment{{
+			RoleName:         "Contributor",
+			Scope:            "/subscriptions/sub-1",
+			RoleDefinitionID: "rd-1",
+			StartDateTime:    time.Now().Add(-time.Minute).Format(time.RFC3339),
+			EndDateTime:      "2099-01-01T00:00:00Z",
+		}},
+		deactivateErr: &azure.APIError{StatusCode: 400, Code: "ActiveDurationTooShort"},
+	}
+	if _, err := captureOutput(t, func(w io.Writer) error {
+		return runDeactivate(t.Context(), a, client, user, w, io.Discard)
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The TUI lists and retries the queue by a.Client.TenantID().
+	if queued := a.QueuedDeactivations(a.Client.TenantID(), user.ID); len(queued) != 1 {
+		t.Fatalf("queue for tenant %q = %+v, want the headless entry", a.Client.TenantID(), queued)
+	}
+}
+
+func TestRetriesQueue(t *testing.T) {
+	for _, cmd := range []string{app.CmdStatus, app.CmdActivate, app.CmdDeactivate} {
+		if !retriesQueue(cmd) {
+			t.Errorf("retriesQueue(%q) = false, want true", cmd)
+		}
+	}
+	for _, cmd := range []string{app.CmdWhoami, app.CmdSearch, app.CmdHistory, app.CmdRole, app.CmdRequests, app.CmdRenew} {
+		if retriesQueue(cmd) {
+			t.Errorf("retriesQueue(%q) = true, want false", cmd)
+		}
+	}
+}
+
 func TestRunDeactivateClientError(t *testing.T) {
 	user := &azure.User{ID: "uid-1"}
 	a := newTestApp(t, app.Config{
@@ -299,7 +754,7 @@ func TestRunDeactivateClientError(t *testing.T) {
 	}
 
 	_, err := captureOutput(t, func(w io.Writer) error {
-		return runDeactivate(context.Background(), a, client, user, w)
+		return runDeactivate(context.Background(), a, client, user, w, io.Discard)
 	})
 	if err == nil {
 		t.Fatal("want error, got nil")
@@ -309,6 +764,389 @@ func TestRunDeactivateClientError(t *testing.T) {
 	}
 }
 
+func TestRunActivateWait(t *testing.T) {
+	user := &azure.User{ID: "uid-1"}
+	eligible := []azure.Role{{
+		RoleName:         "Contributor",
+		Scope:            "/subscriptions/sub-1",
+		RoleDefinitionID: "rd-1",
+	}}
+	cfg := app.Config{
+		Command:       app.CmdActivate,
+		Roles:         []string{"Contributor"},
+		TimeStr:       "1h",
+		Justification: "need access",
+		Wait:          true,
+	}
+
+	t.Run("provisioned", func(t *testing.T) {
+		client := &mockClient{user: user, eligible: eligible}
+		out, err := captureOutput(t, func(w io.Writer) error {
+			return runActivate(context.Background(), newTestApp(t, cfg), client, user, w, io.Discard)
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if client.waitCalls != 1 {
+			t.Errorf("WaitForActivation called %d times, want 1", client.waitCalls)
+		}
+		if !strings.Contains(out, "Activated: Contributor") {
+			t.Errorf("output %q does not contain Activated", out)
+		}
+	})
+
+	t.Run("timeout fails", func(t *testing.T) {
+		client := &mockClient{user: user, eligible: eligible, waitErr: context.DeadlineExceeded}
+		out, err := captureOutput(t, func(w io.Writer) error {
+			return runActivate(context.Background(), newTestApp(t, cfg), client, user, w, io.Discard)
+		})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("err = %v, want deadline exceeded", err)
+		}
+		if strings.Contains(out, "Activated:") {
+			t.Errorf("output %q should not report activation", out)
+		}
+	})
+
+	t.Run("pending approval is not waited on", func(t *testing.T) {
+		client := &mockClient{user: user, eligible: eligible, activateState: azure.StatusPendingApproval}
+		if _, err := captureOutput(t, func(w io.Writer) error {
+			return runActivate(context.Background(), newTestApp(t, cfg), client, user, w, io.Discard)
+		}); !errors.Is(err, app.ErrPendingApproval) {
+			t.Fatalf("err = %v, want pending approval", err)
+		}
+		if client.waitCalls != 0 {
+			t.Errorf("WaitForActivation called %d times, want 0", client.waitCalls)
+		}
+	})
+}
+
+func TestRunRequests(t *testing.T) {
+	pending := []azure.AssignmentRequest{
+		{
+			ID:           "/subscriptions/sub-1/providers/Microsoft.Authorization/roleAssignmentScheduleRequests/r1",
+			Scope:        "/subscriptions/sub-1",
+			ScopeDisplay: "sub-one",
+			RoleName:     "Contributor",
+			RequestType:  "SelfActivate",
+			Status:       azure.StatusPendingApproval,
+		},
+		{
+			ID:           "r2",
+			Scope:        "/directory",
+			ScopeDisplay: "Directory",
+			RoleName:     "Global Reader",
+			RequestType:  "selfActivate",
+			Status:       azure.StatusPendingApproval,
+		},
+	}
+
+	tests := []struct {
+		name          string
+		cfg           app.Config
+		client        *mockClient
+		wantErr       string
+		wantOut       string
+		wantCancelled int
+	}{
+		{
+			name:    "list table",
+			cfg:     app.Config{Command: app.CmdRequests},
+			client:  &mockClient{pending: pending},
+			wantOut: "pending approval",
+		},
+		{
+			name:    "list filtered by role",
+			cfg:     app.Config{Command: app.CmdRequests, Roles: []string{"Global Reader"}},
+			client:  &mockClient{pending: pending},
+			wantOut: "Global Reader",
+		},
+		{
+			name:    "list empty",
+			cfg:     app.Config{Command: app.CmdRequests},
+			client:  &mockClient{},
+			wantOut: "No pending requests.",
+		},
+		{
+			name:    "list error",
+			cfg:     app.Config{Command: app.CmdRequests},
+			client:  &mockClient{pendingErr: errors.New("boom")},
+			wantErr: "get pending requests",
+		},
+		{
+			name:    "cancel requires filter or yes",
+			cfg:     app.Config{Command: app.CmdRequests, Cancel: true},
+			client:  &mockClient{pending: pending},
+			wantErr: "--cancel requires",
+		},
+		{
+			name:          "cancel matching role",
+			cfg:           app.Config{Command: app.CmdRequests, Cancel: true, Roles: []string{"Contributor"}},
+			client:        &mockClient{pending: pending},
+			wantOut:       "Cancelled: Contributor @ sub-one",
+			wantCancelled: 1,
+		},
+		{
+			name:          "cancel all with yes",
+			cfg:           app.Config{Command: app.CmdRequests, Cancel: true, Yes: true},
+			client:        &mockClient{pending: pending},
+			wantCancelled: 2,
+		},
+		{
+			name:    "cancel no match",
+			cfg:     app.Config{Command: app.CmdRequests, Cancel: true, Roles: []string{"Owner"}},
+			client:  &mockClient{pending: pending},
+			wantErr: "no pending requests match",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			a := newTestApp(t, tc.cfg)
+			out, err := captureOutput(t, func(w io.Writer) error {
+				return runRequests(context.Background(), a, tc.client, w)
+			})
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("error = %v, want containing %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tc.wantOut != "" && !strings.Contains(out, tc.wantOut) {
+				t.Errorf("output %q does not contain %q", out, tc.wantOut)
+			}
+			if len(tc.client.cancelled) != tc.wantCancelled {
+				t.Errorf("cancelled %d requests, want %d", len(tc.client.cancelled), tc.wantCancelled)
+			}
+		})
+	}
+}
+
+func TestRunRenew(t *testing.T) {
+	user := &azure.User{ID: "uid-1"}
+	day := 24 * time.Hour
+	eligible := []azure.Role{
+		{
+			RoleName:       "Contributor",
+			Scope:          "/subscriptions/sub-1",
+			ScopeDisplay:   "sub-one",
+			EligibilityEnd: time.Now().Add(5 * day).UTC().Format(time.RFC3339),
+		},
+		{
+			RoleName:     "Owner",
+			Scope:        "/subscriptions/sub-2",
+			ScopeDisplay: "sub-two",
+		},
+	}
+	expired := []azure.Role{
+		{
+			RoleName:       "Reader",
+			Scope:          "/subscriptions/sub-1",
+			ScopeDisplay:   "sub-one",
+			EligibilityEnd: time.Now().Add(-day).UTC().Format(time.RFC3339),
+		},
+	}
+
+	tests := []struct {
+		name        string
+		cfg         app.Config
+		client      *mockClient
+		wantErr     string
+		wantOut     string
+		wantRenewed int
+	}{
+		{
+			name:    "requires filter",
+			cfg:     app.Config{Command: app.CmdRenew, Justification: "x"},
+			client:  &mockClient{eligible: eligible, expired: expired},
+			wantErr: "requires --role or --scope",
+		},
+		{
+			name:    "requires justification",
+			cfg:     app.Config{Command: app.CmdRenew, Roles: []string{"Contributor"}},
+			client:  &mockClient{eligible: eligible, expired: expired},
+			wantErr: "requires --justification",
+		},
+		{
+			name:        "extend expiring",
+			cfg:         app.Config{Command: app.CmdRenew, Roles: []string{"Contributor"}, Justification: "x"},
+			client:      &mockClient{eligible: eligible, expired: expired},
+			wantOut:     "Extension pending approval: Contributor @ sub-one",
+			wantRenewed: 1,
+		},
+		{
+			name:        "renew expired",
+			cfg:         app.Config{Command: app.CmdRenew, Roles: []string{"Reader"}, Justification: "x"},
+			client:      &mockClient{eligible: eligible, expired: expired},
+			wantOut:     "Renewal pending approval: Reader @ sub-one",
+			wantRenewed: 1,
+		},
+		{
+			name:        "scope selects time-bound only",
+			cfg:         app.Config{Command: app.CmdRenew, Scopes: []string{"/subscriptions/sub-1"}, Justification: "x"},
+			client:      &mockClient{eligible: eligible, expired: expired},
+			wantRenewed: 2,
+		},
+		{
+			name:   "permanent skipped",
+			cfg:    app.Config{Command: app.CmdRenew, Roles: []string{"Owner"}, Justification: "x"},
+			client: &mockClient{eligible: eligible, expired: expired},
+		},
+		{
+			name:    "no match",
+			cfg:     app.Config{Command: app.CmdRenew, Roles: []string{"Nope"}, Justification: "x"},
+			client:  &mockClient{eligible: eligible, expired: expired},
+			wantErr: "no eligible roles match",
+		},
+		{
+			name:    "renew error",
+			cfg:     app.Config{Command: app.CmdRenew, Roles: []string{"Contributor"}, Justification: "x"},
+			client:  &mockClient{eligible: eligible, renewErr: errors.New("boom")},
+			wantErr: "boom",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			a := newTestApp(t, tc.cfg)
+			out, err := captureOutput(t, func(w io.Writer) error {
+				return runRenew(context.Background(), a, tc.client, user, w, io.Discard)
+			})
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("error = %v, want containing %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tc.wantOut != "" && !strings.Contains(out, tc.wantOut) {
+				t.Errorf("output %q does not contain %q", out, tc.wantOut)
+			}
+			if len(tc.client.renewed) != tc.wantRenewed {
+				t.Errorf("renewed %d eligibilities, want %d", len(tc.client.renewed), tc.wantRenewed)
+			}
+		})
+	}
+}
+
+func TestRunHistory(t *testing.T) {
+	history := []azure.AssignmentRequest{
+		{
+			RoleName:      "Owner",
+			Scope:         "/subscriptions/sub-1",
+			ScopeDisplay:  "sub-one",
+			RequestType:   "SelfActivate",
+			Status:        "Provisioned",
+			CreatedOn:     "2024-05-02T10:00:00Z",
+			Duration:      "PT2H",
+			Justification: "deploy, hotfix",
+			ApprovedBy:    "Ada",
+		},
+		{
+			RoleName:     "Reader",
+			Scope:        "/subscriptions/sub-2",
+			ScopeDisplay: "sub-two",
+			RequestType:  "SelfDeactivate",
+			Status:       "Revoked",
+			CreatedOn:    "2024-05-01T10:00:00Z",
+		},
+	}
+	since := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		cfg     app.Config
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "table",
+			cfg:  app.Config{Command: app.CmdHistory, Since: since},
+			want: []string{"APPROVED BY", "Owner", "sub-one", "2h", "Ada", "Reader"},
+		},
+		{
+			name:    "role filter",
+			cfg:     app.Config{Command: app.CmdHistory, Roles: []string{"Reader"}},
+			want:    []string{"Reader"},
+			notWant: []string{"Owner"},
+		},
+		{
+			name: "csv",
+			cfg:  app.Config{Command: app.CmdHistory, Output: app.OutputCSV},
+			want: []string{
+				"created,request_type,role,scope,scope_display,status,duration,approved_by,justification",
+				`2024-05-02T10:00:00Z,SelfActivate,Owner,/subscriptions/sub-1,sub-one,Provisioned,PT2H,Ada,"deploy, hotfix"`,
+			},
+		},
+		{
+			name: "no match",
+			cfg:  app.Config{Command: app.CmdHistory, Roles: []string{"Nope"}},
+			want: []string{"No requests in range."},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &mockClient{history: history}
+			a := newTestApp(t, tc.cfg)
+			out, err := captureOutput(t, func(w io.Writer) error {
+				return runHistory(context.Background(), a, client, w)
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !client.historyFilter.Since.Equal(tc.cfg.Since) {
+				t.Errorf("filter Since = %v, want %v", client.historyFilter.Since, tc.cfg.Since)
+			}
+			for _, w := range tc.want {
+				if !strings.Contains(out, w) {
+					t.Errorf("output %q does not contain %q", out, w)
+				}
+			}
+			for _, w := range tc.notWant {
+				if strings.Contains(out, w) {
+					t.Errorf("output %q unexpectedly contains %q", out, w)
+				}
+			}
+		})
+	}
+}
+
+func TestRunHistoryJSONEmpty(t *testing.T) {
+	a := newTestApp(t, app.Config{Command: app.CmdHistory, Output: app.OutputJSON})
+	out, err := captureOutput(t, func(w io.Writer) error {
+		return runHistory(context.Background(), a, &mockClient{}, w)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(out) != "[]" {
+		t.Errorf("output = %q, want []", out)
+	}
+}
+
+func TestRunRequestsJSON(t *testing.T) {
+	a := newTestApp(t, app.Config{Command: app.CmdRequests, Output: app.OutputJSON})
+	out, err := captureOutput(t, func(w io.Writer) error {
+		return runRequests(context.Background(), a, &mockClient{}, w)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []azure.AssignmentRequest
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out, err)
+	}
+	if got == nil || len(got) != 0 {
+		t.Errorf("got %v, want empty array", got)
+	}
+}
+
 func TestRunStatus(t *testing.T) {
 	user := &azure.User{ID: "uid-1"}
 	activeAssignment := azure.ActiveAssignment{
@@ -343,7 +1181,7 @@ func TestRunStatus(t *testing.T) {
 				user:   user,
 				active: []azure.ActiveAssignment{activeAssignment},
 			},
-			wantOut: "[",
+			wantOut: `"schemaVersion": 1`,
 		},
 		{
 			name: "table output contains ROLE header",
@@ -357,13 +1195,27 @@ func TestRunStatus(t *testing.T) {
 			},
 			wantOut: "ROLE",
 		},
+		{
+			name: "scheduled assignment shows start time",
+			cfg:  app.Config{Command: app.CmdStatus},
+			client: &mockClient{
+				user: user,
+				scheduled: []azure.ActiveAssignment{{
+					RoleName:      "Reader",
+					Scope:         "/subscriptions/sub-1",
+					ScopeDisplay:  "My Sub",
+					StartDateTime: time.Now().Add(3 * time.Hour).UTC().Format(time.RFC3339),
+				}},
+			},
+			wantOut: "starts ",
+		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			a := newTestApp(t, tc.cfg)
 			out, err := captureOutput(t, func(w io.Writer) error {
-				return runStatus(context.Background(), a, tc.client, user, w)
+				return runStatus(context.Background(), a, tc.client, user, w, io.Discard)
 			})
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
@@ -386,18 +1238,27 @@ func TestRunStatusJSONValid(t *testing.T) {
 	}
 
 	out, err := captureOutput(t, func(w io.Writer) error {
-		return runStatus(context.Background(), a, client, user, w)
+		return runStatus(context.Background(), a, client, user, w, io.Discard)
 	})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	var result []azure.ActiveAssignment
+	var result StatusOutput
 	if err := json.Unmarshal([]byte(out), &result); err != nil {
-		t.Errorf("output is not valid JSON array: %v\noutput: %s", err, out)
+		t.Fatalf("output is not a valid status document: %v\noutput: %s", err, out)
+	}
+	if result.SchemaVersion != SchemaVersion || result.Command != app.CmdStatus {
+		t.Errorf("header = %d %q", result.SchemaVersion, result.Command)
+	}
+	if len(result.Assignments) != 1 {
+		t.Fatalf("want 1 assignment, got %d", len(result.Assignments))
 	}
-	if len(result) != 1 {
-		t.Errorf("want 1 element, got %d", len(result))
+	if got := result.Assignments[0]; got.Role != "Owner" || got.Scope != "/subscriptions/sub-1" || got.State != "active" {
+		t.Errorf("assignment = %+v", got)
+	}
+	if result.QueuedDeactivations == nil {
+		t.Error("queuedDeactivations should be an empty array, not null")
 	}
 }
 
@@ -408,7 +1269,7 @@ func TestFilterRolesBareGUID(t *testing.T) {
 		{RoleName: "Owner", Scope: "/subscriptions/other-sub", ScopeDisplay: "Other Sub"},
 	}
 
-	targets, err := filterRoles(context.Background(), &mockClient{}, roles, []string{"Owner"}, []string{guid})
+	targets, err := filterRoles(context.Background(), &mockClient{}, roles, []string{"Owner"}, []string{guid}, io.Discard)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -464,7 +1325,7 @@ func TestFilterRolesMGInheritedGUID(t *testing.T) {
 		},
 	}
 
-	targets, err := filterRoles(context.Background(), mc, roles, []string{"Owner"}, []string{childGUID})
+	targets, err := filterRoles(context.Background(), mc, roles, []string{"Owner"}, []string{childGUID}, io.Discard)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -487,7 +1348,7 @@ func TestFilterRolesMGInheritedGUIDSlashPrefix(t *testing.T) {
 		},
 	}
 
-	targets, err := filterRoles(context.Background(), mc, roles, []string{"Owner"}, []string{"/subscriptions/" + childGUID})
+	targets, err := filterRoles(context.Background(), mc, roles, []string{"Owner"}, []string{"/subscriptions/" + childGUID}, io.Discard)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -507,7 +1368,7 @@ func TestFilterRolesMGInheritedGUIDTrailingSlash(t *testing.T) {
 		},
 	}
 
-	targets, err := filterRoles(context.Background(), mc, roles, []string{"Owner"}, []string{"/subscriptions/" + childGUID + "/"})
+	targets, err := filterRoles(context.Background(), mc, roles, []string{"Owner"}, []string{"/subscriptions/" + childGUID + "/"}, io.Discard)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -519,6 +1380,40 @@ func TestFilterRolesMGInheritedGUIDTrailingSlash(t *testing.T) {
 	}
 }
 
+func TestFilterRolesMGInheritedResourceID(t *testing.T) {
+	roles := []azure.Role{mgRole()}
+	mc := &mockClient{
+		mgSubs: map[string][]azure.Subscription{
+			"mg-root": {{ID: childGUID}},
+		},
+	}
+	vault := "/subscriptions/" + childGUID + "/resourceGroups/rg-app/providers/Microsoft.KeyVault/vaults/kv-prod"
+
+	targets, err := filterRoles(context.Background(), mc, roles, []string{"Owner"}, []string{vault}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(targets) != 1 {
+		t.Fatalf("want 1 target, got %d", len(targets))
+	}
+	if targets[0].scope != vault {
+		t.Errorf("scope = %q; want %q", targets[0].scope, vault)
+	}
+}
+
+func TestFilterRolesSubscriptionResourceID(t *testing.T) {
+	roles := []azure.Role{{RoleName: "Reader", Scope: "/subscriptions/" + childGUID, ScopeDisplay: "sub-one"}}
+	vault := "/subscriptions/" + childGUID + "/resourceGroups/rg-app/providers/Microsoft.Storage/storageAccounts/stprod"
+
+	targets, err := filterRoles(context.Background(), &mockClient{}, roles, []string{"Reader"}, []string{vault}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(targets) != 1 || targets[0].scope != vault {
+		t.Fatalf("targets = %+v; want one at %q", targets, vault)
+	}
+}
+
 func TestFilterRolesMGGUIDNotChild(t *testing.T) {
 	roles := []azure.Role{mgRole()}
 	mc := &mockClient{
@@ -527,7 +1422,7 @@ func TestFilterRolesMGGUIDNotChild(t *testing.T) {
 		},
 	}
 
-	targets, err := filterRoles(context.Background(), mc, roles, []string{"Owner"}, []string{childGUID})
+	targets, err := filterRoles(context.Background(), mc, roles, []string{"Owner"}, []string{childGUID}, io.Discard)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -554,7 +1449,7 @@ func TestFilterRolesMGFanoutCachePerMG(t *testing.T) {
 		},
 	}
 
-	_, err := filterRoles(context.Background(), mc, roles, []string{"Owner", "Contributor"}, []string{childGUID, guid2})
+	_, err := filterRoles(context.Background(), mc, roles, []string{"Owner", "Contributor"}, []string{childGUID, guid2}, io.Discard)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -569,7 +1464,7 @@ func TestFilterRolesMGFanoutErrorWraps(t *testing.T) {
 		mgSubsErr: fmt.Errorf("api unavailable"),
 	}
 
-	_, err := filterRoles(context.Background(), mc, roles, []string{"Owner"}, []string{childGUID})
+	_, err := filterRoles(context.Background(), mc, roles, []string{"Owner"}, []string{childGUID}, io.Discard)
 	if err == nil {
 		t.Fatal("want error, got nil")
 	}
@@ -591,7 +1486,7 @@ func TestFilterRolesDirectSubGUIDNoExtraCall(t *testing.T) {
 	}
 	mc := &mockClient{}
 
-	targets, err := filterRoles(context.Background(), mc, roles, []string{"Owner"}, []string{guid})
+	targets, err := filterRoles(context.Background(), mc, roles, []string{"Owner"}, []string{guid}, io.Discard)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -637,6 +1532,7 @@ func TestFilterRolesMGFanoutSecondScopeDisplayFallback(t *testing.T) {
 	targets, err := filterRoles(context.Background(), mc, roles,
 		[]string{"Owner"},
 		[]string{mgChildGUID, "Direct Production"},
+		io.Discard,
 	)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -645,3 +1541,101 @@ func TestFilterRolesMGFanoutSecondScopeDisplayFallback(t *testing.T) {
 		t.Fatalf("want 2 targets, got %d: %+v", len(targets), targets)
 	}
 }
+
+func TestRunRoleShow(t *testing.T) {
+	eligible := []azure.Role{
+		{RoleName: "Website Contributor", Scope: "/subscriptions/sub-1", ScopeDisplay: "sub-one", RoleDefinitionID: "rd-web"},
+		{RoleName: "Website Contributor", Scope: "/subscriptions/sub-2", ScopeDisplay: "sub-two", RoleDefinitionID: "rd-web"},
+		{RoleName: "Contributor", Scope: "/subscriptions/sub-1", ScopeDisplay: "sub-one", RoleDefinitionID: "rd-c"},
+	}
+	defs := map[string]*azure.RoleDefinition{
+		"rd-web":      {Name: "Website Contributor", Description: "Manage websites", Actions: []string{"Microsoft.Web/sites/*"}},
+		"rd-c":        {Name: "Contributor", Actions: []string{"*"}, NotActions: []string{"Microsoft.Authorization/*/Write"}},
+		"Key Vault X": {Name: "Key Vault X", Custom: true, DataActions: []string{"Microsoft.KeyVault/vaults/secrets/*"}},
+	}
+
+	tests := []struct {
+		name      string
+		cfg       app.Config
+		wantScope string
+		want      []string
+		wantErr   string
+	}{
+		{
+			name:      "substring match across scopes",
+			cfg:       app.Config{Command: app.CmdRole, RoleQuery: "website"},
+			wantScope: "/subscriptions/sub-1",
+			want:      []string{"Website Contributor (built-in)", "Manage websites", "Actions:\n  Microsoft.Web/sites/*", "Data actions:\n  (none)"},
+		},
+		{
+			name:      "exact match narrowed by scope",
+			cfg:       app.Config{Command: app.CmdRole, RoleQuery: "Website Contributor", Scopes: []string{"sub-two"}},
+			wantScope: "/subscriptions/sub-2",
+			want:      []string{"Website Contributor"},
+		},
+		{
+			name: "exact wins over substring",
+			cfg:  app.Config{Command: app.CmdRole, RoleQuery: "contributor"},
+			want: []string{"Contributor (built-in)", "Not actions:\n  Microsoft.Authorization/*/Write"},
+		},
+		{
+			name: "not eligible falls back to name lookup",
+			cfg:  app.Config{Command: app.CmdRole, RoleQuery: "Key Vault X"},
+			want: []string{"Key Vault X (custom)", "Microsoft.KeyVault/vaults/secrets/*"},
+		},
+		{
+			name:      "name lookup at the scope of a named eligibility",
+			cfg:       app.Config{Command: app.CmdRole, RoleQuery: "Key Vault X", Scopes: []string{"sub-two"}},
+			wantScope: "/subscriptions/sub-2",
+			want:      []string{"Key Vault X (custom)"},
+		},
+		{
+			name:      "name lookup at a scope ID",
+			cfg:       app.Config{Command: app.CmdRole, RoleQuery: "Key Vault X", Scopes: []string{"/subscriptions/sub-9/resourceGroups/rg"}},
+			wantScope: "/subscriptions/sub-9/resourceGroups/rg",
+			want:      []string{"Key Vault X (custom)"},
+		},
+		{
+			name: "json",
+			cfg:  app.Config{Command: app.CmdRole, RoleQuery: "Contributor", Output: app.OutputJSON},
+			want: []string{`"roleName": "Contributor"`, `"notActions": [`},
+		},
+		{
+			name:    "scope excludes every match",
+			cfg:     app.Config{Command: app.CmdRole, RoleQuery: "Contributor", Scopes: []string{"sub-two"}},
+			wantErr: "no eligible Contributor role matches --scope",
+		},
+		{
+			name:    "unknown role",
+			cfg:     app.Config{Command: app.CmdRole, RoleQuery: "Nope"},
+			wantErr: "not found",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &mockClient{eligible: eligible, roleDefs: defs}
+			a := newTestApp(t, tc.cfg)
+			out, err := captureOutput(t, func(w io.Writer) error {
+				return runRoleShow(context.Background(), a, client, w)
+			})
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tc.wantScope != "" && client.roleDefScope != tc.wantScope {
+				t.Errorf("definition scope = %q, want %q", client.roleDefScope, tc.wantScope)
+			}
+			for _, w := range tc.want {
+				if !strings.Contains(out, w) {
+					t.Errorf("output %q does not contain %q", out, w)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package headless
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jeircul/pim/internal/azure"
+	"github.com/jeircul/pim/internal/state"
+)
+
+// SchemaVersion is the version of the documents status, activate, deactivate,
+// search, whoami and doctor print with --output json. Fields may be added
+// within a version; removing a field or changing its meaning bumps it.
+const SchemaVersion = 1
+
+// StatusOutput is the JSON document printed by pim status.
+type StatusOutput struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Command       string `json:"command"`
+	// Assignments lists active assignments, then scheduled ones.
+	Assignments []AssignmentOutput `json:"assignments"`
+	// QueuedDeactivations lists deactivations waiting for Azure's minimum
+	// active duration to pass.
+	QueuedDeactivations []QueuedDeactivationOutput `json:"queuedDeactivations"`
+}
+
+// AssignmentOutput is an active or scheduled role assignment.
+type AssignmentOutput struct {
+	Role             string `json:"role"`
+	RoleDefinitionID string `json:"roleDefinitionId"`
+	Scope            string `json:"scope"`
+	ScopeDisplay     string `json:"scopeDisplay"`
+	// MemberType is "Direct", "Group" or "Inherited".
+	MemberType string `json:"memberType,omitempty"`
+	// State is "active" or "scheduled".
+	State         string `json:"state"`
+	StartDateTime string `json:"startDateTime,omitempty"`
+	// EndDateTime is empty for permanent assignments.
+	EndDateTime string `json:"endDateTime,omitempty"`
+}
+
+// QueuedDeactivationOutput is a deactivation pim retries once NotBefore has
+// passed.
+type QueuedDeactivationOutput struct {
+	Role             string    `json:"role"`
+	RoleDefinitionID string    `json:"roleDefinitionId"`
+	Scope            string    `json:"scope"`
+	ScopeDisplay     string    `json:"scopeDisplay"`
+	NotBefore        time.Time `json:"notBefore"`
+	LastError        string    `json:"lastError,omitempty"`
+}
+
+// ResultsOutput is the JSON document printed by pim activate and pim
+// deactivate: one result per target, in target order.
+type ResultsOutput struct {
+	SchemaVersion int            `json:"schemaVersion"`
+	Command       string         `json:"command"`
+	Succeeded     int            `json:"succeeded"`
+	Failed        int            `json:"failed"`
+	Results       []TargetResult `json:"results"`
+}
+
+// Target statuses reported in TargetResult.Status.
+const (
+	StatusActivated       = "Activated"
+	StatusScheduled       = "Scheduled"
+	StatusPendingApproval = "PendingApproval"
+	StatusAlreadyActive   = "AlreadyActive"
+	StatusDeactivated     = "Deactivated"
+	StatusQueued          = "Queued"
+	StatusCancelled       = "Cancelled"
+	// StatusExtensionRequested and StatusRenewalRequested report a submitted
+	// eligibility extension or renewal; RequestStatus shows whether it awaits
+	// approval.
+	StatusExtensionRequested = "ExtensionRequested"
+	StatusRenewalRequested   = "RenewalRequested"
+	StatusFailed             = "Failed"
+)
+
+// TargetResult is the outcome of activating, deactivating, cancelling or
+// renewing one target.
+type TargetResult struct {
+	Role             string `json:"role"`
+	RoleDefinitionID string `json:"roleDefinitionId,omitempty"`
+	Scope            string `json:"scope"`
+	ScopeDisplay     string `json:"scopeDisplay,omitempty"`
+	// EligibilityScope is the scope of the eligibility an activation used;
+	// it differs from Scope when activating below it.
+	EligibilityScope string `json:"eligibilityScope,omitempty"`
+	MemberType       string `json:"memberType,omitempty"`
+	// Status is one of the Status constants.
+	Status    string `json:"status"`
+	RequestID string `json:"requestId,omitempty"`
+	// RequestStatus is the schedule request status Azure returned, e.g.
+	// "Provisioned", "PendingApproval" or "Revoked".
+	RequestStatus string       `json:"requestStatus,omitempty"`
+	StartDateTime string       `json:"startDateTime,omitempty"`
+	EndDateTime   string       `json:"endDateTime,omitempty"`
+	Error         *ErrorOutput `json:"error,omitempty"`
+
+	err error
+}
+
+// ErrorOutput describes why a target failed. Code, HTTPStatus and the request
+// IDs are set for errors returned by the Azure API.
+type ErrorOutput struct {
+	Message       string `json:"message"`
+	Code          string `json:"code,omitempty"`
+	HTTPStatus    int    `json:"httpStatus,omitempty"`
+	RequestID     string `json:"requestId,omitempty"`
+	CorrelationID string `json:"correlationId,omitempty"`
+}
+
+// SearchOutput is the JSON document printed by pim search.
+type SearchOutput struct {
+	SchemaVersion int         `json:"schemaVersion"`
+	Command       string      `json:"command"`
+	Subscriptions []SearchHit `json:"subscriptions"`
+}
+
+// WhoamiOutput is the JSON document printed by pim whoami.
+type WhoamiOutput struct {
+	SchemaVersion     int    `json:"schemaVersion"`
+	Command           string `json:"command"`
+	UserPrincipalName string `json:"userPrincipalName"`
+	DisplayName       string `json:"displayName,omitempty"`
+	ObjectID          string `json:"objectId"`
+	TenantID          string `json:"tenantId"`
+	CredentialSource  string `json:"credentialSource"`
+	Cloud             string `json:"cloud"`
+	// TokenExpiresOn is the expiry of the current ARM token.
+	TokenExpiresOn time.Time `json:"tokenExpiresOn"`
+}
+
+// DoctorOutput is the JSON document printed by pim doctor.
+type DoctorOutput struct {
+	SchemaVersion int           `json:"schemaVersion"`
+	Command       string        `json:"command"`
+	Failed        int           `json:"failed"`
+	Warnings      int           `json:"warnings"`
+	Checks        []DoctorCheck `json:"checks"`
+}
+
+// Doctor check statuses reported in DoctorCheck.Status.
+const (
+	CheckPass = "pass"
+	CheckWarn = "warn"
+	CheckFail = "fail"
+	CheckSkip = "skip"
+)
+
+// DoctorCheck is one line of the pim doctor report. Hint says how to fix a
+// failure or warning.
+type DoctorCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	Hint   string `json:"hint,omitempty"`
+}
+
+// fail records err as the target's failure.
+func (r *TargetResult) fail(err error) {
+	r.Status, r.err = StatusFailed, err
+	r.Error = &ErrorOutput{Message: err.Error()}
+	var apiErr *azure.APIError
+	if errors.As(err, &apiErr) {
+		r.Error.Code, r.Error.HTTPStatus = apiErr.Code, apiErr.StatusCode
+		r.Error.RequestID, r.Error.CorrelationID = apiErr.RequestID, apiErr.CorrelationID
+	}
+}
+
+// errorMessage returns the failure message, or "".
+func (r TargetResult) errorMessage() string {
+	if r.Error == nil {
+		return ""
+	}
+	return r.Error.Message
+}
+
+func assignmentOutput(a azure.ActiveAssignment) AssignmentOutput {
+	st := "active"
+	if a.IsScheduled() {
+		st = "scheduled"
+	}
+	return AssignmentOutput{
+		Role:             a.RoleName,
+		RoleDefinitionID: a.RoleDefinitionID,
+		Scope:            a.Scope,
+		ScopeDisplay:     azure.DefaultScopeDisplay(a.Scope, a.ScopeDisplay),
+		MemberType:       a.MemberType,
+		State:            st,
+		StartDateTime:    a.StartDateTime,
+		EndDateTime:      a.EndDateTime,
+	}
+}
+
+func queuedDeactivationOutput(q state.QueuedDeactivation) QueuedDeactivationOutput {
+	return QueuedDeactivationOutput{
+		Role:             q.Role,
+		RoleDefinitionID: q.RoleDefinitionID,
+		Scope:            q.Scope,
+		ScopeDisplay:     q.ScopeDisplay,
+		NotBefore:        q.NotBefore,
+		LastError:        q.LastError,
+	}
+}
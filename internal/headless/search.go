@@ -7,6 +7,7 @@ import (
 	"sort"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/jeircul/pim/internal/app"
 	"github.com/jeircul/pim/internal/azure"
@@ -19,6 +20,18 @@ type SearchHit struct {
 	ManagementGroup  string   `json:"managementGroup,omitempty"`
 	EligibilityScope string   `json:"eligibilityScope,omitempty"`
 	EligibleRoles    []string `json:"eligibleRoles"`
+	// EligibilityEnd is the earliest end (RFC 3339) among the eligibilities
+	// granting this subscription; empty when all of them are permanent.
+	EligibilityEnd string `json:"eligibilityEnd,omitempty"`
+}
+
+// EligibilityDisplay returns e.g. "expires in 5d" for EligibilityEnd, or
+// "permanent".
+func (h SearchHit) EligibilityDisplay() string {
+	if h.EligibilityEnd == "" {
+		return "permanent"
+	}
+	return strings.TrimPrefix(azure.Role{EligibilityEnd: h.EligibilityEnd}.EligibilityExpiryDisplay(), "eligibility ")
 }
 
 // runSearch lists PIM-eligible subscriptions, optionally filtered by query.
@@ -55,11 +68,11 @@ func runSearchWithErr(ctx context.Context, a *app.App, client ClientAPI, out io.
 		if hits == nil {
 			hits = []SearchHit{}
 		}
-		return jsonOut(hits, out)
+		return jsonOut(SearchOutput{SchemaVersion: SchemaVersion, Command: app.CmdSearch, Subscriptions: hits}, out)
 	}
 
 	if a.Config.Output == app.OutputTOML {
-		return tomlFromHits(hits, subRoleMap, out)
+		return tomlFromHits(hits, subRoleMap, a.Config.Tenant, out)
 	}
 
 	if len(hits) == 0 {
@@ -68,14 +81,14 @@ func runSearchWithErr(ctx context.Context, a *app.App, client ClientAPI, out io.
 	}
 
 	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(tw, "SUBSCRIPTION\tGUID\tMANAGEMENT GROUP\tELIGIBLE ROLES")
+	fmt.Fprintln(tw, "SUBSCRIPTION\tGUID\tMANAGEMENT GROUP\tELIGIBLE ROLES\tELIGIBILITY")
 	for _, h := range hits {
 		mg := h.ManagementGroup
 		if mg == "" {
 			mg = "(direct)"
 		}
-		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n",
-			h.DisplayName, h.SubscriptionID, mg, strings.Join(h.EligibleRoles, ","))
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+			h.DisplayName, h.SubscriptionID, mg, strings.Join(h.EligibleRoles, ","), h.EligibilityDisplay())
 	}
 	return tw.Flush()
 }
@@ -93,6 +106,7 @@ func buildSearchHits(ctx context.Context, client ClientAPI, roles []azure.Role,
 		display          string
 		mg               string
 		eligibilityScope string
+		eligibilityEnd   string
 		roles            map[string]struct{}
 	}
 	bySub := map[string]*acc{}
@@ -119,6 +133,9 @@ func buildSearchHits(ctx context.Context, client ClientAPI, roles []azure.Role,
 			a.eligibilityScope = eligibilityScope
 		}
 		a.roles[role.RoleName] = struct{}{}
+		if end := role.EligibilityEnd; end != "" && (a.eligibilityEnd == "" || earlier(end, a.eligibilityEnd)) {
+			a.eligibilityEnd = end
+		}
 		if _, ok := subRoleMap[key]; !ok {
 			subRoleMap[key] = map[string]azure.Role{}
 		}
@@ -183,13 +200,26 @@ func buildSearchHits(ctx context.Context, client ClientAPI, roles []azure.Role,
 			ManagementGroup:  a.mg,
 			EligibilityScope: a.eligibilityScope,
 			EligibleRoles:    names,
+			EligibilityEnd:   a.eligibilityEnd,
 		})
 	}
 	return out, subRoleMap, nil
 }
 
+// earlier reports whether RFC 3339 timestamp a is before b. Unparseable
+// values compare as strings.
+func earlier(a, b string) bool {
+	ta, errA := time.Parse(time.RFC3339, a)
+	tb, errB := time.Parse(time.RFC3339, b)
+	if errA != nil || errB != nil {
+		return a < b
+	}
+	return ta.Before(tb)
+}
+
 // favBlock is a single paste-ready favorite entry for --output toml.
 type favBlock struct {
+	tenant           string // --tenant the search ran against; empty for the default tenant
 	displayName      string
 	role             string
 	eligibilityScope string // /subscriptions/<guid> — activation target
@@ -200,8 +230,9 @@ type favBlock struct {
 // tomlFromHits emits one [[favorites]] block per (subscription, role) pair.
 // subRoleMap carries the exact azure.Role that granted each role to each
 // subscription — built by buildSearchHits at expansion time. This eliminates
-// any need to reconstruct the granting Role from MG IDs.
-func tomlFromHits(hits []SearchHit, subRoleMap map[string]map[string]azure.Role, out io.Writer) error {
+// any need to reconstruct the granting Role from MG IDs. tenant, when set, is
+// written to each block so the favorite activates in the searched tenant.
+func tomlFromHits(hits []SearchHit, subRoleMap map[string]map[string]azure.Role, tenant string, out io.Writer) error {
 	if len(hits) == 0 {
 		return nil
 	}
@@ -226,6 +257,7 @@ func tomlFromHits(hits []SearchHit, subRoleMap map[string]map[string]azure.Role,
 			seen[blockKey] = struct{}{}
 
 			b := favBlock{
+				tenant:           tenant,
 				displayName:      h.DisplayName,
 				role:             roleName,
 				eligibilityScope: subScope,
@@ -255,6 +287,9 @@ func tomlOut(blocks []favBlock, out io.Writer) error {
 		}
 		fmt.Fprintf(out, "[[favorites]]\n")
 		fmt.Fprintf(out, "label         = %q\n", b.role+" @ "+b.displayName)
+		if b.tenant != "" {
+			fmt.Fprintf(out, "tenant        = %q\n", b.tenant)
+		}
 		fmt.Fprintf(out, "role          = %q\n", b.role)
 		fmt.Fprintf(out, "scope         = %q\n", b.eligibilityScope)
 		if b.mgEligibility != "" {
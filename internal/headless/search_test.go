@@ -7,13 +7,16 @@ import (
 	"errors"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/jeircul/pim/internal/app"
 	"github.com/jeircul/pim/internal/azure"
 )
 
-// searchMock implements ClientAPI for search tests.
+// searchMock serves eligible roles and management group listings for search
+// tests.
 type searchMock struct {
+	fakeClient
 	eligibleRoles []azure.Role
 	mgSubs        map[string][]azure.Subscription
 	mgSubsErr     error
@@ -22,21 +25,9 @@ type searchMock struct {
 	mgParents     map[string]map[string]string
 }
 
-func (m *searchMock) GetCurrentUser(_ context.Context) (*azure.User, error) {
-	return &azure.User{}, nil
-}
-func (m *searchMock) GetActiveAssignments(_ context.Context) ([]azure.ActiveAssignment, error) {
-	return nil, nil
-}
 func (m *searchMock) GetEligibleRoles(_ context.Context) ([]azure.Role, error) {
 	return m.eligibleRoles, nil
 }
-func (m *searchMock) ActivateRole(_ context.Context, _ azure.Role, _, _ string, _ int, _ string) (*azure.ScheduleResponse, error) {
-	return nil, nil
-}
-func (m *searchMock) DeactivateRole(_ context.Context, _ azure.ActiveAssignment, _ string) (*azure.ScheduleResponse, error) {
-	return nil, nil
-}
 func (m *searchMock) ListAllSubscriptionsUnderMG(_ context.Context, mgID string) ([]azure.Subscription, map[string]string, []string, error) {
 	m.mgSubsCalls++
 	if m.mgSubsErr != nil {
@@ -55,30 +46,15 @@ func (m *searchMock) ListAllSubscriptionsUnderMG(_ context.Context, mgID string)
 
 // perMGErrorMock wraps searchMock and returns per-MG errors from ListAllSubscriptionsUnderMG.
 type perMGErrorMock struct {
-	base   *searchMock
+	*searchMock
 	errMGs map[string]error
 }
 
-func (m *perMGErrorMock) GetCurrentUser(ctx context.Context) (*azure.User, error) {
-	return m.base.GetCurrentUser(ctx)
-}
-func (m *perMGErrorMock) GetActiveAssignments(ctx context.Context) ([]azure.ActiveAssignment, error) {
-	return m.base.GetActiveAssignments(ctx)
-}
-func (m *perMGErrorMock) GetEligibleRoles(ctx context.Context) ([]azure.Role, error) {
-	return m.base.GetEligibleRoles(ctx)
-}
-func (m *perMGErrorMock) ActivateRole(ctx context.Context, r azure.Role, a, b string, d int, j string) (*azure.ScheduleResponse, error) {
-	return m.base.ActivateRole(ctx, r, a, b, d, j)
-}
-func (m *perMGErrorMock) DeactivateRole(ctx context.Context, a azure.ActiveAssignment, s string) (*azure.ScheduleResponse, error) {
-	return m.base.DeactivateRole(ctx, a, s)
-}
 func (m *perMGErrorMock) ListAllSubscriptionsUnderMG(ctx context.Context, mgID string) ([]azure.Subscription, map[string]string, []string, error) {
 	if err, ok := m.errMGs[mgID]; ok {
 		return nil, nil, nil, err
 	}
-	return m.base.ListAllSubscriptionsUnderMG(ctx, mgID)
+	return m.searchMock.ListAllSubscriptionsUnderMG(ctx, mgID)
 }
 
 func makeApp(query string, output app.OutputFormat) *app.App {
@@ -211,10 +187,11 @@ func TestRunSearchMergesSameSubMultipleRoles(t *testing.T) {
 	if err := runSearch(t.Context(), makeApp("", app.OutputJSON), mock, &buf); err != nil {
 		t.Fatal(err)
 	}
-	var hits []SearchHit
-	if err := json.Unmarshal(buf.Bytes(), &hits); err != nil {
+	var doc SearchOutput
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
 		t.Fatal(err)
 	}
+	hits := doc.Subscriptions
 	if len(hits) != 1 {
 		t.Fatalf("expected 1 hit, got %d: %+v", len(hits), hits)
 	}
@@ -224,6 +201,52 @@ func TestRunSearchMergesSameSubMultipleRoles(t *testing.T) {
 	}
 }
 
+func TestRunSearchEligibilityEndEarliest(t *testing.T) {
+	soon := time.Now().Add(5*24*time.Hour + time.Hour).UTC().Format(time.RFC3339)
+	later := time.Now().Add(90 * 24 * time.Hour).UTC().Format(time.RFC3339)
+	owner := subRole("/subscriptions/sub-shared", "Shared Sub", "Owner")
+	owner.EligibilityEnd = later
+	reader := subRole("/subscriptions/sub-shared", "Shared Sub", "Reader")
+	reader.EligibilityEnd = soon
+	mock := &searchMock{
+		eligibleRoles: []azure.Role{owner, reader, subRole("/subscriptions/sub-perm", "Perm Sub", "Reader")},
+	}
+
+	var buf bytes.Buffer
+	if err := runSearch(t.Context(), makeApp("", app.OutputJSON), mock, &buf); err != nil {
+		t.Fatal(err)
+	}
+	var doc SearchOutput
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	hits := doc.Subscriptions
+	if len(hits) != 2 {
+		t.Fatalf("expected 2 hits, got %d: %+v", len(hits), hits)
+	}
+	for _, h := range hits {
+		switch h.SubscriptionID {
+		case "sub-shared":
+			if h.EligibilityEnd != soon {
+				t.Errorf("EligibilityEnd = %q, want earliest %q", h.EligibilityEnd, soon)
+			}
+		case "sub-perm":
+			if h.EligibilityEnd != "" {
+				t.Errorf("EligibilityEnd = %q, want empty for permanent", h.EligibilityEnd)
+			}
+		}
+	}
+
+	buf.Reset()
+	if err := runSearch(t.Context(), makeApp("", app.OutputTable), mock, &buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "expires in 5d") || !strings.Contains(out, "permanent") {
+		t.Errorf("expected eligibility column in table output, got: %s", out)
+	}
+}
+
 func TestRunSearchQueryExactGUID(t *testing.T) {
 	mock := &searchMock{
 		eligibleRoles: []azure.Role{
@@ -309,9 +332,12 @@ func TestRunSearchNoMatchesJSON(t *testing.T) {
 	if err := runSearch(t.Context(), makeApp("nope", app.OutputJSON), mock, &buf); err != nil {
 		t.Fatal(err)
 	}
-	out := strings.TrimSpace(buf.String())
-	if out != "[]" {
-		t.Errorf("expected [], got: %s", out)
+	var doc SearchOutput
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("unmarshal: %v\noutput: %s", err, buf.String())
+	}
+	if doc.SchemaVersion != SchemaVersion || doc.Command != app.CmdSearch || doc.Subscriptions == nil || len(doc.Subscriptions) != 0 {
+		t.Errorf("expected an empty subscriptions array, got: %s", buf.String())
 	}
 }
 
@@ -325,10 +351,11 @@ func TestRunSearchJSONShape(t *testing.T) {
 	if err := runSearch(t.Context(), makeApp("", app.OutputJSON), mock, &buf); err != nil {
 		t.Fatal(err)
 	}
-	var hits []SearchHit
-	if err := json.Unmarshal(buf.Bytes(), &hits); err != nil {
+	var doc SearchOutput
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
 		t.Fatalf("unmarshal: %v\noutput: %s", err, buf.String())
 	}
+	hits := doc.Subscriptions
 	if len(hits) != 1 {
 		t.Fatalf("expected 1 hit, got %d", len(hits))
 	}
@@ -402,8 +429,8 @@ func TestBuildSearchHitsMGExpansionError(t *testing.T) {
 	_ = callCount
 
 	errMock := &perMGErrorMock{
-		base:   mock,
-		errMGs: map[string]error{"example-mg-err": errors.New("timeout expanding MG")},
+		searchMock: mock,
+		errMGs:     map[string]error{"example-mg-err": errors.New("timeout expanding MG")},
 	}
 
 	var stdout, stderr bytes.Buffer
@@ -444,10 +471,11 @@ func TestRunSearchJSONPreservesGUIDCase(t *testing.T) {
 	if err := runSearch(t.Context(), makeApp("", app.OutputJSON), mock, &buf); err != nil {
 		t.Fatal(err)
 	}
-	var hits []SearchHit
-	if err := json.Unmarshal(buf.Bytes(), &hits); err != nil {
+	var doc SearchOutput
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
 		t.Fatalf("unmarshal: %v\noutput: %s", err, buf.String())
 	}
+	hits := doc.Subscriptions
 	if len(hits) != 1 {
 		t.Fatalf("expected 1 hit, got %d", len(hits))
 	}
@@ -537,10 +565,11 @@ func TestRunSearchDirectSubGetsMGFromParents(t *testing.T) {
 	if err := runSearch(t.Context(), makeApp("", app.OutputJSON), mock, &buf); err != nil {
 		t.Fatal(err)
 	}
-	var hits []SearchHit
-	if err := json.Unmarshal(buf.Bytes(), &hits); err != nil {
+	var doc SearchOutput
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
 		t.Fatalf("unmarshal: %v\noutput: %s", err, buf.String())
 	}
+	hits := doc.Subscriptions
 	var sub2Hit *SearchHit
 	for i := range hits {
 		if hits[i].SubscriptionID == "sub-2" {
@@ -565,10 +594,11 @@ func TestRunSearchDirectSubMGBlankWhenNoWalk(t *testing.T) {
 	if err := runSearch(t.Context(), makeApp("", app.OutputJSON), mock, &buf); err != nil {
 		t.Fatal(err)
 	}
-	var hits []SearchHit
-	if err := json.Unmarshal(buf.Bytes(), &hits); err != nil {
+	var doc SearchOutput
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
 		t.Fatalf("unmarshal: %v\noutput: %s", err, buf.String())
 	}
+	hits := doc.Subscriptions
 	if len(hits) != 1 {
 		t.Fatalf("expected 1 hit, got %d", len(hits))
 	}
@@ -646,10 +676,11 @@ func TestBuildSearchHitsMGColumnUsesDirectParent(t *testing.T) {
 	if err := runSearch(t.Context(), makeApp("", app.OutputJSON), mock, &buf); err != nil {
 		t.Fatal(err)
 	}
-	var hits []SearchHit
-	if err := json.Unmarshal(buf.Bytes(), &hits); err != nil {
+	var doc SearchOutput
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
 		t.Fatalf("unmarshal: %v\noutput: %s", err, buf.String())
 	}
+	hits := doc.Subscriptions
 	if len(hits) != 1 {
 		t.Fatalf("expected 1 hit, got %d", len(hits))
 	}
@@ -737,25 +768,11 @@ func TestRunSearchMGFilterNoMatch(t *testing.T) {
 
 // perMGCallMock records which MG IDs were passed to ListAllSubscriptionsUnderMG.
 type perMGCallMock struct {
+	fakeClient
 	subs  map[string][]azure.Subscription
 	calls map[string]int
 }
 
-func (m *perMGCallMock) GetCurrentUser(_ context.Context) (*azure.User, error) {
-	return &azure.User{}, nil
-}
-func (m *perMGCallMock) GetActiveAssignments(_ context.Context) ([]azure.ActiveAssignment, error) {
-	return nil, nil
-}
-func (m *perMGCallMock) GetEligibleRoles(_ context.Context) ([]azure.Role, error) {
-	return nil, nil
-}
-func (m *perMGCallMock) ActivateRole(_ context.Context, _ azure.Role, _, _ string, _ int, _ string) (*azure.ScheduleResponse, error) {
-	return nil, nil
-}
-func (m *perMGCallMock) DeactivateRole(_ context.Context, _ azure.ActiveAssignment, _ string) (*azure.ScheduleResponse, error) {
-	return nil, nil
-}
 func (m *perMGCallMock) ListAllSubscriptionsUnderMG(_ context.Context, mgID string) ([]azure.Subscription, map[string]string, []string, error) {
 	m.calls[mgID]++
 	parents := map[string]string{}
@@ -895,6 +912,27 @@ func TestRunSearchTOMLOutput(t *testing.T) {
 	}
 }
 
+func TestRunSearchTOMLOutputTenant(t *testing.T) {
+	mock := &searchMock{
+		eligibleRoles: []azure.Role{
+			{
+				RoleName:     "Contributor",
+				Scope:        "/subscriptions/00000000-0000-0000-0000-000000000000",
+				ScopeDisplay: "my-subscription",
+			},
+		},
+	}
+	a := makeApp("", app.OutputTOML)
+	a.Config.Tenant = "11111111-1111-1111-1111-111111111111"
+	var buf strings.Builder
+	if err := runSearch(t.Context(), a, mock, &buf); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.Contains(out, `tenant        = "11111111-1111-1111-1111-111111111111"`) {
+		t.Errorf("expected tenant in TOML output, got:\n%s", out)
+	}
+}
+
 func TestTomlFromHitsParentChildMG(t *testing.T) {
 	// Sub is physically under child-mg-a, but the eligibility is granted at the
 	// ancestor parent-mg. The role loop looks up by parent-mg's ID, while the
@@ -0,0 +1,140 @@
+package headless
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/jeircul/pim/internal/app"
+	"github.com/jeircul/pim/internal/azure"
+)
+
+// requestID returns the schedule request name, which is its ID for ARM and
+// Graph requests alike.
+func requestID(resp *azure.ScheduleResponse) string {
+	if resp == nil {
+		return ""
+	}
+	return resp.Name
+}
+
+// forEachTarget calls fn for indexes 0..n-1, at most parallel at a time, and
+// returns once every call has finished.
+func forEachTarget(n, parallel int, fn func(i int)) {
+	sem := make(chan struct{}, max(parallel, 1))
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(i)
+		}()
+	}
+	wg.Wait()
+}
+
+// progress serialises the per-target lines printed as targets finish. It
+// prints nothing for JSON output, which carries the results instead.
+type progress struct {
+	mu  sync.Mutex
+	out io.Writer
+	off bool
+}
+
+func newProgress(out io.Writer, format app.OutputFormat) *progress {
+	return &progress{out: out, off: format == app.OutputJSON}
+}
+
+func (p *progress) printf(format string, args ...any) {
+	if p.off {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fmt.Fprintf(p.out, format, args...)
+}
+
+// writeResults prints results as a ResultsOutput document for JSON output,
+// or as a summary table after the progress lines when there was more than
+// one target.
+func writeResults(out io.Writer, format app.OutputFormat, command string, results []TargetResult) error {
+	if format == app.OutputJSON {
+		doc := ResultsOutput{SchemaVersion: SchemaVersion, Command: command, Results: results}
+		for _, r := range results {
+			if r.err != nil {
+				doc.Failed++
+			} else {
+				doc.Succeeded++
+			}
+		}
+		return jsonOut(doc, out)
+	}
+	if len(results) < 2 {
+		return nil
+	}
+	fmt.Fprintln(out)
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ROLE\tSCOPE\tSTATUS\tREQUEST ID\tERROR")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", r.Role, r.Scope, r.Status, r.RequestID, firstLine(r.errorMessage()))
+	}
+	return tw.Flush()
+}
+
+// resultsError returns an error naming every failed target, or nil when all
+// succeeded. verb describes the operation ("activate", "deactivate"). When
+// only some targets failed the error is marked as a partial failure.
+func resultsError(verb string, results []TargetResult) error {
+	var errs []error
+	for _, r := range results {
+		if r.err != nil {
+			errs = append(errs, fmt.Errorf("%s %s @ %s: %w", verb, r.Role, r.Scope, r.err))
+		}
+	}
+	var err error
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		err = errs[0]
+	default:
+		err = fmt.Errorf("%d of %d targets failed:\n%w", len(errs), len(results), errors.Join(errs...))
+	}
+	if len(errs) < len(results) {
+		return app.Mark(app.ErrPartialFailure, err)
+	}
+	return err
+}
+
+// outcomeError reports activations that succeeded without granting access
+// yet: any pending approval, or every target already being active.
+func outcomeError(results []TargetResult) error {
+	var pending, active int
+	for _, r := range results {
+		switch r.Status {
+		case StatusPendingApproval:
+			pending++
+		case StatusAlreadyActive:
+			active++
+		}
+	}
+	switch {
+	case pending > 0:
+		return app.Mark(app.ErrPendingApproval,
+			fmt.Errorf("%d of %d activations pending approval; see 'pim requests'", pending, len(results)))
+	case active > 0 && active == len(results):
+		return app.Mark(app.ErrAlreadyActive, fmt.Errorf("already active: %d of %d targets", active, len(results)))
+	}
+	return nil
+}
+
+// firstLine truncates s at its first newline for table cells.
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
@@ -0,0 +1,325 @@
+package headless
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jeircul/pim/internal/app"
+	"github.com/jeircul/pim/internal/azure"
+)
+
+// parallelMock fails the targets in failScopes, caps activations in
+// shortPolicy at 30 minutes, and records how many requests were in flight at
+// once.
+type parallelMock struct {
+	*mockClient
+	failScopes  map[string]bool
+	shortPolicy map[string]bool
+
+	mu         sync.Mutex
+	inFlight   int
+	peak       int
+	calls      int
+	policyPeak int // most policy reads in flight at once
+	policyRuns int
+}
+
+func (m *parallelMock) enter() {
+	m.mu.Lock()
+	m.calls++
+	m.inFlight++
+	m.peak = max(m.peak, m.inFlight)
+	m.mu.Unlock()
+	time.Sleep(20 * time.Millisecond)
+	m.mu.Lock()
+	m.inFlight--
+	m.mu.Unlock()
+}
+
+func (m *parallelMock) GetActivationPolicy(_ context.Context, _ azure.Role, scope string) (azure.ActivationPolicy, error) {
+	m.mu.Lock()
+	m.policyRuns++
+	m.policyPeak = max(m.policyPeak, m.policyRuns)
+	m.mu.Unlock()
+	time.Sleep(20 * time.Millisecond)
+	m.mu.Lock()
+	m.policyRuns--
+	m.mu.Unlock()
+	if m.shortPolicy[scope] {
+		return azure.ActivationPolicy{MaxMinutes: 30}, nil
+	}
+	return azure.ActivationPolicy{}, nil
+}
+
+func (m *parallelMock) ActivateRole(_ context.Context, req azure.ActivationRequest) (*azure.ScheduleResponse, error) {
+	m.enter()
+	if m.failScopes[req.TargetScope] {
+		return nil, &azure.APIError{StatusCode: 403, Code: "AuthorizationFailed", Message: "denied at " + req.TargetScope}
+	}
+	resp := &azure.ScheduleResponse{Name: "req-" + req.TargetScope[len(req.TargetScope)-1:]}
+	resp.Properties.Status = azure.StatusProvisioned
+	return resp, nil
+}
+
+func (m *parallelMock) DeactivateRole(_ context.Context, a azure.ActiveAssignment, _ string) (*azure.ScheduleResponse, error) {
+	m.enter()
+	if m.failScopes[a.Scope] {
+		return nil, errors.New("boom at " + a.Scope)
+	}
+	resp := &azure.ScheduleResponse{Name: "deact"}
+	resp.Properties.Status = "Revoked"
+	return resp, nil
+}
+
+func (m *parallelMock) CancelRequest(_ context.Context, req azure.AssignmentRequest) error {
+	m.enter()
+	if m.failScopes[req.Scope] {
+		return errors.New("boom at " + req.Scope)
+	}
+	return nil
+}
+
+func (m *parallelMock) RenewEligibility(_ context.Context, req azure.RenewalRequest) (*azure.ScheduleResponse, error) {
+	m.enter()
+	if m.failScopes[req.Role.Scope] {
+		return nil, &azure.APIError{StatusCode: 400, Code: "RoleEligibilityRequestPolicyValidationFailed", Message: "denied at " + req.Role.Scope}
+	}
+	resp := &azure.ScheduleResponse{Name: "renew"}
+	resp.Properties.Status = azure.StatusPendingAdminDecision
+	return resp, nil
+}
+
+func TestRunActivateParallel(t *testing.T) {
+	user := &azure.User{ID: "uid-1"}
+	var eligible []azure.Role
+	var scopes []string
+	for i := range 6 {
+		scope := fmt.Sprintf("/subscriptions/sub-%d", i)
+		scopes = append(scopes, scope)
+		eligible = append(eligible, azure.Role{RoleName: "Reader", Scope: scope, RoleDefinitionID: "rd-r"})
+	}
+	client := &parallelMock{
+		mockClient: &mockClient{user: user, eligible: eligible},
+		failScopes: map[string]bool{scopes[1]: true, scopes[4]: true},
+	}
+	a := newTestApp(t, app.Config{
+		Command: app.CmdActivate, Roles: []string{"Reader"}, Scopes: scopes,
+		TimeStr: "1h", Justification: "patching", Parallel: 3,
+	})
+
+	out, err := captureOutput(t, func(w io.Writer) error {
+		return runActivate(context.Background(), a, client, user, w, io.Discard)
+	})
+	if client.calls != 6 || client.peak > 3 || client.peak < 2 {
+		t.Errorf("calls = %d, peak in flight = %d; want 6 calls, at most 3 at once", client.calls, client.peak)
+	}
+	if err == nil {
+		t.Fatal("want error for the failed targets")
+	}
+	for _, want := range []string{"2 of 6 targets failed", "activate Reader @ /subscriptions/sub-1:", "activate Reader @ /subscriptions/sub-4:"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+	var apiErr *azure.APIError
+	if !errors.As(err, &apiErr) {
+		t.Error("aggregated error does not wrap the API errors")
+	}
+	if code := app.ExitCode(err); code != app.ExitPartialFailure {
+		t.Errorf("exit code = %d, want %d", code, app.ExitPartialFailure)
+	}
+	for _, want := range []string{"ROLE", "REQUEST ID", "req-0", "Failed", "denied at /subscriptions/sub-4"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("summary %q does not contain %q", out, want)
+		}
+	}
+	if got := len(a.Store.RecentActivations()); got != 4 {
+		t.Errorf("recorded %d recent activations, want 4", got)
+	}
+}
+
+func TestRunActivatePolicyPerTarget(t *testing.T) {
+	user := &azure.User{ID: "uid-1"}
+	var eligible []azure.Role
+	var scopes []string
+	for i := range 4 {
+		scope := fmt.Sprintf("/subscriptions/sub-%d", i)
+		scopes = append(scopes, scope)
+		eligible = append(eligible, azure.Role{RoleName: "Reader", Scope: scope, RoleDefinitionID: "rd-r"})
+	}
+	client := &parallelMock{
+		mockClient:  &mockClient{user: user, eligible: eligible},
+		shortPolicy: map[string]bool{scopes[2]: true},
+	}
+	a := newTestApp(t, app.Config{
+		Command: app.CmdActivate, Roles: []string{"Reader"}, Scopes: scopes,
+		TimeStr: "1h", Justification: "patching", Parallel: 4,
+	})
+
+	out, err := captureOutput(t, func(w io.Writer) error {
+		return runActivate(context.Background(), a, client, user, w, io.Discard)
+	})
+	if err == nil || !strings.Contains(err.Error(), "activate Reader @ /subscriptions/sub-2: activation blocked by role management policy") {
+		t.Fatalf("err = %v, want the sub-2 policy violation", err)
+	}
+	if code := app.ExitCode(err); code != app.ExitPartialFailure {
+		t.Errorf("exit code = %d, want %d", code, app.ExitPartialFailure)
+	}
+	if !strings.Contains(out, "Activated: Reader @ /subscriptions/sub-3") {
+		t.Errorf("output %q: the other targets should still be activated", out)
+	}
+	if client.calls != 3 {
+		t.Errorf("activated %d targets, want 3", client.calls)
+	}
+	if client.policyPeak < 2 {
+		t.Errorf("policy reads peaked at %d in flight; want them checked in the parallel workers", client.policyPeak)
+	}
+}
+
+func TestRunDeactivateParallelJSON(t *testing.T) {
+	user := &azure.User{ID: "uid-1"}
+	var active []azure.ActiveAssignment
+	for i := range 3 {
+		scope := fmt.Sprintf("/subscriptions/sub-%d", i)
+		active = append(active, azure.ActiveAssignment{RoleName: "Reader", Scope: scope, ScopeDisplay: scope,
+			RoleDefinitionID: "rd-r", EndDateTime: "2099-01-01T00:00:00Z"})
+	}
+	client := &parallelMock{
+		mockClient: &mockClient{user: user, active: active},
+		failScopes: map[string]bool{"/subscriptions/sub-2": true},
+	}
+	a := newTestApp(t, app.Config{Command: app.CmdDeactivate, Yes: true, Parallel: 4, Output: app.OutputJSON})
+
+	out, err := captureOutput(t, func(w io.Writer) error {
+		return runDeactivate(context.Background(), a, client, user, w, io.Discard)
+	})
+	if err == nil || !strings.Contains(err.Error(), "deactivate Reader @ /subscriptions/sub-2: boom") {
+		t.Errorf("err = %v, want the sub-2 failure", err)
+	}
+	var doc ResultsOutput
+	if jerr := json.Unmarshal([]byte(out), &doc); jerr != nil {
+		t.Fatalf("output is not a JSON results document: %v\n%s", jerr, out)
+	}
+	if doc.SchemaVersion != SchemaVersion || doc.Command != app.CmdDeactivate || doc.Succeeded != 2 || doc.Failed != 1 {
+		t.Errorf("document header = %+v", doc)
+	}
+	results := doc.Results
+	if len(results) != 3 || results[0].Status != StatusDeactivated || results[0].RequestID != "deact" ||
+		results[0].RequestStatus != "Revoked" || results[0].EndDateTime != "2099-01-01T00:00:00Z" ||
+		results[2].Status != StatusFailed || results[2].Error == nil || results[2].Error.Message == "" {
+		t.Errorf("results = %+v", results)
+	}
+}
+
+func TestRunActivateExitCodes(t *testing.T) {
+	user := &azure.User{ID: "uid-1"}
+	eligible := []azure.Role{
+		{RoleName: "Reader", Scope: "/subscriptions/sub-1", RoleDefinitionID: "rd-r"},
+		{RoleName: "Reader and Data Access", Scope: "/subscriptions/sub-1", RoleDefinitionID: "rd-rd"},
+		{RoleName: "Contributor", Scope: "/subscriptions/sub-1", RoleDefinitionID: "rd-c"},
+	}
+	tests := []struct {
+		name   string
+		roles  []string
+		client *mockClient
+		want   int
+	}{
+		{"activated", []string{"Contributor"}, &mockClient{}, app.ExitOK},
+		{"no match", []string{"Owner"}, &mockClient{}, app.ExitNoMatch},
+		{"ambiguous", []string{"Read"}, &mockClient{}, app.ExitAmbiguous},
+		{"policy", []string{"Contributor"}, &mockClient{policy: azure.ActivationPolicy{MaxMinutes: 30}}, app.ExitPolicy},
+		{"azure policy", []string{"Contributor"}, &mockClient{activateErr: &azure.APIError{StatusCode: 400,
+			Code: "RoleAssignmentRequestPolicyValidationFailed", Message: "JustificationRule"}}, app.ExitPolicy},
+		{"auth", []string{"Contributor"}, &mockClient{activateErr: &azure.TokenError{Scope: "arm", Err: errors.New("az login")}}, app.ExitAuth},
+		{"pending approval", []string{"Contributor"}, &mockClient{activateState: azure.StatusPendingApproval}, app.ExitPendingApproval},
+		{"already active", []string{"Contributor"}, &mockClient{alreadyActive: true}, app.ExitAlreadyActive},
+		{"other failure", []string{"Contributor"}, &mockClient{activateErr: errors.New("boom")}, app.ExitError},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.client.user, tc.client.eligible = user, eligible
+			a := newTestApp(t, app.Config{Command: app.CmdActivate, Roles: tc.roles, TimeStr: "1h", Justification: "x"})
+			_, err := captureOutput(t, func(w io.Writer) error {
+				return runActivate(context.Background(), a, tc.client, user, w, io.Discard)
+			})
+			if code := app.ExitCode(err); code != tc.want {
+				t.Errorf("exit code = %d (err %v), want %d", code, err, tc.want)
+			}
+		})
+	}
+}
+
+func TestRunCancelPartialFailure(t *testing.T) {
+	var pending []azure.AssignmentRequest
+	for i := range 3 {
+		scope := fmt.Sprintf("/subscriptions/sub-%d", i)
+		pending = append(pending, azure.AssignmentRequest{ID: fmt.Sprintf("req-%d", i), RoleName: "Owner",
+			Scope: scope, ScopeDisplay: scope, Status: azure.StatusPendingApproval})
+	}
+	client := &parallelMock{
+		mockClient: &mockClient{pending: pending},
+		failScopes: map[string]bool{"/subscriptions/sub-1": true},
+	}
+	a := newTestApp(t, app.Config{Command: app.CmdRequests, Cancel: true, Yes: true, Parallel: 2, Output: app.OutputJSON})
+
+	out, err := captureOutput(t, func(w io.Writer) error {
+		return runRequests(context.Background(), a, client, w)
+	})
+	if err == nil || !strings.Contains(err.Error(), "cancel Owner @ /subscriptions/sub-1: boom") {
+		t.Errorf("err = %v, want the sub-1 failure", err)
+	}
+	if code := app.ExitCode(err); code != app.ExitPartialFailure {
+		t.Errorf("exit code = %d, want %d", code, app.ExitPartialFailure)
+	}
+	if client.calls != 3 {
+		t.Errorf("cancelled %d requests, want 3 attempts", client.calls)
+	}
+	var doc ResultsOutput
+	if jerr := json.Unmarshal([]byte(out), &doc); jerr != nil {
+		t.Fatalf("output is not a JSON results document: %v\n%s", jerr, out)
+	}
+	if doc.Command != app.CmdRequests || doc.Succeeded != 2 || doc.Failed != 1 {
+		t.Errorf("document header = %+v", doc)
+	}
+	if r := doc.Results[0]; r.Status != StatusCancelled || r.RequestID != "req-0" {
+		t.Errorf("results[0] = %+v", r)
+	}
+}
+
+func TestRunRenewPartialFailure(t *testing.T) {
+	user := &azure.User{ID: "uid-1"}
+	end := time.Now().Add(72 * time.Hour).UTC().Format(time.RFC3339)
+	var eligible []azure.Role
+	for i := range 3 {
+		eligible = append(eligible, azure.Role{RoleName: "Reader", Scope: fmt.Sprintf("/subscriptions/sub-%d", i),
+			RoleDefinitionID: "rd-r", EligibilityEnd: end})
+	}
+	client := &parallelMock{
+		mockClient: &mockClient{eligible: eligible},
+		failScopes: map[string]bool{"/subscriptions/sub-2": true},
+	}
+	a := newTestApp(t, app.Config{Command: app.CmdRenew, Roles: []string{"Reader"}, Justification: "x", Parallel: 3})
+
+	out, err := captureOutput(t, func(w io.Writer) error {
+		return runRenew(context.Background(), a, client, user, w, io.Discard)
+	})
+	var apiErr *azure.APIError
+	if err == nil || !strings.Contains(err.Error(), "renew Reader @ /subscriptions/sub-2:") || !errors.As(err, &apiErr) {
+		t.Errorf("err = %v, want the sub-2 API error", err)
+	}
+	if code := app.ExitCode(err); code != app.ExitPartialFailure {
+		t.Errorf("exit code = %d, want %d", code, app.ExitPartialFailure)
+	}
+	for _, want := range []string{"Extension pending approval: Reader @ sub-0", "ExtensionRequested", "Failed", "denied at /subscriptions/sub-2"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output %q does not contain %q", out, want)
+		}
+	}
+}
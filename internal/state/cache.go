@@ -0,0 +1,158 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	cacheDir = "cache"
+
+	// DefaultCacheTTL is how long cached responses are served before they
+	// are fetched again.
+	DefaultCacheTTL = time.Hour
+	// ActiveCacheTTL caps the age of cached active assignments, which change
+	// outside pim (portal activations, expiry) far more often than
+	// eligibilities.
+	ActiveCacheTTL = 2 * time.Minute
+)
+
+// Response cache keys.
+const (
+	CacheEligibleRoles     = "eligible-roles"
+	CacheActiveAssignments = "active-assignments"
+)
+
+// CacheMGSubscriptions returns the cache key for the subscriptions listed
+// under management group mgID.
+func CacheMGSubscriptions(mgID string) string { return "mg-subscriptions-" + mgID }
+
+// ResponseCache stores API responses under <config dir>/cache, one JSON file
+// per tenant, principal and key. A zero TTL disables it.
+type ResponseCache struct {
+	dir string
+	ttl time.Duration
+}
+
+type cacheEntry struct {
+	SavedAt time.Time       `json:"saved_at"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// ResponseCache returns the response cache, expiring entries after
+// [cache] ttl from config.toml (default DefaultCacheTTL).
+func (s *Store) ResponseCache() (*ResponseCache, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ttl := DefaultCacheTTL
+	if v := s.Config.Cache.TTL; v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("cache: invalid ttl %q: must be a duration such as 30m, or 0 to disable", v)
+		}
+		ttl = d
+	}
+	return &ResponseCache{dir: filepath.Join(s.dir, cacheDir), ttl: ttl}, nil
+}
+
+// ClearCache removes every cached response.
+func (s *Store) ClearCache() error {
+	if err := os.RemoveAll(filepath.Join(s.dir, cacheDir)); err != nil {
+		return fmt.Errorf("clear cache: %w", err)
+	}
+	return nil
+}
+
+// Enabled reports whether the cache stores anything.
+func (c *ResponseCache) Enabled() bool { return c != nil && c.ttl > 0 }
+
+// Get decodes the entry for tenant, principal and key into v. It reports
+// false when the entry is missing, unreadable, or older than its TTL.
+func (c *ResponseCache) Get(tenant, principal, key string, v any) bool {
+	if !c.Enabled() {
+		return false
+	}
+	data, err := os.ReadFile(c.path(tenant, principal, key))
+	if err != nil {
+		return false
+	}
+	var e cacheEntry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return false
+	}
+	if age := time.Since(e.SavedAt); age < 0 || age >= c.ttlFor(key) {
+		return false
+	}
+	return json.Unmarshal(e.Data, v) == nil
+}
+
+// Has reports whether an entry, fresh or expired, exists for tenant,
+// principal and key.
+func (c *ResponseCache) Has(tenant, principal, key string) bool {
+	if !c.Enabled() {
+		return false
+	}
+	_, err := os.Stat(c.path(tenant, principal, key))
+	return err == nil
+}
+
+// Put stores v as the entry for tenant, principal and key.
+func (c *ResponseCache) Put(tenant, principal, key string, v any) error {
+	if !c.Enabled() {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("encode cache entry: %w", err)
+	}
+	entry, err := json.Marshal(cacheEntry{SavedAt: time.Now(), Data: data})
+	if err != nil {
+		return fmt.Errorf("encode cache entry: %w", err)
+	}
+	path := c.path(tenant, principal, key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create cache dir: %w", err)
+	}
+	return writeAtomic(path, entry)
+}
+
+// Invalidate removes the entry for tenant, principal and key.
+func (c *ResponseCache) Invalidate(tenant, principal, key string) error {
+	if c == nil {
+		return nil
+	}
+	if err := os.Remove(c.path(tenant, principal, key)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("invalidate cache entry: %w", err)
+	}
+	return nil
+}
+
+func (c *ResponseCache) ttlFor(key string) time.Duration {
+	if key == CacheActiveAssignments {
+		return min(c.ttl, ActiveCacheTTL)
+	}
+	return c.ttl
+}
+
+// path returns the entry file; an empty tenant is the credential's home tenant.
+func (c *ResponseCache) path(tenant, principal, key string) string {
+	if tenant == "" {
+		tenant = "home"
+	}
+	return filepath.Join(c.dir, cacheName(tenant), cacheName(principal), cacheName(key)+".json")
+}
+
+// cacheName makes s safe to use as a single path element.
+func cacheName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-':
+			return r
+		}
+		return '_'
+	}, strings.ToLower(s))
+}
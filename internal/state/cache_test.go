@@ -0,0 +1,138 @@
+package state
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestResponseCache(t *testing.T) {
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := s.ResponseCache()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	if c.Get("t-1", "p-1", CacheEligibleRoles, &got) || c.Has("t-1", "p-1", CacheEligibleRoles) {
+		t.Fatal("empty cache reported a hit")
+	}
+	if err := c.Put("t-1", "p-1", CacheEligibleRoles, []string{"Owner"}); err != nil {
+		t.Fatal(err)
+	}
+	if !c.Get("t-1", "p-1", CacheEligibleRoles, &got) || len(got) != 1 || got[0] != "Owner" {
+		t.Fatalf("Get = %v, want [Owner]", got)
+	}
+	for _, other := range [][2]string{{"t-2", "p-1"}, {"t-1", "p-2"}, {"", "p-1"}} {
+		if c.Get(other[0], other[1], CacheEligibleRoles, &got) {
+			t.Errorf("entry leaked to tenant %q principal %q", other[0], other[1])
+		}
+	}
+
+	if err := c.Invalidate("t-1", "p-1", CacheEligibleRoles); err != nil {
+		t.Fatal(err)
+	}
+	if c.Has("t-1", "p-1", CacheEligibleRoles) {
+		t.Error("entry still present after Invalidate")
+	}
+	if err := c.Invalidate("t-1", "p-1", CacheEligibleRoles); err != nil {
+		t.Errorf("Invalidate of a missing entry: %v", err)
+	}
+
+	if err := c.Put("t-1", "p-1", CacheMGSubscriptions("mg/../x"), 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ClearCache(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(s.dir, cacheDir)); !os.IsNotExist(err) {
+		t.Errorf("cache dir survived ClearCache: %v", err)
+	}
+}
+
+func TestResponseCacheExpiry(t *testing.T) {
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := s.ResponseCache()
+	if err != nil {
+		t.Fatal(err)
+	}
+	age := func(key string, d time.Duration) {
+		t.Helper()
+		data, _ := json.Marshal(cacheEntry{SavedAt: time.Now().Add(-d), Data: json.RawMessage(`1`)})
+		path := c.path("t", "p", key)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var v int
+
+	age(CacheEligibleRoles, 30*time.Minute)
+	if !c.Get("t", "p", CacheEligibleRoles, &v) {
+		t.Error("30m old eligible roles expired under the default TTL")
+	}
+	age(CacheEligibleRoles, 2*time.Hour)
+	if c.Get("t", "p", CacheEligibleRoles, &v) {
+		t.Error("2h old eligible roles served under the default TTL")
+	}
+	if !c.Has("t", "p", CacheEligibleRoles) {
+		t.Error("Has = false for an expired entry")
+	}
+	age(CacheActiveAssignments, 5*time.Minute)
+	if c.Get("t", "p", CacheActiveAssignments, &v) {
+		t.Errorf("5m old active assignments served; cap is %v", ActiveCacheTTL)
+	}
+}
+
+func TestStoreResponseCacheTTL(t *testing.T) {
+	tests := []struct {
+		ttl     string
+		want    time.Duration
+		enabled bool
+		wantErr bool
+	}{
+		{"", DefaultCacheTTL, true, false},
+		{"15m", 15 * time.Minute, true, false},
+		{"0", 0, false, false},
+		{"-1m", 0, false, true},
+		{"soon", 0, false, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.ttl, func(t *testing.T) {
+			s, err := New(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			s.Config.Cache.TTL = tc.ttl
+			c, err := s.ResponseCache()
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.ttl != tc.want || c.Enabled() != tc.enabled {
+				t.Errorf("ttl = %v, enabled = %v; want %v, %v", c.ttl, c.Enabled(), tc.want, tc.enabled)
+			}
+			if err := c.Put("t", "p", CacheEligibleRoles, 1); err != nil {
+				t.Fatal(err)
+			}
+			if got := c.Has("t", "p", CacheEligibleRoles); got != tc.enabled {
+				t.Errorf("Has after Put = %v, want %v", got, tc.enabled)
+			}
+		})
+	}
+}
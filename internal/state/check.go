@@ -0,0 +1,105 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/jeircul/pim/internal/azure"
+)
+
+// FileCheck reports on the config directory or one of the store's files.
+type FileCheck struct {
+	Path   string
+	Dir    bool
+	Exists bool
+	Mode   fs.FileMode
+	// Err is why the directory is unusable or the file does not load,
+	// including config values pim otherwise only validates when used.
+	Err error
+}
+
+// Check inspects dir, config.toml and state.toml without creating or
+// changing anything. A missing file is not an error; New uses defaults.
+func Check(dir string) []FileCheck {
+	checks := []FileCheck{checkPath(dir, true)}
+	if !checks[0].Exists || checks[0].Err != nil {
+		return checks
+	}
+
+	cfg := checkPath(filepath.Join(dir, configFile), false)
+	if cfg.Exists && cfg.Err == nil {
+		s := &Store{dir: dir}
+		if _, err := toml.DecodeFile(cfg.Path, &s.Config); err != nil {
+			cfg.Err = err
+		} else {
+			cfg.Err = validateConfig(s)
+		}
+	}
+
+	st := checkPath(filepath.Join(dir, stateFile), false)
+	if st.Exists && st.Err == nil {
+		var state State
+		_, st.Err = toml.DecodeFile(st.Path, &state)
+	}
+	return append(checks, cfg, st)
+}
+
+func checkPath(path string, dir bool) FileCheck {
+	c := FileCheck{Path: path, Dir: dir}
+	info, err := os.Stat(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return c
+	case err != nil:
+		c.Err = err
+		return c
+	}
+	c.Exists, c.Mode = true, info.Mode()
+	if info.IsDir() != dir {
+		if dir {
+			c.Err = fmt.Errorf("%s is not a directory", path)
+		} else {
+			c.Err = fmt.Errorf("%s is a directory", path)
+		}
+		return c
+	}
+	if dir {
+		_, c.Err = os.ReadDir(path)
+	} else if f, err := os.Open(path); err != nil {
+		c.Err = err
+	} else {
+		f.Close()
+	}
+	return c
+}
+
+// validateConfig checks the settings New accepts but later calls reject.
+func validateConfig(s *Store) error {
+	var errs []error
+	if _, err := s.RetryPolicy(); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := s.ResponseCache(); err != nil {
+		errs = append(errs, err)
+	}
+	if n, err := s.Network(); err != nil {
+		errs = append(errs, err)
+	} else if _, err := n.HTTPClient(); err != nil {
+		errs = append(errs, fmt.Errorf("network: %w", err))
+	}
+	if _, err := azure.ParseCredentialSources(strings.Join(s.AuthSources(), ",")); err != nil {
+		errs = append(errs, fmt.Errorf("auth: %w", err))
+	}
+	// Custom endpoints may come from the environment; Connect checks them.
+	if name, _ := s.CloudPreference(); name != "" && !strings.EqualFold(name, azure.CloudCustom) {
+		if _, err := azure.LookupCloud(name, azure.Cloud{}); err != nil {
+			errs = append(errs, fmt.Errorf("preferences: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -1,6 +1,7 @@
 package state
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -19,21 +20,37 @@ const (
 	maxRecentActs  = 10
 )
 
-// Favorite is a saved role+scope+duration combo.
+// Favorite is a saved role+scope+duration combo. Group targets a PIM for
+// Groups membership or ownership by group name or ID instead of an ARM scope.
+// Tenant, when set, is the tenant ID the favorite activates in.
 type Favorite struct {
 	Label            string `toml:"label"`
+	Tenant           string `toml:"tenant,omitempty"`
 	Role             string `toml:"role"`
 	Scope            string `toml:"scope"`
+	Group            string `toml:"group,omitempty"`
 	Duration         string `toml:"duration"`
 	Justification    string `toml:"justification"`
+	Ticket           string `toml:"ticket,omitempty"`
+	TicketSystem     string `toml:"ticket_system,omitempty"`
 	EligibilityScope string `toml:"eligibility_scope,omitempty"`
 	ScheduleID       string `toml:"schedule_id,omitempty"`
 	Key              int    `toml:"key"`
 }
 
 // Complete reports whether all four required activation fields are set.
+// Either Scope or Group satisfies the target requirement.
 func (f Favorite) Complete() bool {
-	return f.Role != "" && f.Scope != "" && f.Duration != "" && f.Justification != ""
+	return f.Role != "" && f.Target() != "" && f.Duration != "" && f.Justification != ""
+}
+
+// Target returns the scope filter the favorite activates at: Scope when set,
+// otherwise Group.
+func (f Favorite) Target() string {
+	if f.Scope != "" {
+		return f.Scope
+	}
+	return f.Group
 }
 
 // MissingFields returns a comma-separated list of unset required fields.
@@ -42,8 +59,8 @@ func (f Favorite) MissingFields() string {
 	if f.Role == "" {
 		missing = append(missing, "role")
 	}
-	if f.Scope == "" {
-		missing = append(missing, "scope")
+	if f.Target() == "" {
+		missing = append(missing, "scope or group")
 	}
 	if f.Duration == "" {
 		missing = append(missing, "duration")
@@ -56,6 +73,7 @@ func (f Favorite) MissingFields() string {
 
 // RecentActivation records a successfully completed role activation.
 type RecentActivation struct {
+	Tenant           string    `toml:"tenant,omitempty"`
 	Role             string    `toml:"role"`
 	Scope            string    `toml:"scope"`
 	ScopeDisplay     string    `toml:"scope_display"`
@@ -63,25 +81,110 @@ type RecentActivation struct {
 	ScheduleID       string    `toml:"schedule_id,omitempty"`
 	Duration         string    `toml:"duration"`
 	Justification    string    `toml:"justification"`
+	Ticket           string    `toml:"ticket,omitempty"`
+	TicketSystem     string    `toml:"ticket_system,omitempty"`
 	ActivatedAt      time.Time `toml:"activated_at"`
 }
 
+// QueuedDeactivation is a deactivation Azure rejected because the activation
+// was too recent. pim retries it once NotBefore has passed.
+type QueuedDeactivation struct {
+	Tenant           string    `toml:"tenant,omitempty"`
+	PrincipalID      string    `toml:"principal_id"`
+	Role             string    `toml:"role"`
+	RoleDefinitionID string    `toml:"role_definition_id"`
+	Scope            string    `toml:"scope"`
+	ScopeDisplay     string    `toml:"scope_display"`
+	NotBefore        time.Time `toml:"not_before"`
+	// ExpiresAt is when the assignment ends on its own; zero if permanent.
+	ExpiresAt time.Time `toml:"expires_at,omitempty"`
+	// LastError is the error of the most recent failed retry.
+	LastError string `toml:"last_error,omitempty"`
+}
+
+// Assignment returns the active assignment the entry deactivates.
+func (q QueuedDeactivation) Assignment() azure.ActiveAssignment {
+	return azure.ActiveAssignment{
+		Scope:            q.Scope,
+		ScopeDisplay:     q.ScopeDisplay,
+		RoleName:         q.Role,
+		RoleDefinitionID: q.RoleDefinitionID,
+	}
+}
+
 // Preferences holds user-editable preferences.
 type Preferences struct {
 	DefaultDuration string `toml:"default_duration"`
+	// Cloud selects the Azure cloud profile; the endpoint fields override
+	// individual endpoints and are required for the "Custom" cloud.
+	Cloud         string `toml:"cloud,omitempty"`
+	AuthorityHost string `toml:"authority_host,omitempty"`
+	ARMEndpoint   string `toml:"arm_endpoint,omitempty"`
+	GraphEndpoint string `toml:"graph_endpoint,omitempty"`
+}
+
+// Auth selects the credential sources to try, in order (e.g. "azure-cli",
+// "device-code"); empty uses the default chain.
+type Auth struct {
+	Sources []string `toml:"sources,omitempty"`
+}
+
+// Retry overrides the request retry policy. Unset fields keep the defaults;
+// max_retries = 0 disables retries.
+type Retry struct {
+	MaxRetries *int   `toml:"max_retries,omitempty"`
+	BaseDelay  string `toml:"base_delay,omitempty"`
+	MaxDelay   string `toml:"max_delay,omitempty"`
+}
+
+// Cache configures the on-disk response cache. TTL is a duration such as
+// "30m"; "0" disables the cache.
+type Cache struct {
+	TTL string `toml:"ttl,omitempty"`
+}
+
+// Network configures the proxy, extra CA certificates and request timeout.
+// A relative ca_bundle is resolved against the config directory; timeout is
+// a duration such as "2m".
+type Network struct {
+	Proxy    string   `toml:"proxy,omitempty"`
+	NoProxy  []string `toml:"no_proxy,omitempty"`
+	CABundle string   `toml:"ca_bundle,omitempty"`
+	Timeout  string   `toml:"timeout,omitempty"`
+}
+
+// Tenant is a tenant listed in config.toml for the tenant switcher and the
+// dashboard's cross-tenant view.
+type Tenant struct {
+	ID    string `toml:"id"`
+	Label string `toml:"label,omitempty"`
+}
+
+// Display returns the label, or the ID when no label is set.
+func (t Tenant) Display() string {
+	if t.Label != "" {
+		return t.Label
+	}
+	return t.ID
 }
 
 // Config is the hand-editable config file (~/.config/pim/config.toml).
 type Config struct {
 	Preferences Preferences `toml:"preferences"`
+	Auth        Auth        `toml:"auth"`
+	Retry       Retry       `toml:"retry"`
+	Cache       Cache       `toml:"cache"`
+	Network     Network     `toml:"network"`
+	Tenants     []Tenant    `toml:"tenants"`
 	Favorites   []Favorite  `toml:"favorites"`
 }
 
 // State is auto-managed runtime state (~/.config/pim/state.toml).
 type State struct {
-	Version              int                `toml:"version"`
-	RecentJustifications []string           `toml:"recent_justifications"`
-	RecentActivations    []RecentActivation `toml:"recent_activations"`
+	Version              int                  `toml:"version"`
+	RecentJustifications []string             `toml:"recent_justifications"`
+	RecentActivations    []RecentActivation   `toml:"recent_activations"`
+	QueuedDeactivations  []QueuedDeactivation `toml:"queued_deactivations,omitempty"`
 }
 
 // Store manages persistent config and state files.
@@ -96,11 +199,10 @@ type Store struct {
 // Pass an empty string to use the platform default: $XDG_CONFIG_HOME/pim on Linux/macOS (falls back to ~/.config/pim), %APPDATA%\pim on Windows.
 func New(dir string) (*Store, error) {
 	if dir == "" {
-		cfgDir, err := os.UserConfigDir()
-		if err != nil {
-			return nil, fmt.Errorf("locate config dir: %w", err)
+		var err error
+		if dir, err = DefaultDir(); err != nil {
+			return nil, err
 		}
-		dir = filepath.Join(cfgDir, "pim")
 	}
 	if err := os.MkdirAll(dir, 0o700); err != nil {
 		return nil, fmt.Errorf("create config dir: %w", err)
@@ -126,6 +228,18 @@ func New(dir string) (*Store, error) {
 	return s, nil
 }
 
+// DefaultDir returns the platform default config directory New uses.
+func DefaultDir() (string, error) {
+	cfgDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("locate config dir: %w", err)
+	}
+	return filepath.Join(cfgDir, "pim"), nil
+}
+
+// Dir returns the config directory the store reads and writes.
+func (s *Store) Dir() string { return s.dir }
+
 // Favorites returns a copy of the configured favorites slice.
 func (s *Store) Favorites() []Favorite {
 	s.mu.Lock()
@@ -155,6 +269,115 @@ func (s *Store) DefaultDuration() string {
 	return d
 }
 
+// Tenants returns the configured tenants followed by any other tenant a
+// favorite refers to, deduplicated case-insensitively by ID.
+func (s *Store) Tenants() []Tenant {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seen := make(map[string]bool)
+	var out []Tenant
+	add := func(t Tenant) {
+		k := strings.ToLower(strings.TrimSpace(t.ID))
+		if k == "" || seen[k] {
+			return
+		}
+		seen[k] = true
+		out = append(out, t)
+	}
+	for _, t := range s.Config.Tenants {
+		add(t)
+	}
+	for _, f := range s.Config.Favorites {
+		add(Tenant{ID: f.Tenant})
+	}
+	return out
+}
+
+// TenantLabel returns the configured label for tenantID, or tenantID itself.
+func (s *Store) TenantLabel(tenantID string) string {
+	for _, t := range s.Tenants() {
+		if strings.EqualFold(t.ID, tenantID) {
+			return t.Display()
+		}
+	}
+	return tenantID
+}
+
+// AuthSources returns a copy of the configured credential sources.
+func (s *Store) AuthSources() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.Config.Auth.Sources...)
+}
+
+// RetryPolicy returns the configured retry policy, or nil when [retry] is
+// not set.
+func (s *Store) RetryPolicy() (*azure.RetryPolicy, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := s.Config.Retry
+	if r.MaxRetries == nil && r.BaseDelay == "" && r.MaxDelay == "" {
+		return nil, nil
+	}
+	p := azure.DefaultRetryPolicy
+	if r.MaxRetries != nil {
+		if *r.MaxRetries < 0 {
+			return nil, fmt.Errorf("retry: max_retries must not be negative, got %d", *r.MaxRetries)
+		}
+		p.MaxRetries = *r.MaxRetries
+	}
+	for _, f := range []struct {
+		name string
+		val  string
+		dst  *time.Duration
+	}{
+		{"base_delay", r.BaseDelay, &p.BaseDelay},
+		{"max_delay", r.MaxDelay, &p.MaxDelay},
+	} {
+		if f.val == "" {
+			continue
+		}
+		d, err := time.ParseDuration(f.val)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("retry: invalid %s %q: must be a positive duration such as 2s", f.name, f.val)
+		}
+		*f.dst = d
+	}
+	return &p, nil
+}
+
+// Network returns the configured network settings. Unset fields are left
+// zero for azure.NetworkConfig's defaults.
+func (s *Store) Network() (azure.NetworkConfig, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := s.Config.Network
+	cfg := azure.NetworkConfig{Proxy: n.Proxy, NoProxy: append([]string(nil), n.NoProxy...), CABundle: n.CABundle}
+	if cfg.CABundle != "" && !filepath.IsAbs(cfg.CABundle) {
+		cfg.CABundle = filepath.Join(s.dir, cfg.CABundle)
+	}
+	if n.Timeout != "" {
+		d, err := time.ParseDuration(n.Timeout)
+		if err != nil || d <= 0 {
+			return azure.NetworkConfig{}, fmt.Errorf("network: invalid timeout %q: must be a positive duration such as 2m", n.Timeout)
+		}
+		cfg.Timeout = d
+	}
+	return cfg, nil
+}
+
+// CloudPreference returns the configured cloud name and endpoint overrides.
+func (s *Store) CloudPreference() (string, azure.Cloud) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.Config.Preferences
+	return p.Cloud, azure.Cloud{
+		AuthorityHost: p.AuthorityHost,
+		ARMEndpoint:   p.ARMEndpoint,
+		GraphEndpoint: p.GraphEndpoint,
+	}
+}
+
 // SaveConfig persists config.toml.
 func (s *Store) SaveConfig() error {
 	s.mu.Lock()
@@ -199,18 +422,17 @@ func (s *Store) RecentActivations() []RecentActivation {
 	return out
 }
 
-// AddRecentActivation prepends an activation record, deduped on role+scope+duration (max 10).
-// Duplicate entries (same role, scope, and duration, case-insensitive) are moved to front
+// AddRecentActivation prepends an activation record, deduped on tenant+role+scope+duration (max 10).
+// Duplicate entries (same tenant, role, scope, and duration, case-insensitive) are moved to front
 // with a refreshed ActivatedAt.
 func (s *Store) AddRecentActivation(a RecentActivation) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	key := strings.ToLower(a.Role) + "|" + strings.ToLower(a.Scope) + "|" + strings.ToLower(a.Duration)
+	key := recentKey(a)
 	out := make([]RecentActivation, 0, maxRecentActs)
 	out = append(out, a)
 	for _, existing := range s.State.RecentActivations {
-		k := strings.ToLower(existing.Role) + "|" + strings.ToLower(existing.Scope) + "|" + strings.ToLower(existing.Duration)
-		if k == key {
+		if recentKey(existing) == key {
 			continue
 		}
 		out = append(out, existing)
@@ -221,6 +443,53 @@ func (s *Store) AddRecentActivation(a RecentActivation) {
 	s.State.RecentActivations = out
 }
 
+func recentKey(a RecentActivation) string {
+	return strings.ToLower(a.Tenant) + "|" + strings.ToLower(a.Role) + "|" + strings.ToLower(a.Scope) + "|" + strings.ToLower(a.Duration)
+}
+
+// QueuedDeactivations returns a copy of the deactivation queue.
+func (s *Store) QueuedDeactivations() []QueuedDeactivation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]QueuedDeactivation, len(s.State.QueuedDeactivations))
+	copy(out, s.State.QueuedDeactivations)
+	return out
+}
+
+// QueueDeactivation adds q to the deactivation queue, replacing any entry for
+// the same tenant, principal, role and scope.
+func (s *Store) QueueDeactivation(q QueuedDeactivation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := queuedKey(q)
+	for i, existing := range s.State.QueuedDeactivations {
+		if queuedKey(existing) == key {
+			s.State.QueuedDeactivations[i] = q
+			return
+		}
+	}
+	s.State.QueuedDeactivations = append(s.State.QueuedDeactivations, q)
+}
+
+// RemoveQueuedDeactivation drops the queue entry for q's tenant, principal,
+// role and scope.
+func (s *Store) RemoveQueuedDeactivation(q QueuedDeactivation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := queuedKey(q)
+	out := s.State.QueuedDeactivations[:0]
+	for _, existing := range s.State.QueuedDeactivations {
+		if queuedKey(existing) != key {
+			out = append(out, existing)
+		}
+	}
+	s.State.QueuedDeactivations = out
+}
+
+func queuedKey(q QueuedDeactivation) string {
+	return strings.ToLower(q.Tenant) + "|" + strings.ToLower(q.PrincipalID) + "|" + strings.ToLower(q.RoleDefinitionID) + "|" + strings.ToLower(q.Scope)
+}
+
 // FavoriteByKey returns the favorite assigned to a number key (1-9).
 func (s *Store) FavoriteByKey(key int) (Favorite, bool) {
 	s.mu.Lock()
@@ -280,16 +549,26 @@ func (s *Store) loadState() error {
 }
 
 func (s *Store) write(name string, v any) error {
-	path := filepath.Join(s.dir, name)
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(v); err != nil {
+		return fmt.Errorf("encode %s: %w", name, err)
+	}
+	return writeAtomic(filepath.Join(s.dir, name), buf.Bytes())
+}
+
+// writeAtomic writes data to a temporary file next to path and renames it
+// into place, so readers never see a partial file.
+func writeAtomic(path string, data []byte) error {
+	name := filepath.Base(path)
 	tmp := path + ".tmp." + fmt.Sprintf("%d", time.Now().UnixNano())
 	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
 	if err != nil {
 		return fmt.Errorf("open %s: %w", name, err)
 	}
-	if err := toml.NewEncoder(f).Encode(v); err != nil {
+	if _, err := f.Write(data); err != nil {
 		f.Close()
 		os.Remove(tmp)
-		return fmt.Errorf("encode %s: %w", name, err)
+		return fmt.Errorf("write %s: %w", name, err)
 	}
 	if err := f.Close(); err != nil {
 		os.Remove(tmp)
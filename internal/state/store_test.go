@@ -2,7 +2,12 @@ package state
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/jeircul/pim/internal/azure"
 )
 
 func TestStoreRecentJustifications(t *testing.T) {
@@ -133,6 +138,16 @@ func TestFavoriteComplete(t *testing.T) {
 			fav:  Favorite{Role: "Reader", Scope: "/sub/abc", Duration: "1h"},
 			want: false,
 		},
+		{
+			name: "group instead of scope",
+			fav:  Favorite{Role: "Group Member", Group: "sg-admins", Duration: "1h", Justification: "reason"},
+			want: true,
+		},
+		{
+			name: "missing scope and group",
+			fav:  Favorite{Role: "Reader", Duration: "1h", Justification: "reason"},
+			want: false,
+		},
 		{
 			name: "missing role",
 			fav:  Favorite{Scope: "/sub/abc", Duration: "1h", Justification: "reason"},
@@ -236,3 +251,166 @@ func TestRecentActivationEligibilityScope(t *testing.T) {
 		t.Errorf("expected empty eligibility scope for old entry, got %q", acts[0].EligibilityScope)
 	}
 }
+
+func TestStoreTenants(t *testing.T) {
+	dir := t.TempDir()
+	s, err := New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Config.Tenants = []Tenant{{ID: "aaaa", Label: "Contoso"}, {ID: "bbbb"}}
+	s.UpsertFavorite(Favorite{Label: "home", Role: "Reader", Scope: "/subscriptions/abc"})
+	s.UpsertFavorite(Favorite{Label: "contoso", Tenant: "AAAA", Role: "Reader", Scope: "/subscriptions/abc"})
+	s.UpsertFavorite(Favorite{Label: "fabrikam", Tenant: "cccc", Role: "Reader", Scope: "/subscriptions/def"})
+
+	got := s.Tenants()
+	want := []string{"aaaa", "bbbb", "cccc"}
+	if len(got) != len(want) {
+		t.Fatalf("Tenants() = %v, want IDs %v", got, want)
+	}
+	for i, id := range want {
+		if got[i].ID != id {
+			t.Errorf("Tenants()[%d].ID = %q, want %q", i, got[i].ID, id)
+		}
+	}
+	if l := s.TenantLabel("AAAA"); l != "Contoso" {
+		t.Errorf("TenantLabel(AAAA) = %q, want Contoso", l)
+	}
+	if l := s.TenantLabel("cccc"); l != "cccc" {
+		t.Errorf("TenantLabel(cccc) = %q, want cccc", l)
+	}
+}
+
+func TestAddRecentActivationPerTenant(t *testing.T) {
+	dir := t.TempDir()
+	s, err := New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.AddRecentActivation(RecentActivation{Tenant: "aaaa", Role: "Reader", Scope: "/subscriptions/x", Duration: "1h"})
+	s.AddRecentActivation(RecentActivation{Tenant: "bbbb", Role: "Reader", Scope: "/subscriptions/x", Duration: "1h"})
+	if acts := s.RecentActivations(); len(acts) != 2 {
+		t.Fatalf("expected activations in different tenants to be kept apart, got %v", acts)
+	}
+}
+
+func TestStoreRetryPolicy(t *testing.T) {
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, err := s.RetryPolicy(); err != nil || p != nil {
+		t.Fatalf("RetryPolicy() without [retry] = %v, %v; want nil, nil", p, err)
+	}
+
+	zero := 0
+	s.Config.Retry = Retry{MaxRetries: &zero, MaxDelay: "10s"}
+	p, err := s.RetryPolicy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.MaxRetries != 0 || p.MaxDelay != 10*time.Second || p.BaseDelay != azure.DefaultRetryPolicy.BaseDelay {
+		t.Errorf("RetryPolicy() = %+v", *p)
+	}
+
+	s.Config.Retry = Retry{BaseDelay: "fast"}
+	if _, err := s.RetryPolicy(); err == nil {
+		t.Error("expected error for invalid base_delay")
+	}
+}
+
+func TestStoreNetwork(t *testing.T) {
+	dir := t.TempDir()
+	s, err := New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := s.Network(); err != nil || n.Proxy != "" || n.CABundle != "" || n.Timeout != 0 {
+		t.Fatalf("Network() without [network] = %+v, %v; want zero", n, err)
+	}
+
+	s.Config.Network = Network{Proxy: "http://proxy:3128", NoProxy: []string{".corp"}, CABundle: "corp-ca.pem", Timeout: "2m"}
+	n, err := s.Network()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.Proxy != "http://proxy:3128" || len(n.NoProxy) != 1 || n.Timeout != 2*time.Minute {
+		t.Errorf("Network() = %+v", n)
+	}
+	if want := filepath.Join(dir, "corp-ca.pem"); n.CABundle != want {
+		t.Errorf("CABundle = %q, want %q", n.CABundle, want)
+	}
+
+	s.Config.Network = Network{Timeout: "-1s"}
+	if _, err := s.Network(); err == nil {
+		t.Error("expected error for negative timeout")
+	}
+}
+
+func TestQueuedDeactivations(t *testing.T) {
+	dir := t.TempDir()
+	s, err := New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notBefore := time.Date(2026, 1, 2, 10, 5, 0, 0, time.UTC)
+	q1 := QueuedDeactivation{PrincipalID: "p1", Role: "Contributor", RoleDefinitionID: "/rd/c", Scope: "/subscriptions/aaa", NotBefore: notBefore}
+	q2 := QueuedDeactivation{PrincipalID: "p1", Role: "Reader", RoleDefinitionID: "/rd/r", Scope: "/subscriptions/aaa", NotBefore: notBefore}
+	s.QueueDeactivation(q1)
+	s.QueueDeactivation(q2)
+	s.QueueDeactivation(QueuedDeactivation{PrincipalID: "P1", Role: "Contributor", RoleDefinitionID: "/RD/C", Scope: "/subscriptions/AAA", NotBefore: notBefore, LastError: "retry"})
+
+	queued := s.QueuedDeactivations()
+	if len(queued) != 2 || queued[0].LastError != "retry" {
+		t.Fatalf("queue = %+v, want the Contributor entry replaced in place", queued)
+	}
+	if err := s.SaveState(); err != nil {
+		t.Fatal(err)
+	}
+
+	s2, err := New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s2.RemoveQueuedDeactivation(q1)
+	queued = s2.QueuedDeactivations()
+	if len(queued) != 1 || queued[0].Role != "Reader" || !queued[0].NotBefore.Equal(notBefore) {
+		t.Fatalf("queue after reload and remove = %+v", queued)
+	}
+	if a := queued[0].Assignment(); a.RoleDefinitionID != "/rd/r" || a.Scope != "/subscriptions/aaa" {
+		t.Errorf("Assignment() = %+v", a)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	checks := Check(dir)
+	if len(checks) != 3 || !checks[0].Exists || checks[1].Exists || checks[2].Exists {
+		t.Fatalf("fresh dir: %+v", checks)
+	}
+	for _, c := range checks {
+		if c.Err != nil {
+			t.Errorf("%s: unexpected error %v", c.Path, c.Err)
+		}
+	}
+
+	cfg := "[retry]\nbase_delay = \"soon\"\n\n[auth]\nsources = [\"carrier-pigeon\"]\n\n[network]\nca_bundle = \"missing.pem\"\n"
+	if err := os.WriteFile(filepath.Join(dir, configFile), []byte(cfg), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, stateFile), []byte("version = ["), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	checks = Check(dir)
+	if err := checks[1].Err; err == nil || !strings.Contains(err.Error(), "base_delay") || !strings.Contains(err.Error(), "carrier-pigeon") || !strings.Contains(err.Error(), "CA bundle") {
+		t.Errorf("config.toml error = %v, want the invalid retry, auth and network settings", err)
+	}
+	if checks[2].Err == nil {
+		t.Error("state.toml parse error not reported")
+	}
+
+	if checks := Check(filepath.Join(dir, "missing")); len(checks) != 1 || checks[0].Exists {
+		t.Errorf("missing dir: %+v", checks)
+	}
+}
@@ -3,6 +3,7 @@ package activate
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"charm.land/bubbles/v2/key"
 	tea "charm.land/bubbletea/v2"
@@ -12,14 +13,23 @@ import (
 )
 
 // Result holds the outcome of a single activation or deactivation.
+// Status is the request status Azure reported, e.g. "PendingApproval".
+// Start is set when the activation was scheduled for a later time.
 type Result struct {
 	RoleName         string
 	Scope            string
 	EligibilityScope string
 	ScheduleID       string
+	Status           string
+	Start            time.Time
 	Err              error
 }
 
+// Pending reports whether the request was accepted but waits on an approver.
+func (r Result) Pending() bool {
+	return r.Err == nil && azure.IsAwaitingApproval(r.Status)
+}
+
 // ConfirmDoneMsg is sent when all activations have completed (success or partial failure).
 type ConfirmDoneMsg struct {
 	Results []Result
@@ -30,6 +40,7 @@ type activationItem struct {
 	role        azure.Role
 	targetScope string // may differ from role.Scope for MG-scoped
 	status      itemStatus
+	reqStatus   string // Azure request status once submitted
 	err         error
 }
 
@@ -38,49 +49,65 @@ type itemStatus int
 const (
 	statusPending itemStatus = iota
 	statusRunning
+	statusProvisioning
 	statusDone
 	statusFailed
 )
 
 type activationResultMsg struct {
+	idx  int
+	resp *azure.ScheduleResponse
+	err  error
+}
+
+// provisionResultMsg reports the end of the wait for one item's assignment.
+type provisionResultMsg struct {
 	idx int
 	err error
 }
 
+// provisionStatusMsg reports a request status observed while waiting for one
+// item's assignment. next delivers the item's following message.
+type provisionStatusMsg struct {
+	idx    int
+	status string
+	next   tea.Cmd
+}
+
 // Confirm is Step 4: shows a summary and executes activations.
 type Confirm struct {
-	theme         styles.Theme
-	keys          styles.KeyMap
-	spinner       components.Spinner
-	items         []activationItem
-	minutes       int
-	justification string
-	principalID   string
-	submitted     bool
-	width         int
-	height        int
-	activateFunc  func(role azure.Role, principalID, justification string, minutes int, targetScope string) error
-}
-
-// NewConfirm creates a Confirm model.
+	theme        styles.Theme
+	keys         styles.KeyMap
+	spinner      components.Spinner
+	items        []activationItem
+	base         azure.ActivationRequest // shared fields; Role and TargetScope are set per item
+	submitted    bool
+	width        int
+	height       int
+	activateFunc func(req azure.ActivationRequest) (*azure.ScheduleResponse, error)
+	waitFunc     func(req azure.ActivationRequest, resp *azure.ScheduleResponse, onStatus func(string)) error
+}
+
+// NewConfirm creates a Confirm model. base carries the principal, duration,
+// justification, and ticket shared by every item. When waitFunc is non-nil,
+// each submitted activation is shown as provisioning until waitFunc returns,
+// along with each request status waitFunc passes to onStatus.
 func NewConfirm(
 	theme styles.Theme,
 	keys styles.KeyMap,
 	items []activationItem,
-	minutes int,
-	justification string,
-	principalID string,
-	activateFunc func(azure.Role, string, string, int, string) error,
+	base azure.ActivationRequest,
+	activateFunc func(azure.ActivationRequest) (*azure.ScheduleResponse, error),
+	waitFunc func(azure.ActivationRequest, *azure.ScheduleResponse, func(string)) error,
 ) Confirm {
 	return Confirm{
-		theme:         theme,
-		keys:          keys,
-		spinner:       components.NewSpinner(theme.Active),
-		items:         items,
-		minutes:       minutes,
-		justification: justification,
-		principalID:   principalID,
-		activateFunc:  activateFunc,
+		theme:        theme,
+		keys:         keys,
+		spinner:      components.NewSpinner(theme.Active),
+		items:        items,
+		base:         base,
+		activateFunc: activateFunc,
+		waitFunc:     waitFunc,
 	}
 }
 
@@ -95,6 +122,27 @@ func (m Confirm) Update(msg tea.Msg) (Confirm, tea.Cmd) {
 		m.height = msg.Height
 
 	case activationResultMsg:
+		m.items[msg.idx].err = msg.err
+		m.items[msg.idx].reqStatus = msg.resp.Status()
+		switch {
+		case msg.err != nil:
+			m.items[msg.idx].status = statusFailed
+		case m.waitFunc != nil && m.base.Start.IsZero() && !azure.IsAwaitingApproval(msg.resp.Status()):
+			m.items[msg.idx].status = statusProvisioning
+			return m, m.runWait(msg.idx, msg.resp)
+		default:
+			m.items[msg.idx].status = statusDone
+		}
+		if m.allDone() {
+			results := m.collectResults()
+			return m, func() tea.Msg { return ConfirmDoneMsg{Results: results} }
+		}
+
+	case provisionStatusMsg:
+		m.items[msg.idx].reqStatus = msg.status
+		return m, msg.next
+
+	case provisionResultMsg:
 		m.items[msg.idx].err = msg.err
 		if msg.err != nil {
 			m.items[msg.idx].status = statusFailed
@@ -137,20 +185,49 @@ func (m Confirm) Update(msg tea.Msg) (Confirm, tea.Cmd) {
 }
 
 func (m Confirm) runActivation(i int) tea.Cmd {
-	item := m.items[i]
-	principalID := m.principalID
-	justification := m.justification
-	minutes := m.minutes
+	req := m.base
+	req.Role = m.items[i].role
+	req.TargetScope = m.items[i].targetScope
 	fn := m.activateFunc
 	return func() tea.Msg {
-		err := fn(item.role, principalID, justification, minutes, item.targetScope)
-		return activationResultMsg{idx: i, err: err}
+		resp, err := fn(req)
+		return activationResultMsg{idx: i, resp: resp, err: err}
+	}
+}
+
+func (m Confirm) runWait(i int, resp *azure.ScheduleResponse) tea.Cmd {
+	req := m.base
+	req.Role = m.items[i].role
+	req.TargetScope = m.items[i].targetScope
+	fn := m.waitFunc
+	// Statuses are dropped rather than block the wait when the screen has
+	// stopped reading them; only the final result is always delivered.
+	msgs := make(chan tea.Msg, 8)
+	go func() {
+		err := fn(req, resp, func(status string) {
+			select {
+			case msgs <- provisionStatusMsg{idx: i, status: status}:
+			default:
+			}
+		})
+		msgs <- provisionResultMsg{idx: i, err: err}
+		close(msgs)
+	}()
+	var next tea.Cmd
+	next = func() tea.Msg {
+		msg := <-msgs
+		if sm, ok := msg.(provisionStatusMsg); ok {
+			sm.next = next
+			return sm
+		}
+		return msg
 	}
+	return next
 }
 
 func (m *Confirm) allDone() bool {
 	for _, it := range m.items {
-		if it.status == statusPending || it.status == statusRunning {
+		if it.status == statusPending || it.status == statusRunning || it.status == statusProvisioning {
 			return false
 		}
 	}
@@ -169,6 +246,8 @@ func (m *Confirm) collectResults() []Result {
 			Scope:            scope,
 			EligibilityScope: it.role.Scope,
 			ScheduleID:       it.role.EligibilityScheduleID,
+			Status:           it.reqStatus,
+			Start:            m.base.Start,
 			Err:              it.err,
 		})
 	}
@@ -179,7 +258,7 @@ func (m *Confirm) collectResults() []Result {
 func (m Confirm) View() string {
 	var sb strings.Builder
 
-	dur := azure.FormatDuration(m.minutes)
+	dur := azure.FormatDuration(m.base.Minutes)
 	sb.WriteString(m.theme.Title.Render(fmt.Sprintf("Activating %s for %s:", pluralf(len(m.items), "role"), dur)) + "\n\n")
 
 	for _, it := range m.items {
@@ -192,8 +271,19 @@ func (m Confirm) View() string {
 		switch it.status {
 		case statusRunning:
 			statusStr = m.spinner.View() + " pending"
+		case statusProvisioning:
+			statusStr = m.spinner.View() + " provisioning"
+			if it.reqStatus != "" {
+				statusStr += " (" + it.reqStatus + ")"
+			}
 		case statusDone:
 			statusStr = m.theme.Active.Render("✓ done")
+			switch {
+			case azure.IsAwaitingApproval(it.reqStatus):
+				statusStr = m.theme.Tag.Render("⧗ pending approval")
+			case !m.base.Start.IsZero():
+				statusStr = m.theme.Active.Render("✓ scheduled")
+			}
 		case statusFailed:
 			msg := "failed"
 			if it.err != nil {
@@ -209,7 +299,14 @@ func (m Confirm) View() string {
 	}
 
 	sb.WriteString("\n")
-	sb.WriteString(m.theme.Subtle.Render(fmt.Sprintf("Justification: %q", m.justification)) + "\n\n")
+	if !m.base.Start.IsZero() {
+		sb.WriteString(m.theme.Subtle.Render("Starts: "+m.base.Start.Format("2006-01-02 15:04")) + "\n")
+	}
+	sb.WriteString(m.theme.Subtle.Render(fmt.Sprintf("Justification: %q", m.base.Justification)) + "\n")
+	if t := m.base.Ticket; !t.IsZero() {
+		sb.WriteString(m.theme.Subtle.Render("Ticket: "+formatTicket(t)) + "\n")
+	}
+	sb.WriteString("\n")
 
 	if !m.submitted {
 		hints := []key.Binding{m.keys.Enter, m.keys.Back, m.keys.Quit}
@@ -220,6 +317,14 @@ func (m Confirm) View() string {
 	return sb.String()
 }
 
+// formatTicket renders a ticket as "NUMBER (SYSTEM)" or just the number.
+func formatTicket(t azure.TicketInfo) string {
+	if t.TicketSystem == "" {
+		return t.TicketNumber
+	}
+	return fmt.Sprintf("%s (%s)", t.TicketNumber, t.TicketSystem)
+}
+
 func pluralf(n int, noun string) string {
 	if n == 1 {
 		return "1 " + noun
@@ -0,0 +1,56 @@
+package activate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jeircul/pim/internal/azure"
+	"github.com/jeircul/pim/internal/tui/styles"
+)
+
+func TestConfirmWaitReportsRequestStatus(t *testing.T) {
+	items := []activationItem{{role: azure.Role{RoleName: "Contributor", Scope: "/subscriptions/sub-1"}}}
+	activate := func(azure.ActivationRequest) (*azure.ScheduleResponse, error) {
+		return &azure.ScheduleResponse{}, nil
+	}
+	wait := func(_ azure.ActivationRequest, _ *azure.ScheduleResponse, onStatus func(string)) error {
+		onStatus("PendingProvisioning")
+		onStatus(azure.StatusProvisioned)
+		return nil
+	}
+	m := NewConfirm(styles.NewTheme(true), styles.DefaultKeyMap, items, azure.ActivationRequest{Minutes: 60}, activate, wait)
+	m.submitted = true
+	m.items[0].status = statusRunning
+
+	m, cmd := m.Update(activationResultMsg{idx: 0, resp: &azure.ScheduleResponse{}})
+	if m.items[0].status != statusProvisioning || cmd == nil {
+		t.Fatalf("status = %v, cmd = %v; want provisioning with a wait cmd", m.items[0].status, cmd)
+	}
+
+	msg := cmd()
+	first, ok := msg.(provisionStatusMsg)
+	if !ok || first.status != "PendingProvisioning" {
+		t.Fatalf("first msg = %#v, want PendingProvisioning status", msg)
+	}
+	m, cmd = m.Update(first)
+	if got := m.items[0].reqStatus; got != "PendingProvisioning" {
+		t.Errorf("reqStatus = %q, want PendingProvisioning", got)
+	}
+	if view := m.View(); !strings.Contains(view, "provisioning (PendingProvisioning)") {
+		t.Errorf("view does not show the request status:\n%s", view)
+	}
+
+	m, cmd = m.Update(cmd())
+	if got := m.items[0].reqStatus; got != azure.StatusProvisioned {
+		t.Errorf("reqStatus = %q, want %s", got, azure.StatusProvisioned)
+	}
+
+	m, cmd = m.Update(cmd())
+	if m.items[0].status != statusDone || cmd == nil {
+		t.Fatalf("status = %v after wait, want done", m.items[0].status)
+	}
+	done, ok := cmd().(ConfirmDoneMsg)
+	if !ok || len(done.Results) != 1 || done.Results[0].Status != azure.StatusProvisioned {
+		t.Errorf("done = %#v", done)
+	}
+}
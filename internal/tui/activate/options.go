@@ -1,76 +1,119 @@
 package activate
 
 import (
+	"fmt"
 	"strings"
 
 	"charm.land/bubbles/v2/key"
 	tea "charm.land/bubbletea/v2"
+	"github.com/jeircul/pim/internal/azure"
 	"github.com/jeircul/pim/internal/tui/components"
 	"github.com/jeircul/pim/internal/tui/styles"
 )
 
-// OptionsDoneMsg is sent when the user confirms duration and justification.
+// OptionsDoneMsg is sent when the user confirms duration, justification,
+// ticket, and start time. Start is the raw input; empty means now.
 type OptionsDoneMsg struct {
 	Minutes       int
 	Justification string
+	Ticket        string
+	Start         string
 }
 
-var durationChoices = []struct {
+type durationChoice struct {
 	label   string
 	minutes int
-}{
-	{"30m", 30},
-	{"1h", 60},
-	{"1h30", 90},
-	{"2h", 120},
-	{"2h30", 150},
-	{"3h", 180},
-	{"3h30", 210},
-	{"4h", 240},
-	{"4h30", 270},
-	{"5h", 300},
-	{"5h30", 330},
-	{"6h", 360},
-	{"6h30", 390},
-	{"7h", 420},
-	{"7h30", 450},
-	{"8h", 480},
+}
+
+// extendedChoiceMinutes are offered beyond 8h when the policy allows it.
+var extendedChoiceMinutes = []int{600, 720, 960, 1200, 1440}
+
+// durationChoicesFor returns the duration picker choices allowed by a policy
+// maximum: 30-minute steps up to 8h, coarser steps beyond, and the maximum
+// itself when it is not already a step.
+func durationChoicesFor(maxMinutes int) []durationChoice {
+	if maxMinutes <= 0 {
+		maxMinutes = azure.DefaultMaxMinutes
+	}
+	var out []durationChoice
+	for m := 30; m <= min(maxMinutes, azure.DefaultMaxMinutes); m += 30 {
+		out = append(out, durationChoice{durationLabel(m), m})
+	}
+	for _, m := range extendedChoiceMinutes {
+		if m <= maxMinutes {
+			out = append(out, durationChoice{durationLabel(m), m})
+		}
+	}
+	if len(out) == 0 || out[len(out)-1].minutes != maxMinutes {
+		out = append(out, durationChoice{durationLabel(maxMinutes), maxMinutes})
+	}
+	return out
+}
+
+// durationLabel renders minutes in the compact picker form: 30m, 1h, 1h30.
+func durationLabel(minutes int) string {
+	h, m := minutes/60, minutes%60
+	switch {
+	case h == 0:
+		return fmt.Sprintf("%dm", m)
+	case m == 0:
+		return fmt.Sprintf("%dh", h)
+	default:
+		return fmt.Sprintf("%dh%02d", h, m)
+	}
 }
 
 // Options is Step 3: duration selection and justification entry.
 type Options struct {
 	theme         styles.Theme
 	keys          styles.KeyMap
+	choices       []durationChoice
 	durationIdx   int
+	policy        azure.ActivationPolicy
+	notice        string // policy load warning or validation error
 	justification string
 	recentJusts   []string
+	ticket        string
+	start         string
 	recentCursor  int  // -1 = not in recent list
 	focusJust     bool // true = justification text field has focus
+	focusTicket   bool // true = ticket text field has focus
+	focusStart    bool // true = start time text field has focus
 	width         int
 	height        int
 }
 
-// NewOptions creates an Options model.
+// NewOptions creates an Options model. The duration choices are limited to the
+// policy maximum; notice is shown above the picker when non-empty.
 func NewOptions(
 	theme styles.Theme,
 	keys styles.KeyMap,
 	defaultMinutes int,
 	recentJusts []string,
 	justification string, // pre-filled from --justification flag
+	ticket string, // pre-filled from --ticket flag or favorite
+	start string, // pre-filled from --at flag
+	policy azure.ActivationPolicy,
+	notice string,
 ) Options {
-	// pick closest duration choice
-	idx := 1 // default 1h
-	for i, d := range durationChoices {
-		if d.minutes == defaultMinutes {
+	choices := durationChoicesFor(policy.MaxMinutes)
+	// pick closest duration choice not above the default
+	idx := 0
+	for i, d := range choices {
+		if d.minutes <= defaultMinutes {
 			idx = i
-			break
 		}
 	}
 	return Options{
 		theme:         theme,
 		keys:          keys,
+		choices:       choices,
 		durationIdx:   idx,
+		policy:        policy,
+		notice:        notice,
 		justification: justification,
+		ticket:        ticket,
+		start:         start,
 		recentJusts:   recentJusts,
 		recentCursor:  -1,
 		focusJust:     false,
@@ -88,16 +131,60 @@ func (m Options) Update(msg tea.Msg) (Options, tea.Cmd) {
 		m.height = msg.Height
 
 	case tea.KeyPressMsg:
+		if m.focusStart {
+			switch msg.String() {
+			case "tab":
+				m.focusStart = false
+			case "enter":
+				if done := m.done(); done != nil {
+					return m, done
+				}
+				m.focusStart = false
+				m.focusJust = true
+			case "backspace":
+				if len(m.start) > 0 {
+					m.start = m.start[:len(m.start)-1]
+				}
+			default:
+				if msg.Text != "" {
+					m.start += msg.Text
+				}
+			}
+			return m, func() tea.Msg { return nil }
+		}
+
+		if m.focusTicket {
+			switch msg.String() {
+			case "tab":
+				m.focusTicket = false
+				m.focusStart = true
+			case "enter":
+				if done := m.done(); done != nil {
+					return m, done
+				}
+				m.focusTicket = false
+				m.focusJust = true
+			case "backspace":
+				if len(m.ticket) > 0 {
+					m.ticket = m.ticket[:len(m.ticket)-1]
+				}
+			default:
+				if msg.Text != "" {
+					m.ticket += msg.Text
+				}
+			}
+			return m, func() tea.Msg { return nil }
+		}
+
 		if m.focusJust {
 			switch msg.String() {
 			case "tab":
 				m.focusJust = false
+				m.focusTicket = true
 				m.recentCursor = -1
 			case "enter":
-				if m.justification != "" {
-					mins := durationChoices[m.durationIdx].minutes
-					j := m.justification
-					return m, func() tea.Msg { return OptionsDoneMsg{Minutes: mins, Justification: j} }
+				if done := m.done(); done != nil {
+					return m, done
 				}
 			case "up":
 				if len(m.recentJusts) > 0 && m.recentCursor < len(m.recentJusts)-1 {
@@ -137,7 +224,7 @@ func (m Options) Update(msg tea.Msg) (Options, tea.Cmd) {
 		case "enter":
 			m.focusJust = true
 		case "right", "l":
-			if m.durationIdx < len(durationChoices)-1 {
+			if m.durationIdx < len(m.choices)-1 {
 				m.durationIdx++
 			}
 		case "left", "h":
@@ -168,24 +255,45 @@ func (m Options) Update(msg tea.Msg) (Options, tea.Cmd) {
 	return m, nil
 }
 
-// Editing reports whether the justification text field has focus.
-func (m Options) Editing() bool { return m.focusJust }
+// done returns the command that completes the step, or nil while the
+// justification is still empty.
+func (m Options) done() tea.Cmd {
+	if m.justification == "" {
+		return nil
+	}
+	msg := OptionsDoneMsg{
+		Minutes:       m.choices[m.durationIdx].minutes,
+		Justification: m.justification,
+		Ticket:        strings.TrimSpace(m.ticket),
+		Start:         strings.TrimSpace(m.start),
+	}
+	return func() tea.Msg { return msg }
+}
+
+// Editing reports whether a text field has focus.
+func (m Options) Editing() bool { return m.focusJust || m.focusTicket || m.focusStart }
 
 // View renders the options step.
 func (m Options) View() string {
 	var sb strings.Builder
 
+	sb.WriteString(m.theme.Subtle.Render("Policy: "+m.policy.Summary()) + "\n")
+	if m.notice != "" {
+		sb.WriteString(m.theme.DangerText.Render(m.notice) + "\n")
+	}
+	sb.WriteString("\n")
+
 	// Duration rows: split choices evenly into two rows
 	sb.WriteString(m.theme.Title.Render("Duration:") + "\n")
-	perRow := (len(durationChoices) + 1) / 2
+	perRow := (len(m.choices) + 1) / 2
 	for row := 0; row < 2; row++ {
 		start := row * perRow
-		end := min(start+perRow, len(durationChoices))
+		end := min(start+perRow, len(m.choices))
 		for i := start; i < end; i++ {
 			if i > start {
 				sb.WriteString("  ")
 			}
-			d := durationChoices[i]
+			d := m.choices[i]
 			if i == m.durationIdx {
 				sb.WriteString(m.theme.TableRowSelected.Render("● " + d.label))
 			} else {
@@ -209,6 +317,32 @@ func (m Options) View() string {
 	}
 	sb.WriteString(m.theme.Bold.Render(m.justification) + cursor + "\n\n")
 
+	ticketLabel := "Ticket:"
+	if m.policy.RequireTicket {
+		ticketLabel = "Ticket (required):"
+	}
+	ticketTitle := m.theme.Title.Render(ticketLabel)
+	ticketCursor := ""
+	if m.focusTicket {
+		ticketTitle = m.theme.TableRowSelected.Render(ticketLabel)
+		ticketCursor = "█"
+	}
+	sb.WriteString(ticketTitle + "\n")
+	sb.WriteString(m.theme.Bold.Render(m.ticket) + ticketCursor + "\n\n")
+
+	startTitle := m.theme.Title.Render("Start:")
+	startCursor := ""
+	if m.focusStart {
+		startTitle = m.theme.TableRowSelected.Render("Start:")
+		startCursor = "█"
+	}
+	sb.WriteString(startTitle + "\n")
+	if m.start == "" && !m.focusStart {
+		sb.WriteString(m.theme.Subtle.Render("now  (e.g. +3h, 22:00, 2006-01-02 15:04)") + "\n\n")
+	} else {
+		sb.WriteString(m.theme.Bold.Render(m.start) + startCursor + "\n\n")
+	}
+
 	// Recent justifications
 	if len(m.recentJusts) > 0 {
 		sb.WriteString(m.theme.Subtle.Render("Recent:") + "\n")
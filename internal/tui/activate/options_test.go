@@ -0,0 +1,49 @@
+package activate
+
+import "testing"
+
+func TestDurationChoicesFor(t *testing.T) {
+	tests := []struct {
+		name      string
+		max       int
+		wantLen   int
+		wantLast  int
+		wantLabel string
+	}{
+		{"unknown max uses 8h", 0, 16, 480, "8h"},
+		{"1h policy", 60, 2, 60, "1h"},
+		{"45m policy appends max", 45, 2, 45, "45m"},
+		{"12h policy adds extended steps", 720, 18, 720, "12h"},
+		{"9h policy appends max", 540, 17, 540, "9h"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := durationChoicesFor(tc.max)
+			if len(got) != tc.wantLen {
+				t.Fatalf("len = %d, want %d (%v)", len(got), tc.wantLen, got)
+			}
+			last := got[len(got)-1]
+			if last.minutes != tc.wantLast || last.label != tc.wantLabel {
+				t.Errorf("last = %+v, want %d %q", last, tc.wantLast, tc.wantLabel)
+			}
+		})
+	}
+}
+
+func TestDurationLabel(t *testing.T) {
+	tests := []struct {
+		minutes int
+		want    string
+	}{
+		{30, "30m"},
+		{60, "1h"},
+		{90, "1h30"},
+		{65, "1h05"},
+		{720, "12h"},
+	}
+	for _, tc := range tests {
+		if got := durationLabel(tc.minutes); got != tc.want {
+			t.Errorf("durationLabel(%d) = %q, want %q", tc.minutes, got, tc.want)
+		}
+	}
+}
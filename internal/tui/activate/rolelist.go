@@ -8,6 +8,7 @@ import (
 	tea "charm.land/bubbletea/v2"
 	"github.com/jeircul/pim/internal/azure"
 	"github.com/jeircul/pim/internal/tui/components"
+	"github.com/jeircul/pim/internal/tui/roledetail"
 	"github.com/jeircul/pim/internal/tui/styles"
 )
 
@@ -38,6 +39,11 @@ type RoleList struct {
 	scopeFilter      []string
 	eligibilityScope string
 	scheduleID       string
+	// loadDefinition fetches a role's permissions for the detail view; nil
+	// disables it.
+	loadDefinition func(scope, roleDefinitionID string) (*azure.RoleDefinition, error)
+	detail         roledetail.Model
+	showDetail     bool
 }
 
 // NewRoleList creates a RoleList model.
@@ -103,6 +109,16 @@ type roleListLoadMsg struct {
 
 // Update handles messages.
 func (m RoleList) Update(msg tea.Msg) (RoleList, tea.Cmd) {
+	if m.showDetail {
+		if _, ok := msg.(roledetail.CloseMsg); ok {
+			m.showDetail = false
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.detail, cmd = m.detail.Update(msg)
+		return m, cmd
+	}
+
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
@@ -147,6 +163,16 @@ func (m RoleList) Update(msg tea.Msg) (RoleList, tea.Cmd) {
 				m.applyFilter()
 				m.cursor = 0
 			}
+		case key.Matches(msg, m.keys.Details):
+			if len(m.visible) > 0 && m.loadDefinition != nil {
+				r := m.roles[m.visible[m.cursor]]
+				fn := m.loadDefinition
+				m.detail = roledetail.New(m.theme, m.keys, r.RoleName, m.height, func() (*azure.RoleDefinition, error) {
+					return fn(r.Scope, r.RoleDefinitionID)
+				})
+				m.showDetail = true
+				return m, m.detail.Init()
+			}
 		case key.Matches(msg, m.keys.Enter), msg.String() == "right", msg.String() == "l":
 			if len(m.visible) > 0 {
 				ri := m.visible[m.cursor]
@@ -287,11 +313,14 @@ func (m *RoleList) autoAdvance() tea.Cmd {
 	return nil
 }
 
-// Editing reports whether the filter text field is active.
-func (m RoleList) Editing() bool { return m.filtering }
+// Editing reports whether the filter text field (or the detail view's) is active.
+func (m RoleList) Editing() bool { return m.filtering || (m.showDetail && m.detail.Editing()) }
 
 // View renders the role list step.
 func (m RoleList) View() string {
+	if m.showDetail {
+		return m.detail.View()
+	}
 	var sb strings.Builder
 
 	if m.loading {
@@ -321,6 +350,13 @@ func (m RoleList) View() string {
 		if m.active[r.RoleDefinitionID] {
 			line += " " + m.theme.Subtle.Render("(active)")
 		}
+		if exp := r.EligibilityExpiryDisplay(); exp != "" {
+			style := m.theme.Subtle
+			if r.IsEligibilityExpiring() {
+				style = m.theme.DangerText
+			}
+			line += " " + style.Render("("+exp+")")
+		}
 		if pos == m.cursor {
 			line = m.theme.TableRowSelected.Render("▸") + line[1:]
 		}
@@ -329,6 +365,9 @@ func (m RoleList) View() string {
 
 	sb.WriteString("\n")
 	hints := []key.Binding{m.keys.Up, m.keys.Down, m.keys.Enter, m.keys.Back, m.keys.Quit}
+	if m.loadDefinition != nil {
+		hints = append(hints, m.keys.Details)
+	}
 	sb.WriteString(components.RenderStatusBar(m.theme.HelpKey, m.theme.HelpDesc, m.theme.Subtle, hints,
 		"/ filter  → activate"))
 
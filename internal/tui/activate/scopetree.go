@@ -18,7 +18,7 @@ type ScopeTreeDoneMsg struct {
 	Scopes []string // one activation will be created per scope
 }
 
-// scopeNode is a node in the tree (MG, subscription, or resource group).
+// scopeNode is a node in the tree (MG, subscription, resource group, or resource).
 type scopeNode struct {
 	id       string
 	display  string
@@ -54,6 +54,9 @@ type ScopeTree struct {
 	loadSubs func(mgID string) ([]azure.ManagementGroup, []azure.Subscription, error)
 	// loadRGs fetches resource groups under a subscription ID.
 	loadRGs func(subID string) ([]azure.ResourceGroup, error)
+	// loadResources fetches individual resources inside a resource group;
+	// nil leaves resource groups as leaves.
+	loadResources func(subID, resourceGroup string) ([]azure.Resource, error)
 }
 
 type scopeChildrenMsg struct {
@@ -61,6 +64,7 @@ type scopeChildrenMsg struct {
 	mgs         []azure.ManagementGroup
 	subs        []azure.Subscription
 	rgs         []azure.ResourceGroup
+	resources   []azure.Resource
 	err         error
 }
 
@@ -71,6 +75,7 @@ func NewScopeTree(
 	role azure.Role,
 	loadSubs func(string) ([]azure.ManagementGroup, []azure.Subscription, error),
 	loadRGs func(string) ([]azure.ResourceGroup, error),
+	loadResources func(string, string) ([]azure.Resource, error),
 ) ScopeTree {
 	root := &scopeNode{
 		id:      azure.ManagementGroupIDFromScope(role.Scope),
@@ -79,14 +84,15 @@ func NewScopeTree(
 		scope:   role.Scope,
 	}
 	st := ScopeTree{
-		theme:    theme,
-		keys:     keys,
-		spinner:  components.NewSpinner(theme.Active),
-		role:     role,
-		root:     root,
-		selected: make(map[string]bool),
-		loadSubs: loadSubs,
-		loadRGs:  loadRGs,
+		theme:         theme,
+		keys:          keys,
+		spinner:       components.NewSpinner(theme.Active),
+		role:          role,
+		root:          root,
+		selected:      make(map[string]bool),
+		loadSubs:      loadSubs,
+		loadRGs:       loadRGs,
+		loadResources: loadResources,
 	}
 	st.flatten()
 	return st
@@ -94,12 +100,13 @@ func NewScopeTree(
 
 // NewScopeTreeForSub creates a ScopeTree rooted at the subscription for the
 // given subscription-scoped role. The user may select the subscription itself
-// or expand it to choose a resource group.
+// or expand it to choose a resource group or a resource inside one.
 func NewScopeTreeForSub(
 	theme styles.Theme,
 	keys styles.KeyMap,
 	role azure.Role,
 	loadRGs func(string) ([]azure.ResourceGroup, error),
+	loadResources func(string, string) ([]azure.Resource, error),
 ) ScopeTree {
 	root := &scopeNode{
 		id:      azure.SubscriptionIDFromScope(role.Scope),
@@ -108,14 +115,15 @@ func NewScopeTreeForSub(
 		scope:   role.Scope,
 	}
 	st := ScopeTree{
-		theme:    theme,
-		keys:     keys,
-		spinner:  components.NewSpinner(theme.Active),
-		role:     role,
-		root:     root,
-		selected: make(map[string]bool),
-		subRoot:  true,
-		loadRGs:  loadRGs,
+		theme:         theme,
+		keys:          keys,
+		spinner:       components.NewSpinner(theme.Active),
+		role:          role,
+		root:          root,
+		selected:      make(map[string]bool),
+		subRoot:       true,
+		loadRGs:       loadRGs,
+		loadResources: loadResources,
 	}
 	st.flatten()
 	return st
@@ -151,6 +159,11 @@ func (m ScopeTree) expandNode(n *scopeNode) tea.Cmd {
 			rgs, err := m.loadRGs(subID)
 			msg.rgs = rgs
 			msg.err = err
+		case azure.ScopeResourceGroup:
+			subID, rg := azure.ResourceGroupNameFromScope(scope)
+			resources, err := m.loadResources(subID, rg)
+			msg.resources = resources
+			msg.err = err
 		}
 		return msg
 	}
@@ -202,6 +215,15 @@ func (m ScopeTree) Update(msg tea.Msg) (ScopeTree, tea.Cmd) {
 				parent:  n,
 			})
 		}
+		for _, r := range msg.resources {
+			n.children = append(n.children, &scopeNode{
+				id:      r.Name,
+				display: resourceDisplay(r),
+				kind:    azure.ScopeResource,
+				scope:   r.Scope(),
+				parent:  n,
+			})
+		}
 		m.flatten()
 
 	case tea.KeyPressMsg:
@@ -231,10 +253,11 @@ func (m ScopeTree) Update(msg tea.Msg) (ScopeTree, tea.Cmd) {
 		case msg.String() == "l", msg.String() == "right":
 			if m.cursor < len(m.flat) {
 				n := m.flat[m.cursor]
-				if n.kind == azure.ScopeResourceGroup || n.expanded {
+				if !m.expandable(n) || n.expanded {
 					break
 				}
-				if n.kind == azure.ScopeManagementGroup || (n.kind == azure.ScopeSubscription && n != m.root) || m.subRoot {
+				if n.kind == azure.ScopeManagementGroup || (n.kind == azure.ScopeSubscription && n != m.root) ||
+					n.kind == azure.ScopeResourceGroup || m.subRoot {
 					if n.loadErr != nil {
 						n.loadErr = nil
 					}
@@ -267,7 +290,7 @@ func (m ScopeTree) Update(msg tea.Msg) (ScopeTree, tea.Cmd) {
 		case msg.String() == "space":
 			if m.cursor < len(m.flat) {
 				n := m.flat[m.cursor]
-				if n.loadErr != nil && n != m.root && n.kind != azure.ScopeSubscription {
+				if n.loadErr != nil && n != m.root && n.kind != azure.ScopeSubscription && n.kind != azure.ScopeResourceGroup {
 					break
 				}
 				scope := n.scope
@@ -478,7 +501,7 @@ func (m ScopeTree) View() string {
 			prefix = m.spinner.View() + " "
 		case n.loadErr != nil:
 			prefix = "✗ "
-		case n.kind == azure.ScopeResourceGroup:
+		case !m.expandable(n):
 			prefix = "  "
 		case n.expanded:
 			prefix = "▾ "
@@ -512,6 +535,35 @@ func (m ScopeTree) View() string {
 	return sb.String()
 }
 
+// expandable reports whether n can have children: resources never do, and
+// resource groups only when a resource loader is configured.
+func (m ScopeTree) expandable(n *scopeNode) bool {
+	switch n.kind {
+	case azure.ScopeResource:
+		return false
+	case azure.ScopeResourceGroup:
+		return m.loadResources != nil
+	}
+	return true
+}
+
+// resourceDisplay labels a resource node with its name and short type,
+// e.g. "kv-prod (vaults)".
+func resourceDisplay(r azure.Resource) string {
+	name := r.DisplayName
+	if name == "" {
+		name = r.Name
+	}
+	typ := r.Type
+	if i := strings.LastIndex(typ, "/"); i >= 0 {
+		typ = typ[i+1:]
+	}
+	if typ == "" {
+		return name
+	}
+	return fmt.Sprintf("%s (%s)", name, typ)
+}
+
 func nodeDepth(n *scopeNode) int {
 	d := 0
 	for n.parent != nil {
@@ -18,7 +18,7 @@ func newTestScopeTree(loadSubs func(string) ([]azure.ManagementGroup, []azure.Su
 	}
 	theme := styles.NewTheme(true)
 	keys := styles.DefaultKeyMap
-	return NewScopeTree(theme, keys, role, loadSubs, nil)
+	return NewScopeTree(theme, keys, role, loadSubs, nil, nil)
 }
 
 func TestScopeTreeInitDoesNotAutoExpand(t *testing.T) {
@@ -233,7 +233,7 @@ func TestScopeTreeFilterEscDoesNotTriggerWizardBack(t *testing.T) {
 			},
 		},
 	}
-	w.scopeTree = NewScopeTree(theme, keys, role, w.deps.LoadSubs, w.deps.LoadRGs)
+	w.scopeTree = NewScopeTree(theme, keys, role, w.deps.LoadSubs, w.deps.LoadRGs, w.deps.LoadResources)
 	w.scopeTree.width = 120
 	w.scopeTree.height = 40
 
@@ -252,3 +252,50 @@ func TestScopeTreeFilterEscDoesNotTriggerWizardBack(t *testing.T) {
 	}
 	_ = cmd
 }
+
+func TestScopeTreeResourceGroupExpandsToResources(t *testing.T) {
+	role := azure.Role{RoleName: "Reader", Scope: "/subscriptions/sub-1", ScopeDisplay: "My Sub"}
+	var gotSub, gotRG string
+	st := NewScopeTreeForSub(styles.NewTheme(true), styles.DefaultKeyMap, role, nil,
+		func(subID, rg string) ([]azure.Resource, error) {
+			gotSub, gotRG = subID, rg
+			return nil, nil
+		})
+	rg := azure.ResourceGroup{SubscriptionID: "sub-1", Name: "rg-app"}
+	st, _ = st.Update(scopeChildrenMsg{parentScope: st.root.scope, rgs: []azure.ResourceGroup{rg}})
+
+	st.cursor = 1
+	st, cmd := st.Update(tea.KeyPressMsg{Code: 'l', Text: "l"})
+	if cmd == nil {
+		t.Fatal("expanding a resource group returned no command")
+	}
+	// Run the batched load and feed its result back.
+	var msg scopeChildrenMsg
+	for _, c := range cmd().(tea.BatchMsg) {
+		if m, ok := c().(scopeChildrenMsg); ok {
+			msg = m
+		}
+	}
+	if gotSub != "sub-1" || gotRG != "rg-app" {
+		t.Errorf("loadResources(%q, %q); want sub-1, rg-app", gotSub, gotRG)
+	}
+
+	vault := azure.Resource{ID: rg.Scope() + "/providers/Microsoft.KeyVault/vaults/kv-prod", Name: "kv-prod", Type: "Microsoft.KeyVault/vaults"}
+	msg.resources = []azure.Resource{vault}
+	st, _ = st.Update(msg)
+	if len(st.flat) != 3 {
+		t.Fatalf("expected 3 flat nodes (sub + RG + resource), got %d", len(st.flat))
+	}
+	if n := st.flat[2]; n.kind != azure.ScopeResource || n.display != "kv-prod (vaults)" {
+		t.Errorf("resource node = %+v", n)
+	}
+
+	st.cursor = 2
+	st, _ = st.Update(tea.KeyPressMsg{Code: tea.KeySpace, Text: " "})
+	if !st.selected[vault.ID] {
+		t.Error("resource scope not selectable")
+	}
+	if _, cmd = st.Update(tea.KeyPressMsg{Code: 'l', Text: "l"}); cmd != nil {
+		t.Error("resources should not expand")
+	}
+}
@@ -1,6 +1,7 @@
 package activate
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -31,10 +32,14 @@ const (
 // Deps groups external dependencies injected into the Wizard.
 type Deps struct {
 	PrincipalID      string
+	Tenant           string   // tenant ID recorded with recent activations; "" = default
 	RoleFilter       []string // from --role flags
 	ScopeFilter      []string // from --scope flags
 	TimeStr          string   // from --time flag
 	Justific         string   // from --justification flag
+	Ticket           string   // from --ticket flag or favorite
+	TicketSystem     string   // from --ticket-system flag or favorite
+	StartStr         string   // from --at flag
 	AutoSubmit       bool     // from --yes flag
 	Silent           bool     // suppress role-list render during direct favorite activation
 	Store            *state.Store
@@ -42,11 +47,21 @@ type Deps struct {
 	LoadActive       func() ([]azure.ActiveAssignment, error)
 	LoadSubs         func(mgID string) ([]azure.ManagementGroup, []azure.Subscription, error)
 	LoadRGs          func(subID string) ([]azure.ResourceGroup, error)
-	Activate         func(role azure.Role, principalID, justification string, minutes int, targetScope string) error
+	LoadResources    func(subID, resourceGroup string) ([]azure.Resource, error) // nil = resource groups are leaves
+	Activate         func(req azure.ActivationRequest) (*azure.ScheduleResponse, error)
+	Wait             func(req azure.ActivationRequest, resp *azure.ScheduleResponse, onStatus func(string)) error // nil = do not wait for provisioning
+	LoadPolicy       func(role azure.Role, targetScope string) (azure.ActivationPolicy, error)
+	LoadDefinition   func(scope, roleDefinitionID string) (*azure.RoleDefinition, error) // nil = no permission detail view
 	EligibilityScope string
 	ScheduleID       string
 }
 
+// policyLoadedMsg carries the merged activation policy for all selected items.
+type policyLoadedMsg struct {
+	policy azure.ActivationPolicy
+	err    error
+}
+
 // autoConfirmMsg triggers auto-submission on the confirm step (--yes flag).
 type autoConfirmMsg struct{}
 
@@ -72,12 +87,15 @@ type Wizard struct {
 	scopeVisited      bool // whether the scope tree step was visited this run
 	lastMinutes       int
 	lastJustification string
+	lastTicket        azure.TicketInfo
+	policy            azure.ActivationPolicy
 }
 
 // New creates a Wizard. Call Init() to start.
 func New(theme styles.Theme, keys styles.KeyMap, deps Deps) Wizard {
 	w := Wizard{theme: theme, keys: keys, deps: deps}
 	w.roleList = NewRoleList(theme, keys, deps.LoadActive, deps.RoleFilter, deps.ScopeFilter, deps.LoadRoles, deps.EligibilityScope, deps.ScheduleID)
+	w.roleList.loadDefinition = deps.LoadDefinition
 	return w
 }
 
@@ -139,19 +157,36 @@ func (w Wizard) Update(msg tea.Msg) (Wizard, tea.Cmd) {
 		}
 		return w.startOptions()
 
+	case policyLoadedMsg:
+		w.policy = msg.policy
+		notice := ""
+		if msg.err != nil {
+			notice = "could not read role policy: " + msg.err.Error()
+		}
+		return w.showOptions(notice)
+
 	case OptionsDoneMsg:
-		w.lastMinutes = msg.Minutes
-		w.lastJustification = msg.Justification
+		start, err := azure.ParseStartTime(msg.Start, time.Now())
+		if err != nil {
+			w.options.notice = err.Error()
+			return w, nil
+		}
+		req := w.baseRequest(msg.Minutes, msg.Justification, msg.Ticket, start)
+		if err := w.policy.Validate(req); err != nil {
+			w.options.notice = err.Error()
+			return w, nil
+		}
 		w.deps.Store.AddRecentJustification(msg.Justification)
 		_ = w.deps.Store.SaveState()
-		return w.startConfirm(msg.Minutes, msg.Justification)
+		return w.startConfirm(req)
 
 	case ConfirmDoneMsg:
 		for _, r := range msg.Results {
-			if r.Err != nil {
+			if r.Err != nil || r.Pending() {
 				continue
 			}
 			w.deps.Store.AddRecentActivation(state.RecentActivation{
+				Tenant:           w.deps.Tenant,
 				Role:             r.RoleName,
 				Scope:            r.Scope,
 				ScopeDisplay:     azure.DefaultScopeDisplay(r.Scope, ""),
@@ -159,6 +194,8 @@ func (w Wizard) Update(msg tea.Msg) (Wizard, tea.Cmd) {
 				ScheduleID:       r.ScheduleID,
 				Duration:         azure.FormatDuration(w.lastMinutes),
 				Justification:    w.lastJustification,
+				Ticket:           w.lastTicket.TicketNumber,
+				TicketSystem:     w.lastTicket.TicketSystem,
 				ActivatedAt:      time.Now(),
 			})
 		}
@@ -256,6 +293,10 @@ func roleListConsumed(prev, next RoleList, msg tea.Msg) bool {
 	if !ok {
 		return false
 	}
+	// The detail view owns every key while open, including esc.
+	if prev.showDetail {
+		return true
+	}
 	// If the list was filtering, it consumed everything except enter/esc that exit filter.
 	if prev.filtering {
 		s := kp.String()
@@ -354,11 +395,11 @@ func (w Wizard) scopeOverride(r azure.Role) string {
 		if azure.ScopeIsChildOf(expanded, r.Scope) {
 			return expanded
 		}
-		// MG-scoped role + subscription/RG filter: only trust when exactly one
+		// MG-scoped role + subscription/RG/resource filter: only trust when exactly one
 		// MG-scoped role is selected, ensuring async resolution or manual
 		// unambiguous pick — never blind-trust when multiple MG candidates exist.
 		if azure.IsManagementGroupScope(r.Scope) &&
-			(azure.IsSubscriptionScope(expanded) || azure.IsResourceGroupScope(expanded)) {
+			(azure.IsSubscriptionScope(expanded) || azure.IsResourceGroupScope(expanded) || azure.IsResourceScope(expanded)) {
 			mgCount := 0
 			for _, sel := range w.selectedRoles {
 				if azure.IsManagementGroupScope(sel.Scope) {
@@ -382,9 +423,9 @@ func (w Wizard) scopeOverride(r azure.Role) string {
 func (w Wizard) startNextScopeTree() (Wizard, tea.Cmd) {
 	role := w.scopeQueue[0]
 	if role.ScopeKind() == azure.ScopeSubscription {
-		w.scopeTree = NewScopeTreeForSub(w.theme, w.keys, role, w.deps.LoadRGs)
+		w.scopeTree = NewScopeTreeForSub(w.theme, w.keys, role, w.deps.LoadRGs, w.deps.LoadResources)
 	} else {
-		w.scopeTree = NewScopeTree(w.theme, w.keys, role, w.deps.LoadSubs, w.deps.LoadRGs)
+		w.scopeTree = NewScopeTree(w.theme, w.keys, role, w.deps.LoadSubs, w.deps.LoadRGs, w.deps.LoadResources)
 	}
 	w.scopeTree.width = w.width
 	w.scopeTree.height = w.height
@@ -393,7 +434,32 @@ func (w Wizard) startNextScopeTree() (Wizard, tea.Cmd) {
 	return w, w.scopeTree.Init()
 }
 
+// startOptions loads the role management policy for every selected item, then
+// shows the options step. Without a LoadPolicy dependency the policy is unknown
+// and the default 8h ceiling applies.
 func (w Wizard) startOptions() (Wizard, tea.Cmd) {
+	w.policy = azure.ActivationPolicy{}
+	if w.deps.LoadPolicy == nil {
+		return w.showOptions("")
+	}
+	items := w.items
+	load := w.deps.LoadPolicy
+	return w, func() tea.Msg {
+		var merged azure.ActivationPolicy
+		var errs []error
+		for _, it := range items {
+			p, err := load(it.role, it.targetScope)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", it.role.RoleName, err))
+				continue
+			}
+			merged = merged.Merge(p)
+		}
+		return policyLoadedMsg{policy: merged, err: errors.Join(errs...)}
+	}
+}
+
+func (w Wizard) showOptions(notice string) (Wizard, tea.Cmd) {
 	defaultMinutes := w.deps.Store.DefaultDurationMinutes()
 	if w.deps.TimeStr != "" {
 		if m, err := azure.ParseDurationMinutes(w.deps.TimeStr); err == nil {
@@ -405,31 +471,54 @@ func (w Wizard) startOptions() (Wizard, tea.Cmd) {
 		defaultMinutes,
 		w.deps.Store.RecentJustifications(),
 		w.deps.Justific,
+		w.deps.Ticket,
+		w.deps.StartStr,
+		w.policy,
+		notice,
 	)
 	w.step = stepOptions
 
-	// Flag acceleration: if --time and --justification both provided, skip options step.
+	// Flag acceleration: if --time and --justification both provided and the
+	// policy accepts them, skip options step.
 	if w.deps.TimeStr != "" && w.deps.Justific != "" {
 		mins, err := azure.ParseDurationMinutes(w.deps.TimeStr)
+		start, serr := azure.ParseStartTime(w.deps.StartStr, time.Now())
+		if serr != nil {
+			w.options.notice = serr.Error()
+			return w, w.options.Init()
+		}
 		if err == nil {
-			w.lastMinutes = mins
-			w.lastJustification = w.deps.Justific
-			return w.startConfirm(mins, w.deps.Justific)
+			req := w.baseRequest(mins, w.deps.Justific, w.deps.Ticket, start)
+			if verr := w.policy.Validate(req); verr != nil {
+				w.options.notice = verr.Error()
+				return w, w.options.Init()
+			}
+			return w.startConfirm(req)
 		}
 	}
 
 	return w, w.options.Init()
 }
 
-func (w Wizard) startConfirm(minutes int, justification string) (Wizard, tea.Cmd) {
-	w.confirm = NewConfirm(
-		w.theme, w.keys,
-		w.items,
-		minutes,
-		justification,
-		w.deps.PrincipalID,
-		w.deps.Activate,
-	)
+// baseRequest builds the activation fields shared by every selected item.
+func (w Wizard) baseRequest(minutes int, justification, ticket string, start time.Time) azure.ActivationRequest {
+	req := azure.ActivationRequest{
+		PrincipalID:   w.deps.PrincipalID,
+		Justification: justification,
+		Minutes:       minutes,
+		Start:         start,
+	}
+	if ticket != "" {
+		req.Ticket = azure.TicketInfo{TicketNumber: ticket, TicketSystem: w.deps.TicketSystem}
+	}
+	return req
+}
+
+func (w Wizard) startConfirm(req azure.ActivationRequest) (Wizard, tea.Cmd) {
+	w.lastMinutes = req.Minutes
+	w.lastJustification = req.Justification
+	w.lastTicket = req.Ticket
+	w.confirm = NewConfirm(w.theme, w.keys, w.items, req, w.deps.Activate, w.deps.Wait)
 	w.step = stepConfirm
 
 	// --yes: auto-submit immediately via a typed message so Confirm.Update handles it.
@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"slices"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -21,8 +23,11 @@ import (
 	"github.com/jeircul/pim/internal/tui/dashboard"
 	"github.com/jeircul/pim/internal/tui/deactivate"
 	"github.com/jeircul/pim/internal/tui/favorites"
+	"github.com/jeircul/pim/internal/tui/history"
 	"github.com/jeircul/pim/internal/tui/recent"
+	"github.com/jeircul/pim/internal/tui/requests"
 	"github.com/jeircul/pim/internal/tui/status"
+	"github.com/jeircul/pim/internal/tui/tenants"
 )
 
 // ErrSilent is returned by Run when activation or deactivation completed with
@@ -40,8 +45,19 @@ const (
 	ScreenDeactivate
 	ScreenFavorites
 	ScreenRecent
+	ScreenRequests
+	ScreenTenants
+	ScreenHistory
 )
 
+// pendingPollInterval is how often the dashboard badge is refreshed while
+// requests are outstanding.
+const pendingPollInterval = 30 * time.Second
+
+// queuePollInterval is how often queued deactivations are retried while any
+// are waiting.
+const queuePollInterval = 30 * time.Second
+
 // titleOf returns the header subtitle for a screen.
 func titleOf(s Screen) string {
 	switch s {
@@ -55,6 +71,12 @@ func titleOf(s Screen) string {
 		return "favorites"
 	case ScreenRecent:
 		return "recent"
+	case ScreenRequests:
+		return "requests"
+	case ScreenTenants:
+		return "tenants"
+	case ScreenHistory:
+		return "history"
 	default:
 		return "pim"
 	}
@@ -74,29 +96,62 @@ type AppModel struct {
 	deactivateModel deactivate.Model
 	favoritesModel  favorites.Model
 	recentModel     recent.Model
+	requestsModel   requests.Model
+	tenantsModel    tenants.Model
+	historyModel    history.Model
 	principalID     string
 	userReady       bool
 	favoritePending bool
-	width           int
-	height          int
-	isDark          bool
-	showHelp        bool
-	exitSummary     string
-	exitErr         error
+	// switchedFav is a favorite waiting for the identity lookup in its tenant.
+	switchedFav    *state.Favorite
+	switchedAuto   bool
+	pendingPolling bool
+	queuePolling   bool
+	width          int
+	height         int
+	isDark         bool
+	showHelp       bool
+	exitSummary    string
+	exitErr        error
 }
 
 // userReadyMsg carries the resolved principal ID from the background user fetch.
 type userReadyMsg struct {
 	principalID string
+	source      string // credential source that produced the token
 	err         error
 }
 
+// pendingCountMsg carries the number of outstanding activation requests.
+type pendingCountMsg struct {
+	n   int
+	err error
+}
+
+// pendingTickMsg triggers the next pending request refresh.
+type pendingTickMsg struct{}
+
+// queueRunMsg carries the outcome of retrying the due queued deactivations.
+type queueRunMsg struct {
+	results []app.DeactivationResult
+	err     error
+}
+
+// queueTickMsg triggers the next retry of queued deactivations.
+type queueTickMsg struct{}
+
+// elevationsMsg carries active assignments across the configured tenants.
+type elevationsMsg struct{ elevs []dashboard.TenantElevations }
+
 // New creates the root AppModel. User fetch is deferred to Init().
 func New(a *app.App, ctx context.Context, cancel context.CancelFunc) (AppModel, error) {
 	keys := DefaultKeyMap
 	theme := NewTheme(true)
 
 	dash := dashboard.New(theme, keys, a.Store)
+	if id := a.Client.TenantID(); id != "" {
+		dash.SetTenant(a.Store.TenantLabel(id))
+	}
 	favs := favorites.New(theme, keys, a.Store)
 	rec := recent.New(theme, keys, a.Store)
 
@@ -115,19 +170,25 @@ func New(a *app.App, ctx context.Context, cancel context.CancelFunc) (AppModel,
 
 // Init initialises the active screen and starts the background user fetch.
 func (m AppModel) Init() tea.Cmd {
-	fetchUser := func() tea.Msg {
-		callCtx, callCancel := context.WithTimeout(m.ctx, 30*time.Second)
+	if m.a.Config.Command == "" {
+		return tea.Batch(m.fetchUser(), m.dashboardModel.Init())
+	}
+	return m.fetchUser()
+}
+
+// fetchUser resolves the signed-in user's object ID in the current tenant.
+func (m *AppModel) fetchUser() tea.Cmd {
+	client := m.a.Client
+	ctx := m.ctx
+	return func() tea.Msg {
+		callCtx, callCancel := context.WithTimeout(ctx, 30*time.Second)
 		defer callCancel()
-		user, err := m.a.Client.GetCurrentUser(callCtx)
+		user, err := client.GetCurrentUser(callCtx)
 		if err != nil {
 			return userReadyMsg{err: err}
 		}
-		return userReadyMsg{principalID: user.ID}
-	}
-	if m.a.Config.Command == "" {
-		return tea.Batch(fetchUser, m.dashboardModel.Init())
+		return userReadyMsg{principalID: user.ID, source: client.CredentialSource()}
 	}
-	return fetchUser
 }
 
 // Update routes messages to the active screen and handles global keys.
@@ -144,23 +205,68 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case userReadyMsg:
 		if msg.err != nil {
+			m.switchedFav = nil
 			m.dashboardModel.SetAuthErr(fmt.Sprintf("auth: %v", msg.err))
 			return m, nil
 		}
 		m.principalID = msg.principalID
 		m.userReady = true
 		m.dashboardModel.SetReady()
+		m.dashboardModel.SetSource(msg.source)
+		if fav := m.switchedFav; fav != nil {
+			m.switchedFav = nil
+			return m, m.startWizard(fav, m.switchedAuto)
+		}
+		revalidate := tea.Batch(m.revalidateCache(), m.runQueuedDeactivations())
 		// If a headless command was pending, dispatch it now.
 		switch m.a.Config.Command {
 		case app.CmdActivate:
-			return m, m.startWizard(nil, false)
+			return m, tea.Batch(m.startWizard(nil, false), revalidate)
 		case app.CmdDeactivate:
-			return m, m.startDeactivate()
+			return m, tea.Batch(m.startDeactivate(), revalidate)
 		case app.CmdStatus:
-			return m, m.startStatus()
+			return m, tea.Batch(m.startStatus(), revalidate)
+		case app.CmdRequests:
+			return m, tea.Batch(m.startRequests(), revalidate)
+		case app.CmdHistory:
+			return m, tea.Batch(m.startHistory(), revalidate)
+		}
+		return m, tea.Batch(m.loadPendingCount(), m.loadElevations(), revalidate)
+
+	case elevationsMsg:
+		m.dashboardModel.SetElevations(msg.elevs)
+		return m, nil
+
+	case tenants.SelectMsg:
+		m.screen = ScreenDashboard
+		return m, m.switchTenant(msg.TenantID)
+
+	case tenants.DoneMsg:
+		m.screen = ScreenDashboard
+		return m, nil
+
+	case pendingCountMsg:
+		if msg.err != nil {
+			return m, nil
+		}
+		m.dashboardModel.SetPending(msg.n)
+		if msg.n > 0 && !m.pendingPolling {
+			m.pendingPolling = true
+			return m, tea.Tick(pendingPollInterval, func(time.Time) tea.Msg { return pendingTickMsg{} })
 		}
 		return m, nil
 
+	case pendingTickMsg:
+		m.pendingPolling = false
+		return m, m.loadPendingCount()
+
+	case queueRunMsg:
+		return m, m.handleQueueRun(msg)
+
+	case queueTickMsg:
+		m.queuePolling = false
+		return m, m.runQueuedDeactivations()
+
 	case status.CancelMsg:
 		m.screen = ScreenDashboard
 		return m, nil
@@ -172,7 +278,7 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			notice := strings.TrimRight(summary, "\n")
 			m.dashboardModel.SetNotice(notice, err != nil)
 			m.screen = ScreenDashboard
-			return m, nil
+			return m, tea.Batch(m.loadPendingCount(), m.loadElevations())
 		}
 		m.exitSummary, m.exitErr = buildActivationSummary(msg.Results)
 		return m, tea.Quit
@@ -186,7 +292,15 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case deactivate.DoneMsg:
-		m.exitSummary, m.exitErr = buildDeactivationSummary(msg.Results)
+		summary, err := buildDeactivationSummary(msg.Results)
+		// Queued deactivations only run while pim is open, so stay on the
+		// dashboard instead of exiting.
+		if slices.ContainsFunc(msg.Results, deactivate.Result.Queued) {
+			m.dashboardModel.SetNotice(strings.TrimRight(summary, "\n"), err != nil)
+			m.screen = ScreenDashboard
+			return m, tea.Batch(m.runQueuedDeactivations(), m.loadElevations())
+		}
+		m.exitSummary, m.exitErr = summary, err
 		return m, tea.Quit
 
 	case deactivate.CancelMsg:
@@ -205,6 +319,15 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.screen = ScreenDashboard
 		return m, nil
 
+	case requests.DoneMsg:
+		m.dashboardModel.SetPending(msg.Pending)
+		m.screen = ScreenDashboard
+		return m, nil
+
+	case history.DoneMsg:
+		m.screen = ScreenDashboard
+		return m, nil
+
 	case recent.ActivateMsg:
 		fav := msg.Favorite
 		return m, m.startWizard(&fav, false)
@@ -241,6 +364,12 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case key.Matches(msg, m.keys.Recent):
 				m.screen = ScreenRecent
 				return m, m.recentModel.Init()
+			case key.Matches(msg, m.keys.Requests):
+				return m, m.startRequests()
+			case key.Matches(msg, m.keys.Tenants):
+				return m, m.startTenants()
+			case key.Matches(msg, m.keys.History):
+				return m, m.startHistory()
 			}
 		}
 	}
@@ -253,6 +382,7 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		editing = m.wizardModel.Editing()
 	case ScreenStatus:
 		m.statusModel, cmd = m.statusModel.Update(msg)
+		editing = m.statusModel.Editing()
 	case ScreenDeactivate:
 		m.deactivateModel, cmd = m.deactivateModel.Update(msg)
 	case ScreenFavorites:
@@ -260,6 +390,13 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		editing = m.favoritesModel.Editing()
 	case ScreenRecent:
 		m.recentModel, cmd = m.recentModel.Update(msg)
+	case ScreenRequests:
+		m.requestsModel, cmd = m.requestsModel.Update(msg)
+	case ScreenTenants:
+		m.tenantsModel, cmd = m.tenantsModel.Update(msg)
+	case ScreenHistory:
+		m.historyModel, cmd = m.historyModel.Update(msg)
+		editing = m.historyModel.Editing()
 	default:
 		m.dashboardModel, cmd = m.dashboardModel.Update(msg)
 	}
@@ -273,7 +410,7 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // startStatus constructs a fresh status model and switches to that screen.
 func (m *AppModel) startStatus() tea.Cmd {
-	client := m.a.Client
+	client := m.a.Cached(m.a.Client, m.principalID)
 	ctx := m.ctx
 	m.statusModel = status.New(m.theme, m.keys, func() ([]azure.ActiveAssignment, []azure.Role, error) {
 		callCtx, callCancel := context.WithTimeout(ctx, 30*time.Second)
@@ -282,6 +419,11 @@ func (m *AppModel) startStatus() tea.Cmd {
 		if err != nil {
 			return nil, nil, err
 		}
+		// Scheduled assignments are informational; a failure to list them
+		// should not hide the active and eligible roles.
+		if scheduled, err := client.GetScheduledAssignments(callCtx); err == nil {
+			active = append(active, scheduled...)
+		}
 		callCtx2, callCancel2 := context.WithTimeout(ctx, 30*time.Second)
 		defer callCancel2()
 		eligible, err := client.GetEligibleRoles(callCtx2)
@@ -289,15 +431,207 @@ func (m *AppModel) startStatus() tea.Cmd {
 			return nil, nil, err
 		}
 		return active, eligible, nil
-	})
+	}, roleDefinitionLoader(ctx, client.Client))
 	m.screen = ScreenStatus
 	return m.statusModel.Init()
 }
 
+// roleDefinitionLoader returns the fetch used by the role permission views.
+func roleDefinitionLoader(ctx context.Context, client *azure.Client) func(scope, roleDefinitionID string) (*azure.RoleDefinition, error) {
+	return func(scope, roleDefinitionID string) (*azure.RoleDefinition, error) {
+		callCtx, callCancel := context.WithTimeout(ctx, 30*time.Second)
+		defer callCancel()
+		return client.GetRoleDefinition(callCtx, scope, roleDefinitionID)
+	}
+}
+
+// startRequests constructs the pending requests model and switches to that screen.
+func (m *AppModel) startRequests() tea.Cmd {
+	client := m.a.Client
+	ctx := m.ctx
+	m.requestsModel = requests.New(
+		m.theme,
+		m.keys,
+		func() ([]azure.AssignmentRequest, error) {
+			callCtx, callCancel := context.WithTimeout(ctx, 30*time.Second)
+			defer callCancel()
+			return client.GetPendingRequests(callCtx)
+		},
+		func(req azure.AssignmentRequest) error {
+			callCtx, callCancel := context.WithTimeout(ctx, 30*time.Second)
+			defer callCancel()
+			return client.CancelRequest(callCtx, req)
+		},
+	)
+	m.screen = ScreenRequests
+	return m.requestsModel.Init()
+}
+
+// startHistory constructs the request history model and switches to that screen.
+func (m *AppModel) startHistory() tea.Cmd {
+	client := m.a.Client
+	ctx := m.ctx
+	m.historyModel = history.New(m.theme, m.keys, func(since time.Time) ([]azure.AssignmentRequest, error) {
+		// Approver lookups add a call per approved request.
+		callCtx, callCancel := context.WithTimeout(ctx, 60*time.Second)
+		defer callCancel()
+		return client.GetRequestHistory(callCtx, azure.HistoryFilter{Since: since})
+	})
+	m.screen = ScreenHistory
+	return m.historyModel.Init()
+}
+
+// startTenants constructs the tenant switcher and switches to that screen.
+func (m *AppModel) startTenants() tea.Cmd {
+	client := m.a.Client
+	ctx := m.ctx
+	m.tenantsModel = tenants.New(m.theme, m.keys, client.TenantID(), m.a.Store.Tenants(),
+		func() ([]azure.Tenant, error) {
+			callCtx, callCancel := context.WithTimeout(ctx, 30*time.Second)
+			defer callCancel()
+			return client.ListTenants(callCtx)
+		})
+	m.screen = ScreenTenants
+	return m.tenantsModel.Init()
+}
+
+// switchTenant points the client at tenantID and re-resolves the user, whose
+// object ID differs per tenant.
+func (m *AppModel) switchTenant(tenantID string) tea.Cmd {
+	if err := m.a.SwitchTenant(tenantID); err != nil {
+		m.dashboardModel.SetNotice(err.Error(), true)
+		return nil
+	}
+	m.principalID = ""
+	m.userReady = false
+	m.dashboardModel.SetTenant(m.a.Store.TenantLabel(m.a.Client.TenantID()))
+	m.dashboardModel.SetPending(0)
+	m.dashboardModel.SetQueued(nil)
+	return m.fetchUser()
+}
+
+// loadElevations lists active assignments in every configured tenant for the
+// dashboard. It does nothing when no tenants are configured.
+func (m *AppModel) loadElevations() tea.Cmd {
+	configured := m.a.Store.Tenants()
+	if len(configured) == 0 {
+		return nil
+	}
+	client := m.a.Client
+	ctx := m.ctx
+	return func() tea.Msg {
+		elevs := make([]dashboard.TenantElevations, len(configured))
+		var wg sync.WaitGroup
+		for i, t := range configured {
+			elevs[i].Tenant = t.Display()
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				tc, err := client.ForTenant(t.ID)
+				if err != nil {
+					elevs[i].Err = err
+					return
+				}
+				callCtx, callCancel := context.WithTimeout(ctx, 30*time.Second)
+				defer callCancel()
+				elevs[i].Assignments, elevs[i].Err = tc.GetActiveAssignments(callCtx)
+			}()
+		}
+		wg.Wait()
+		return elevationsMsg{elevs: elevs}
+	}
+}
+
+// revalidateCache refreshes the cached eligible roles and active assignments
+// in the background, so screens render from the cache right away and the next
+// one opened sees current data.
+func (m *AppModel) revalidateCache() tea.Cmd {
+	client := m.a.Cached(m.a.Client, m.principalID)
+	ctx := m.ctx
+	return func() tea.Msg {
+		callCtx, callCancel := context.WithTimeout(ctx, 60*time.Second)
+		defer callCancel()
+		_ = client.Revalidate(callCtx)
+		return nil
+	}
+}
+
+// runQueuedDeactivations retries the due deactivations queued for the current
+// tenant and user.
+func (m *AppModel) runQueuedDeactivations() tea.Cmd {
+	if m.principalID == "" {
+		return nil
+	}
+	a := m.a
+	client := a.Cached(a.Client, m.principalID)
+	principalID := m.principalID
+	ctx := m.ctx
+	return func() tea.Msg {
+		callCtx, callCancel := context.WithTimeout(ctx, 60*time.Second)
+		defer callCancel()
+		results, err := a.RunQueuedDeactivations(callCtx, client, client.TenantID(), principalID, time.Now())
+		return queueRunMsg{results: results, err: err}
+	}
+}
+
+// handleQueueRun shows the outcome of a queue retry on the dashboard and
+// schedules the next one while deactivations are still queued.
+func (m *AppModel) handleQueueRun(msg queueRunMsg) tea.Cmd {
+	queued := m.a.QueuedDeactivations(m.a.Client.TenantID(), m.principalID)
+	m.dashboardModel.SetQueued(queued)
+
+	var cmds []tea.Cmd
+	var sb strings.Builder
+	isErr := msg.err != nil
+	for _, r := range msg.results {
+		if r.Err != nil {
+			fmt.Fprintf(&sb, "queued deactivation failed: %s @ %s — %v\n", r.Entry.Role, r.Entry.ScopeDisplay, r.Err)
+			isErr = true
+			continue
+		}
+		fmt.Fprintf(&sb, "deactivated: %s @ %s\n", r.Entry.Role, r.Entry.ScopeDisplay)
+	}
+	if msg.err != nil {
+		fmt.Fprintf(&sb, "save state: %v\n", msg.err)
+	}
+	if sb.Len() > 0 {
+		m.dashboardModel.SetNotice(strings.TrimRight(sb.String(), "\n"), isErr)
+	}
+	if len(msg.results) > 0 {
+		cmds = append(cmds, m.loadElevations())
+	}
+	if len(queued) > 0 && !m.queuePolling {
+		m.queuePolling = true
+		cmds = append(cmds, tea.Tick(queuePollInterval, func(time.Time) tea.Msg { return queueTickMsg{} }))
+	}
+	return tea.Batch(cmds...)
+}
+
+// loadPendingCount fetches the number of outstanding requests for the dashboard badge.
+func (m *AppModel) loadPendingCount() tea.Cmd {
+	client := m.a.Client
+	ctx := m.ctx
+	return func() tea.Msg {
+		callCtx, callCancel := context.WithTimeout(ctx, 30*time.Second)
+		defer callCancel()
+		reqs, err := client.GetPendingRequests(callCtx)
+		return pendingCountMsg{n: len(reqs), err: err}
+	}
+}
+
 // startWizard builds the Wizard deps and switches to the activate screen.
 // fav may be nil (full wizard) or point to a pre-filled favorite.
 // autoSubmit skips all interactive steps and submits immediately.
 func (m *AppModel) startWizard(fav *state.Favorite, autoSubmit bool) tea.Cmd {
+	if fav != nil && fav.Tenant != "" && !strings.EqualFold(fav.Tenant, m.a.Client.TenantID()) {
+		cmd := m.switchTenant(fav.Tenant)
+		if cmd == nil {
+			return nil
+		}
+		m.switchedFav, m.switchedAuto = fav, autoSubmit
+		m.screen = ScreenDashboard
+		return cmd
+	}
 	if m.principalID == "" {
 		m.exitSummary = "error: user identity not yet resolved — please retry\n"
 		m.exitErr = errors.New("principal ID unavailable")
@@ -305,33 +639,45 @@ func (m *AppModel) startWizard(fav *state.Favorite, autoSubmit bool) tea.Cmd {
 	}
 	cfg := m.a.Config
 	principalID := m.principalID
-	client := m.a.Client
+	client := m.a.Cached(m.a.Client, principalID)
 	ctx := m.ctx
 
 	roleFilter := cfg.Roles
 	scopeFilter := cfg.Scopes
 	timeStr := cfg.TimeStr
+	ticket := cfg.Ticket
+	ticketSystem := cfg.TicketSystem
 
 	if fav != nil {
 		if fav.Role != "" {
 			roleFilter = append([]string{fav.Role}, roleFilter...)
 		}
-		if fav.Scope != "" && len(scopeFilter) == 0 {
-			scopeFilter = []string{fav.Scope}
+		if fav.Target() != "" && len(scopeFilter) == 0 {
+			scopeFilter = []string{fav.Target()}
 		}
 		if fav.Duration != "" && timeStr == "" {
 			timeStr = fav.Duration
 		}
+		if fav.Ticket != "" && ticket == "" {
+			ticket = fav.Ticket
+		}
+		if fav.TicketSystem != "" && ticketSystem == "" {
+			ticketSystem = fav.TicketSystem
+		}
 	}
 
 	deps := activate.Deps{
-		PrincipalID: principalID,
-		RoleFilter:  roleFilter,
-		ScopeFilter: scopeFilter,
-		TimeStr:     timeStr,
-		Justific:    cfg.Justification,
-		AutoSubmit:  cfg.Yes,
-		Store:       m.a.Store,
+		PrincipalID:  principalID,
+		Tenant:       client.TenantID(),
+		RoleFilter:   roleFilter,
+		ScopeFilter:  scopeFilter,
+		TimeStr:      timeStr,
+		Justific:     cfg.Justification,
+		Ticket:       ticket,
+		TicketSystem: ticketSystem,
+		StartStr:     cfg.StartStr,
+		AutoSubmit:   cfg.Yes,
+		Store:        m.a.Store,
 		LoadRoles: func() ([]azure.Role, error) {
 			callCtx, callCancel := context.WithTimeout(ctx, 60*time.Second)
 			defer callCancel()
@@ -352,11 +698,30 @@ func (m *AppModel) startWizard(fav *state.Favorite, autoSubmit bool) tea.Cmd {
 			defer callCancel()
 			return client.ListEligibleResourceGroups(callCtx, subID)
 		},
-		Activate: func(role azure.Role, pid, justification string, minutes int, targetScope string) error {
+		LoadResources: func(subID, resourceGroup string) ([]azure.Resource, error) {
+			callCtx, callCancel := context.WithTimeout(ctx, 30*time.Second)
+			defer callCancel()
+			return client.ListEligibleResources(callCtx, subID, resourceGroup)
+		},
+		Activate: func(req azure.ActivationRequest) (*azure.ScheduleResponse, error) {
 			callCtx, callCancel := context.WithTimeout(ctx, 60*time.Second)
 			defer callCancel()
-			_, err := client.ActivateRole(callCtx, role, pid, justification, minutes, targetScope)
-			return err
+			return client.ActivateRole(callCtx, req)
+		},
+		Wait: func(req azure.ActivationRequest, resp *azure.ScheduleResponse, onStatus func(string)) error {
+			timeout := cfg.WaitTimeout
+			if timeout <= 0 {
+				timeout = app.DefaultWaitTimeout
+			}
+			callCtx, callCancel := context.WithTimeout(ctx, timeout)
+			defer callCancel()
+			return client.WaitForActivation(callCtx, req, resp, onStatus)
+		},
+		LoadDefinition: roleDefinitionLoader(ctx, client.Client),
+		LoadPolicy: func(role azure.Role, targetScope string) (azure.ActivationPolicy, error) {
+			callCtx, callCancel := context.WithTimeout(ctx, 30*time.Second)
+			defer callCancel()
+			return client.GetActivationPolicy(callCtx, role, targetScope)
 		},
 	}
 
@@ -388,7 +753,8 @@ func (m *AppModel) startDeactivate() tea.Cmd {
 		return tea.Quit
 	}
 	principalID := m.principalID
-	client := m.a.Client
+	a := m.a
+	client := a.Cached(a.Client, principalID)
 	ctx := m.ctx
 
 	m.deactivateModel = deactivate.New(
@@ -406,6 +772,10 @@ func (m *AppModel) startDeactivate() tea.Cmd {
 			_, err := client.DeactivateRole(callCtx, assignment, pid)
 			return err
 		},
+		func(assignment azure.ActiveAssignment) (time.Time, error) {
+			q, err := a.QueueDeactivation(client.TenantID(), principalID, assignment, time.Now())
+			return q.NotBefore, err
+		},
 	)
 	m.screen = ScreenDeactivate
 	return m.deactivateModel.Init()
@@ -444,6 +814,12 @@ func (m AppModel) View() tea.View {
 			body = m.favoritesModel.View()
 		case ScreenRecent:
 			body = m.recentModel.View()
+		case ScreenRequests:
+			body = m.requestsModel.View()
+		case ScreenTenants:
+			body = m.tenantsModel.View()
+		case ScreenHistory:
+			body = m.historyModel.View()
 		default:
 			body = m.dashboardModel.View()
 		}
@@ -490,10 +866,15 @@ func buildActivationSummary(results []activate.Result) (string, error) {
 	var sb strings.Builder
 	var errs []error
 	for _, r := range results {
-		if r.Err != nil {
+		switch {
+		case r.Err != nil:
 			fmt.Fprintf(&sb, "failed:  %s @ %s — %v\n", r.RoleName, r.Scope, r.Err)
 			errs = append(errs, r.Err)
-		} else {
+		case r.Pending():
+			fmt.Fprintf(&sb, "pending approval: %s @ %s (see 'pim requests')\n", r.RoleName, r.Scope)
+		case !r.Start.IsZero():
+			fmt.Fprintf(&sb, "scheduled: %s @ %s from %s\n", r.RoleName, r.Scope, r.Start.Format("2006-01-02 15:04"))
+		default:
 			fmt.Fprintf(&sb, "activated: %s @ %s\n", r.RoleName, r.Scope)
 		}
 	}
@@ -505,10 +886,14 @@ func buildDeactivationSummary(results []deactivate.Result) (string, error) {
 	var sb strings.Builder
 	var errs []error
 	for _, r := range results {
-		if r.Err != nil {
+		switch {
+		case r.Err != nil:
 			fmt.Fprintf(&sb, "failed:      %s @ %s — %v\n", r.RoleName, r.Scope, r.Err)
 			errs = append(errs, r.Err)
-		} else {
+		case r.Queued():
+			fmt.Fprintf(&sb, "queued:      %s @ %s — deactivates after %s while pim is open\n",
+				r.RoleName, r.Scope, r.QueuedUntil.Local().Format("15:04"))
+		default:
 			fmt.Fprintf(&sb, "deactivated: %s @ %s\n", r.RoleName, r.Scope)
 		}
 	}
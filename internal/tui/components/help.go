@@ -22,6 +22,10 @@ func RenderHelp(theme styles.Theme, keys styles.KeyMap, width int) string {
 				keys.Activate,
 				keys.Deactivate,
 				keys.Favorites,
+				keys.Recent,
+				keys.Requests,
+				keys.Tenants,
+				keys.History,
 				keys.Back,
 				keys.Quit,
 			},
@@ -35,6 +39,7 @@ func RenderHelp(theme styles.Theme, keys styles.KeyMap, width int) string {
 				keys.Enter,
 				key.NewBinding(key.WithKeys("/"), key.WithHelp("/", "filter")),
 				keys.Refresh,
+				keys.Details,
 			},
 		},
 		{
@@ -7,6 +7,7 @@ import (
 	"charm.land/bubbles/v2/key"
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
+	"github.com/jeircul/pim/internal/azure"
 	"github.com/jeircul/pim/internal/state"
 	"github.com/jeircul/pim/internal/tui/components"
 	"github.com/jeircul/pim/internal/tui/styles"
@@ -17,6 +18,14 @@ type ActivateMsg struct {
 	Favorite *state.Favorite // nil = open full wizard
 }
 
+// TenantElevations holds the active assignments listed in one configured
+// tenant, or the error that prevented listing them.
+type TenantElevations struct {
+	Tenant      string // display label
+	Assignments []azure.ActiveAssignment
+	Err         error
+}
+
 var logo = []string{
 	" ___  _ __  __ ",
 	"| _ \\| |  \\/  |",
@@ -33,6 +42,11 @@ type Model struct {
 	authErr   string
 	notice    string
 	noticeErr bool
+	pending   int
+	tenant    string
+	source    string
+	elevs     []TenantElevations
+	queued    []state.QueuedDeactivation
 	width     int
 	height    int
 }
@@ -56,6 +70,23 @@ func (m *Model) SetAuthErr(msg string) { m.authErr = msg }
 // SetNotice sets an informational or error notice to display on the dashboard.
 func (m *Model) SetNotice(msg string, isErr bool) { m.notice = msg; m.noticeErr = isErr }
 
+// SetTenant records the label of the active tenant and marks identity as
+// unresolved until SetReady is called for the new tenant.
+func (m *Model) SetTenant(label string) { m.tenant = label; m.userReady = false }
+
+// SetSource records the credential source that signed the user in.
+func (m *Model) SetSource(source string) { m.source = source }
+
+// SetElevations records the active assignments across configured tenants.
+func (m *Model) SetElevations(e []TenantElevations) { m.elevs = e }
+
+// SetQueued records the deactivations waiting for Azure's minimum active
+// duration to pass.
+func (m *Model) SetQueued(q []state.QueuedDeactivation) { m.queued = q }
+
+// SetPending records the number of outstanding activation requests shown as a badge.
+func (m *Model) SetPending(n int) { m.pending = n }
+
 // Init is a no-op — the landing screen loads no data.
 func (m Model) Init() tea.Cmd { return nil }
 
@@ -117,12 +148,52 @@ func (m Model) View() string {
 				line += "    "
 			}
 			line += m.theme.Bold.Render(f.Label)
-			line += m.theme.Subtle.Render(fmt.Sprintf("  %s  %s  %s", f.Role, f.Scope, f.Duration))
+			line += m.theme.Subtle.Render(fmt.Sprintf("  %s  %s  %s", f.Role, f.Target(), f.Duration))
+			if f.Tenant != "" {
+				line += "  " + m.theme.Tag.Render(m.store.TenantLabel(f.Tenant))
+			}
 			sb.WriteString(line + "\n")
 		}
 		sb.WriteString("\n")
 	}
 
+	if len(m.elevs) > 0 {
+		sb.WriteString(m.theme.Bold.Render("Active elevations") + "\n")
+		for _, e := range m.elevs {
+			sb.WriteString("  " + m.theme.Tag.Render(e.Tenant) + "\n")
+			switch {
+			case e.Err != nil:
+				sb.WriteString("    " + lipgloss.NewStyle().Foreground(m.theme.Danger).Render(e.Err.Error()) + "\n")
+			case len(e.Assignments) == 0:
+				sb.WriteString("    " + m.theme.Subtle.Render("none") + "\n")
+			}
+			for _, a := range e.Assignments {
+				sb.WriteString("    " + a.RoleName + m.theme.Subtle.Render(fmt.Sprintf("  %s  %s", a.ScopeDisplay, a.ExpiryDisplay())) + "\n")
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(m.queued) > 0 {
+		sb.WriteString(m.theme.Bold.Render("Queued deactivations") + "\n")
+		for _, q := range m.queued {
+			when := "after " + q.NotBefore.Local().Format("15:04")
+			if q.LastError != "" {
+				when = "retrying: " + q.LastError
+			}
+			sb.WriteString("  " + q.Role + m.theme.Subtle.Render(fmt.Sprintf("  %s  %s", q.ScopeDisplay, when)) + "\n")
+		}
+		sb.WriteString("\n")
+	}
+
+	if m.pending > 0 {
+		badge := fmt.Sprintf("⧗ %d pending request", m.pending)
+		if m.pending > 1 {
+			badge += "s"
+		}
+		sb.WriteString(m.theme.Tag.Render(badge) + m.theme.Subtle.Render("  press p to view") + "\n\n")
+	}
+
 	if m.notice != "" {
 		style := lipgloss.NewStyle().Foreground(m.theme.Success)
 		if m.noticeErr {
@@ -141,12 +212,22 @@ func (m Model) View() string {
 		m.keys.Deactivate,
 		m.keys.Favorites,
 		m.keys.Recent,
+		m.keys.Requests,
+		m.keys.Tenants,
+		m.keys.History,
 		m.keys.Quit,
 	}
-	extra := ""
+	var extras []string
+	if m.tenant != "" {
+		extras = append(extras, "tenant "+m.tenant)
+	}
+	if m.userReady && m.source != "" {
+		extras = append(extras, "via "+m.source)
+	}
 	if !m.userReady {
-		extra = "resolving identity…"
+		extras = append(extras, "resolving identity…")
 	}
+	extra := strings.Join(extras, "  ")
 	sb.WriteString(components.RenderStatusBar(m.theme.HelpKey, m.theme.HelpDesc, m.theme.Subtle, hints, extra))
 
 	return sb.String()
@@ -3,6 +3,7 @@ package deactivate
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"charm.land/bubbles/v2/key"
 	tea "charm.land/bubbletea/v2"
@@ -11,13 +12,18 @@ import (
 	"github.com/jeircul/pim/internal/tui/styles"
 )
 
-// Result holds the outcome of a single deactivation.
+// Result holds the outcome of a single deactivation. QueuedUntil is set when
+// Azure rejected the deactivation as too early and it was queued instead.
 type Result struct {
-	RoleName string
-	Scope    string
-	Err      error
+	RoleName    string
+	Scope       string
+	Err         error
+	QueuedUntil time.Time
 }
 
+// Queued reports whether the deactivation was queued for later.
+func (r Result) Queued() bool { return !r.QueuedUntil.IsZero() }
+
 // DoneMsg is sent when deactivation completes (success or partial failure).
 type DoneMsg struct{ Results []Result }
 
@@ -29,6 +35,8 @@ type deactStep int
 const (
 	stepSelect deactStep = iota
 	stepConfirm
+	// stepSchedule offers to queue deactivations Azure rejected as too early.
+	stepSchedule
 )
 
 type deactResultMsg struct {
@@ -36,6 +44,12 @@ type deactResultMsg struct {
 	err error
 }
 
+type queueResultMsg struct {
+	idx         int
+	queuedUntil time.Time
+	err         error
+}
+
 type itemState int
 
 const (
@@ -43,6 +57,10 @@ const (
 	itemRunning
 	itemDone
 	itemFailed
+	// itemTooEarly is a deactivation Azure rejected within the first minutes
+	// of the activation; it can be queued.
+	itemTooEarly
+	itemQueued
 )
 
 type deactItem struct {
@@ -50,23 +68,27 @@ type deactItem struct {
 	selected   bool
 	state      itemState
 	err        error
+	notBefore  time.Time
 }
 
 // Model is the deactivation screen.
 type Model struct {
-	theme       styles.Theme
-	keys        styles.KeyMap
-	spinner     components.Spinner
-	items       []deactItem
-	skipped     int // permanent + inherited assignments hidden from the list
-	cursor      int
-	step        deactStep
-	loading     bool
-	err         error
-	width       int
-	height      int
-	loadFunc    func() ([]azure.ActiveAssignment, error)
-	deactivate  func(assignment azure.ActiveAssignment, principalID string) error
+	theme      styles.Theme
+	keys       styles.KeyMap
+	spinner    components.Spinner
+	items      []deactItem
+	skipped    int // permanent + inherited assignments hidden from the list
+	cursor     int
+	step       deactStep
+	loading    bool
+	err        error
+	width      int
+	height     int
+	loadFunc   func() ([]azure.ActiveAssignment, error)
+	deactivate func(assignment azure.ActiveAssignment, principalID string) error
+	// queue schedules a rejected deactivation for its earliest allowed time
+	// and returns that time; nil disables the offer.
+	queue       func(assignment azure.ActiveAssignment) (time.Time, error)
 	principalID string
 }
 
@@ -77,6 +99,7 @@ func New(
 	principalID string,
 	loadFunc func() ([]azure.ActiveAssignment, error),
 	deactivateFunc func(azure.ActiveAssignment, string) error,
+	queueFunc func(azure.ActiveAssignment) (time.Time, error),
 ) Model {
 	return Model{
 		theme:       theme,
@@ -85,6 +108,7 @@ func New(
 		loading:     true,
 		loadFunc:    loadFunc,
 		deactivate:  deactivateFunc,
+		queue:       queueFunc,
 		principalID: principalID,
 	}
 }
@@ -127,14 +151,38 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		m.cursor = 0
 
 	case deactResultMsg:
-		m.items[msg.idx].state = itemDone
+		it := &m.items[msg.idx]
+		switch {
+		case msg.err == nil:
+			it.state = itemDone
+		case m.queue != nil && azure.IsActiveDurationTooShort(msg.err):
+			it.state = itemTooEarly
+			it.err = msg.err
+			it.notBefore = it.assignment.EarliestDeactivation(time.Now())
+		default:
+			it.state = itemFailed
+			it.err = msg.err
+		}
+		if m.allDone() {
+			if m.countState(itemTooEarly) > 0 {
+				m.step = stepSchedule
+				return m, nil
+			}
+			return m, m.done()
+		}
+
+	case queueResultMsg:
+		it := &m.items[msg.idx]
 		if msg.err != nil {
-			m.items[msg.idx].state = itemFailed
-			m.items[msg.idx].err = msg.err
+			it.state = itemFailed
+			it.err = fmt.Errorf("%w (queue deactivation: %v)", it.err, msg.err)
+		} else {
+			it.state = itemQueued
+			it.err = nil
+			it.notBefore = msg.queuedUntil
 		}
 		if m.allDone() {
-			results := m.collectResults()
-			return m, func() tea.Msg { return DoneMsg{Results: results} }
+			return m, m.done()
 		}
 
 	case tea.KeyPressMsg:
@@ -146,6 +194,8 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 			return m.updateSelect(msg)
 		case stepConfirm:
 			return m.updateConfirm(msg)
+		case stepSchedule:
+			return m.updateSchedule(msg)
 		}
 
 	default:
@@ -200,6 +250,42 @@ func (m Model) updateConfirm(msg tea.KeyPressMsg) (Model, tea.Cmd) {
 	return m, nil
 }
 
+func (m Model) updateSchedule(msg tea.KeyPressMsg) (Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Enter), msg.String() == "s":
+		var cmds []tea.Cmd
+		for i := range m.items {
+			if m.items[i].selected && m.items[i].state == itemTooEarly {
+				m.items[i].state = itemRunning
+				cmds = append(cmds, m.queueDeactivation(i))
+			}
+		}
+		return m, tea.Batch(cmds...)
+	case key.Matches(msg, m.keys.Back), msg.String() == "esc", msg.String() == "q":
+		for i := range m.items {
+			if m.items[i].state == itemTooEarly {
+				m.items[i].state = itemFailed
+			}
+		}
+		return m, m.done()
+	}
+	return m, nil
+}
+
+func (m Model) queueDeactivation(i int) tea.Cmd {
+	assignment := m.items[i].assignment
+	fn := m.queue
+	return func() tea.Msg {
+		t, err := fn(assignment)
+		return queueResultMsg{idx: i, queuedUntil: t, err: err}
+	}
+}
+
+func (m Model) done() tea.Cmd {
+	results := m.collectResults()
+	return func() tea.Msg { return DoneMsg{Results: results} }
+}
+
 func (m Model) runDeactivation(i int) tea.Cmd {
 	assignment := m.items[i].assignment
 	pid := m.principalID
@@ -224,15 +310,29 @@ func (m *Model) collectResults() []Result {
 		if !it.selected {
 			continue
 		}
-		results = append(results, Result{
+		r := Result{
 			RoleName: it.assignment.RoleName,
 			Scope:    it.assignment.ScopeDisplay,
 			Err:      it.err,
-		})
+		}
+		if it.state == itemQueued {
+			r.QueuedUntil = it.notBefore
+		}
+		results = append(results, r)
 	}
 	return results
 }
 
+func (m *Model) countState(s itemState) int {
+	n := 0
+	for _, it := range m.items {
+		if it.selected && it.state == s {
+			n++
+		}
+	}
+	return n
+}
+
 func (m *Model) countSelected() int {
 	n := 0
 	for _, it := range m.items {
@@ -295,7 +395,7 @@ func (m Model) View() string {
 		sb.WriteString(components.RenderStatusBar(m.theme.HelpKey, m.theme.HelpDesc, m.theme.Subtle, hints,
 			"space toggle  → confirm"))
 
-	case stepConfirm:
+	case stepConfirm, stepSchedule:
 		sb.WriteString(m.theme.Title.Render(fmt.Sprintf("Deactivating %d role(s):", m.countSelected())) + "\n\n")
 		for _, it := range m.items {
 			if !it.selected {
@@ -310,6 +410,10 @@ func (m Model) View() string {
 				stateStr = m.theme.Active.Render("✓ done")
 			case itemFailed:
 				stateStr = m.theme.DangerText.Render("✗ failed")
+			case itemTooEarly:
+				stateStr = m.theme.Tag.Render("too early — allowed from " + it.notBefore.Local().Format("15:04"))
+			case itemQueued:
+				stateStr = m.theme.Active.Render("queued for " + it.notBefore.Local().Format("15:04"))
 			default:
 				stateStr = m.theme.Subtle.Render("waiting")
 			}
@@ -317,6 +421,14 @@ func (m Model) View() string {
 				it.assignment.RoleName, scope, stateStr))
 		}
 		sb.WriteString("\n")
+		if m.step == stepSchedule {
+			sb.WriteString(m.theme.Subtle.Render(fmt.Sprintf(
+				"Azure rejects deactivation within %d minutes of activation. Schedule it and pim deactivates it while running.",
+				int(azure.MinActiveDuration.Minutes()))) + "\n\n")
+			sb.WriteString(components.RenderStatusBar(m.theme.HelpKey, m.theme.HelpDesc, m.theme.Subtle, nil,
+				"enter/s schedule  esc skip"))
+			break
+		}
 		hints := []key.Binding{m.keys.Enter, m.keys.Back}
 		sb.WriteString(components.RenderStatusBar(m.theme.HelpKey, m.theme.HelpDesc, m.theme.Subtle, hints,
 			"enter confirm  ← back  q cancel"))
@@ -31,6 +31,8 @@ const (
 	fieldLabel editField = iota
 	fieldRole
 	fieldScope
+	fieldGroup
+	fieldTenant
 	fieldDuration
 	fieldKey
 	fieldCount // sentinel
@@ -204,6 +206,10 @@ func (m Model) fieldPtr(f *state.Favorite, fld editField) *string {
 		return &f.Role
 	case fieldScope:
 		return &f.Scope
+	case fieldGroup:
+		return &f.Group
+	case fieldTenant:
+		return &f.Tenant
 	case fieldDuration:
 		return &f.Duration
 	default:
@@ -232,7 +238,10 @@ func (m Model) View() string {
 					keyTag = m.theme.Tag.Render(fmt.Sprintf("[%d]", f.Key)) + " "
 				}
 				line := cur + keyTag + m.theme.Bold.Render(padRight(f.Label, 20)) +
-					m.theme.Subtle.Render(fmt.Sprintf("  %s  %s  %s", f.Role, f.Scope, f.Duration))
+					m.theme.Subtle.Render(fmt.Sprintf("  %s  %s  %s", f.Role, f.Target(), f.Duration))
+				if f.Tenant != "" {
+					line += "  " + m.theme.Tag.Render(m.store.TenantLabel(f.Tenant))
+				}
 				sb.WriteString(line + "\n")
 			}
 		}
@@ -259,6 +268,8 @@ func (m Model) View() string {
 			{"Label   ", m.edit.Label, fieldLabel},
 			{"Role    ", m.edit.Role, fieldRole},
 			{"Scope   ", m.edit.Scope, fieldScope},
+			{"Group   ", m.edit.Group, fieldGroup},
+			{"Tenant  ", m.edit.Tenant, fieldTenant},
 			{"Duration", m.edit.Duration, fieldDuration},
 			{"Key (1-9)", fmt.Sprintf("%d", m.edit.Key), fieldKey},
 		}
@@ -0,0 +1,255 @@
+package history
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"github.com/jeircul/pim/internal/azure"
+	"github.com/jeircul/pim/internal/tui/components"
+	"github.com/jeircul/pim/internal/tui/styles"
+)
+
+// windows are the look-back periods cycled with "w", in days.
+var windows = []int{7, 30, 90, 365}
+
+// defaultWindow is the index into windows used when the screen opens.
+const defaultWindow = 1
+
+// DoneMsg is sent when the user navigates back from the history screen.
+type DoneMsg struct{}
+
+type loadMsg struct {
+	reqs []azure.AssignmentRequest
+	err  error
+}
+
+// Model is the request history screen, with a filter box matching role,
+// scope, request type and status.
+type Model struct {
+	theme     styles.Theme
+	keys      styles.KeyMap
+	spinner   components.Spinner
+	reqs      []azure.AssignmentRequest
+	cursor    int
+	window    int
+	filter    string
+	filtering bool
+	loading   bool
+	err       error
+	width     int
+	height    int
+	loadFunc  func(since time.Time) ([]azure.AssignmentRequest, error)
+}
+
+// New creates a request history Model. loadFunc lists the requests created
+// since the given time.
+func New(theme styles.Theme, keys styles.KeyMap, loadFunc func(since time.Time) ([]azure.AssignmentRequest, error)) Model {
+	return Model{
+		theme:    theme,
+		keys:     keys,
+		spinner:  components.NewSpinner(theme.Active),
+		window:   defaultWindow,
+		loading:  true,
+		loadFunc: loadFunc,
+	}
+}
+
+// Init starts the spinner and triggers data load.
+func (m Model) Init() tea.Cmd {
+	return tea.Batch(m.spinner.Init(), m.load())
+}
+
+func (m Model) load() tea.Cmd {
+	fn := m.loadFunc
+	since := time.Now().AddDate(0, 0, -windows[m.window])
+	return func() tea.Msg {
+		reqs, err := fn(since)
+		return loadMsg{reqs: reqs, err: err}
+	}
+}
+
+// Editing reports whether the filter text field is active.
+func (m Model) Editing() bool { return m.filtering }
+
+// visible returns the loaded requests matching every word of the filter.
+func (m Model) visible() []azure.AssignmentRequest {
+	words := strings.Fields(strings.ToLower(m.filter))
+	if len(words) == 0 {
+		return m.reqs
+	}
+	var out []azure.AssignmentRequest
+	for _, r := range m.reqs {
+		text := strings.ToLower(strings.Join([]string{
+			r.RoleName, r.Scope, azure.DefaultScopeDisplay(r.Scope, r.ScopeDisplay), r.RequestType, r.StatusDisplay(),
+		}, " "))
+		if matchesAll(text, words) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func matchesAll(text string, words []string) bool {
+	for _, w := range words {
+		if !strings.Contains(text, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// Update handles messages.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case loadMsg:
+		m.loading = false
+		m.err = msg.err
+		m.reqs = msg.reqs
+		if n := len(m.visible()); m.cursor >= n {
+			m.cursor = max(n-1, 0)
+		}
+
+	case tea.KeyPressMsg:
+		if m.loading {
+			break
+		}
+		if m.filtering {
+			return m.updateFilter(msg), nil
+		}
+		switch {
+		case msg.String() == "esc" && m.filter != "":
+			m.filter = ""
+			m.cursor = 0
+		case key.Matches(msg, m.keys.Back), msg.String() == "esc", msg.String() == "q":
+			return m, func() tea.Msg { return DoneMsg{} }
+		case msg.String() == "/":
+			m.filtering = true
+		case key.Matches(msg, m.keys.Up):
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case key.Matches(msg, m.keys.Down):
+			if m.cursor < len(m.visible())-1 {
+				m.cursor++
+			}
+		case msg.String() == "w":
+			m.window = (m.window + 1) % len(windows)
+			m.loading = true
+			return m, tea.Batch(m.spinner.Init(), m.load())
+		case key.Matches(msg, m.keys.Refresh):
+			m.loading = true
+			return m, tea.Batch(m.spinner.Init(), m.load())
+		}
+
+	default:
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+	}
+
+	return m, nil
+}
+
+func (m Model) updateFilter(msg tea.KeyPressMsg) Model {
+	switch msg.String() {
+	case "enter":
+		m.filtering = false
+	case "esc":
+		m.filter = ""
+		m.filtering = false
+	case "backspace":
+		if len(m.filter) > 0 {
+			m.filter = m.filter[:len(m.filter)-1]
+		}
+	case "space":
+		m.filter += " "
+	default:
+		if r := msg.String(); len(r) == 1 {
+			m.filter += r
+		}
+	}
+	m.cursor = 0
+	return m
+}
+
+// View renders the request history screen.
+func (m Model) View() string {
+	var sb strings.Builder
+
+	sb.WriteString(m.theme.Title.Render(fmt.Sprintf("Request history (last %dd)", windows[m.window])) + "\n\n")
+
+	if m.loading {
+		sb.WriteString(m.spinner.View() + " loading…\n")
+		return sb.String()
+	}
+
+	if m.err != nil {
+		sb.WriteString(m.theme.DangerText.Render("error: "+m.err.Error()) + "\n")
+		return sb.String()
+	}
+
+	switch {
+	case m.filtering:
+		sb.WriteString(m.theme.Subtle.Render("Filter: ") + m.filter + "█\n")
+	case m.filter != "":
+		sb.WriteString(m.theme.Subtle.Render("Filter: "+m.filter+"  (esc clear)") + "\n")
+	}
+
+	reqs := m.visible()
+	switch {
+	case len(m.reqs) == 0:
+		sb.WriteString(m.theme.Subtle.Render("  No requests in range.") + "\n")
+	case len(reqs) == 0:
+		sb.WriteString(m.theme.Subtle.Render("  No requests match the filter.") + "\n")
+	}
+	for i, r := range reqs {
+		scope := azure.DefaultScopeDisplay(r.Scope, r.ScopeDisplay)
+		line := fmt.Sprintf("  %-16s %-30s %-30s %-14s %s", created(r.CreatedOn), r.RoleName, scope, r.RequestType, m.theme.Tag.Render(r.StatusDisplay()))
+		if i == m.cursor {
+			line = m.theme.TableRowSelected.Render(line)
+		}
+		sb.WriteString(line + "\n")
+		if i == m.cursor {
+			for _, d := range details(r) {
+				sb.WriteString("    " + m.theme.Subtle.Render(d) + "\n")
+			}
+		}
+	}
+	sb.WriteString("\n")
+
+	hints := []key.Binding{m.keys.Up, m.keys.Down, m.keys.Refresh, m.keys.Back}
+	sb.WriteString(components.RenderStatusBar(m.theme.HelpKey, m.theme.HelpDesc, m.theme.Subtle, hints, "w window  / filter"))
+
+	return sb.String()
+}
+
+// created formats an RFC 3339 creation time in local time.
+func created(s string) string {
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return s
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+// details returns the extra lines shown under the selected request.
+func details(r azure.AssignmentRequest) []string {
+	var out []string
+	if d := r.DurationDisplay(); d != "" {
+		out = append(out, "duration: "+d)
+	}
+	if r.ApprovedBy != "" {
+		out = append(out, "approved by: "+r.ApprovedBy)
+	}
+	if r.Justification != "" {
+		out = append(out, "justification: "+r.Justification)
+	}
+	return out
+}
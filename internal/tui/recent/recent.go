@@ -67,12 +67,15 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 				}
 				fav := state.Favorite{
 					Label:            a.Role + " @ " + scopeLabel,
+					Tenant:           a.Tenant,
 					Role:             a.Role,
 					Scope:            reactivateScope,
 					EligibilityScope: a.EligibilityScope,
 					ScheduleID:       a.ScheduleID,
 					Duration:         a.Duration,
 					Justification:    a.Justification,
+					Ticket:           a.Ticket,
+					TicketSystem:     a.TicketSystem,
 				}
 				return m, func() tea.Msg { return ActivateMsg{Favorite: fav} }
 			}
@@ -104,10 +107,16 @@ func (m Model) View() string {
 				scopeStr = a.Scope
 			}
 			header := fmt.Sprintf("%s  %s  %s  %s", a.Role, scopeStr, a.Duration, age)
+			if a.Tenant != "" {
+				header += "  " + m.store.TenantLabel(a.Tenant)
+			}
 			sb.WriteString(cursor + m.theme.Bold.Render(header) + "\n")
 			if a.Justification != "" {
 				sb.WriteString("    " + m.theme.Subtle.Render(a.Justification) + "\n")
 			}
+			if a.Ticket != "" {
+				sb.WriteString("    " + m.theme.Subtle.Render("ticket "+a.Ticket) + "\n")
+			}
 		}
 	}
 
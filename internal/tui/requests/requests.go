@@ -0,0 +1,201 @@
+package requests
+
+import (
+	"fmt"
+	"strings"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"github.com/jeircul/pim/internal/azure"
+	"github.com/jeircul/pim/internal/tui/components"
+	"github.com/jeircul/pim/internal/tui/styles"
+)
+
+// DoneMsg is sent when the user navigates back from the requests screen.
+// Pending is the number of outstanding requests at the last refresh.
+type DoneMsg struct{ Pending int }
+
+type loadMsg struct {
+	reqs []azure.AssignmentRequest
+	err  error
+}
+
+type cancelResultMsg struct {
+	req azure.AssignmentRequest
+	err error
+}
+
+// Model is the pending requests screen.
+type Model struct {
+	theme      styles.Theme
+	keys       styles.KeyMap
+	spinner    components.Spinner
+	reqs       []azure.AssignmentRequest
+	cursor     int
+	loading    bool
+	confirming bool
+	cancelling bool
+	err        error
+	notice     string
+	noticeErr  bool
+	width      int
+	height     int
+	loadFunc   func() ([]azure.AssignmentRequest, error)
+	cancelFunc func(azure.AssignmentRequest) error
+}
+
+// New creates a pending requests Model.
+func New(
+	theme styles.Theme,
+	keys styles.KeyMap,
+	loadFunc func() ([]azure.AssignmentRequest, error),
+	cancelFunc func(azure.AssignmentRequest) error,
+) Model {
+	return Model{
+		theme:      theme,
+		keys:       keys,
+		spinner:    components.NewSpinner(theme.Active),
+		loading:    true,
+		loadFunc:   loadFunc,
+		cancelFunc: cancelFunc,
+	}
+}
+
+// Init starts the spinner and triggers data load.
+func (m Model) Init() tea.Cmd {
+	return tea.Batch(m.spinner.Init(), m.load())
+}
+
+func (m Model) load() tea.Cmd {
+	fn := m.loadFunc
+	return func() tea.Msg {
+		reqs, err := fn()
+		return loadMsg{reqs: reqs, err: err}
+	}
+}
+
+// Update handles messages.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case loadMsg:
+		m.loading = false
+		m.err = msg.err
+		m.reqs = msg.reqs
+		if m.cursor >= len(m.reqs) {
+			m.cursor = max(len(m.reqs)-1, 0)
+		}
+
+	case cancelResultMsg:
+		m.cancelling = false
+		if msg.err != nil {
+			m.notice = fmt.Sprintf("cancel %s @ %s: %v", msg.req.RoleName, msg.req.ScopeDisplay, msg.err)
+			m.noticeErr = true
+			return m, nil
+		}
+		m.notice = fmt.Sprintf("cancelled %s @ %s", msg.req.RoleName, msg.req.ScopeDisplay)
+		m.noticeErr = false
+		m.loading = true
+		return m, tea.Batch(m.spinner.Init(), m.load())
+
+	case tea.KeyPressMsg:
+		if m.loading || m.cancelling {
+			break
+		}
+		if m.confirming {
+			m.confirming = false
+			if msg.String() == "y" && m.cursor < len(m.reqs) {
+				req := m.reqs[m.cursor]
+				fn := m.cancelFunc
+				m.cancelling = true
+				return m, tea.Batch(m.spinner.Init(), func() tea.Msg {
+					return cancelResultMsg{req: req, err: fn(req)}
+				})
+			}
+			break
+		}
+		m.notice = ""
+		switch {
+		case key.Matches(msg, m.keys.Back), msg.String() == "esc", msg.String() == "q":
+			pending := len(m.reqs)
+			return m, func() tea.Msg { return DoneMsg{Pending: pending} }
+		case key.Matches(msg, m.keys.Up):
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case key.Matches(msg, m.keys.Down):
+			if m.cursor < len(m.reqs)-1 {
+				m.cursor++
+			}
+		case msg.String() == "x":
+			if len(m.reqs) > 0 {
+				m.confirming = true
+			}
+		case key.Matches(msg, m.keys.Refresh):
+			m.loading = true
+			return m, tea.Batch(m.spinner.Init(), m.load())
+		}
+
+	default:
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+	}
+
+	return m, nil
+}
+
+// View renders the pending requests screen.
+func (m Model) View() string {
+	var sb strings.Builder
+
+	sb.WriteString(m.theme.Title.Render("Pending requests") + "\n\n")
+
+	if m.loading {
+		sb.WriteString(m.spinner.View() + " loading…\n")
+		return sb.String()
+	}
+
+	if m.err != nil {
+		sb.WriteString(m.theme.DangerText.Render("error: "+m.err.Error()) + "\n")
+		return sb.String()
+	}
+
+	if len(m.reqs) == 0 {
+		sb.WriteString(m.theme.Subtle.Render("  No pending requests.") + "\n")
+	}
+	for i, r := range m.reqs {
+		scope := azure.DefaultScopeDisplay(r.Scope, r.ScopeDisplay)
+		line := fmt.Sprintf("  %-30s %-30s %-14s %s", r.RoleName, scope, r.RequestType, m.theme.Tag.Render(r.StatusDisplay()))
+		if i == m.cursor {
+			line = m.theme.TableRowSelected.Render(line)
+		}
+		sb.WriteString(line + "\n")
+		if i == m.cursor && r.Justification != "" {
+			sb.WriteString("    " + m.theme.Subtle.Render(r.Justification) + "\n")
+		}
+	}
+	sb.WriteString("\n")
+
+	switch {
+	case m.cancelling:
+		sb.WriteString(m.spinner.View() + " cancelling…\n\n")
+	case m.confirming && m.cursor < len(m.reqs):
+		r := m.reqs[m.cursor]
+		sb.WriteString(m.theme.DangerText.Render(fmt.Sprintf("Cancel %s @ %s? (y/n)", r.RoleName, r.ScopeDisplay)) + "\n\n")
+	case m.notice != "":
+		style := m.theme.Active
+		if m.noticeErr {
+			style = m.theme.DangerText
+		}
+		sb.WriteString(style.Render(m.notice) + "\n\n")
+	}
+
+	hints := []key.Binding{m.keys.Up, m.keys.Down, m.keys.Refresh, m.keys.Back}
+	sb.WriteString(components.RenderStatusBar(m.theme.HelpKey, m.theme.HelpDesc, m.theme.Subtle, hints, "x cancel request"))
+
+	return sb.String()
+}
@@ -0,0 +1,203 @@
+package roledetail
+
+import (
+	"fmt"
+	"strings"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"github.com/jeircul/pim/internal/azure"
+	"github.com/jeircul/pim/internal/tui/components"
+	"github.com/jeircul/pim/internal/tui/styles"
+)
+
+// CloseMsg is sent when the user closes the role detail view.
+type CloseMsg struct{}
+
+type loadMsg struct {
+	def *azure.RoleDefinition
+	err error
+}
+
+// Model shows the permissions a role grants, with a filter box.
+type Model struct {
+	theme     styles.Theme
+	keys      styles.KeyMap
+	spinner   components.Spinner
+	roleName  string
+	def       *azure.RoleDefinition
+	loading   bool
+	err       error
+	filter    string
+	filtering bool
+	offset    int
+	height    int
+	loadFunc  func() (*azure.RoleDefinition, error)
+}
+
+// New creates a role detail Model for roleName; loadFunc fetches its definition.
+func New(theme styles.Theme, keys styles.KeyMap, roleName string, height int, loadFunc func() (*azure.RoleDefinition, error)) Model {
+	return Model{
+		theme:    theme,
+		keys:     keys,
+		spinner:  components.NewSpinner(theme.Active),
+		roleName: roleName,
+		loading:  true,
+		height:   height,
+		loadFunc: loadFunc,
+	}
+}
+
+// Init starts the spinner and fetches the role definition.
+func (m Model) Init() tea.Cmd {
+	fn := m.loadFunc
+	return tea.Batch(m.spinner.Init(), func() tea.Msg {
+		def, err := fn()
+		return loadMsg{def: def, err: err}
+	})
+}
+
+// Editing reports whether the filter text field is active.
+func (m Model) Editing() bool { return m.filtering }
+
+// Update handles messages.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+
+	case loadMsg:
+		m.loading = false
+		m.def = msg.def
+		m.err = msg.err
+
+	case tea.KeyPressMsg:
+		if m.filtering {
+			return m.updateFilter(msg), nil
+		}
+		switch {
+		case msg.String() == "esc" && m.filter != "":
+			m.filter = ""
+			m.offset = 0
+		case key.Matches(msg, m.keys.Back), msg.String() == "esc", msg.String() == "q", key.Matches(msg, m.keys.Details):
+			return m, func() tea.Msg { return CloseMsg{} }
+		case msg.String() == "/":
+			if !m.loading {
+				m.filtering = true
+			}
+		case key.Matches(msg, m.keys.Up):
+			if m.offset > 0 {
+				m.offset--
+			}
+		case key.Matches(msg, m.keys.Down):
+			if m.offset < len(m.lines())-m.visibleRows() {
+				m.offset++
+			}
+		}
+
+	default:
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m Model) updateFilter(msg tea.KeyPressMsg) Model {
+	switch msg.String() {
+	case "enter":
+		m.filtering = false
+	case "esc":
+		m.filter = ""
+		m.filtering = false
+	case "backspace":
+		if len(m.filter) > 0 {
+			m.filter = m.filter[:len(m.filter)-1]
+		}
+	case "space":
+		m.filter += " "
+	default:
+		if r := msg.String(); len(r) == 1 {
+			m.filter += r
+		}
+	}
+	m.offset = 0
+	return m
+}
+
+// lines returns the permission sections after filtering.
+func (m Model) lines() []string {
+	if m.def == nil {
+		return nil
+	}
+	def := m.def.Filter(m.filter)
+	var out []string
+	for _, sec := range []struct {
+		title string
+		perms []string
+	}{
+		{"Actions", def.Actions},
+		{"Not actions", def.NotActions},
+		{"Data actions", def.DataActions},
+		{"Not data actions", def.NotDataActions},
+	} {
+		out = append(out, m.theme.Title.Render(fmt.Sprintf("%s (%d)", sec.title, len(sec.perms))))
+		if len(sec.perms) == 0 {
+			out = append(out, m.theme.Subtle.Render("  none"))
+		}
+		for _, p := range sec.perms {
+			out = append(out, "  "+p)
+		}
+	}
+	return out
+}
+
+// visibleRows returns how many permission lines fit; all of them when the
+// height is unknown.
+func (m Model) visibleRows() int {
+	if m.height == 0 {
+		return len(m.lines())
+	}
+	return max(1, m.height-10)
+}
+
+// View renders the role detail view.
+func (m Model) View() string {
+	var sb strings.Builder
+
+	if m.loading {
+		sb.WriteString(m.theme.Title.Render(m.roleName) + "\n\n")
+		sb.WriteString(m.spinner.View() + " loading role definition…\n")
+		return sb.String()
+	}
+	if m.err != nil {
+		sb.WriteString(m.theme.Title.Render(m.roleName) + "\n\n")
+		sb.WriteString(m.theme.DangerText.Render("error: "+m.err.Error()) + "\n\n")
+		sb.WriteString(components.RenderStatusBar(m.theme.HelpKey, m.theme.HelpDesc, m.theme.Subtle, []key.Binding{m.keys.Back}, ""))
+		return sb.String()
+	}
+
+	sb.WriteString(m.theme.Title.Render(m.def.Name) + " " + m.theme.Tag.Render(m.def.TypeDisplay()) + "\n")
+	if m.def.Description != "" {
+		sb.WriteString(m.theme.Subtle.Render(m.def.Description) + "\n")
+	}
+	switch {
+	case m.filtering:
+		sb.WriteString(m.theme.Subtle.Render("Filter: ") + m.filter + "█\n")
+	case m.filter != "":
+		sb.WriteString(m.theme.Subtle.Render("Filter: "+m.filter+"  (esc clear)") + "\n")
+	default:
+		sb.WriteString("\n")
+	}
+
+	lines := m.lines()
+	end := min(m.offset+m.visibleRows(), len(lines))
+	for _, l := range lines[min(m.offset, end):end] {
+		sb.WriteString(l + "\n")
+	}
+
+	sb.WriteString("\n")
+	hints := []key.Binding{m.keys.Up, m.keys.Down, m.keys.Back}
+	sb.WriteString(components.RenderStatusBar(m.theme.HelpKey, m.theme.HelpDesc, m.theme.Subtle, hints, "/ filter"))
+	return sb.String()
+}
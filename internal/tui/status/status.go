@@ -8,13 +8,15 @@ import (
 	tea "charm.land/bubbletea/v2"
 	"github.com/jeircul/pim/internal/azure"
 	"github.com/jeircul/pim/internal/tui/components"
+	"github.com/jeircul/pim/internal/tui/roledetail"
 	"github.com/jeircul/pim/internal/tui/styles"
 )
 
 // CancelMsg is sent when the user navigates back from the status screen.
 type CancelMsg struct{}
 
-// LoadMsg carries the result of the status data fetch.
+// LoadMsg carries the result of the status data fetch. Active may include
+// scheduled assignments; they are listed in their own section.
 type LoadMsg struct {
 	Active   []azure.ActiveAssignment
 	Eligible []azure.Role
@@ -23,17 +25,23 @@ type LoadMsg struct {
 
 // Model is the status screen.
 type Model struct {
-	theme    styles.Theme
-	keys     styles.KeyMap
-	spinner  components.Spinner
-	active   []azure.ActiveAssignment
-	eligible []azure.Role
-	loading  bool
-	err      error
-	cursor   int
-	width    int
-	height   int
-	loadFunc func() ([]azure.ActiveAssignment, []azure.Role, error)
+	theme     styles.Theme
+	keys      styles.KeyMap
+	spinner   components.Spinner
+	active    []azure.ActiveAssignment
+	scheduled []azure.ActiveAssignment
+	eligible  []azure.Role
+	loading   bool
+	err       error
+	cursor    int
+	width     int
+	height    int
+	loadFunc  func() ([]azure.ActiveAssignment, []azure.Role, error)
+	// loadDefinition fetches a role's permissions for the detail view; nil
+	// disables it.
+	loadDefinition func(scope, roleDefinitionID string) (*azure.RoleDefinition, error)
+	detail         roledetail.Model
+	showDetail     bool
 }
 
 // New creates a status Model.
@@ -41,16 +49,39 @@ func New(
 	theme styles.Theme,
 	keys styles.KeyMap,
 	loadFunc func() ([]azure.ActiveAssignment, []azure.Role, error),
+	loadDefinition func(scope, roleDefinitionID string) (*azure.RoleDefinition, error),
 ) Model {
 	return Model{
-		theme:    theme,
-		keys:     keys,
-		spinner:  components.NewSpinner(theme.Active),
-		loading:  true,
-		loadFunc: loadFunc,
+		theme:          theme,
+		keys:           keys,
+		spinner:        components.NewSpinner(theme.Active),
+		loading:        true,
+		loadFunc:       loadFunc,
+		loadDefinition: loadDefinition,
 	}
 }
 
+// Editing reports whether the detail view's filter field is active.
+func (m Model) Editing() bool { return m.showDetail && m.detail.Editing() }
+
+// selected returns the role name, scope and role definition ID of the row
+// under the cursor.
+func (m Model) selected() (name, scope, roleDefinitionID string, ok bool) {
+	i := m.cursor
+	for _, list := range [][]azure.ActiveAssignment{m.active, m.scheduled} {
+		if i < len(list) {
+			a := list[i]
+			return a.RoleName, a.Scope, a.RoleDefinitionID, true
+		}
+		i -= len(list)
+	}
+	if i < len(m.eligible) {
+		r := m.eligible[i]
+		return r.RoleName, r.Scope, r.RoleDefinitionID, true
+	}
+	return "", "", "", false
+}
+
 // Init starts the spinner and triggers data load.
 func (m Model) Init() tea.Cmd {
 	if m.loadFunc == nil {
@@ -67,6 +98,16 @@ func (m Model) Init() tea.Cmd {
 
 // Update handles messages.
 func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	if m.showDetail {
+		if _, ok := msg.(roledetail.CloseMsg); ok {
+			m.showDetail = false
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.detail, cmd = m.detail.Update(msg)
+		return m, cmd
+	}
+
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
@@ -75,7 +116,14 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	case LoadMsg:
 		m.loading = false
 		m.err = msg.Err
-		m.active = msg.Active
+		m.active, m.scheduled = nil, nil
+		for _, a := range msg.Active {
+			if a.IsScheduled() {
+				m.scheduled = append(m.scheduled, a)
+			} else {
+				m.active = append(m.active, a)
+			}
+		}
 		m.eligible = msg.Eligible
 		m.cursor = 0
 
@@ -88,10 +136,21 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 				m.cursor--
 			}
 		case key.Matches(msg, m.keys.Down):
-			maxCursor := len(m.active) + len(m.eligible) - 1
+			maxCursor := len(m.active) + len(m.scheduled) + len(m.eligible) - 1
 			if m.cursor < maxCursor {
 				m.cursor++
 			}
+		case key.Matches(msg, m.keys.Details):
+			name, scope, id, ok := m.selected()
+			if !ok || m.loadDefinition == nil || m.loading {
+				break
+			}
+			fn := m.loadDefinition
+			m.detail = roledetail.New(m.theme, m.keys, name, m.height, func() (*azure.RoleDefinition, error) {
+				return fn(scope, id)
+			})
+			m.showDetail = true
+			return m, m.detail.Init()
 		case key.Matches(msg, m.keys.Refresh):
 			if m.loadFunc == nil {
 				break
@@ -117,6 +176,9 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 
 // View renders the status screen.
 func (m Model) View() string {
+	if m.showDetail {
+		return m.detail.View()
+	}
 	var sb strings.Builder
 
 	if m.loading {
@@ -147,6 +209,21 @@ func (m Model) View() string {
 		}
 	}
 
+	if len(m.scheduled) > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(m.theme.Title.Render("Scheduled") + "\n")
+		for _, a := range m.scheduled {
+			selected := row == m.cursor
+			scope := azure.DefaultScopeDisplay(a.Scope, a.ScopeDisplay)
+			line := fmt.Sprintf("  %-40s %-30s %s", a.RoleName, scope, m.theme.Tag.Render(a.ExpiryDisplay()))
+			if selected {
+				line = m.theme.TableRowSelected.Render(line)
+			}
+			sb.WriteString(line + "\n")
+			row++
+		}
+	}
+
 	sb.WriteString("\n")
 	sb.WriteString(m.theme.Title.Render("Eligible") + "\n")
 	if len(m.eligible) == 0 {
@@ -155,7 +232,14 @@ func (m Model) View() string {
 		for _, r := range m.eligible {
 			selected := row == m.cursor
 			scope := azure.DefaultScopeDisplay(r.Scope, r.ScopeDisplay)
-			line := fmt.Sprintf("  %-40s %s", r.RoleName, scope)
+			line := fmt.Sprintf("  %-40s %-30s", r.RoleName, scope)
+			if exp := r.EligibilityExpiryDisplay(); exp != "" {
+				style := m.theme.Subtle
+				if r.IsEligibilityExpiring() {
+					style = m.theme.DangerText
+				}
+				line += " " + style.Render(exp)
+			}
 			if selected {
 				line = m.theme.TableRowSelected.Render(line)
 			}
@@ -166,6 +250,9 @@ func (m Model) View() string {
 
 	sb.WriteString("\n")
 	hints := []key.Binding{m.keys.Up, m.keys.Down, m.keys.Refresh, m.keys.Back}
+	if m.loadDefinition != nil {
+		hints = append(hints, m.keys.Details)
+	}
 	sb.WriteString(components.RenderStatusBar(m.theme.HelpKey, m.theme.HelpDesc, m.theme.Subtle, hints, ""))
 
 	return sb.String()
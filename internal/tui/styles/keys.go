@@ -13,10 +13,14 @@ type KeyMap struct {
 	Dashboard  key.Binding
 	Favorites  key.Binding
 	Recent     key.Binding
+	Requests   key.Binding
+	Tenants    key.Binding
+	History    key.Binding
 	Up         key.Binding
 	Down       key.Binding
 	Enter      key.Binding
 	Refresh    key.Binding
+	Details    key.Binding
 }
 
 // DefaultKeyMap returns the application-wide default keybindings.
@@ -57,6 +61,18 @@ var DefaultKeyMap = KeyMap{
 		key.WithKeys("R"),
 		key.WithHelp("R", "recent"),
 	),
+	Requests: key.NewBinding(
+		key.WithKeys("p"),
+		key.WithHelp("p", "requests"),
+	),
+	Tenants: key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "tenant"),
+	),
+	History: key.NewBinding(
+		key.WithKeys("h"),
+		key.WithHelp("h", "history"),
+	),
 	Up: key.NewBinding(
 		key.WithKeys("up", "k"),
 		key.WithHelp("↑/k", "up"),
@@ -73,4 +89,8 @@ var DefaultKeyMap = KeyMap{
 		key.WithKeys("r"),
 		key.WithHelp("r", "refresh"),
 	),
+	Details: key.NewBinding(
+		key.WithKeys("i"),
+		key.WithHelp("i", "permissions"),
+	),
 }
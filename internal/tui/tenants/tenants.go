@@ -0,0 +1,182 @@
+package tenants
+
+import (
+	"fmt"
+	"strings"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"github.com/jeircul/pim/internal/azure"
+	"github.com/jeircul/pim/internal/state"
+	"github.com/jeircul/pim/internal/tui/components"
+	"github.com/jeircul/pim/internal/tui/styles"
+)
+
+// SelectMsg is sent when the user picks a tenant. An empty TenantID selects
+// the tenant pim started in.
+type SelectMsg struct {
+	TenantID string
+	Label    string
+}
+
+// DoneMsg is sent when the user leaves the switcher without picking a tenant.
+type DoneMsg struct{}
+
+type loadMsg struct {
+	tenants []azure.Tenant
+	err     error
+}
+
+// entry is one row of the switcher.
+type entry struct {
+	id     string
+	label  string
+	domain string
+}
+
+// Model is the tenant switcher screen. It lists the startup tenant, the
+// tenants configured in config.toml, and the tenants the account can access.
+type Model struct {
+	theme    styles.Theme
+	keys     styles.KeyMap
+	spinner  components.Spinner
+	current  string
+	entries  []entry
+	cursor   int
+	loading  bool
+	err      error
+	width    int
+	height   int
+	loadFunc func() ([]azure.Tenant, error)
+}
+
+// New creates a tenant switcher Model. current is the active tenant ID.
+func New(
+	theme styles.Theme,
+	keys styles.KeyMap,
+	current string,
+	configured []state.Tenant,
+	loadFunc func() ([]azure.Tenant, error),
+) Model {
+	m := Model{
+		theme:    theme,
+		keys:     keys,
+		spinner:  components.NewSpinner(theme.Active),
+		current:  current,
+		loading:  true,
+		loadFunc: loadFunc,
+	}
+	m.entries = append(m.entries, entry{label: "startup tenant"})
+	for _, t := range configured {
+		m.entries = append(m.entries, entry{id: t.ID, label: t.Display()})
+	}
+	return m
+}
+
+// Init starts the spinner and lists accessible tenants.
+func (m Model) Init() tea.Cmd {
+	fn := m.loadFunc
+	return tea.Batch(m.spinner.Init(), func() tea.Msg {
+		tenants, err := fn()
+		return loadMsg{tenants: tenants, err: err}
+	})
+}
+
+// Update handles messages.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case loadMsg:
+		m.loading = false
+		m.err = msg.err
+		m.merge(msg.tenants)
+
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, m.keys.Back), msg.String() == "q":
+			return m, func() tea.Msg { return DoneMsg{} }
+		case key.Matches(msg, m.keys.Up):
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case key.Matches(msg, m.keys.Down):
+			if m.cursor < len(m.entries)-1 {
+				m.cursor++
+			}
+		case key.Matches(msg, m.keys.Enter):
+			e := m.entries[m.cursor]
+			return m, func() tea.Msg { return SelectMsg{TenantID: e.id, Label: e.label} }
+		}
+
+	default:
+		if m.loading {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+	}
+	return m, nil
+}
+
+// merge fills in names and domains for configured tenants and appends the
+// accessible tenants that are not configured.
+func (m *Model) merge(tenants []azure.Tenant) {
+	for _, t := range tenants {
+		found := false
+		for i := range m.entries {
+			if m.entries[i].id != "" && strings.EqualFold(m.entries[i].id, t.ID) {
+				if m.entries[i].label == m.entries[i].id && t.DisplayName != "" {
+					m.entries[i].label = t.DisplayName
+				}
+				m.entries[i].domain = t.DefaultDomain
+				found = true
+			}
+		}
+		if !found {
+			label := t.DisplayName
+			if label == "" {
+				label = t.ID
+			}
+			m.entries = append(m.entries, entry{id: t.ID, label: label, domain: t.DefaultDomain})
+		}
+	}
+}
+
+// View renders the tenant switcher.
+func (m Model) View() string {
+	var sb strings.Builder
+
+	sb.WriteString(m.theme.Title.Render("Switch tenant") + "\n\n")
+
+	for i, e := range m.entries {
+		cur := "  "
+		if i == m.cursor {
+			cur = m.theme.TableRowSelected.Render("▸") + " "
+		}
+		line := cur + m.theme.Bold.Render(fmt.Sprintf("%-30s", e.label))
+		detail := e.id
+		if e.domain != "" {
+			detail += "  " + e.domain
+		}
+		line += m.theme.Subtle.Render("  " + detail)
+		if strings.EqualFold(e.id, m.current) {
+			line += "  " + m.theme.Tag.Render("current")
+		}
+		sb.WriteString(line + "\n")
+	}
+	sb.WriteString("\n")
+
+	switch {
+	case m.loading:
+		sb.WriteString(m.spinner.View() + " listing tenants…\n\n")
+	case m.err != nil:
+		sb.WriteString(m.theme.DangerText.Render("list tenants: "+m.err.Error()) + "\n\n")
+	}
+
+	hints := []key.Binding{m.keys.Up, m.keys.Down, m.keys.Enter, m.keys.Back}
+	sb.WriteString(components.RenderStatusBar(m.theme.HelpKey, m.theme.HelpDesc, m.theme.Subtle, hints, ""))
+	return sb.String()
+}
@@ -8,7 +8,6 @@ import (
 	"os"
 
 	"github.com/jeircul/pim/internal/app"
-	"github.com/jeircul/pim/internal/azure"
 	"github.com/jeircul/pim/internal/completion"
 	"github.com/jeircul/pim/internal/headless"
 	"github.com/jeircul/pim/internal/tui"
@@ -19,15 +18,15 @@ var Version = "dev"
 
 func main() {
 	if err := run(); err != nil {
-		if errors.Is(err, azure.ErrUserCancelled) {
+		code := app.ExitCode(err)
+		switch {
+		case code == app.ExitCancelled:
 			fmt.Fprintln(os.Stderr, "cancelled")
-			os.Exit(130)
-		}
-		if errors.Is(err, tui.ErrSilent) {
-			os.Exit(1)
+		case errors.Is(err, tui.ErrSilent):
+		default:
+			fmt.Fprintln(os.Stderr, err)
 		}
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		os.Exit(code)
 	}
 }
 
@@ -62,15 +61,30 @@ func run() error {
 		return nil
 	}
 
+	if cfg.Command == app.CmdDoctor {
+		ctx, cancel := app.DefaultContext(cfg)
+		defer cancel()
+		return headless.Doctor(ctx, cfg, Version, os.Stdout)
+	}
+
 	a, err := app.New(cfg, Version)
 	if err != nil {
 		return err
 	}
+	defer a.Close()
+
+	if cfg.Command == app.CmdCache {
+		if err := a.Store.ClearCache(); err != nil {
+			return err
+		}
+		fmt.Println("Cache cleared.")
+		return nil
+	}
 
-	ctx, cancel := app.DefaultContext()
+	ctx, cancel := app.DefaultContext(cfg)
 	defer cancel()
 
-	if cfg.IsHeadless() || cfg.Command == app.CmdSearch {
+	if !cfg.RunsTUI() {
 		if err := a.Connect(ctx); err != nil {
 			return err
 		}